@@ -23,6 +23,8 @@ import (
 	"github.com/alva-ai/cozeloop-go/internal/logger"
 	"github.com/alva-ai/cozeloop-go/internal/prompt"
 	"github.com/alva-ai/cozeloop-go/internal/trace"
+	"github.com/alva-ai/cozeloop-go/internal/util"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
 )
 
 // Client interface of loop client.
@@ -37,6 +39,9 @@ type Client interface {
 	GetWorkspaceID() string
 	// Close close the client. Should be called before program exit.
 	Close(ctx context.Context)
+	// RotateAPIToken replaces the api token without recreating the client. In-flight requests keep the old token,
+	// and new requests use the new one. Only supported when the client is created with api token.
+	RotateAPIToken(newToken string) error
 }
 
 type Option func(o *options)
@@ -66,9 +71,45 @@ type options struct {
 	traceFinishEventProcessor  func(ctx context.Context, info *FinishEventInfo)
 	traceTagTruncateConf       *TagTruncateConf
 	traceQueueConf             *TraceQueueConf
+	traceMaxSpansPerTrace      int
+	gcpResourceDetector        bool
+	spanNameFormatter          func(name, spanType string) string
+	largeInputAsFileThreshold  int
+	inheritedAttributes        []string
+	httpBodyRedactor           func(body []byte, contentType string) []byte
+	attributeNameValidator     func(key string) error
+	fieldEncryption            *trace.FieldEncryption
+	exporterOptions            []trace.ExporterOption
+	latencyBudgets             map[string]time.Duration
+	cardinalityLimit           int
+	uploadMultiModalContent    bool
+	spanNameCollisionWarn      bool
+	onSpanFinish               func(span *entity.UploadSpan)
+	onSpanFinishAsync          func(span *entity.UploadSpan)
+	maxVectorResultsInSpan     int
+	hallucinationThreshold     float64
+	contextWindowWarnThreshold float64
+	compressionMinRatio        float64
+	spanProcessors             []trace.SpanProcessor
+	spanPooling                bool
+	queueSize                  int
+	queueOverflowStrategy      OverflowStrategy
+	traceGroupMaxAge           time.Duration
+	leakDetection              bool
+	modelDriftCallback         func(model, oldFP, newFP string)
+	samplingLogExporter        *trace.SamplingLogExporter
+	gpuMetricsCollector        trace.GPUMetricsCollector
+	sampler                    trace.Sampler
+	errorAwareDropFilter       bool
+	samplingWeightTag          bool
+	codeLocationOnAllSpans     bool
+	promptCacheDiscounts       map[string]float64
+	agentMemorySerializer      func(mem interface{}) tracespec.AgentMemory
+	spanFactory                SpanFactory
 
 	localFileExportEnabled bool
 	localFileExportPath    string
+	debugExportEnabled     bool
 }
 
 func (o *options) MD5() string {
@@ -92,8 +133,44 @@ func (o *options) MD5() string {
 	h.Write([]byte(fmt.Sprintf("%p", o.traceFinishEventProcessor) + separator))
 	h.Write([]byte(fmt.Sprintf("%p", o.traceTagTruncateConf) + separator))
 	h.Write([]byte(fmt.Sprintf("%p", o.traceQueueConf) + separator))
+	h.Write([]byte(fmt.Sprintf("%d", o.traceMaxSpansPerTrace) + separator))
+	h.Write([]byte(fmt.Sprintf("%v", o.gcpResourceDetector) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.spanNameFormatter) + separator))
+	h.Write([]byte(fmt.Sprintf("%d", o.largeInputAsFileThreshold) + separator))
+	h.Write([]byte(strings.Join(o.inheritedAttributes, ",") + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.httpBodyRedactor) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.attributeNameValidator) + separator))
+	h.Write([]byte(o.fieldEncryption.Fingerprint() + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.exporterOptions) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.latencyBudgets) + separator))
+	h.Write([]byte(fmt.Sprintf("%d", o.cardinalityLimit) + separator))
+	h.Write([]byte(fmt.Sprintf("%v", o.uploadMultiModalContent) + separator))
+	h.Write([]byte(fmt.Sprintf("%v", o.spanNameCollisionWarn) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.onSpanFinish) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.onSpanFinishAsync) + separator))
+	h.Write([]byte(fmt.Sprintf("%d", o.maxVectorResultsInSpan) + separator))
+	h.Write([]byte(fmt.Sprintf("%v", o.hallucinationThreshold) + separator))
+	h.Write([]byte(fmt.Sprintf("%v", o.contextWindowWarnThreshold) + separator))
+	h.Write([]byte(fmt.Sprintf("%v", o.compressionMinRatio) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.spanProcessors) + separator))
+	h.Write([]byte(fmt.Sprintf("%v", o.spanPooling) + separator))
+	h.Write([]byte(fmt.Sprintf("%d", o.queueSize) + separator))
+	h.Write([]byte(o.traceGroupMaxAge.String() + separator))
+	h.Write([]byte(fmt.Sprintf("%d", o.queueOverflowStrategy) + separator))
+	h.Write([]byte(fmt.Sprintf("%v", o.leakDetection) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.modelDriftCallback) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.samplingLogExporter) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.gpuMetricsCollector) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.sampler) + separator))
+	h.Write([]byte(fmt.Sprintf("%v", o.errorAwareDropFilter) + separator))
+	h.Write([]byte(fmt.Sprintf("%v", o.samplingWeightTag) + separator))
+	h.Write([]byte(fmt.Sprintf("%v", o.codeLocationOnAllSpans) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.promptCacheDiscounts) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.agentMemorySerializer) + separator))
+	h.Write([]byte(fmt.Sprintf("%p", o.spanFactory) + separator))
 	h.Write([]byte(fmt.Sprintf("%v", o.localFileExportEnabled) + separator))
 	h.Write([]byte(o.localFileExportPath + separator))
+	h.Write([]byte(fmt.Sprintf("%v", o.debugExportEnabled) + separator))
 	return hex.EncodeToString(h.Sum(nil))
 }
 
@@ -141,6 +218,7 @@ func NewClient(opts ...Option) (Client, error) {
 
 	c := &loopClient{
 		workspaceID: options.workspaceID,
+		spanFactory: options.spanFactory,
 	}
 	httpClient := httpclient.NewClient(options.apiBaseURL, options.httpClient, auth,
 		&httpclient.ClientOptions{
@@ -148,6 +226,7 @@ func NewClient(opts ...Option) (Client, error) {
 			UploadTimeout:  options.uploadTimeout,
 			HeaderEnricher: createTraceHeaderEnricher(),
 		})
+	c.httpClient = httpClient
 	traceFinishEventProcessor := trace.DefaultFinishEventProcessor
 	if options.traceFinishEventProcessor != nil {
 		traceFinishEventProcessor = func(ctx context.Context, info *consts.FinishEventInfo) {
@@ -161,6 +240,10 @@ func NewClient(opts ...Option) (Client, error) {
 		spanUploadPath = options.apiBasePath.TraceSpanUploadPath
 		fileUploadPath = options.apiBasePath.TraceFileUploadPath
 	}
+	var resourceTags map[string]string
+	if options.gcpResourceDetector {
+		resourceTags = trace.DetectGCPResource(context.Background(), options.httpClient)
+	}
 	c.traceProvider = trace.NewTraceProvider(httpClient, trace.Options{
 		WorkspaceID:            options.workspaceID,
 		UltraLargeReport:       options.ultraLargeReport,
@@ -170,8 +253,43 @@ func NewClient(opts ...Option) (Client, error) {
 		SpanUploadPath:         spanUploadPath,
 		FileUploadPath:         fileUploadPath,
 		QueueConf:              (*trace.QueueConf)(options.traceQueueConf),
+		MaxSpansPerTrace:       options.traceMaxSpansPerTrace,
+		ResourceTags:           resourceTags,
+		SpanNameFormatter:      options.spanNameFormatter,
+		InputFileThreshold:     options.largeInputAsFileThreshold,
+		InheritedTagKeys:       options.inheritedAttributes,
+		HTTPBodyRedactor:       options.httpBodyRedactor,
+		TagKeyValidator:        options.attributeNameValidator,
+		FieldEncryption:        options.fieldEncryption,
+		ExporterOptions:        options.exporterOptions,
+		LatencyBudgets:         options.latencyBudgets,
+		CardinalityLimit:       options.cardinalityLimit,
+		UploadMultiModalData:   options.uploadMultiModalContent,
+		SpanNameCollisionWarn:  options.spanNameCollisionWarn,
+		OnSpanFinish:           options.onSpanFinish,
+		OnSpanFinishAsync:      options.onSpanFinishAsync,
+		MaxVectorResults:       options.maxVectorResultsInSpan,
+		HallucinationThreshold: options.hallucinationThreshold,
+		ContextWindowThreshold: options.contextWindowWarnThreshold,
+		CompressionMinRatio:    options.compressionMinRatio,
+		SpanProcessors:         options.spanProcessors,
+		SpanPooling:            options.spanPooling,
+		QueueSize:              options.queueSize,
+		TraceGroupMaxAge:       options.traceGroupMaxAge,
+		QueueOverflowStrategy:  options.queueOverflowStrategy,
+		LeakDetection:          options.leakDetection,
+		ModelDriftCallback:     options.modelDriftCallback,
+		SamplingLogExporter:    options.samplingLogExporter,
+		GPUMetricsCollector:    options.gpuMetricsCollector,
+		Sampler:                options.sampler,
+		ErrorAwareDropFilter:   options.errorAwareDropFilter,
+		SamplingWeightTag:      options.samplingWeightTag,
+		CodeLocationOnAllSpans: options.codeLocationOnAllSpans,
+		PromptCacheDiscounts:   options.promptCacheDiscounts,
+		AgentMemorySerializer:  options.agentMemorySerializer,
 		LocalFileExportEnabled: options.localFileExportEnabled,
 		LocalFileExportPath:    options.localFileExportPath,
+		DebugExportEnabled:     options.debugExportEnabled,
 	})
 	c.promptProvider = prompt.NewPromptProvider(httpClient, c.traceProvider, prompt.Options{
 		WorkspaceID:                options.workspaceID,
@@ -291,12 +409,148 @@ func WithPromptTrace(enable bool) Option {
 }
 
 // WithExporter set custom trace exporter.
-func WithExporter(e trace.Exporter) Option {
+func WithExporter(e Exporter) Option {
 	return func(p *options) {
 		p.exporter = e
 	}
 }
 
+// WithSpanProcessor add a processor called when spans are started and finished, such as enriching spans with
+// tags on start. Processors are called in the order added, and before the BatchSpanProcessor exporting spans
+// by the exporter set by WithExporter.
+func WithSpanProcessor(p SpanProcessor) Option {
+	return func(o *options) {
+		if p != nil {
+			o.spanProcessors = append(o.spanProcessors, p)
+		}
+	}
+}
+
+// WithSpanPooling set whether to reuse spans converted for export and their tag maps after they are exported
+// successfully, which reduces GC pressure of services generating many spans. Default is false. Enable it only
+// if the exporter set by WithExporter does not retain spans passed to ExportSpans after it returns, such as
+// queueing them to export asynchronously, as they are cleared and reused for later spans.
+func WithSpanPooling(enable bool) Option {
+	return func(p *options) {
+		p.spanPooling = enable
+	}
+}
+
+// WithLeakDetection set whether to warn on spans garbage-collected without Finish, such as forgetting
+// `defer span.Finish(ctx)`. The warning contains span name, type, start time and the stack of StartSpan.
+// Default is false, as capturing stack on every StartSpan is expensive, enable it in development or tests.
+func WithLeakDetection(enable bool) Option {
+	return func(p *options) {
+		p.leakDetection = enable
+	}
+}
+
+// WithModelDriftCallback set the callback called when the fingerprint set by Span.SetModelFingerprint changes
+// between consecutive finished spans of the same model name, which detects silent update of model by provider.
+// It is called synchronously in Span.Finish, so it should return quickly.
+func WithModelDriftCallback(fn func(model, oldFP, newFP string)) Option {
+	return func(p *options) {
+		p.modelDriftCallback = fn
+	}
+}
+
+// WithSamplerLogger set the SamplingLogExporter logging why each span is kept or dropped on StartSpan,
+// such as by upstream sampling decision or WithMaxSpansPerTrace, which helps debugging sampling config.
+func WithSamplerLogger(e *SamplingLogExporter) Option {
+	return func(p *options) {
+		p.samplingLogExporter = e
+	}
+}
+
+// WithGPUMetricsCollector set the collector of GPU metrics, which are set on spans of type model on finish
+// unless set by Span.SetGPUMetrics. Use NewNvidiaSMICollector for NVIDIA GPUs, which samples nvidia-smi at an
+// interval, or implement GPUMetricsCollector by NVML bindings for readings at each span finish.
+func WithGPUMetricsCollector(collector GPUMetricsCollector) Option {
+	return func(p *options) {
+		p.gpuMetricsCollector = collector
+	}
+}
+
+// WithSampler set the sampler deciding whether to keep traces when local root spans are started. Default keeps
+// all traces. Spans of traces with sampling decision from upstream, such as traceparent header, are not sampled again.
+func WithSampler(s Sampler) Option {
+	return func(p *options) {
+		p.sampler = s
+	}
+}
+
+// WithErrorAwareDropFilter enables the second phase of NewErrorAwareSampler, which drops spans without error on
+// finish if they are not sampled by the base sampler, so that spans with error are always exported.
+// It is a client option rather than an exporter wrapper: the default exporter is created inside the SDK and cannot
+// be wrapped, and a wrapper only sees spans after they are converted for export, so spans to be dropped would still
+// upload their large input files and reach span processors. The status of span is final on finish, so dropping
+// there has the same result as dropping on export.
+func WithErrorAwareDropFilter() Option {
+	return func(p *options) {
+		p.errorAwareDropFilter = true
+	}
+}
+
+// WithPromptCacheDiscounts set the discount of each prompt cache read token by model name, such as
+// {"claude-sonnet-4": 0.0000027}. Span.EffectiveCost subtracts llm.cache_read_tokens * discount of
+// the model set by SetModelName from the cost.
+func WithPromptCacheDiscounts(discounts map[string]float64) Option {
+	return func(p *options) {
+		p.promptCacheDiscounts = discounts
+	}
+}
+
+// WithSamplingWeightTag set whether to set `sampling.weight` on spans of traces sampled by the ratio sampler set
+// by WithSampler, which is 1/ratio, so that aggregate metrics such as total cost can be estimated by multiplying
+// sampled values by their weight. Spans kept by NewErrorAwareSampler because of errors have weight 1.0.
+func WithSamplingWeightTag(enable bool) Option {
+	return func(p *options) {
+		p.samplingWeightTag = enable
+	}
+}
+
+// WithCodeLocationOnAllSpans set `code.function`, `code.filepath` and `code.lineno` of the caller of StartSpan on
+// every span, the same as calling Span.SetCodeLocation after StartSpan. Frames of the SDK are skipped.
+func WithCodeLocationOnAllSpans() Option {
+	return func(p *options) {
+		p.codeLocationOnAllSpans = true
+	}
+}
+
+// WithAgentMemorySerializer set the function adapting memory of custom type passed to Span.SetCustomAgentMemory,
+// such as memory of an agent framework, to tracespec.AgentMemory.
+func WithAgentMemorySerializer(fn func(mem interface{}) tracespec.AgentMemory) Option {
+	return func(p *options) {
+		p.agentMemorySerializer = fn
+	}
+}
+
+// WithSpanFactory set the factory creating spans returned by StartSpan instead of the SDK, used to inject
+// spans such as mock.MockSpan in tests. IDs passed to the factory are resolved from options and the parent span.
+// Spans created by the factory are not processed or exported by the SDK.
+func WithSpanFactory(f SpanFactory) Option {
+	return func(p *options) {
+		p.spanFactory = f
+	}
+}
+
+// WithGlobalLatencyBudgets set the default latency budget of span types, such as {"model": 2 * time.Second}.
+// If the duration of span exceeds the budget on finish, `slo.violated` and `slo.excess_micros` are set.
+// The budget of a single span can be overridden by Span.SetLatencyBudget.
+func WithGlobalLatencyBudgets(budgets map[string]time.Duration) Option {
+	return func(p *options) {
+		p.latencyBudgets = budgets
+	}
+}
+
+// WithExporterOptions set options of the default exporter which exports spans to cozeloop server,
+// such as WithExportSignature. They are ignored if custom exporter is set by WithExporter.
+func WithExporterOptions(opts ...ExporterOption) Option {
+	return func(p *options) {
+		p.exporterOptions = append(p.exporterOptions, opts...)
+	}
+}
+
 // WithTraceFinishEventProcessor set custom finish event processor, after span finish.
 func WithTraceFinishEventProcessor(f func(ctx context.Context, info *FinishEventInfo)) Option {
 	return func(p *options) {
@@ -311,12 +565,232 @@ func WithTraceTagTruncateConf(conf *TagTruncateConf) Option {
 	}
 }
 
+// WithQueueSize set the size of span queue waiting for export, which is rounded up to a power of two.
+// It overrides TraceQueueConf.SpanQueueLength. Default is 1024.
+func WithQueueSize(n int) Option {
+	return func(p *options) {
+		p.queueSize = n
+	}
+}
+
+// WithQueueOverflowStrategy set the behavior of Span.Finish when the span queue is full.
+// Default is OverflowDrop, which drops the span and counts it by DroppedSpansTotal.
+// OverflowBlock applies backpressure to callers of Span.Finish instead of losing spans.
+func WithQueueOverflowStrategy(s OverflowStrategy) Option {
+	return func(p *options) {
+		p.queueOverflowStrategy = s
+	}
+}
+
+// WithTraceGroupedBatching set the span queue to hold finished spans until the local root span of their trace,
+// which has no parent span in this process, is finished, and export spans of a trace together, so that the server
+// receives complete traces. Traces whose local root span is not finished within maxTraceAge are exported without it.
+// Held spans take the capacity of span queue, and a large trace is still split into batches by the batch limits.
+// Flush exports all held spans.
+func WithTraceGroupedBatching(maxTraceAge time.Duration) Option {
+	return func(p *options) {
+		p.traceGroupMaxAge = maxTraceAge
+	}
+}
+
 func WithTraceQueueConf(conf *TraceQueueConf) Option {
 	return func(p *options) {
 		p.traceQueueConf = conf
 	}
 }
 
+// WithMaxSpansPerTrace set the max count of open spans in one trace.
+// When exceeded, StartSpan returns a noop span, which protects against runaway recursion of agents.
+// The count is decreased when span finished. Default is 0, means no limit.
+func WithMaxSpansPerTrace(n int) Option {
+	return func(p *options) {
+		p.traceMaxSpansPerTrace = n
+	}
+}
+
+// WithGCPResourceDetector detects resource of Google Cloud Run / GCE from metadata server when creating client,
+// and adds cloud.provider, cloud.region, faas.name and faas.version as system tags to every span.
+// It takes at most 500ms, and does nothing if metadata server is unreachable.
+func WithGCPResourceDetector() Option {
+	return func(p *options) {
+		p.gcpResourceDetector = true
+	}
+}
+
+// WithLargeInputAsFile set the threshold of input/output size in bytes. When input or output of span, including
+// multi-modality content, exceeds it, the content is exported as a file by Exporter.ExportFiles with TosKey like
+// <traceID>/<spanID>/input, and the field of span is replaced with reference `file:<TosKey>`. Default is 0,
+// means disabled.
+// Note that input/output are truncated when set if exceeding 1MB, unless WithUltraLargeTraceReport is enabled.
+func WithLargeInputAsFile(thresholdBytes int) Option {
+	return func(p *options) {
+		p.largeInputAsFileThreshold = thresholdBytes
+	}
+}
+
+// WithInheritedAttributes set the tag keys which child span inherits from parent span in context when StartSpan,
+// such as model_provider. Tags set on child span explicitly override the inherited ones.
+func WithInheritedAttributes(keys ...string) Option {
+	return func(p *options) {
+		p.inheritedAttributes = append(p.inheritedAttributes, keys...)
+	}
+}
+
+// WithHTTPBodyRedactor set the redactor applied to body by Span.SetHTTPRequestBody and Span.SetHTTPResponseBody.
+// contentType is empty for response body. Default is DefaultHTTPBodyRedactor, which replaces values of keys
+// matching password, token, secret, etc. in JSON body with [REDACTED].
+func WithHTTPBodyRedactor(fn func(body []byte, contentType string) []byte) Option {
+	return func(p *options) {
+		p.httpBodyRedactor = fn
+	}
+}
+
+// WithAttributeNameValidator set the validator of tag keys, which is called every time tags are set on span,
+// including by SetTags and convenience setters such as SetModelName. If fn returns an error, the tag is rejected
+// and a warning is logged. See SnakeCaseValidator.
+func WithAttributeNameValidator(fn func(key string) error) Option {
+	return func(p *options) {
+		p.attributeNameValidator = fn
+	}
+}
+
+// WithCardinalityLimiter caps the number of unique string values of each tag key. Once a key has maxUniqueValues
+// values, new values are replaced by `_other_` and counted by CardinalityLimitedValuesTotal. Values of input, output,
+// error, call_options, user_id, message_id and thread_id are never limited. Default is 0, means no limit.
+// At most 1024 tag keys are tracked and never forgotten, tags of new keys beyond it are dropped and counted by
+// CardinalityLimitedKeysTotal, which limits tag keys of high cardinality, such as user ids in tag keys.
+func WithCardinalityLimiter(maxUniqueValues int) Option {
+	return func(p *options) {
+		p.cardinalityLimit = maxUniqueValues
+	}
+}
+
+// WithSpanNameCollisionWarner logs a warning when a span has the same name as another child of its parent
+// in the same trace, which makes the trace hard to read. Default is disabled.
+func WithSpanNameCollisionWarner() Option {
+	return func(p *options) {
+		p.spanNameCollisionWarn = true
+	}
+}
+
+// WithOnSpanFinish set the callback called synchronously with every finished span, just before it is handed to
+// the export pipeline, such as for alerting on error spans. Keep it fast, since it blocks the goroutine calling
+// Finish. Panics in fn are recovered and logged.
+func WithOnSpanFinish(fn func(span *entity.UploadSpan)) Option {
+	return func(p *options) {
+		p.onSpanFinish = fn
+	}
+}
+
+// WithOnSpanFinishAsync set the callback called in a new goroutine with a copy of every finished span, so that
+// slow callbacks don't block the goroutine calling Finish. Panics in fn are recovered and logged.
+func WithOnSpanFinishAsync(fn func(span *entity.UploadSpan)) Option {
+	return func(p *options) {
+		p.onSpanFinishAsync = fn
+	}
+}
+
+// WithMaxVectorResultsInSpan set the max number of results of the highest scores kept by Span.SetVectorSearchResults.
+// Default is 0, means all results are kept as long as they fit in the size limit of tag value.
+func WithMaxVectorResultsInSpan(k int) Option {
+	return func(p *options) {
+		p.maxVectorResultsInSpan = k
+	}
+}
+
+// WithContextWindowWarningThreshold set the ratio of context window used, above which Span.SetContextWindow
+// sets `llm.context_window_warning` and logs a warning. Default is 0.9.
+func WithContextWindowWarningThreshold(threshold float64) Option {
+	return func(p *options) {
+		p.contextWindowWarnThreshold = threshold
+	}
+}
+
+// WithContextCompressionThreshold set the expected min compression ratio of context, that is original / compressed
+// tokens. Span.SetContextCompression sets `context.compression_below_min` and logs a warning if the ratio is lower.
+// Default is 0, means no warning.
+func WithContextCompressionThreshold(minRatio float64) Option {
+	return func(p *options) {
+		p.compressionMinRatio = minRatio
+	}
+}
+
+// WithHallucinationThreshold set the threshold of score set by Span.SetHallucinationScore, spans of scores
+// above it are marked as error with message `hallucination detected`. Default is 0, means no span is marked.
+func WithHallucinationThreshold(threshold float64) Option {
+	return func(p *options) {
+		p.hallucinationThreshold = threshold
+	}
+}
+
+// WithUploadMultiModalContent set whether to upload Data of image and audio inputs set by Span.SetMultiModalInputs
+// as attachments of input. Default is false, only the sizes are recorded.
+func WithUploadMultiModalContent(enable bool) Option {
+	return func(p *options) {
+		p.uploadMultiModalContent = enable
+	}
+}
+
+// WithFieldEncryption encrypts input, output and tags of tagKeys by encryptor before spans are exported,
+// for deployments requiring sensitive content encrypted at rest. Encrypted value is formatted as
+// enc:<keyID>:<base64 ciphertext>, keyID identifies the key for decryption and key rotation, see DecryptFieldValue.
+// Non-string tags of tagKeys are exported as encrypted string tags. Large input/output exported as text files are
+// encrypted too. Fields failed to encrypt are cleared. See NewAESEncryptor.
+func WithFieldEncryption(keyID string, encryptor Encryptor, tagKeys ...string) Option {
+	return func(p *options) {
+		if encryptor == nil {
+			p.fieldEncryption = nil
+			return
+		}
+		p.fieldEncryption = &trace.FieldEncryption{
+			KeyID:     keyID,
+			Encryptor: encryptor,
+			TagKeys:   tagKeys,
+		}
+	}
+}
+
+// WithSpanNameFormatter set the formatter of span name, which is applied to the name passed to StartSpan.
+// It is used to avoid high-cardinality span names, such as names containing user id. See TemplateSpanNameFormatter.
+func WithSpanNameFormatter(fn func(name, spanType string) string) Option {
+	return func(p *options) {
+		p.spanNameFormatter = fn
+	}
+}
+
+// TemplateSpanNameFormatter returns a span name formatter for WithSpanNameFormatter,
+// which strips non-printable characters and truncates span name to maxLen characters.
+func TemplateSpanNameFormatter(maxLen int) func(name, spanType string) string {
+	return trace.TemplateSpanNameFormatter(maxLen)
+}
+
+// SnakeCaseValidator returns a validator for WithAttributeNameValidator, which rejects keys
+// with uppercase letters or spaces.
+func SnakeCaseValidator() func(key string) error {
+	return trace.SnakeCaseValidator()
+}
+
+// CardinalityLimitedValuesTotal returns the value of counter `cozeloop.cardinality_limited_values_total`,
+// which is the count of tag values replaced by `_other_` by WithCardinalityLimiter of all clients in process.
+func CardinalityLimitedValuesTotal() int64 {
+	return trace.GetCardinalityLimitedValuesTotal()
+}
+
+// CardinalityLimitedKeysTotal returns the value of counter `cozeloop.cardinality_limited_keys_total`,
+// which is the count of tags dropped because their keys exceed the limit of WithCardinalityLimiter of all clients
+// in process.
+func CardinalityLimitedKeysTotal() int64 {
+	return trace.GetCardinalityLimitedKeysTotal()
+}
+
+// DefaultHTTPBodyRedactor is the default redactor of WithHTTPBodyRedactor, which replaces values of sensitive keys
+// in JSON body with [REDACTED]. Each value of body of multiple JSON values, such as NDJSON, is redacted, and the whole
+// body is replaced with [REDACTED] if data after the first value can not be parsed. It can be wrapped by custom redactor
+// to redact more fields.
+func DefaultHTTPBodyRedactor(body []byte, contentType string) []byte {
+	return trace.DefaultHTTPBodyRedactor(body, contentType)
+}
+
 // WithLocalFileExport enables or disables local file export.
 // When enabled, spans are exported to both the server and a local markdown file.
 // Default is false.
@@ -326,6 +800,15 @@ func WithLocalFileExport(enabled bool) Option {
 	}
 }
 
+// WithDebugExport enables or disables printing colored span summaries to stderr, for development.
+// It can also be enabled by environment COZELOOP_DEBUG=true. Colors are disabled if stderr is not a terminal.
+// Default is false.
+func WithDebugExport(enabled bool) Option {
+	return func(p *options) {
+		p.debugExportEnabled = enabled
+	}
+}
+
 // WithLocalFileExportPath sets the path for local file export.
 // Default is "./cozeloop_traces.md".
 func WithLocalFileExportPath(path string) Option {
@@ -344,6 +827,11 @@ func Close(ctx context.Context) {
 	getDefaultClient().Close(ctx)
 }
 
+// RotateAPIToken replaces the api token of default client.
+func RotateAPIToken(newToken string) error {
+	return getDefaultClient().RotateAPIToken(newToken)
+}
+
 // GetPrompt get prompt by prompt key and version
 func GetPrompt(ctx context.Context, param GetPromptParam, options ...GetPromptOption) (*entity.Prompt, error) {
 	return getDefaultClient().GetPrompt(ctx, param, options...)
@@ -364,6 +852,21 @@ func StartSpan(ctx context.Context, name, spanType string, opts ...StartSpanOpti
 	return getDefaultClient().StartSpan(ctx, name, spanType, opts...)
 }
 
+// GroupSpans Create a synthetic span grouping spans by default client.
+func GroupSpans(ctx context.Context, groupName string, spans []Span) (GroupSpan, error) {
+	return getDefaultClient().GroupSpans(ctx, groupName, spans)
+}
+
+// StartRetrySpan Start a span of an attempt among retries by default client.
+func StartRetrySpan(ctx context.Context, name, spanType string, attempt, maxAttempts int) (context.Context, Span) {
+	return getDefaultClient().StartRetrySpan(ctx, name, spanType, attempt, maxAttempts)
+}
+
+// NextAgentRound Increment the round of agentic loop in ctx by default client.
+func NextAgentRound(ctx context.Context) context.Context {
+	return getDefaultClient().NextAgentRound(ctx)
+}
+
 // GetSpanFromContext Get the span from the context.
 func GetSpanFromContext(ctx context.Context) Span {
 	return getDefaultClient().GetSpanFromContext(ctx)
@@ -407,6 +910,9 @@ func buildOptionsFromEnv(opts *options) {
 	if localFileExportPath := os.Getenv(EnvLocalFileExportPath); localFileExportPath != "" {
 		opts.localFileExportPath = localFileExportPath
 	}
+	if debug := os.Getenv(EnvDebug); debug != "" {
+		opts.debugExportEnabled = strings.ToLower(debug) == "true"
+	}
 }
 
 func checkOptions(opts *options) error {
@@ -514,8 +1020,10 @@ var (
 type loopClient struct {
 	traceProvider  *trace.Provider
 	promptProvider *prompt.Provider
+	httpClient     *httpclient.Client
 
 	workspaceID string
+	spanFactory SpanFactory
 
 	closed bool
 }
@@ -532,6 +1040,13 @@ func (c *loopClient) Close(ctx context.Context) {
 	c.closed = true
 }
 
+func (c *loopClient) RotateAPIToken(newToken string) error {
+	if c.closed {
+		return consts.ErrClientClosed
+	}
+	return c.httpClient.RotateToken(newToken)
+}
+
 func (c *loopClient) GetPrompt(ctx context.Context, param GetPromptParam, options ...GetPromptOption) (*entity.Prompt, error) {
 	if c.closed {
 		return nil, consts.ErrClientClosed
@@ -579,23 +1094,115 @@ func (c *loopClient) StartSpan(ctx context.Context, name, spanType string, opts
 		}
 		opt(&config)
 	}
+	if c.spanFactory != nil {
+		return c.startFactorySpan(ctx, name, spanType, config)
+	}
 	ctx, span, err := c.traceProvider.StartSpan(ctx, name, spanType, config)
 	if err != nil {
 		logger.CtxWarnf(ctx, "start span failed, return noop span. %v", err)
 		return ctx, DefaultNoopSpan
 	}
+	if span == nil {
+		return ctx, DefaultNoopSpan
+	}
+	s := &loopSpan{Span: span, client: c}
+	return context.WithValue(ctx, loopSpanKey{}, s), s
+}
+
+// loopSpanKey is the context key of the loopSpan returned by StartSpan, so that GetSpanFromContext
+// returns the same value instead of wrapping the internal span again.
+type loopSpanKey struct{}
+
+type factorySpanKey struct{}
+
+// startFactorySpan starts a span by spanFactory, the parent is the span in ctx created by spanFactory,
+// or the remote span extracted by propagator.
+func (c *loopClient) startFactorySpan(ctx context.Context, name, spanType string, config trace.StartSpanOptions) (context.Context, Span) {
+	var parent SpanContext
+	if span, ok := ctx.Value(factorySpanKey{}).(Span); ok {
+		parent = span
+	} else if remote := trace.RemoteSpanContextFromContext(ctx); remote != nil {
+		parent = remote
+	}
+	if parent != nil && !config.StartNewTrace {
+		if config.TraceID == "" {
+			config.TraceID = parent.GetTraceID()
+		}
+		if config.ParentSpanID == "" {
+			config.ParentSpanID = parent.GetSpanID()
+		}
+	}
+	if config.TraceID == "" {
+		config.TraceID = util.Gen32CharID()
+	}
+	if config.SpanID == "" {
+		config.SpanID = util.Gen16CharID()
+	}
+	if config.ParentSpanID == "" {
+		config.ParentSpanID = "0"
+	}
+	span := c.spanFactory(ctx, name, spanType, config.TraceID, config.SpanID, config.ParentSpanID)
+	if span == nil {
+		return ctx, DefaultNoopSpan
+	}
+	return context.WithValue(ctx, factorySpanKey{}, span), span
+}
+
+func (c *loopClient) GroupSpans(ctx context.Context, groupName string, spans []Span) (GroupSpan, error) {
+	if c.closed {
+		return nil, consts.ErrClientClosed
+	}
+	internalSpans := make([]*trace.Span, 0, len(spans))
+	for i, span := range spans {
+		s, ok := span.(interface{ getInternalSpan() *trace.Span })
+		if !ok || s.getInternalSpan() == nil {
+			// spans dropped by sampler or span quota are noop, so is the group
+			logger.CtxDebugf(ctx, "span[%d] to group is noop, return noop group span", i)
+			return &noopGroupSpan{noopSpan: DefaultNoopSpan, spans: append([]Span(nil), spans...)}, nil
+		}
+		internalSpans = append(internalSpans, s.getInternalSpan())
+	}
+	group, err := c.traceProvider.GroupSpans(ctx, groupName, internalSpans)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return &noopGroupSpan{noopSpan: DefaultNoopSpan, spans: append([]Span(nil), spans...)}, nil
+	}
+	return &loopGroupSpan{
+		loopSpan: &loopSpan{Span: group, client: c},
+		spans:    append([]Span(nil), spans...),
+	}, nil
+}
+
+func (c *loopClient) StartRetrySpan(ctx context.Context, name, spanType string, attempt, maxAttempts int) (context.Context, Span) {
+	ctx, span := c.StartSpan(ctx, name, spanType)
+	span.SetTags(ctx, map[string]interface{}{
+		tracespec.RetryAttempt:     attempt,
+		tracespec.RetryMaxAttempts: maxAttempts,
+	})
 	return ctx, span
 }
 
+func (c *loopClient) NextAgentRound(ctx context.Context) context.Context {
+	return trace.ContextWithNextAgentRound(ctx)
+}
+
 func (c *loopClient) GetSpanFromContext(ctx context.Context) Span {
 	if c.closed {
 		return DefaultNoopSpan
 	}
+	if span, ok := ctx.Value(factorySpanKey{}).(Span); ok && c.spanFactory != nil {
+		return span
+	}
 	span := c.traceProvider.GetSpanFromContext(ctx)
 	if span == nil {
 		return DefaultNoopSpan
 	}
-	return span
+	if s, ok := ctx.Value(loopSpanKey{}).(*loopSpan); ok && s.Span == span {
+		return s
+	}
+	return &loopSpan{Span: span, client: c}
 }
 
 func (c *loopClient) GetSpanFromHeader(ctx context.Context, header map[string]string) SpanContext {
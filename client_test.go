@@ -4,8 +4,11 @@
 package cozeloop
 
 import (
+	"bytes"
+	"context"
 	"testing"
 
+	"github.com/alva-ai/cozeloop-go/tracetest"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -21,4 +24,204 @@ func TestNewClient(t *testing.T) {
 		So(client1, ShouldEqual, client2)
 		So(client1, ShouldNotEqual, client3)
 	})
+
+	Convey("new client repeatedly with field encryption of the same key", t, func() {
+		newClient := func(key byte) Client {
+			encryptor, err := NewAESEncryptor(bytes.Repeat([]byte{key}, 32))
+			So(err, ShouldBeNil)
+			client, err := NewClient(WithWorkspaceID("encryption"), WithAPIToken("token"),
+				WithFieldEncryption("key1", encryptor))
+			So(err, ShouldBeNil)
+			return client
+		}
+		So(newClient(1), ShouldEqual, newClient(1))
+		So(newClient(1), ShouldNotEqual, newClient(2))
+	})
+}
+
+func TestSpanFork(t *testing.T) {
+	Convey("fork span from a canceled context", t, func() {
+		client, err := NewClient(WithWorkspaceID("fork"), WithAPIToken("token"))
+		So(err, ShouldBeNil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx, parent := client.StartSpan(ctx, "parent", "custom")
+		cancel()
+
+		forkCtx, child := parent.Fork(ctx, "background", "custom")
+		So(forkCtx.Err(), ShouldBeNil)
+		So(child.GetTraceID(), ShouldEqual, parent.GetTraceID())
+		So(child.GetSpanID(), ShouldNotEqual, parent.GetSpanID())
+		So(client.GetSpanFromContext(forkCtx), ShouldEqual, child)
+
+		Convey("fork noop span should return noop span", func() {
+			_, span := DefaultNoopSpan.Fork(ctx, "background", "custom")
+			So(span, ShouldEqual, DefaultNoopSpan)
+		})
+	})
+}
+
+func TestGetSpanFromContext(t *testing.T) {
+	Convey("GetSpanFromContext returns the span returned by StartSpan", t, func() {
+		client, err := NewClient(WithWorkspaceID("span_from_ctx"), WithAPIToken("token"))
+		So(err, ShouldBeNil)
+
+		ctx, span := client.StartSpan(context.Background(), "parent", "custom")
+		So(client.GetSpanFromContext(ctx), ShouldEqual, span)
+		So(client.GetSpanFromContext(ctx), ShouldEqual, client.GetSpanFromContext(ctx))
+		So(client.GetSpanFromContext(client.NextAgentRound(ctx)), ShouldEqual, span)
+	})
+}
+
+func TestLogContextWithSpan(t *testing.T) {
+	Convey("LogContextWithSpan carries ids of span for logs", t, func() {
+		client, err := NewClient(WithWorkspaceID("log_ctx"), WithAPIToken("token"))
+		So(err, ShouldBeNil)
+
+		_, span := client.StartSpan(context.Background(), "parent", "custom")
+		traceID, spanID := SpanFromLogContext(LogContextWithSpan(context.Background(), span))
+		So(traceID, ShouldEqual, span.GetTraceID())
+		So(spanID, ShouldEqual, span.GetSpanID())
+
+		traceID, _ = SpanFromLogContext(LogContextWithSpan(context.Background(), DefaultNoopSpan))
+		So(traceID, ShouldBeEmpty)
+	})
+}
+
+func TestGroupSpans(t *testing.T) {
+	Convey("GroupSpans with noop spans returns noop group span", t, func() {
+		client, err := NewClient(WithWorkspaceID("group"), WithAPIToken("token"))
+		So(err, ShouldBeNil)
+
+		ctx, parent := client.StartSpan(context.Background(), "agent", "agent")
+		_, tool := client.StartSpan(ctx, "search", "tool")
+		group, err := client.GroupSpans(ctx, "tools", []Span{tool, DefaultNoopSpan})
+		So(err, ShouldBeNil)
+		So(group.GetSpanID(), ShouldBeEmpty)
+		So(group.GroupedSpans(), ShouldResemble, []Span{tool, DefaultNoopSpan})
+		So(tool.(*loopSpan).GetParentID(), ShouldEqual, parent.GetSpanID())
+	})
+}
+
+func TestChainStep(t *testing.T) {
+	Convey("start steps of pipeline under workflow span", t, func() {
+		client, err := NewClient(WithWorkspaceID("chain"), WithAPIToken("token"))
+		So(err, ShouldBeNil)
+
+		ctx, workflow := client.StartSpan(context.Background(), "etl", "workflow")
+		_, step := client.StartSpan(context.Background(), "", "custom", WithChainStepParent(workflow))
+		So(step.GetTraceID(), ShouldEqual, workflow.GetTraceID())
+		So(step.(*loopSpan).GetParentID(), ShouldEqual, workflow.GetSpanID())
+
+		step.SetChainStep(ctx, 1, "transform", 3)
+		So(step.(*loopSpan).GetSpanName(), ShouldEqual, "etl/transform")
+		So(step.(*loopSpan).GetTagMap(), ShouldContainKey, "chain.step_index")
+	})
+}
+
+func TestStartRetrySpan(t *testing.T) {
+	Convey("start spans of retry attempts under the current span", t, func() {
+		client, err := NewClient(WithWorkspaceID("retry"), WithAPIToken("token"))
+		So(err, ShouldBeNil)
+
+		ctx, parent := client.StartSpan(context.Background(), "call_llm", "custom")
+		for attempt := 1; attempt <= 2; attempt++ {
+			_, span := client.StartRetrySpan(ctx, "llm_attempt", "model", attempt, 3)
+			So(span.(*loopSpan).GetParentID(), ShouldEqual, parent.GetSpanID())
+			tags := span.(*loopSpan).GetTagMap()
+			So(tags["retry.attempt"], ShouldEqual, attempt)
+			So(tags["retry.max_attempts"], ShouldEqual, 3)
+			span.Finish(ctx)
+		}
+	})
+}
+
+func TestNextAgentRound(t *testing.T) {
+	Convey("spans of each agent round are tagged with the round", t, func() {
+		client, err := NewClient(WithWorkspaceID("agent_round"), WithAPIToken("token"))
+		So(err, ShouldBeNil)
+
+		ctx, agent := client.StartSpan(context.Background(), "agent", "agent")
+		for round := 1; round <= 2; round++ {
+			ctx = client.NextAgentRound(ctx)
+			_, span := client.StartSpan(ctx, "think", "model")
+			So(span.(*loopSpan).GetTagMap()["agent.round"], ShouldEqual, round)
+			span.Finish(ctx)
+		}
+		So(agent.(*loopSpan).GetTagMap(), ShouldNotContainKey, "agent.round")
+	})
+}
+
+type factorySpan struct {
+	noopSpan
+	traceID, spanID, parentID string
+}
+
+func (s *factorySpan) GetTraceID() string { return s.traceID }
+func (s *factorySpan) GetSpanID() string  { return s.spanID }
+
+func TestSpanFactory(t *testing.T) {
+	Convey("spans are created by span factory", t, func() {
+		var created []*factorySpan
+		client, err := NewClient(WithWorkspaceID("span_factory"), WithAPIToken("token"),
+			WithSpanFactory(func(ctx context.Context, name, spanType, traceID, spanID, parentID string) Span {
+				s := &factorySpan{traceID: traceID, spanID: spanID, parentID: parentID}
+				created = append(created, s)
+				return s
+			}))
+		So(err, ShouldBeNil)
+
+		ctx, root := client.StartSpan(context.Background(), "agent", "agent")
+		So(client.GetSpanFromContext(ctx), ShouldEqual, root)
+		_, child := client.StartSpan(ctx, "llm", "model", WithSpanID("0123456789abcdef"))
+		_, other := client.StartSpan(ctx, "other", "agent", WithStartNewTrace())
+
+		So(len(created), ShouldEqual, 3)
+		So(root, ShouldEqual, created[0])
+		So(created[0].parentID, ShouldEqual, "0")
+		So(len(created[0].traceID), ShouldEqual, 32)
+		So(len(created[0].spanID), ShouldEqual, 16)
+		So(child, ShouldEqual, created[1])
+		So(created[1].traceID, ShouldEqual, created[0].traceID)
+		So(created[1].spanID, ShouldEqual, "0123456789abcdef")
+		So(created[1].parentID, ShouldEqual, created[0].spanID)
+		So(other.GetTraceID(), ShouldNotEqual, created[0].traceID)
+		So(created[2].parentID, ShouldEqual, "0")
+	})
+}
+
+type envSpanProcessor struct {
+	ended []string
+}
+
+func (p *envSpanProcessor) OnStart(ctx context.Context, s ReadWriteSpan) {
+	s.SetTags(ctx, map[string]interface{}{"env": "test"})
+}
+
+func (p *envSpanProcessor) OnEnd(ctx context.Context, s ReadOnlySpan) {
+	p.ended = append(p.ended, s.GetSpanName())
+}
+
+func (p *envSpanProcessor) Shutdown(ctx context.Context) error   { return nil }
+func (p *envSpanProcessor) ForceFlush(ctx context.Context) error { return nil }
+
+func TestSpanProcessor(t *testing.T) {
+	Convey("span processor is called before export", t, func() {
+		ctx := context.Background()
+		processor := &envSpanProcessor{}
+		recorder := tracetest.NewRecorderExporter()
+		client, err := NewClient(WithWorkspaceID("processor"), WithAPIToken("token"),
+			WithExporter(recorder), WithSpanProcessor(processor))
+		So(err, ShouldBeNil)
+		defer client.Close(ctx)
+
+		_, span := client.StartSpan(ctx, "agent", "agent")
+		span.Finish(ctx)
+		client.Flush(ctx)
+
+		So(processor.ended, ShouldResemble, []string{"agent"})
+		spans := recorder.Spans()
+		So(len(spans), ShouldEqual, 1)
+		So(spans[0].TagsString["env"], ShouldEqual, "test")
+	})
 }
@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package cloudlogging provides an exporter writing spans to Google Cloud Logging.
+// It is a separate module, so that users not using Google Cloud don't depend on it.
+package cloudlogging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/entity"
+	"google.golang.org/api/option"
+)
+
+var _ cozeloop.Exporter = (*CloudLoggingExporter)(nil)
+
+// entryLogger is the part of logging.Logger used by CloudLoggingExporter.
+type entryLogger interface {
+	Log(e logging.Entry)
+	Flush() error
+}
+
+// CloudLoggingExporter exports spans to Google Cloud Logging, one log entry per span.
+// Trace and SpanID of entries are set, so that logs can be correlated with Cloud Trace in the Cloud Console.
+type CloudLoggingExporter struct {
+	projectID  string
+	client     *logging.Client
+	logger     entryLogger
+	clientOpts []option.ClientOption
+	loggerOpts []logging.LoggerOption
+}
+
+// CloudLoggingOption is used to set options for CloudLoggingExporter.
+type CloudLoggingOption func(e *CloudLoggingExporter)
+
+// WithClientOptions set options of logging.Client, such as option.WithCredentialsFile.
+// Default is Application Default Credentials.
+func WithClientOptions(opts ...option.ClientOption) CloudLoggingOption {
+	return func(e *CloudLoggingExporter) {
+		e.clientOpts = append(e.clientOpts, opts...)
+	}
+}
+
+// WithLoggerOptions set options of logging.Logger, such as logging.CommonResource or logging.DelayThreshold.
+// Default monitored resource is detected from the environment.
+func WithLoggerOptions(opts ...logging.LoggerOption) CloudLoggingOption {
+	return func(e *CloudLoggingExporter) {
+		e.loggerOpts = append(e.loggerOpts, opts...)
+	}
+}
+
+// NewCloudLoggingExporter creates a new CloudLoggingExporter which writes to projects/{projectID}/logs/{logName},
+// which can be set by cozeloop.WithExporter. Call Close to flush buffered entries when the exporter is not used.
+func NewCloudLoggingExporter(projectID string, logName string, opts ...CloudLoggingOption) (*CloudLoggingExporter, error) {
+	if projectID == "" {
+		return nil, cozeloop.ErrInvalidParam.Wrap(errors.New("projectID is required"))
+	}
+	if logName == "" {
+		return nil, cozeloop.ErrInvalidParam.Wrap(errors.New("logName is required"))
+	}
+	e := &CloudLoggingExporter{projectID: projectID}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	client, err := logging.NewClient(context.Background(), projectID, e.clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	client.OnError = func(err error) {
+		cozeloop.GetLogger().CtxErrorf(context.Background(), "failed to write entries to cloud logging: %v", err)
+	}
+	e.client = client
+	e.logger = client.Logger(logName, e.loggerOpts...)
+	return e, nil
+}
+
+// ExportSpans writes spans to Cloud Logging, and flushes them before return.
+// Severity is ERROR if status code of span is non-zero, otherwise INFO.
+func (e *CloudLoggingExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	count := 0
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		entry, err := e.toEntry(span)
+		if err != nil {
+			cozeloop.GetLogger().CtxErrorf(ctx, "failed to marshal span for cloud logging: %v", err)
+			continue
+		}
+		e.logger.Log(entry)
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	return e.logger.Flush()
+}
+
+// ExportFiles is a no-op for cloud logging exporter, as files are too large for log entries
+func (e *CloudLoggingExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return nil
+}
+
+// Close flushes buffered entries and closes the logging client.
+func (e *CloudLoggingExporter) Close() error {
+	if e.client == nil {
+		return nil
+	}
+	return e.client.Close()
+}
+
+func (e *CloudLoggingExporter) toEntry(span *entity.UploadSpan) (logging.Entry, error) {
+	payload, err := json.Marshal(span)
+	if err != nil {
+		return logging.Entry{}, err
+	}
+	entry := logging.Entry{
+		Severity: logging.Info,
+		Payload:  json.RawMessage(payload),
+		SpanID:   span.SpanID,
+	}
+	if span.StatusCode != 0 {
+		entry.Severity = logging.Error
+	}
+	if span.StartedATMicros > 0 {
+		entry.Timestamp = time.UnixMicro(span.StartedATMicros)
+	}
+	if span.TraceID != "" {
+		entry.Trace = fmt.Sprintf("projects/%s/traces/%s", e.projectID, span.TraceID)
+	}
+	return entry, nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cloudlogging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type fakeLogger struct {
+	entries  []logging.Entry
+	flushes  int
+	flushErr error
+}
+
+func (l *fakeLogger) Log(e logging.Entry) {
+	l.entries = append(l.entries, e)
+}
+
+func (l *fakeLogger) Flush() error {
+	l.flushes++
+	return l.flushErr
+}
+
+func TestCloudLoggingExporter(t *testing.T) {
+	Convey("CloudLoggingExporter", t, func() {
+		ctx := context.Background()
+
+		Convey("should check params", func() {
+			_, err := NewCloudLoggingExporter("", "spans")
+			So(err, ShouldNotBeNil)
+			_, err = NewCloudLoggingExporter("project", "")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("should log one entry per span and flush", func() {
+			logger := &fakeLogger{}
+			exporter := &CloudLoggingExporter{projectID: "project", logger: logger}
+
+			err := exporter.ExportSpans(ctx, []*entity.UploadSpan{
+				{TraceID: "trace1", SpanID: "span1", SpanName: "ok", StartedATMicros: 1700000000000000},
+				nil,
+				{TraceID: "trace1", SpanID: "span2", SpanName: "failed", StatusCode: 1},
+			})
+			So(err, ShouldBeNil)
+			So(logger.flushes, ShouldEqual, 1)
+			So(len(logger.entries), ShouldEqual, 2)
+			So(logger.entries[0].Severity, ShouldEqual, logging.Info)
+			So(logger.entries[0].Trace, ShouldEqual, "projects/project/traces/trace1")
+			So(logger.entries[0].SpanID, ShouldEqual, "span1")
+			So(logger.entries[0].Timestamp.Equal(time.Unix(1700000000, 0)), ShouldBeTrue)
+			So(string(logger.entries[0].Payload.(json.RawMessage)), ShouldContainSubstring, `"span_name":"ok"`)
+			So(logger.entries[1].Severity, ShouldEqual, logging.Error)
+		})
+
+		Convey("should return flush error", func() {
+			logger := &fakeLogger{flushErr: errors.New("permission denied")}
+			exporter := &CloudLoggingExporter{projectID: "project", logger: logger}
+			So(exporter.ExportSpans(ctx, []*entity.UploadSpan{{SpanID: "span1"}}), ShouldNotBeNil)
+			So(exporter.ExportSpans(ctx, nil), ShouldBeNil)
+			So(logger.flushes, ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Filter matches spans whose tag or field has the value, such as `model_name=gpt-4o`. Fields of span
+// are matched by keys name, type, status, span_id and parent_id.
+type Filter struct {
+	Key   string
+	Value string
+}
+
+// ParseFilters parses `key=value` expressions, multiple expressions in one string are separated by commas.
+func ParseFilters(exprs []string) ([]Filter, error) {
+	var filters []Filter
+	for _, expr := range exprs {
+		for _, item := range strings.Split(expr, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(item, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				return nil, fmt.Errorf("invalid filter %q, expect key=value", item)
+			}
+			filters = append(filters, Filter{Key: key, Value: strings.TrimSpace(value)})
+		}
+	}
+	return filters, nil
+}
+
+// MatchSpan reports whether span matches all filters.
+func MatchSpan(span *Span, filters []Filter) bool {
+	for _, f := range filters {
+		if spanValue(span, f.Key) != f.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchTrace reports whether any span of trace matches all filters.
+func MatchTrace(trace *Trace, filters []Filter) bool {
+	for _, span := range trace.Spans {
+		if MatchSpan(span, filters) {
+			return true
+		}
+	}
+	return false
+}
+
+func spanValue(span *Span, key string) string {
+	if v, ok := span.Tags[key]; ok {
+		return v
+	}
+	if v, ok := span.SystemTags[key]; ok {
+		return v
+	}
+	switch key {
+	case "name":
+		return span.Name
+	case "type":
+		return span.Type
+	case "status":
+		// status is written as `OK (0)` or `ERROR (1)`
+		status, _, _ := strings.Cut(span.Status, " ")
+		return status
+	case "span_id":
+		return span.SpanID
+	case "parent_id":
+		return span.ParentID
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Command cozeloop-view renders traces in the markdown file written by the local file exporter
+// in the terminal, with a scrollable trace list, expandable span trees and tag filtering.
+//
+// Usage:
+//
+//	cozeloop-view [--file ./cozeloop_traces.md] [--filter key=value]...
+package main
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+// defaultFilePath is the same as the default path of the local file exporter.
+const defaultFilePath = "./cozeloop_traces.md"
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	var (
+		filePath string
+		filters  []string
+	)
+	cmd := &cobra.Command{
+		Use:          "cozeloop-view",
+		Short:        "View traces exported to the local markdown file in the terminal",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parsedFilters, err := ParseFilters(filters)
+			if err != nil {
+				return err
+			}
+			traces, err := loadTraces(filePath)
+			if err != nil {
+				return err
+			}
+			_, err = tea.NewProgram(newModel(traces, parsedFilters), tea.WithAltScreen()).Run()
+			return err
+		},
+	}
+	cmd.Flags().StringVarP(&filePath, "file", "f", defaultFilePath, "path of the markdown file written by the local file exporter")
+	cmd.Flags().StringArrayVar(&filters, "filter", nil, "show traces having a span matching key=value, "+
+		"where key is a tag key or one of name, type, status, span_id and parent_id, can be repeated")
+	return cmd
+}
+
+func loadTraces(filePath string) ([]*Trace, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("open trace file: %w", err)
+	}
+	defer f.Close()
+	spans, err := ParseSpans(f)
+	if err != nil {
+		return nil, fmt.Errorf("parse trace file: %w", err)
+	}
+	return GroupTraces(spans), nil
+}
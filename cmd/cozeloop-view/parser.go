@@ -0,0 +1,198 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+const (
+	traceSectionPrefix = "# Trace: "
+	spanSectionPrefix  = "## Span: "
+	subSectionPrefix   = "### "
+	fieldPrefix        = "- **"
+	fieldSeparator     = ":** "
+	spanSeparator      = "---"
+	codeFence          = "```"
+)
+
+// Span is a span parsed from the markdown file written by the local file exporter.
+type Span struct {
+	TraceID    string
+	SpanID     string
+	ParentID   string
+	Name       string
+	Type       string
+	StartTime  string
+	Duration   string
+	Status     string
+	Input      string
+	Output     string
+	Tags       map[string]string
+	SystemTags map[string]string
+}
+
+// Trace is the spans of one trace, in the order of the file.
+type Trace struct {
+	ID    string
+	Spans []*Span
+}
+
+// ParseSpans parses spans from the markdown file written by the local file exporter. Sections which
+// are not recognized, such as annotations and citations, are skipped.
+func ParseSpans(r io.Reader) ([]*Span, error) {
+	var (
+		spans   []*Span
+		current *Span
+		section string
+		inCode  bool
+		code    []string
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inCode {
+			if line != codeFence {
+				code = append(code, line)
+				continue
+			}
+			inCode = false
+			if current != nil {
+				switch section {
+				case "Input":
+					current.Input = strings.Join(code, "\n")
+				case "Output":
+					current.Output = strings.Join(code, "\n")
+				}
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, traceSectionPrefix):
+			current = &Span{
+				TraceID:    strings.TrimPrefix(line, traceSectionPrefix),
+				Tags:       make(map[string]string),
+				SystemTags: make(map[string]string),
+			}
+			spans = append(spans, current)
+			section = ""
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, spanSectionPrefix):
+			current.Name = strings.TrimPrefix(line, spanSectionPrefix)
+		case strings.HasPrefix(line, subSectionPrefix):
+			section = strings.TrimPrefix(line, subSectionPrefix)
+		case strings.HasPrefix(line, codeFence):
+			inCode, code = true, nil
+		case line == spanSeparator:
+			current, section = nil, ""
+		case section == "" && strings.HasPrefix(line, fieldPrefix):
+			parseField(current, strings.TrimPrefix(line, fieldPrefix))
+		case strings.HasPrefix(line, "|"):
+			key, value, ok := parseTableRow(line)
+			if !ok {
+				continue
+			}
+			switch section {
+			case "Tags":
+				current.Tags[key] = value
+			case "System Tags":
+				current.SystemTags[key] = value
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
+
+func parseField(span *Span, field string) {
+	name, value, ok := strings.Cut(field, fieldSeparator)
+	if !ok {
+		return
+	}
+	switch name {
+	case "Type":
+		span.Type = value
+	case "Span ID":
+		span.SpanID = value
+	case "Parent ID":
+		span.ParentID = value
+	case "Start Time":
+		span.StartTime = value
+	case "Duration":
+		span.Duration = value
+	case "Status":
+		span.Status = value
+	}
+}
+
+// parseTableRow parses a row of key value table, such as `| key | value |`. The header
+// and delimiter rows are skipped.
+func parseTableRow(line string) (key, value string, ok bool) {
+	cells := splitTableRow(line)
+	if len(cells) != 2 || strings.HasPrefix(cells[0], "---") || (cells[0] == "Key" && cells[1] == "Value") {
+		return "", "", false
+	}
+	return cells[0], cells[1], true
+}
+
+// splitTableRow splits a markdown table row by unescaped `|`, and unescapes `\|` in cells.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var (
+		cells []string
+		cell  strings.Builder
+	)
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// GroupTraces groups spans by trace id, traces are ordered by their last span in the file,
+// so that the latest trace comes first.
+func GroupTraces(spans []*Span) []*Trace {
+	var traces []*Trace
+	index := make(map[string]int)
+	for _, span := range spans {
+		i, ok := index[span.TraceID]
+		if !ok {
+			i = len(traces)
+			index[span.TraceID] = i
+			traces = append(traces, &Trace{ID: span.TraceID})
+		}
+		traces[i].Spans = append(traces[i].Spans, span)
+	}
+
+	last := make(map[string]int, len(traces))
+	for i, span := range spans {
+		last[span.TraceID] = i
+	}
+	res := make([]*Trace, 0, len(traces))
+	for i := len(spans) - 1; i >= 0; i-- {
+		if last[spans[i].TraceID] == i {
+			res = append(res, traces[index[spans[i].TraceID]])
+		}
+	}
+	return res
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testTraceFile = `# Trace: trace1
+
+## Span: agent
+
+- **Type:** agent
+- **Span ID:** root
+- **Parent ID:** 0
+- **Start Time:** 2025-01-01 10:00:00.000
+- **Duration:** 2.000s
+- **Status:** OK (0)
+
+### Input
+
+` + "```" + `
+hello
+---
+world
+` + "```" + `
+
+### Tags
+
+| Key | Value |
+|-----|-------|
+| model_name | gpt\|4o |
+| user_id | u1 |
+
+---
+
+# Trace: trace2
+
+## Span: other
+
+- **Type:** tool
+- **Span ID:** other
+- **Parent ID:** 0
+- **Status:** ERROR (1)
+
+---
+
+# Trace: trace1
+
+## Span: llm
+
+- **Type:** model
+- **Span ID:** child
+- **Parent ID:** root
+- **Status:** OK (0)
+
+### System Tags
+
+| Key | Value |
+|-----|-------|
+| runtime | go |
+
+---
+
+`
+
+func TestParseSpans(t *testing.T) {
+	Convey("ParseSpans", t, func() {
+		spans, err := ParseSpans(strings.NewReader(testTraceFile))
+		So(err, ShouldBeNil)
+		So(len(spans), ShouldEqual, 3)
+		So(spans[0].TraceID, ShouldEqual, "trace1")
+		So(spans[0].Name, ShouldEqual, "agent")
+		So(spans[0].Type, ShouldEqual, "agent")
+		So(spans[0].ParentID, ShouldEqual, "0")
+		So(spans[0].Duration, ShouldEqual, "2.000s")
+		So(spans[0].Input, ShouldEqual, "hello\n---\nworld")
+		So(spans[0].Tags, ShouldResemble, map[string]string{"model_name": "gpt|4o", "user_id": "u1"})
+		So(spans[2].SystemTags, ShouldResemble, map[string]string{"runtime": "go"})
+
+		Convey("GroupTraces should put the latest trace first", func() {
+			traces := GroupTraces(spans)
+			So(len(traces), ShouldEqual, 2)
+			So(traces[0].ID, ShouldEqual, "trace1")
+			So(len(traces[0].Spans), ShouldEqual, 2)
+			So(traces[1].ID, ShouldEqual, "trace2")
+
+			rows := buildTreeRows(traces[0], map[*Span]bool{})
+			So(len(rows), ShouldEqual, 2)
+			So(rows[1].depth, ShouldEqual, 1)
+			So(rows[0].hasChildren, ShouldBeTrue)
+			So(len(buildTreeRows(traces[0], map[*Span]bool{spans[0]: true})), ShouldEqual, 1)
+		})
+
+		Convey("filters should match tags and fields", func() {
+			_, err := ParseFilters([]string{"model_name"})
+			So(err, ShouldNotBeNil)
+
+			filters, err := ParseFilters([]string{"model_name=gpt|4o, type=agent", "status=OK"})
+			So(err, ShouldBeNil)
+			So(len(filters), ShouldEqual, 3)
+			So(MatchSpan(spans[0], filters), ShouldBeTrue)
+			So(MatchSpan(spans[2], filters), ShouldBeFalse)
+
+			traces := GroupTraces(spans)
+			filters, _ = ParseFilters([]string{"status=ERROR"})
+			So(MatchTrace(traces[0], filters), ShouldBeFalse)
+			So(MatchTrace(traces[1], filters), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,399 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	defaultHeight = 24
+	detailHeight  = 10 // lines of details of the selected span in span tree view
+	chromeHeight  = 4  // lines of header and footer
+)
+
+type viewMode int
+
+const (
+	modeTraces viewMode = iota
+	modeSpans
+)
+
+// treeRow is a visible row of span tree.
+type treeRow struct {
+	span        *Span
+	depth       int
+	hasChildren bool
+}
+
+// model is the bubbletea model of the viewer, which shows the trace list, or the span tree of the opened trace.
+type model struct {
+	all     []*Trace
+	traces  []*Trace // traces matching filters
+	filters []Filter
+	mode    viewMode
+	height  int
+
+	traceCursor int
+	traceOffset int
+
+	trace      *Trace
+	rows       []treeRow
+	collapsed  map[*Span]bool
+	spanCursor int
+	spanOffset int
+
+	editing bool // editing filters
+	input   string
+	err     error
+}
+
+func newModel(traces []*Trace, filters []Filter) *model {
+	m := &model{
+		all:    traces,
+		height: defaultHeight,
+	}
+	m.applyFilters(filters)
+	return m
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+		if m.editing {
+			m.updateEditing(msg)
+			return m, nil
+		}
+		if msg.String() == "/" {
+			m.editing, m.input, m.err = true, formatFilters(m.filters), nil
+			return m, nil
+		}
+		if m.mode == modeTraces {
+			return m, m.updateTraces(msg)
+		}
+		m.updateSpans(msg)
+	}
+	return m, nil
+}
+
+func (m *model) updateEditing(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		filters, err := ParseFilters([]string{m.input})
+		if err != nil {
+			m.err = err
+			return
+		}
+		m.editing = false
+		m.applyFilters(filters)
+	case tea.KeyEsc:
+		m.editing, m.err = false, nil
+	case tea.KeyBackspace:
+		if runes := []rune(m.input); len(runes) > 0 {
+			m.input = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		m.input += " "
+	case tea.KeyRunes:
+		m.input += string(msg.Runes)
+	}
+}
+
+func (m *model) updateTraces(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "q", "esc":
+		return tea.Quit
+	case "up", "k":
+		m.traceCursor--
+	case "down", "j":
+		m.traceCursor++
+	case "pgup":
+		m.traceCursor -= m.listHeight()
+	case "pgdown":
+		m.traceCursor += m.listHeight()
+	case "enter", "right", "l":
+		if len(m.traces) > 0 {
+			m.openTrace(m.traces[m.traceCursor])
+		}
+	}
+	m.traceCursor, m.traceOffset = scroll(m.traceCursor, m.traceOffset, len(m.traces), m.listHeight())
+	return nil
+}
+
+func (m *model) updateSpans(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "q", "esc", "backspace":
+		m.mode, m.trace = modeTraces, nil
+		return
+	case "up", "k":
+		m.spanCursor--
+	case "down", "j":
+		m.spanCursor++
+	case "pgup":
+		m.spanCursor -= m.listHeight()
+	case "pgdown":
+		m.spanCursor += m.listHeight()
+	case "enter", " ", "right", "left", "l", "h":
+		if len(m.rows) > 0 && m.rows[m.spanCursor].hasChildren {
+			span := m.rows[m.spanCursor].span
+			m.collapsed[span] = !m.collapsed[span]
+			m.rows = buildTreeRows(m.trace, m.collapsed)
+		}
+	}
+	m.spanCursor, m.spanOffset = scroll(m.spanCursor, m.spanOffset, len(m.rows), m.listHeight())
+}
+
+func (m *model) openTrace(trace *Trace) {
+	m.mode, m.trace = modeSpans, trace
+	m.collapsed = make(map[*Span]bool)
+	m.rows = buildTreeRows(trace, m.collapsed)
+	m.spanCursor, m.spanOffset = 0, 0
+}
+
+func (m *model) applyFilters(filters []Filter) {
+	m.filters = filters
+	m.traces = m.traces[:0]
+	for _, trace := range m.all {
+		if MatchTrace(trace, filters) {
+			m.traces = append(m.traces, trace)
+		}
+	}
+	m.traceCursor, m.traceOffset = scroll(m.traceCursor, m.traceOffset, len(m.traces), m.listHeight())
+}
+
+func (m *model) listHeight() int {
+	height := m.height - chromeHeight
+	if m.mode == modeSpans {
+		height -= detailHeight
+	}
+	if height < 1 {
+		height = 1
+	}
+	return height
+}
+
+func (m *model) View() string {
+	sb := &strings.Builder{}
+	if m.mode == modeTraces {
+		m.viewTraces(sb)
+	} else {
+		m.viewSpans(sb)
+	}
+
+	sb.WriteString("\n")
+	switch {
+	case m.editing && m.err != nil:
+		sb.WriteString(fmt.Sprintf("filter: %s  (%v)\n", m.input, m.err))
+	case m.editing:
+		sb.WriteString(fmt.Sprintf("filter: %s█\n", m.input))
+	case m.mode == modeTraces:
+		sb.WriteString("↑/↓ move • enter open • / filter • q quit\n")
+	default:
+		sb.WriteString("↑/↓ move • enter expand/collapse • / filter • esc back • q back\n")
+	}
+	return sb.String()
+}
+
+func (m *model) viewTraces(sb *strings.Builder) {
+	sb.WriteString(fmt.Sprintf("Traces: %d/%d", len(m.traces), len(m.all)))
+	if len(m.filters) > 0 {
+		sb.WriteString("  filter: " + formatFilters(m.filters))
+	}
+	sb.WriteString("\n\n")
+	end := minInt(m.traceOffset+m.listHeight(), len(m.traces))
+	for i := m.traceOffset; i < end; i++ {
+		trace := m.traces[i]
+		root := rootSpan(trace)
+		sb.WriteString(fmt.Sprintf("%s %s  %s  %d spans  %s  %s\n", cursorMark(i == m.traceCursor),
+			trace.ID, root.Name, len(trace.Spans), root.StartTime, root.Duration))
+	}
+}
+
+func (m *model) viewSpans(sb *strings.Builder) {
+	sb.WriteString(fmt.Sprintf("Trace: %s  %d spans\n\n", m.trace.ID, len(m.trace.Spans)))
+	end := minInt(m.spanOffset+m.listHeight(), len(m.rows))
+	for i := m.spanOffset; i < end; i++ {
+		row := m.rows[i]
+		toggle := "  "
+		if row.hasChildren && m.collapsed[row.span] {
+			toggle = "▸ "
+		} else if row.hasChildren {
+			toggle = "▾ "
+		}
+		match := " "
+		if len(m.filters) > 0 && MatchSpan(row.span, m.filters) {
+			match = "*"
+		}
+		sb.WriteString(fmt.Sprintf("%s%s%s%s%s (%s) %s %s\n", cursorMark(i == m.spanCursor), match,
+			strings.Repeat("  ", row.depth), toggle, row.span.Name, row.span.Type, row.span.Duration, row.span.Status))
+	}
+
+	sb.WriteString("\n")
+	if len(m.rows) > 0 {
+		writeSpanDetail(sb, m.rows[m.spanCursor].span, detailHeight-1)
+	}
+}
+
+// writeSpanDetail writes fields and sorted tags of span in at most maxLines lines.
+func writeSpanDetail(sb *strings.Builder, span *Span, maxLines int) {
+	lines := []string{
+		fmt.Sprintf("span_id: %s  parent_id: %s  start: %s", span.SpanID, span.ParentID, span.StartTime),
+	}
+	if span.Input != "" {
+		lines = append(lines, "input: "+oneLine(span.Input))
+	}
+	if span.Output != "" {
+		lines = append(lines, "output: "+oneLine(span.Output))
+	}
+	keys := make([]string, 0, len(span.Tags))
+	for k := range span.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s", k, oneLine(span.Tags[k])))
+	}
+	if len(lines) > maxLines {
+		lines = append(lines[:maxLines-1], fmt.Sprintf("... %d more", len(lines)-maxLines+1))
+	}
+	for _, line := range lines {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+}
+
+// buildTreeRows returns visible rows of span tree of trace, children of collapsed spans are hidden.
+// Spans whose parent is not in the trace are shown as roots.
+func buildTreeRows(trace *Trace, collapsed map[*Span]bool) []treeRow {
+	spanIDs := make(map[string]struct{}, len(trace.Spans))
+	for _, span := range trace.Spans {
+		spanIDs[span.SpanID] = struct{}{}
+	}
+	children := make(map[string][]*Span)
+	var roots []*Span
+	for _, span := range trace.Spans {
+		if _, ok := spanIDs[span.ParentID]; ok && span.ParentID != span.SpanID {
+			children[span.ParentID] = append(children[span.ParentID], span)
+		} else {
+			roots = append(roots, span)
+		}
+	}
+
+	// spans in parent cycle are not reachable from roots, they are shown as roots too
+	reachable := make(map[*Span]struct{}, len(trace.Spans))
+	var mark func(span *Span)
+	mark = func(span *Span) {
+		if _, ok := reachable[span]; ok {
+			return
+		}
+		reachable[span] = struct{}{}
+		for _, child := range children[span.SpanID] {
+			mark(child)
+		}
+	}
+	for _, root := range roots {
+		mark(root)
+	}
+	for _, span := range trace.Spans {
+		if _, ok := reachable[span]; !ok {
+			roots = append(roots, span)
+			mark(span)
+		}
+	}
+
+	var rows []treeRow
+	visited := make(map[*Span]struct{}, len(trace.Spans))
+	var walk func(span *Span, depth int)
+	walk = func(span *Span, depth int) {
+		if _, ok := visited[span]; ok {
+			return
+		}
+		visited[span] = struct{}{}
+		rows = append(rows, treeRow{span: span, depth: depth, hasChildren: len(children[span.SpanID]) > 0})
+		if collapsed[span] {
+			return
+		}
+		for _, child := range children[span.SpanID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	return rows
+}
+
+// rootSpan returns the first span whose parent is not in the trace.
+func rootSpan(trace *Trace) *Span {
+	spanIDs := make(map[string]struct{}, len(trace.Spans))
+	for _, span := range trace.Spans {
+		spanIDs[span.SpanID] = struct{}{}
+	}
+	for _, span := range trace.Spans {
+		if _, ok := spanIDs[span.ParentID]; !ok {
+			return span
+		}
+	}
+	return trace.Spans[0]
+}
+
+// scroll clamps cursor to [0, count), and adjusts offset to keep cursor in the window of height.
+func scroll(cursor, offset, count, height int) (int, int) {
+	if cursor >= count {
+		cursor = count - 1
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	if cursor < offset {
+		offset = cursor
+	}
+	if cursor >= offset+height {
+		offset = cursor - height + 1
+	}
+	return cursor, offset
+}
+
+func formatFilters(filters []Filter) string {
+	items := make([]string, 0, len(filters))
+	for _, f := range filters {
+		items = append(items, f.Key+"="+f.Value)
+	}
+	return strings.Join(items, ",")
+}
+
+func cursorMark(selected bool) string {
+	if selected {
+		return ">"
+	}
+	return " "
+}
+
+func oneLine(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if runes := []rune(s); len(runes) > 100 {
+		return string(runes[:100]) + "…"
+	}
+	return s
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
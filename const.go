@@ -21,6 +21,9 @@ const (
 	EnvLocalFileExportEnabled = "COZELOOP_LOCAL_FILE_EXPORT_ENABLED"
 	EnvLocalFileExportPath    = "COZELOOP_LOCAL_FILE_EXPORT_PATH"
 
+	// environment key for debug export, prints span summaries to stderr
+	EnvDebug = "COZELOOP_DEBUG"
+
 	// ComBaseURL = consts.ComBaseURL
 	CnBaseURL = consts.CnBaseURL
 )
@@ -45,3 +48,21 @@ type APIBasePath struct {
 }
 
 type TraceQueueConf trace.QueueConf
+
+// OverflowStrategy is the behavior of Span.Finish when the span queue is full, set by WithQueueOverflowStrategy.
+type OverflowStrategy = trace.OverflowStrategy
+
+const (
+	// OverflowDrop drops the span and counts it by DroppedSpansTotal, which never blocks Span.Finish. It is the default.
+	OverflowDrop = trace.OverflowDrop
+	// OverflowBlock blocks Span.Finish until the span queue has space, the context is done or the client is closed.
+	OverflowBlock = trace.OverflowBlock
+
+	// DroppedSpansMetricName is the name of counter returned by DroppedSpansTotal.
+	DroppedSpansMetricName = trace.DroppedSpansTotal
+)
+
+// DroppedSpansTotal returns the count of spans dropped because the span queue is full, of all clients in process.
+func DroppedSpansTotal() int64 {
+	return trace.GetDroppedSpansTotal()
+}
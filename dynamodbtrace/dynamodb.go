@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package dynamodbtrace provides tracing of AWS DynamoDB table operations.
+// It is a separate module, so that users not using AWS SDK don't depend on it.
+package dynamodbtrace
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+const dbSystem = "dynamodb"
+
+// api is the operations of dynamodb.Client which are traced.
+type api interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+}
+
+// TracedDynamoDB wraps dynamodb.Client, and starts a child span of the span in ctx for each operation.
+type TracedDynamoDB struct {
+	client *dynamodb.Client
+	api    api
+	tracer cozeloop.Client
+}
+
+// WrapClient returns TracedDynamoDB wrapping c. Spans are of type database, named `DynamoDB <operation>`, with tags
+// `db.system`, `db.table_name`, `db.operation` and `aws.request_id`. If consumed capacity is requested by
+// ReturnConsumedCapacity, `db.consumed_capacity_rcu` or `db.consumed_capacity_wcu` is set. Spans of failed
+// operations are marked as error.
+func WrapClient(c *dynamodb.Client, tracer cozeloop.Client) *TracedDynamoDB {
+	return &TracedDynamoDB{
+		client: c,
+		api:    c,
+		tracer: tracer,
+	}
+}
+
+// Client returns the wrapped dynamodb.Client, for operations not traced.
+func (t *TracedDynamoDB) Client() *dynamodb.Client {
+	return t.client
+}
+
+func (t *TracedDynamoDB) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	var table *string
+	if params != nil {
+		table = params.TableName
+	}
+	ctx, span := t.startSpan(ctx, "GetItem", table)
+	out, err := t.api.GetItem(ctx, params, optFns...)
+	if out != nil {
+		setResult(ctx, span, out.ResultMetadata, out.ConsumedCapacity, false)
+	}
+	finishSpan(ctx, span, err)
+	return out, err
+}
+
+func (t *TracedDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	var table *string
+	if params != nil {
+		table = params.TableName
+	}
+	ctx, span := t.startSpan(ctx, "PutItem", table)
+	out, err := t.api.PutItem(ctx, params, optFns...)
+	if out != nil {
+		setResult(ctx, span, out.ResultMetadata, out.ConsumedCapacity, true)
+	}
+	finishSpan(ctx, span, err)
+	return out, err
+}
+
+func (t *TracedDynamoDB) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	var table *string
+	if params != nil {
+		table = params.TableName
+	}
+	ctx, span := t.startSpan(ctx, "Query", table)
+	out, err := t.api.Query(ctx, params, optFns...)
+	if out != nil {
+		setResult(ctx, span, out.ResultMetadata, out.ConsumedCapacity, false)
+	}
+	finishSpan(ctx, span, err)
+	return out, err
+}
+
+func (t *TracedDynamoDB) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	var table *string
+	if params != nil {
+		table = params.TableName
+	}
+	ctx, span := t.startSpan(ctx, "Scan", table)
+	out, err := t.api.Scan(ctx, params, optFns...)
+	if out != nil {
+		setResult(ctx, span, out.ResultMetadata, out.ConsumedCapacity, false)
+	}
+	finishSpan(ctx, span, err)
+	return out, err
+}
+
+func (t *TracedDynamoDB) startSpan(ctx context.Context, operation string, table *string) (context.Context, cozeloop.Span) {
+	ctx, span := t.tracer.StartSpan(ctx, "DynamoDB "+operation, tracespec.VDatabaseSpanType)
+	span.SetTags(ctx, map[string]interface{}{
+		tracespec.DBSystem:    dbSystem,
+		tracespec.DBTableName: aws.ToString(table),
+		tracespec.DBOperation: operation,
+	})
+	return ctx, span
+}
+
+// setResult sets request id and consumed capacity of a successful operation. Read or write capacity units are
+// preferred, and fall back to the total CapacityUnits, which is the only one returned in some cases.
+func setResult(ctx context.Context, span cozeloop.Span, metadata middleware.Metadata, capacity *types.ConsumedCapacity, isWrite bool) {
+	tags := make(map[string]interface{})
+	if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+		tags[tracespec.AWSRequestID] = requestID
+	}
+	if capacity != nil {
+		if isWrite {
+			if units := firstNonNil(capacity.WriteCapacityUnits, capacity.CapacityUnits); units != nil {
+				tags[tracespec.DBConsumedCapacityWCU] = *units
+			}
+		} else {
+			if units := firstNonNil(capacity.ReadCapacityUnits, capacity.CapacityUnits); units != nil {
+				tags[tracespec.DBConsumedCapacityRCU] = *units
+			}
+		}
+	}
+	span.SetTags(ctx, tags)
+}
+
+// finishSpan finishes span, with error and request id of the failed request if err is not nil.
+func finishSpan(ctx context.Context, span cozeloop.Span, err error) {
+	if err != nil {
+		var respErr *awshttp.ResponseError
+		if errors.As(err, &respErr) && respErr.ServiceRequestID() != "" {
+			span.SetTags(ctx, map[string]interface{}{tracespec.AWSRequestID: respErr.ServiceRequestID()})
+		}
+		span.SetError(ctx, err)
+	}
+	span.Finish(ctx)
+}
+
+func firstNonNil(values ...*float64) *float64 {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package dynamodbtrace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	"github.com/alva-ai/cozeloop-go/tracetest"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type fakeAPI struct {
+	err error
+}
+
+func resultMetadata(requestID string) middleware.Metadata {
+	md := middleware.Metadata{}
+	awsmiddleware.SetRequestIDMetadata(&md, requestID)
+	return md
+}
+
+func (f *fakeAPI) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &dynamodb.GetItemOutput{
+		ConsumedCapacity: &types.ConsumedCapacity{CapacityUnits: aws.Float64(0.5)},
+		ResultMetadata:   resultMetadata("req-get"),
+	}, nil
+}
+
+func (f *fakeAPI) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return &dynamodb.PutItemOutput{
+		ConsumedCapacity: &types.ConsumedCapacity{CapacityUnits: aws.Float64(2), WriteCapacityUnits: aws.Float64(1)},
+		ResultMetadata:   resultMetadata("req-put"),
+	}, nil
+}
+
+func (f *fakeAPI) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return &dynamodb.QueryOutput{ResultMetadata: resultMetadata("req-query")}, nil
+}
+
+func (f *fakeAPI) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return &dynamodb.ScanOutput{ResultMetadata: resultMetadata("req-scan")}, nil
+}
+
+func TestTracedDynamoDB(t *testing.T) {
+	Convey("TracedDynamoDB", t, func() {
+		ctx := context.Background()
+		recorder := tracetest.NewRecorderExporter()
+		client, err := cozeloop.NewClient(cozeloop.WithWorkspaceID("ws"), cozeloop.WithAPIToken("token"),
+			cozeloop.WithExporter(recorder))
+		So(err, ShouldBeNil)
+		defer client.Close(ctx)
+		api := &fakeAPI{}
+		db := WrapClient(nil, client)
+		db.api = api
+
+		ctx, parent := client.StartSpan(ctx, "handler", "custom")
+		_, err = db.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String("users")})
+		So(err, ShouldBeNil)
+		_, err = db.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String("users")})
+		So(err, ShouldBeNil)
+		_, err = db.Query(ctx, &dynamodb.QueryInput{TableName: aws.String("orders")})
+		So(err, ShouldBeNil)
+		_, err = db.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("orders")})
+		So(err, ShouldBeNil)
+		api.err = errors.New("throttled")
+		_, err = db.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String("users")})
+		So(err, ShouldNotBeNil)
+		parent.Finish(ctx)
+		client.Flush(ctx)
+
+		spans := recorder.Spans()
+		So(spans, ShouldHaveLength, 6)
+		get := spans[0]
+		So(get.SpanName, ShouldEqual, "DynamoDB GetItem")
+		So(get.SpanType, ShouldEqual, tracespec.VDatabaseSpanType)
+		So(get.ParentID, ShouldEqual, parent.GetSpanID())
+		So(get.TagsString[tracespec.DBSystem], ShouldEqual, "dynamodb")
+		So(get.TagsString[tracespec.DBTableName], ShouldEqual, "users")
+		So(get.TagsString[tracespec.DBOperation], ShouldEqual, "GetItem")
+		So(get.TagsString[tracespec.AWSRequestID], ShouldEqual, "req-get")
+		So(get.TagsDouble[tracespec.DBConsumedCapacityRCU], ShouldEqual, 0.5)
+
+		put := spans[1]
+		So(put.TagsDouble[tracespec.DBConsumedCapacityWCU], ShouldEqual, 1)
+		So(put.TagsDouble, ShouldNotContainKey, tracespec.DBConsumedCapacityRCU)
+		So(spans[2].TagsString[tracespec.DBOperation], ShouldEqual, "Query")
+		So(spans[2].TagsDouble, ShouldNotContainKey, tracespec.DBConsumedCapacityRCU)
+		So(spans[3].TagsString[tracespec.DBOperation], ShouldEqual, "Scan")
+
+		So(spans[4].StatusCode, ShouldNotEqual, 0)
+		So(spans[4].TagsString[tracespec.Error], ShouldContainSubstring, "throttled")
+	})
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// Encryptor encrypts and decrypts span fields, set by WithFieldEncryption.
+type Encryptor = trace.Encryptor
+
+// AESEncryptor is an Encryptor using AES-256-GCM, the random nonce is prepended to ciphertext.
+type AESEncryptor = trace.AESEncryptor
+
+// NewAESEncryptor creates an AESEncryptor with 32-byte key.
+func NewAESEncryptor(key []byte) (*AESEncryptor, error) {
+	return trace.NewAESEncryptor(key)
+}
+
+// DecryptFieldValue decrypts the field value encrypted by WithFieldEncryption, and returns the key id and plaintext.
+func DecryptFieldValue(value string, encryptor Encryptor) (keyID, plaintext string, err error) {
+	return trace.DecryptFieldValue(value, encryptor)
+}
@@ -22,6 +22,13 @@ type UploadSpan struct {
 	TagsLong         map[string]int64   `json:"tags_long"`
 	TagsDouble       map[string]float64 `json:"tags_double"`
 	TagsBool         map[string]bool    `json:"tags_bool"`
+	Annotations      []SpanAnnotation   `json:"annotations,omitempty"` // timestamped text annotations
+}
+
+// SpanAnnotation is a timestamped text annotation of span.
+type SpanAnnotation struct {
+	Timestamp int64  `json:"timestamp"` // unix time in microseconds
+	Message   string `json:"message"`
 }
 
 type UploadFile struct {
@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"crypto/tls"
+	"io"
+
+	"github.com/alva-ai/cozeloop-go/internal/httpclient"
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// Exporter exports finished spans and their large files, set by WithExporter.
+// Spans passed to ExportSpans are owned by the caller, and are cleared and reused for later spans after ExportSpans
+// returns if WithSpanPooling is enabled. Exporters retaining spans after ExportSpans returns, such as recording or
+// queueing them, must copy them, including their tag maps.
+type Exporter = trace.Exporter
+
+// FileExporter exports spans to a local markdown file, the same as WithLocalFileExport.
+type FileExporter = trace.FileExporter
+
+// NewFileExporter creates a FileExporter writing to filePath, which can be set by WithExporter.
+// Default path is ./cozeloop_traces.md if filePath is empty. Use FileExporter.DeleteSpansByUserID
+// to remove spans of a user on GDPR right-to-erasure requests.
+func NewFileExporter(filePath string, opts ...FileExporterOption) *FileExporter {
+	return trace.NewFileExporter(filePath, opts...)
+}
+
+// FileExporterOption is used to set options for FileExporter.
+type FileExporterOption = trace.FileExporterOption
+
+// WithStreamingWrite set whether FileExporter writes markdown of each span to the file through a fixed size buffer,
+// instead of building the whole markdown of span in memory first, which reduces memory of spans with large tags.
+func WithStreamingWrite(enable bool) FileExporterOption {
+	return trace.WithStreamingWrite(enable)
+}
+
+// WithGzipCompression set FileExporter to compress the file with gzip, and `.gz` is appended to the file path
+// if absent. Each batch of spans is written as a complete gzip member, so the file can be read by zcat at any time.
+func WithGzipCompression() FileExporterOption {
+	return trace.WithGzipCompression()
+}
+
+// NewGzipJSONFileExporter creates a FileExporter writing spans as gzip compressed NDJSON, one span per line,
+// which can be read by `zcat file.json.gz | jq`. Default path is ./cozeloop_traces.json.gz if filePath is empty.
+func NewGzipJSONFileExporter(filePath string) *FileExporter {
+	return trace.NewGzipJSONFileExporter(filePath)
+}
+
+// WriterExporter exports spans in markdown, or JSON lines, to an io.Writer such as os.Stdout or a bytes.Buffer.
+// It is safe for concurrent export. Buffered writers such as bufio.Writer are not flushed by it.
+type WriterExporter = trace.WriterExporter
+
+// WriterExporterOption is used to set options for WriterExporter.
+type WriterExporterOption = trace.WriterExporterOption
+
+// NewWriterExporter creates a WriterExporter writing spans to w in markdown by default, which can be set by
+// WithExporter.
+func NewWriterExporter(w io.Writer, opts ...WriterExporterOption) *WriterExporter {
+	return trace.NewWriterExporter(w, opts...)
+}
+
+// WithJSONLinesFormat set WriterExporter to write each span as a line of JSON, instead of markdown.
+func WithJSONLinesFormat() WriterExporterOption {
+	return trace.WithJSONLinesFormat()
+}
+
+// ExporterOption is used to set options for the default exporter to cozeloop server, set by WithExporterOptions.
+type ExporterOption = trace.ExporterOption
+
+// WithExportSignature set the secret to sign exported span batches with HMAC-SHA256. The signature with timestamp
+// is sent in X-Cozeloop-Signature header, which can be verified by security.VerifySignature in a relay or proxy.
+func WithExportSignature(secret []byte) ExporterOption {
+	return trace.WithExportSignature(secret)
+}
+
+// WithTLSConfig set tls config used to export spans to cozeloop server, such as client certificate for mutual TLS.
+// The default exporter sends requests through a transport using config, instead of the http client set by
+// WithHTTPClient, while other requests to cozeloop server still use that http client. See NewTLSConfigFromFiles.
+func WithTLSConfig(config *tls.Config) ExporterOption {
+	return trace.WithTLSConfig(config)
+}
+
+// NewTLSConfigFromFiles loads the certificate key pair and CA certificates from PEM files, and returns tls config
+// for mutual TLS, which can be set by WithTLSConfig. ClientAuth is set to RequireAndVerifyClientCert,
+// so that the config can also be used by mTLS server.
+func NewTLSConfigFromFiles(certFile, keyFile, caFile string) (*tls.Config, error) {
+	return httpclient.NewTLSConfigFromFiles(certFile, keyFile, caFile)
+}
+
+// AckFunc confirms that exported spans are received, spans are considered delivered only if it returns nil.
+type AckFunc = trace.AckFunc
+
+// AckExporter is an Exporter which supports acknowledgment of exported spans, for at-least-once delivery.
+// Spans are persisted in PersistentQueue set by TraceQueueConf until AckFunc returned by ExportSpansWithAck
+// is called successfully. For exporters not implementing it, spans are acknowledged if ExportSpans succeeds.
+type AckExporter = trace.AckExporter
+
+// PartialExportError is returned by Exporter if only some of spans or files failed to export. The others are
+// considered delivered, and only FailedSpans or FailedFiles are retried, so that they are not sent twice.
+type PartialExportError = trace.PartialExportError
+
+// PersistentQueue persists spans until they are acknowledged by exporter, set by TraceQueueConf.PersistentQueue.
+// Spans persisted but not acknowledged, e.g. the process exits before export, are re-exported when the next
+// client is created. Spans are persisted when their batch is exported, so spans still waiting in the span queue
+// are lost if the process crashes.
+type PersistentQueue = trace.PersistentQueue
+
+// FilePersistentQueue is a PersistentQueue which stores each span as a JSON file in a directory.
+type FilePersistentQueue = trace.FilePersistentQueue
+
+// NewFilePersistentQueue creates a FilePersistentQueue storing spans in dir, which is created if not exist.
+func NewFilePersistentQueue(dir string) (*FilePersistentQueue, error) {
+	return trace.NewFilePersistentQueue(dir)
+}
+
+// SamplingLogExporter writes one JSON line for each sampling decision made on StartSpan, with fields
+// trace_id, span_name, span_type, sampler_type, decision (keep/drop) and reason. Set it by WithSamplerLogger.
+type SamplingLogExporter = trace.SamplingLogExporter
+
+// NewSamplingLogExporter creates a SamplingLogExporter writing to w, such as os.Stderr.
+func NewSamplingLogExporter(w io.Writer) *SamplingLogExporter {
+	return trace.NewSamplingLogExporter(w)
+}
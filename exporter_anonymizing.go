@@ -0,0 +1,34 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// AnonymizationStrategy anonymizes span before export. span is a copy owned by the strategy,
+// which can be modified in place and returned. Returning nil drops the span.
+type AnonymizationStrategy = trace.AnonymizationStrategy
+
+// AnonymizingExporter applies anonymization strategies in order to copies of spans, and exports them by inner.
+type AnonymizingExporter = trace.AnonymizingExporter
+
+// GeneralizeIPStrategy replaces the last octet of IPv4 addresses in string tags and system tags with 0,
+// such as 192.168.1.23 to 192.168.1.0.
+type GeneralizeIPStrategy = trace.GeneralizeIPStrategy
+
+// HashUserIDStrategy replaces the user_id tag set by SetUserID with hex-encoded SHA-256 of Salt and user id,
+// so that spans of the same user can still be correlated. Setting Salt is recommended to resist dictionary attacks.
+type HashUserIDStrategy = trace.HashUserIDStrategy
+
+// NewAnonymizingExporter creates an AnonymizingExporter, which can be set by WithExporter.
+// Spans are cloned before anonymization, so spans exported by other exporters are not affected.
+func NewAnonymizingExporter(inner Exporter, strategies []AnonymizationStrategy) *AnonymizingExporter {
+	return trace.NewAnonymizingExporter(inner, strategies)
+}
+
+// DropTagStrategy returns a strategy which removes tags and system tags of keys, whatever type the value is.
+func DropTagStrategy(keys []string) AnonymizationStrategy {
+	return trace.DropTagStrategy(keys)
+}
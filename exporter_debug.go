@@ -0,0 +1,27 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"io"
+
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// DebugExporter writes human-readable, ANSI-colored span summaries for development, the same as WithDebugExport.
+// Spans of the same trace in a batch are printed as a tree by their parent ids.
+type DebugExporter = trace.DebugExporter
+
+// DebugOption is used to set options for DebugExporter.
+type DebugOption = trace.DebugOption
+
+// NewDebugExporter creates a DebugExporter writing to w, which is os.Stderr if nil. It can be set by WithExporter.
+func NewDebugExporter(w io.Writer, opts ...DebugOption) *DebugExporter {
+	return trace.NewDebugExporter(w, opts...)
+}
+
+// WithColors set whether to write ANSI colors. Default is true, disable it for non-terminal outputs.
+func WithColors(enable bool) DebugOption {
+	return trace.WithColors(enable)
+}
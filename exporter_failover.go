@@ -0,0 +1,34 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// FailoverExporter exports to the primary exporter, and fails over to the secondary exporter
+// if the primary returns a retryable error or times out.
+type FailoverExporter = trace.FailoverExporter
+
+// FailoverOption is used to set options for FailoverExporter.
+type FailoverOption = trace.FailoverOption
+
+// NewFailoverExporter creates a new FailoverExporter which can be set by WithExporter.
+// Errors of invalid param and 4xx responses (except 408 and 429) are not retried on secondary.
+func NewFailoverExporter(primary, secondary Exporter, opts ...FailoverOption) *FailoverExporter {
+	return trace.NewFailoverExporter(primary, secondary, opts...)
+}
+
+// WithFailoverPrimaryTimeout set the timeout of primary exporter, fail over to secondary if exceeded. Default is 30s
+func WithFailoverPrimaryTimeout(d time.Duration) FailoverOption {
+	return trace.WithFailoverPrimaryTimeout(d)
+}
+
+// WithFailoverCooldown set the duration to keep using secondary after a failover event,
+// which avoids thundering herd on primary recovery. Default is 0, means trying primary every time.
+func WithFailoverCooldown(d time.Duration) FailoverOption {
+	return trace.WithFailoverCooldown(d)
+}
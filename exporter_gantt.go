@@ -0,0 +1,18 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// GanttMarkdownExporter exports spans to a local markdown file as Gantt-chart-style timelines, one per trace
+// in each batch, which shows overlapping of parallel spans. Spans longer than 1 minute are marked with ⚠.
+type GanttMarkdownExporter = trace.GanttMarkdownExporter
+
+// NewGanttMarkdownExporter creates a GanttMarkdownExporter writing to filePath, which can be set by WithExporter.
+// Default path is ./cozeloop_timeline.md if filePath is empty.
+func NewGanttMarkdownExporter(filePath string) *GanttMarkdownExporter {
+	return trace.NewGanttMarkdownExporter(filePath)
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// LockedFileExporter exports spans to a local markdown file like FileExporter, and holds an exclusive file lock
+// (flock on Unix, LockFileEx on Windows) while writing each batch, for files shared by processes such as on NFS.
+type LockedFileExporter = trace.LockedFileExporter
+
+// LockedFileExporterOption is used to set options for LockedFileExporter.
+type LockedFileExporterOption = trace.LockedFileExporterOption
+
+// NewLockedFileExporter creates a LockedFileExporter writing to filePath, which can be set by WithExporter.
+// Default path is ./cozeloop_traces.md if filePath is empty.
+func NewLockedFileExporter(filePath string, opts ...LockedFileExporterOption) *LockedFileExporter {
+	return trace.NewLockedFileExporter(filePath, opts...)
+}
+
+// WithFileLockTimeout set the max time to wait for the file lock, the export fails instead of blocking
+// if the lock is not acquired in time. Default is 5s.
+func WithFileLockTimeout(timeout time.Duration) LockedFileExporterOption {
+	return trace.WithFileLockTimeout(timeout)
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// PayloadLimitExporter splits span batches into sub-batches whose JSON payload does not exceed the limit,
+// for gateways or firewalls rejecting large requests.
+type PayloadLimitExporter = trace.PayloadLimitExporter
+
+// NewPayloadLimitExporter creates a PayloadLimitExporter which can be set by WithExporter. Each sub-batch is
+// exported by inner separately, and only spans of failed sub-batches are retried. Spans exceeding maxBytes
+// by themselves are dropped with a warning, and counted by DroppedSpans. maxBytes less than or equal to 0 means no limit.
+func NewPayloadLimitExporter(inner Exporter, maxBytes int) *PayloadLimitExporter {
+	return trace.NewPayloadLimitExporter(inner, maxBytes)
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// PersistentQueueExporter writes spans to write-ahead log segments before forwarding them to the inner exporter,
+// and deletes the segments after spans are acknowledged, so that spans survive process crashes.
+type PersistentQueueExporter = trace.PersistentQueueExporter
+
+// PQOption is used to set options for PersistentQueueExporter.
+type PQOption = trace.PQOption
+
+// NewPersistentQueueExporter creates a PersistentQueueExporter writing WAL segments to dir/wal-*.bin,
+// which can be set by WithExporter. Spans left in dir by previous process are replayed to inner in background
+// within 1 minute. Segments are deleted once all of their spans are acknowledged, see AckExporter, or dropped
+// after the retry of span processor fails. Retries of spans already in WAL do not write new segments.
+// Files exported by ExportFiles are forwarded to inner without WAL.
+func NewPersistentQueueExporter(dir string, inner Exporter, opts ...PQOption) (*PersistentQueueExporter, error) {
+	return trace.NewPersistentQueueExporter(dir, inner, opts...)
+}
+
+// WithPQSync set whether to fsync WAL segment before forwarding spans to inner exporter. Default is true.
+// Disabling it improves performance, but spans may be lost if the machine crashes.
+func WithPQSync(enable bool) PQOption {
+	return trace.WithPQSync(enable)
+}
+
+// WithPQMaxBytes set the max total size of WAL segments. When exceeded, spans are forwarded without WAL.
+// Default is 512MB.
+func WithPQMaxBytes(maxBytes int64) PQOption {
+	return trace.WithPQMaxBytes(maxBytes)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"crypto/tls"
+
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// RelayExporter forwards spans and files as JSON to a relay proxy, used in environments with network egress controls.
+type RelayExporter = trace.RelayExporter
+
+// RelayOption is used to set options for RelayExporter.
+type RelayOption = trace.RelayOption
+
+// RelayWorkspaceHeader is the header carrying workspace id, set when WithForwardWorkspaceHeader is enabled.
+const RelayWorkspaceHeader = trace.RelayWorkspaceHeader
+
+// NewRelayExporter creates a new RelayExporter which can be set by WithExporter.
+// It POSTs batched spans as JSON to proxyURL/v1/spans, and files to proxyURL/v1/files, whose data is base64 encoded.
+func NewRelayExporter(proxyURL string, opts ...RelayOption) *RelayExporter {
+	return trace.NewRelayExporter(proxyURL, opts...)
+}
+
+// WithRelayAuthHeader set the auth header sent to relay proxy, such as Authorization: Bearer xxx.
+func WithRelayAuthHeader(key, value string) RelayOption {
+	return trace.WithRelayAuthHeader(key, value)
+}
+
+// WithRelayTLSConfig set tls config used to connect relay proxy. It is ignored if WithRelayHTTPClient is set.
+func WithRelayTLSConfig(config *tls.Config) RelayOption {
+	return trace.WithRelayTLSConfig(config)
+}
+
+// WithRelayHTTPClient set http client used to connect relay proxy.
+func WithRelayHTTPClient(client HttpClient) RelayOption {
+	return trace.WithRelayHTTPClient(client)
+}
+
+// WithForwardWorkspaceHeader set whether to copy workspace id into X-Cozeloop-Workspace-Id header.
+func WithForwardWorkspaceHeader(enable bool) RelayOption {
+	return trace.WithForwardWorkspaceHeader(enable)
+}
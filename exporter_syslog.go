@@ -0,0 +1,23 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+//go:build !windows && !plan9
+
+package cozeloop
+
+import (
+	"log/syslog"
+
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// SyslogExporter exports spans to syslog daemon, one JSON-formatted line per span.
+type SyslogExporter = trace.SyslogExporter
+
+// NewSyslogExporter connects to syslog daemon and creates a new SyslogExporter, which can be set by WithExporter.
+// If network is empty, it connects to the local syslog server. Only the facility of priority is used,
+// spans with non-zero status code are written with LOG_ERR severity, others with LOG_INFO.
+// It reconnects automatically on write failure.
+func NewSyslogExporter(network, addr string, priority syslog.Priority, tag string) (*SyslogExporter, error) {
+	return trace.NewSyslogExporter(network, addr, priority, tag)
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// ThrottledExporter limits the rate of span batches exported by inner, to protect downstream from spike traffic.
+type ThrottledExporter = trace.ThrottledExporter
+
+// ThrottleOption is used to set options for ThrottledExporter.
+type ThrottleOption = trace.ThrottleOption
+
+// NewThrottledExporter creates a ThrottledExporter which can be set by WithExporter. It exports at most rps
+// span batches per second on average with bursts of up to burst batches, rps less than or equal to 0 means no limit.
+// Batches waiting longer than max wait are dropped and counted by DroppedBatches.
+func NewThrottledExporter(inner Exporter, rps float64, burst int, opts ...ThrottleOption) *ThrottledExporter {
+	return trace.NewThrottledExporter(inner, rps, burst, opts...)
+}
+
+// WithThrottleMaxWait set the max time to wait for rate limiter, batches which need to wait longer are dropped.
+// Default is 5s, 0 means dropping batches exceeding the rate immediately.
+func WithThrottleMaxWait(d time.Duration) ThrottleOption {
+	return trace.WithThrottleMaxWait(d)
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// ValidatingExporter checks spans against the rule of their span type, and exports valid spans by inner.
+type ValidatingExporter = trace.ValidatingExporter
+
+// ValidationRule requires spans of SpanType to have all RequiredTags, such as model_name of model spans.
+type ValidationRule = trace.ValidationRule
+
+// ValidationAction is the behavior of ValidatingExporter on spans missing required tags.
+type ValidationAction = trace.ValidationAction
+
+const (
+	// ValidationActionLog logs a warning and exports the span. It is the default.
+	ValidationActionLog = trace.ValidationActionLog
+	// ValidationActionDrop logs a warning and drops the span.
+	ValidationActionDrop = trace.ValidationActionDrop
+	// ValidationActionError drops the span and makes ExportSpans return an error.
+	ValidationActionError = trace.ValidationActionError
+)
+
+// ValidationOption is used to set options for ValidatingExporter.
+type ValidationOption = trace.ValidationOption
+
+// NewValidatingExporter creates a ValidatingExporter which can be set by WithExporter. Spans of types without
+// rule are exported without validation.
+func NewValidatingExporter(inner Exporter, rules []ValidationRule, opts ...ValidationOption) *ValidatingExporter {
+	return trace.NewValidatingExporter(inner, rules, opts...)
+}
+
+// WithStrictMode makes every violation handled as ValidationActionError, regardless of OnViolation of the rule.
+func WithStrictMode() ValidationOption {
+	return trace.WithStrictMode()
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"os"
+
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// WorkspaceFileExporter exports spans to local markdown files, one file per workspace named `<dir>/<workspaceID>.md`,
+// which prevents spans of different tenants from being written to the same file. Characters of workspace id other than
+// letters, digits, `-` and `_` are escaped as `%XX`. Call Close to close the files.
+type WorkspaceFileExporter = trace.WorkspaceFileExporter
+
+// WorkspaceFileOption is used to set options for WorkspaceFileExporter.
+type WorkspaceFileOption = trace.WorkspaceFileOption
+
+// NewWorkspaceFileExporter creates a WorkspaceFileExporter writing files to dir, which can be set by WithExporter.
+func NewWorkspaceFileExporter(dir string, opts ...WorkspaceFileOption) *WorkspaceFileExporter {
+	return trace.NewWorkspaceFileExporter(dir, opts...)
+}
+
+// WithWorkspaceFilePermissions set the permissions of files created by WorkspaceFileExporter. Default is 0644.
+func WithWorkspaceFilePermissions(perm os.FileMode) WorkspaceFileOption {
+	return trace.WithWorkspaceFilePermissions(perm)
+}
@@ -11,6 +11,8 @@ require (
 	github.com/smartystreets/goconvey v1.8.1
 	github.com/valyala/fasttemplate v1.2.2
 	golang.org/x/sync v0.11.0
+	golang.org/x/sys v0.26.0
+	golang.org/x/time v0.7.0
 )
 
 require (
@@ -26,7 +28,6 @@ require (
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	golang.org/x/arch v0.11.0 // indirect
 	golang.org/x/exp v0.0.0-20240404231335-c0f41cb1a7a0 // indirect
-	golang.org/x/sys v0.26.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 )
 
@@ -0,0 +1,33 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// GPUMetricsCollector queries the current metrics of GPU, which are set on model spans on finish.
+// Set it by WithGPUMetricsCollector.
+type GPUMetricsCollector = trace.GPUMetricsCollector
+
+// NvidiaSMICollector collects metrics of a GPU by running the nvidia-smi command line tool, instead of NVML
+// bindings. Each run forks a process, so a reading is reused by spans finished within the sample interval.
+// Concurrent collections share one run, which times out after 2s.
+type NvidiaSMICollector = trace.NvidiaSMICollector
+
+// NvidiaSMIOption is used to set options for NvidiaSMICollector.
+type NvidiaSMIOption = trace.NvidiaSMIOption
+
+// NewNvidiaSMICollector creates a NvidiaSMICollector of the GPU of deviceID, nvidia-smi must be in PATH.
+func NewNvidiaSMICollector(deviceID int, opts ...NvidiaSMIOption) *NvidiaSMICollector {
+	return trace.NewNvidiaSMICollector(deviceID, opts...)
+}
+
+// WithNvidiaSMISampleInterval set the interval to run nvidia-smi, readings are reused within it.
+// Default is 1s, 0 means running nvidia-smi on each collection.
+func WithNvidiaSMISampleInterval(d time.Duration) NvidiaSMIOption {
+	return trace.WithNvidiaSMISampleInterval(d)
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package tracer provides the gqlgen extension for tracing GraphQL operations and resolvers.
+// It is a separate module, so that users not using gqlgen don't depend on it.
+package tracer
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/alva-ai/cozeloop-go"
+)
+
+const (
+	SpanTypeOperation = "graphql"
+	SpanTypeResolver  = "graphql_resolver"
+
+	TagOperationName = "graphql.operation.name"
+	TagOperationType = "graphql.operation.type"
+	TagFieldName     = "graphql.field.name"
+	TagObjectType    = "graphql.object.type"
+
+	extensionName = "CozeloopTracer"
+)
+
+var (
+	_ graphql.HandlerExtension    = Tracer{}
+	_ graphql.ResponseInterceptor = Tracer{}
+	_ graphql.FieldInterceptor    = Tracer{}
+)
+
+// Tracer is a gqlgen extension, which starts a span for each operation, and a child span for each resolver field.
+// GraphQL errors are set to spans by SetError.
+type Tracer struct {
+	client cozeloop.Client
+}
+
+// New creates a Tracer, which can be added to gqlgen server by handler.Server.Use.
+func New(client cozeloop.Client) graphql.HandlerExtension {
+	return Tracer{client: client}
+}
+
+func (t Tracer) ExtensionName() string {
+	return extensionName
+}
+
+func (t Tracer) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse starts a span named `<operation type> <operation name>`, such as `query GetUser`.
+// For subscriptions, a span is started for each response.
+func (t Tracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+	operationType := ""
+	if oc.Operation != nil {
+		operationType = string(oc.Operation.Operation)
+	}
+	name := operationType
+	if oc.OperationName != "" {
+		name += " " + oc.OperationName
+	}
+
+	ctx, span := t.client.StartSpan(ctx, name, SpanTypeOperation)
+	defer span.Finish(ctx)
+	span.SetTags(ctx, map[string]interface{}{
+		TagOperationName: oc.OperationName,
+		TagOperationType: operationType,
+	})
+	span.SetInput(ctx, oc.RawQuery)
+
+	resp := next(ctx)
+	if errs := graphql.GetErrors(ctx); len(errs) > 0 {
+		span.SetError(ctx, errs)
+	}
+	return resp
+}
+
+// InterceptField starts a child span named `<object type>.<field name>` for resolver fields.
+// Fields which are not resolvers, such as fields of struct, are skipped to avoid too many spans.
+func (t Tracer) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver || fc.Field.Field == nil {
+		return next(ctx)
+	}
+
+	ctx, span := t.client.StartSpan(ctx, fc.Object+"."+fc.Field.Name, SpanTypeResolver)
+	defer span.Finish(ctx)
+	span.SetTags(ctx, map[string]interface{}{
+		TagFieldName:  fc.Field.Name,
+		TagObjectType: fc.Object,
+	})
+
+	res, err := next(ctx)
+	if errs := graphql.GetFieldErrors(ctx, fc); len(errs) > 0 {
+		span.SetError(ctx, errs)
+	} else if err != nil {
+		span.SetError(ctx, err)
+	}
+	return res, err
+}
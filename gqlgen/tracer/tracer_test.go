@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	"github.com/alva-ai/cozeloop-go/tracetest"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestTracer(t *testing.T) {
+	Convey("Tracer", t, func() {
+		ctx := context.Background()
+		recorder := tracetest.NewRecorderExporter()
+		client, err := cozeloop.NewClient(cozeloop.WithWorkspaceID("ws"), cozeloop.WithAPIToken("token"),
+			cozeloop.WithExporter(recorder))
+		So(err, ShouldBeNil)
+		defer client.Close(ctx)
+
+		tracer := New(client).(Tracer)
+		ctx = graphql.WithResponseContext(ctx, graphql.DefaultErrorPresenter, graphql.DefaultRecover)
+		ctx = graphql.WithOperationContext(ctx, &graphql.OperationContext{
+			OperationName: "GetUser",
+			RawQuery:      "query GetUser { user { name } }",
+			Operation:     &ast.OperationDefinition{Operation: ast.Query, Name: "GetUser"},
+		})
+
+		tracer.InterceptResponse(ctx, func(ctx context.Context) *graphql.Response {
+			fieldCtx := graphql.WithFieldContext(ctx, &graphql.FieldContext{
+				Object:     "Query",
+				Field:      graphql.CollectedField{Field: &ast.Field{Name: "user"}},
+				IsResolver: true,
+			})
+			_, _ = tracer.InterceptField(fieldCtx, func(ctx context.Context) (interface{}, error) {
+				graphql.AddError(ctx, fmt.Errorf("user not found"))
+				return nil, nil
+			})
+			return &graphql.Response{}
+		})
+		client.Flush(ctx)
+
+		tree, err := tracetest.BuildTree(recorder.Spans())
+		So(err, ShouldBeNil)
+		So(len(tree), ShouldEqual, 1)
+		So(tree[0].Span.SpanName, ShouldEqual, "query GetUser")
+		So(tree[0].Span.StatusCode, ShouldNotEqual, 0)
+		So(len(tree[0].Children), ShouldEqual, 1)
+
+		resolver := tree[0].Children[0].Span
+		So(resolver.SpanName, ShouldEqual, "Query.user")
+		So(resolver.SpanType, ShouldEqual, SpanTypeResolver)
+		So(resolver.TagsString[TagObjectType], ShouldEqual, "Query")
+		So(resolver.TagsString[TagFieldName], ShouldEqual, "user")
+		So(resolver.StatusCode, ShouldNotEqual, 0)
+		So(resolver.TagsString[tracespec.Error], ShouldContainSubstring, "user not found")
+	})
+}
@@ -17,6 +17,8 @@ const (
 	DefaultPromptCacheRefreshInterval = 1 * time.Minute
 	DefaultTimeout                    = 3 * time.Second
 	DefaultUploadTimeout              = 30 * time.Second
+
+	DefaultContextWindowWarningThreshold = 0.9
 )
 
 const (
@@ -12,5 +12,6 @@ const (
 	LatencyFirstResp   = "latency_first_resp"
 	DeploymentEnv      = "deployment_env"
 
-	CutOff = "cut_off"
+	CutOff            = "cut_off"
+	ChatMessageFormat = "chat_message_format"
 )
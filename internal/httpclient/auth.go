@@ -5,6 +5,7 @@ package httpclient
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/alva-ai/cozeloop-go/internal/consts"
@@ -17,13 +18,22 @@ type Auth interface {
 	Token(ctx context.Context) (string, error)
 }
 
+// TokenRotator is an Auth whose token can be replaced at runtime.
+type TokenRotator interface {
+	Auth
+	RotateToken(token string)
+}
+
 var (
 	_ Auth = &tokenAuthImpl{}
 	_ Auth = &jwtOAuthImpl{}
+
+	_ TokenRotator = &tokenAuthImpl{}
 )
 
-// tokenAuthImpl implements the Auth interface with fixed access token.
+// tokenAuthImpl implements the Auth interface with access token, which can be rotated.
 type tokenAuthImpl struct {
+	mu          sync.RWMutex
 	accessToken string
 }
 
@@ -36,9 +46,18 @@ func NewTokenAuth(accessToken string) Auth {
 
 // Token returns the access token.
 func (r *tokenAuthImpl) Token(ctx context.Context) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.accessToken, nil
 }
 
+// RotateToken replaces the access token. Requests which have set authorization header keep the old token.
+func (r *tokenAuthImpl) RotateToken(token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accessToken = token
+}
+
 func NewJWTAuth(client *JWTOAuthClient, opt *GetJWTAccessTokenReq) Auth {
 	ttl := consts.DefaultOAuthRefreshTTL
 	if opt == nil {
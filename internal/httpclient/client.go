@@ -47,6 +47,26 @@ func NewClient(baseURL string, httpClient HTTPClient, auth Auth, options *Client
 	return c
 }
 
+// CloneWithHTTPClient returns a copy of the client which sends requests by httpClient.
+func (c *Client) CloneWithHTTPClient(httpClient HTTPClient) *Client {
+	clone := *c
+	clone.httpClient = httpClient
+	return &clone
+}
+
+// RotateToken replaces the API token used by subsequent requests. Only API token auth supports rotation.
+func (c *Client) RotateToken(token string) error {
+	if token == "" {
+		return consts.ErrInvalidParam.Wrap(fmt.Errorf("token is empty"))
+	}
+	rotator, ok := c.auth.(TokenRotator)
+	if !ok {
+		return consts.ErrInvalidParam.Wrap(fmt.Errorf("auth does not support token rotation"))
+	}
+	rotator.RotateToken(token)
+	return nil
+}
+
 func (c *Client) GetWithRetry(ctx context.Context, path string, params map[string]string, resp OpenAPIResponse, retryTimes int) error {
 	return defaultBackoff.Retry(ctx, func() error {
 		return c.Get(ctx, path, params, resp)
@@ -92,6 +112,11 @@ func (c *Client) PostWithRetry(ctx context.Context, path string, body any, resp
 }
 
 func (c *Client) Post(ctx context.Context, path string, body any, resp OpenAPIResponse) error {
+	return c.PostWithHeaders(ctx, path, body, nil, resp)
+}
+
+// PostWithHeaders is the same as Post, with extra headers set to the request.
+func (c *Client) PostWithHeaders(ctx context.Context, path string, body any, headers map[string]string, resp OpenAPIResponse) error {
 	var cancel context.CancelFunc
 	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
 		ctx, cancel = context.WithTimeout(ctx, c.timeout)
@@ -113,7 +138,11 @@ func (c *Client) Post(ctx context.Context, path string, body any, resp OpenAPIRe
 		return consts.ErrInternal.Wrap(err)
 	}
 
-	if err := c.setHeaders(ctx, request, map[string]string{"Content-Type": "application/json"}); err != nil {
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	for k, v := range headers {
+		reqHeaders[k] = v
+	}
+	if err := c.setHeaders(ctx, request, reqHeaders); err != nil {
 		return err
 	}
 
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	. "github.com/bytedance/mockey"
@@ -108,3 +109,26 @@ func (a *mockAuthImpl) Token(ctx context.Context) (string, error) {
 func buildBody(body string) io.ReadCloser {
 	return io.NopCloser(bytes.NewReader([]byte(body)))
 }
+
+func Test_RotateToken(t *testing.T) {
+	Convey("Test RotateToken", t, func() {
+		var authorization string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			_, _ = w.Write([]byte("{\"code\":0}"))
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, http.DefaultClient, NewTokenAuth("old_token"), nil)
+		So(client.Get(context.Background(), "/api/v1/data", nil, &BaseResponse{}), ShouldBeNil)
+		So(authorization, ShouldEqual, "Bearer old_token")
+
+		So(client.RotateToken("new_token"), ShouldBeNil)
+		So(client.Get(context.Background(), "/api/v1/data", nil, &BaseResponse{}), ShouldBeNil)
+		So(authorization, ShouldEqual, "Bearer new_token")
+
+		So(errors.Is(client.RotateToken(""), consts.ErrInvalidParam), ShouldBeTrue)
+		jwtClient := NewClient(server.URL, http.DefaultClient, NewJWTAuth(&JWTOAuthClient{}, nil), nil)
+		So(errors.Is(jwtClient.RotateToken("new_token"), consts.ErrInvalidParam), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+)
+
+// NewTLSConfigFromFiles loads the certificate key pair and CA certificates from PEM files, and returns tls config
+// for mutual TLS. The CA verifies the peer certificate: server certificate on client side, and client certificate
+// on server side, which is required by ClientAuth RequireAndVerifyClientCert.
+func NewTLSConfigFromFiles(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("load certificate key pair fail: %w", err))
+	}
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("read ca file fail: %w", err))
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caPEM) {
+		return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("no valid certificate in ca file[%s]", caFile))
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      caPool,
+		ClientCAs:    caPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// NewTLSHTTPClient returns http client whose transport uses config, other settings are the same as
+// http.DefaultTransport.
+func NewTLSHTTPClient(config *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return &http.Client{Transport: transport}
+}
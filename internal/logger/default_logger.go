@@ -35,6 +35,9 @@ func (l stdLogger) CtxFatalf(ctx context.Context, format string, v ...interface{
 
 func (l stdLogger) ctxLogf(ctx context.Context, level LogLevel, format string, v ...interface{}) {
 	msg := level.toString()
+	if traceID, spanID := SpanFromContext(ctx); traceID != "" {
+		msg += fmt.Sprintf("[trace_id=%s span_id=%s] ", traceID, spanID)
+	}
 	msg += fmt.Sprintf(format, v...)
 	_ = l.log.Output(4, msg)
 }
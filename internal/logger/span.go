@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package logger
+
+import (
+	"context"
+)
+
+// SpanContext is the span info used to correlate logs with traces.
+type SpanContext interface {
+	GetTraceID() string
+	GetSpanID() string
+}
+
+type logSpanKey struct{}
+
+type logSpan struct {
+	traceID string
+	spanID  string
+}
+
+// WithSpan returns a copy of ctx carrying trace_id and span_id of span.
+// Every log line emitted from the returned ctx by default logger contains them.
+func WithSpan(ctx context.Context, span SpanContext) context.Context {
+	if span == nil || span.GetTraceID() == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, logSpanKey{}, logSpan{
+		traceID: span.GetTraceID(),
+		spanID:  span.GetSpanID(),
+	})
+}
+
+// SpanFromContext returns trace_id and span_id injected by WithSpan, which can be used by custom Logger.
+func SpanFromContext(ctx context.Context) (traceID, spanID string) {
+	if ctx == nil {
+		return "", ""
+	}
+	s, ok := ctx.Value(logSpanKey{}).(logSpan)
+	if !ok {
+		return "", ""
+	}
+	return s.traceID, s.spanID
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testSpan struct {
+	traceID string
+	spanID  string
+}
+
+func (s testSpan) GetTraceID() string {
+	return s.traceID
+}
+
+func (s testSpan) GetSpanID() string {
+	return s.spanID
+}
+
+func TestWithSpan(t *testing.T) {
+	Convey("WithSpan", t, func() {
+		ctx := context.Background()
+
+		Convey("should carry trace id and span id of span", func() {
+			traceID, spanID := SpanFromContext(WithSpan(ctx, testSpan{traceID: "trace1", spanID: "span1"}))
+			So(traceID, ShouldEqual, "trace1")
+			So(spanID, ShouldEqual, "span1")
+		})
+
+		Convey("should return ctx unchanged without trace id", func() {
+			So(WithSpan(ctx, nil), ShouldEqual, ctx)
+			So(WithSpan(ctx, testSpan{spanID: "span1"}), ShouldEqual, ctx)
+		})
+
+		Convey("should return empty ids without span", func() {
+			traceID, spanID := SpanFromContext(ctx)
+			So(traceID, ShouldBeEmpty)
+			So(spanID, ShouldBeEmpty)
+			traceID, spanID = SpanFromContext(nil)
+			So(traceID, ShouldBeEmpty)
+			So(spanID, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestStdLoggerWithSpan(t *testing.T) {
+	Convey("default logger", t, func() {
+		var buf bytes.Buffer
+		l := stdLogger{log: log.New(&buf, "", 0)}
+
+		Convey("should write trace id and span id of ctx", func() {
+			ctx := WithSpan(context.Background(), testSpan{traceID: "trace1", spanID: "span1"})
+			l.CtxWarnf(ctx, "export %d spans fail", 2)
+			So(buf.String(), ShouldEqual, "[Warn] [cozeloop] [trace_id=trace1 span_id=span1] export 2 spans fail\n")
+		})
+
+		Convey("should not write ids without span", func() {
+			l.CtxInfof(context.Background(), "export %d spans", 2)
+			So(buf.String(), ShouldEqual, "[Info] [cozeloop] export 2 spans\n")
+		})
+	})
+}
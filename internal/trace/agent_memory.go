@@ -0,0 +1,47 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+)
+
+func (s *Span) SetAgentMemory(ctx context.Context, mem tracespec.AgentMemory) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := map[string]interface{}{
+		tracespec.AgentMemoryEntryCount:       mem.EntryCount,
+		tracespec.AgentMemorySizeBytes:        mem.MemorySizeBytes,
+		tracespec.AgentMemoryRetrievedEntries: mem.RetrievedEntries,
+	}
+	if mem.MemoryType != "" {
+		tagMap[tracespec.AgentMemoryType] = mem.MemoryType
+	}
+	s.SetTags(ctx, tagMap)
+}
+
+// SetCustomAgentMemory sets memory of custom type adapted by memorySerializer, tracespec.AgentMemory is set directly.
+func (s *Span) SetCustomAgentMemory(ctx context.Context, mem interface{}) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	switch m := mem.(type) {
+	case tracespec.AgentMemory:
+		s.SetAgentMemory(ctx, m)
+	case *tracespec.AgentMemory:
+		if m != nil {
+			s.SetAgentMemory(ctx, *m)
+		}
+	default:
+		if s.memorySerializer == nil {
+			logger.CtxWarnf(ctx, "agent memory of type %T is ignored without serializer, set it by WithAgentMemorySerializer", mem)
+			return
+		}
+		s.SetAgentMemory(ctx, s.memorySerializer(mem))
+	}
+}
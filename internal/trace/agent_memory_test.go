@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type frameworkMemory struct {
+	messages []string
+}
+
+func TestAgentMemory(t *testing.T) {
+	Convey("agent memory", t, func() {
+		ctx := context.Background()
+		s := &Span{TagMap: make(map[string]interface{})}
+
+		Convey("SetAgentMemory sets memory tags", func() {
+			s.SetAgentMemory(ctx, tracespec.AgentMemory{
+				EntryCount:       12,
+				MemorySizeBytes:  2048,
+				RetrievedEntries: 3,
+				MemoryType:       tracespec.VAgentMemoryTypeVector,
+			})
+			tags := s.GetTagMap()
+			So(tags[tracespec.AgentMemoryEntryCount], ShouldEqual, 12)
+			So(tags[tracespec.AgentMemorySizeBytes], ShouldEqual, 2048)
+			So(tags[tracespec.AgentMemoryRetrievedEntries], ShouldEqual, 3)
+			So(tags[tracespec.AgentMemoryType], ShouldEqual, "vector")
+		})
+
+		Convey("SetCustomAgentMemory adapts memory by serializer", func() {
+			s.SetCustomAgentMemory(ctx, &frameworkMemory{messages: []string{"hi", "hello"}})
+			So(s.GetTagMap(), ShouldBeEmpty)
+
+			s.memorySerializer = func(mem interface{}) tracespec.AgentMemory {
+				m := mem.(*frameworkMemory)
+				return tracespec.AgentMemory{EntryCount: len(m.messages), MemoryType: tracespec.VAgentMemoryTypeBuffer}
+			}
+			s.SetCustomAgentMemory(ctx, &frameworkMemory{messages: []string{"hi", "hello"}})
+			So(s.GetTagMap()[tracespec.AgentMemoryEntryCount], ShouldEqual, 2)
+			So(s.GetTagMap()[tracespec.AgentMemoryType], ShouldEqual, "buffer")
+
+			s.SetCustomAgentMemory(ctx, tracespec.AgentMemory{EntryCount: 5})
+			So(s.GetTagMap()[tracespec.AgentMemoryEntryCount], ShouldEqual, 5)
+		})
+
+		Convey("serializer is passed to spans by provider", func() {
+			p := &Provider{
+				opt: &Options{WorkspaceID: "ws", AgentMemorySerializer: func(mem interface{}) tracespec.AgentMemory {
+					return tracespec.AgentMemory{EntryCount: len(mem.(string))}
+				}},
+				spanProcessor: noopSpanProcessor{},
+			}
+			_, span, _ := p.StartSpan(ctx, "plan", "agent", StartSpanOptions{})
+			span.SetCustomAgentMemory(ctx, "abc")
+			So(span.GetTagMap()[tracespec.AgentMemoryEntryCount], ShouldEqual, 3)
+		})
+	})
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+)
+
+type agentRoundKey struct{}
+
+// ContextWithNextAgentRound returns a copy of ctx with the agent round incremented, starting from 1.
+// Spans started from the returned ctx are tagged with the round by StartSpan.
+func ContextWithNextAgentRound(ctx context.Context) context.Context {
+	round, _ := AgentRoundFromContext(ctx)
+	return context.WithValue(ctx, agentRoundKey{}, round+1)
+}
+
+// AgentRoundFromContext returns the agent round in ctx, ok is false if not exist.
+func AgentRoundFromContext(ctx context.Context) (round int, ok bool) {
+	round, ok = ctx.Value(agentRoundKey{}).(int)
+	return round, ok
+}
+
+// SetAgentRound sets the iteration of agentic loop, and marks the span if the round reaches maxRounds.
+func (s *Span) SetAgentRound(ctx context.Context, round, maxRounds int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := map[string]interface{}{
+		tracespec.AgentRound:     round,
+		tracespec.AgentMaxRounds: maxRounds,
+	}
+	if round >= maxRounds {
+		tagMap[tracespec.AgentMaxRoundsReached] = true
+	}
+	s.SetTags(ctx, tagMap)
+}
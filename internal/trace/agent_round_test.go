@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAgentRound(t *testing.T) {
+	Convey("agent round", t, func() {
+		ctx := context.Background()
+
+		Convey("SetAgentRound sets max rounds reached", func() {
+			s := &Span{TagMap: make(map[string]interface{})}
+			s.SetAgentRound(ctx, 2, 3)
+			tags := s.GetTagMap()
+			So(tags[tracespec.AgentRound], ShouldEqual, 2)
+			So(tags[tracespec.AgentMaxRounds], ShouldEqual, 3)
+			So(tags, ShouldNotContainKey, tracespec.AgentMaxRoundsReached)
+
+			s.SetAgentRound(ctx, 3, 3)
+			So(s.GetTagMap()[tracespec.AgentMaxRoundsReached], ShouldEqual, true)
+		})
+
+		Convey("spans started from ctx of next round are tagged", func() {
+			p := &Provider{
+				opt:           &Options{WorkspaceID: "ws"},
+				spanProcessor: noopSpanProcessor{},
+			}
+			_, ok := AgentRoundFromContext(ctx)
+			So(ok, ShouldBeFalse)
+			_, span, _ := p.StartSpan(ctx, "plan", "agent", StartSpanOptions{})
+			So(span.GetTagMap(), ShouldNotContainKey, tracespec.AgentRound)
+
+			roundCtx := ContextWithNextAgentRound(ctx)
+			roundCtx = ContextWithNextAgentRound(roundCtx)
+			round, ok := AgentRoundFromContext(roundCtx)
+			So(ok, ShouldBeTrue)
+			So(round, ShouldEqual, 2)
+			spanCtx, span, _ := p.StartSpan(roundCtx, "think", "model", StartSpanOptions{})
+			So(span.GetTagMap()[tracespec.AgentRound], ShouldEqual, 2)
+			_, child, _ := p.StartSpan(spanCtx, "act", "tool", StartSpanOptions{})
+			So(child.GetTagMap()[tracespec.AgentRound], ShouldEqual, 2)
+		})
+	})
+}
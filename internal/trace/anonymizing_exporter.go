@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"regexp"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+)
+
+var _ Exporter = (*AnonymizingExporter)(nil)
+
+// AnonymizationStrategy anonymizes span before export. span is a copy owned by the strategy,
+// which can be modified in place and returned. Returning nil drops the span.
+type AnonymizationStrategy interface {
+	Anonymize(span *entity.UploadSpan) *entity.UploadSpan
+}
+
+// AnonymizingExporter applies anonymization strategies in order to copies of spans, and exports them by inner.
+type AnonymizingExporter struct {
+	inner      Exporter
+	strategies []AnonymizationStrategy
+}
+
+// NewAnonymizingExporter creates an AnonymizingExporter. Spans passed to ExportSpans are cloned
+// before anonymization, so they are never modified.
+func NewAnonymizingExporter(inner Exporter, strategies []AnonymizationStrategy) *AnonymizingExporter {
+	validStrategies := make([]AnonymizationStrategy, 0, len(strategies))
+	for _, strategy := range strategies {
+		if strategy != nil {
+			validStrategies = append(validStrategies, strategy)
+		}
+	}
+	return &AnonymizingExporter{
+		inner:      inner,
+		strategies: validStrategies,
+	}
+}
+
+func (e *AnonymizingExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	anonymized := make([]*entity.UploadSpan, 0, len(spans))
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		span = CloneUploadSpan(span)
+		for _, strategy := range e.strategies {
+			if span = strategy.Anonymize(span); span == nil {
+				break
+			}
+		}
+		if span != nil {
+			anonymized = append(anonymized, span)
+		}
+	}
+	if len(anonymized) == 0 {
+		return nil
+	}
+	return e.inner.ExportSpans(ctx, anonymized)
+}
+
+func (e *AnonymizingExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return e.inner.ExportFiles(ctx, files)
+}
+
+// GeneralizeIPStrategy replaces the last octet of IPv4 addresses in string tags and system tags with 0,
+// such as 192.168.1.23 to 192.168.1.0.
+type GeneralizeIPStrategy struct{}
+
+var ipv4Regexp = regexp.MustCompile(`\b(\d{1,3}\.\d{1,3}\.\d{1,3})\.\d{1,3}\b`)
+
+func (GeneralizeIPStrategy) Anonymize(span *entity.UploadSpan) *entity.UploadSpan {
+	generalizeIPs(span.TagsString)
+	generalizeIPs(span.SystemTagsString)
+	return span
+}
+
+func generalizeIPs(tags map[string]string) {
+	for key, value := range tags {
+		tags[key] = ipv4Regexp.ReplaceAllStringFunc(value, func(ip string) string {
+			if net.ParseIP(ip) == nil {
+				return ip
+			}
+			return ipv4Regexp.ReplaceAllString(ip, "${1}.0")
+		})
+	}
+}
+
+// HashUserIDStrategy replaces the user_id tag set by SetUserID with hex-encoded SHA-256 of Salt and user id,
+// so that spans of the same user can still be correlated. Setting Salt is recommended to resist dictionary attacks.
+type HashUserIDStrategy struct {
+	Salt string
+}
+
+func (s HashUserIDStrategy) Anonymize(span *entity.UploadSpan) *entity.UploadSpan {
+	if userID, ok := span.TagsString[consts.UserID]; ok && userID != "" {
+		sum := sha256.Sum256([]byte(s.Salt + userID))
+		span.TagsString[consts.UserID] = hex.EncodeToString(sum[:])
+	}
+	return span
+}
+
+type dropTagStrategy struct {
+	keys []string
+}
+
+// DropTagStrategy returns a strategy which removes tags and system tags of keys, whatever type the value is.
+func DropTagStrategy(keys []string) AnonymizationStrategy {
+	return dropTagStrategy{keys: keys}
+}
+
+func (s dropTagStrategy) Anonymize(span *entity.UploadSpan) *entity.UploadSpan {
+	for _, key := range s.keys {
+		delete(span.TagsString, key)
+		delete(span.TagsLong, key)
+		delete(span.TagsDouble, key)
+		delete(span.TagsBool, key)
+		delete(span.SystemTagsString, key)
+		delete(span.SystemTagsLong, key)
+		delete(span.SystemTagsDouble, key)
+	}
+	return span
+}
+
+// CloneUploadSpan returns a deep copy of span, which can be retained by exporters after ExportSpans returns.
+func CloneUploadSpan(span *entity.UploadSpan) *entity.UploadSpan {
+	res := *span
+	res.SystemTagsString = cloneMap(span.SystemTagsString)
+	res.SystemTagsLong = cloneMap(span.SystemTagsLong)
+	res.SystemTagsDouble = cloneMap(span.SystemTagsDouble)
+	res.TagsString = cloneMap(span.TagsString)
+	res.TagsLong = cloneMap(span.TagsLong)
+	res.TagsDouble = cloneMap(span.TagsDouble)
+	res.TagsBool = cloneMap(span.TagsBool)
+	if span.Annotations != nil {
+		res.Annotations = append([]entity.SpanAnnotation(nil), span.Annotations...)
+	}
+	return &res
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	res := make(map[K]V, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+	return res
+}
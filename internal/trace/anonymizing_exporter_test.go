@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type dropAllStrategy struct{}
+
+func (dropAllStrategy) Anonymize(span *entity.UploadSpan) *entity.UploadSpan { return nil }
+
+func TestAnonymizingExporter(t *testing.T) {
+	Convey("AnonymizingExporter", t, func() {
+		ctx := context.Background()
+		var exported []*entity.UploadSpan
+		inner := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+			exported = spans
+			return nil
+		}}
+		span := &entity.UploadSpan{
+			SpanID:           "span1",
+			TagsString:       map[string]string{"user_id": "u1", "client.ip": "client 192.168.1.23, proxy 10.0.0.255", "email": "a@b.c"},
+			TagsLong:         map[string]int64{"age": 30},
+			SystemTagsString: map[string]string{"host.ip": "172.16.5.4", "version": "1.2.3.4567"},
+			Annotations:      []entity.SpanAnnotation{{Timestamp: 1, Message: "hello"}},
+		}
+
+		Convey("should apply strategies to copies of spans", func() {
+			e := NewAnonymizingExporter(inner, []AnonymizationStrategy{
+				GeneralizeIPStrategy{},
+				HashUserIDStrategy{},
+				DropTagStrategy([]string{"email", "age"}),
+				nil,
+			})
+			So(e.ExportSpans(ctx, []*entity.UploadSpan{span, nil}), ShouldBeNil)
+			So(len(exported), ShouldEqual, 1)
+
+			got := exported[0]
+			So(got, ShouldNotPointTo, span)
+			So(got.TagsString["client.ip"], ShouldEqual, "client 192.168.1.0, proxy 10.0.0.0")
+			So(got.SystemTagsString["host.ip"], ShouldEqual, "172.16.5.0")
+			So(got.SystemTagsString["version"], ShouldEqual, "1.2.3.4567")
+			sum := sha256.Sum256([]byte("u1"))
+			So(got.TagsString["user_id"], ShouldEqual, hex.EncodeToString(sum[:]))
+			So(got.TagsString, ShouldNotContainKey, "email")
+			So(got.TagsLong, ShouldNotContainKey, "age")
+			So(got.Annotations, ShouldResemble, span.Annotations)
+
+			// original span is not modified
+			So(span.TagsString["user_id"], ShouldEqual, "u1")
+			So(span.TagsString["email"], ShouldEqual, "a@b.c")
+			So(span.TagsLong["age"], ShouldEqual, 30)
+			So(span.SystemTagsString["host.ip"], ShouldEqual, "172.16.5.4")
+		})
+
+		Convey("should hash user id with salt", func() {
+			e := NewAnonymizingExporter(inner, []AnonymizationStrategy{HashUserIDStrategy{Salt: "salt"}})
+			So(e.ExportSpans(ctx, []*entity.UploadSpan{span}), ShouldBeNil)
+			sum := sha256.Sum256([]byte("saltu1"))
+			So(exported[0].TagsString["user_id"], ShouldEqual, hex.EncodeToString(sum[:]))
+		})
+
+		Convey("should drop span if strategy returns nil", func() {
+			e := NewAnonymizingExporter(inner, []AnonymizationStrategy{dropAllStrategy{}})
+			So(e.ExportSpans(ctx, []*entity.UploadSpan{span}), ShouldBeNil)
+			So(inner.calls, ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+)
+
+const (
+	// CardinalityOtherValue replaces tag values exceeding the cardinality limit.
+	CardinalityOtherValue = "_other_"
+	// CardinalityLimitedValuesTotal is the name of counter of tag values replaced by CardinalityOtherValue.
+	CardinalityLimitedValuesTotal = "cozeloop.cardinality_limited_values_total"
+	// CardinalityLimitedKeysTotal is the name of counter of tags dropped because their keys exceed cardinalityMaxKeys.
+	CardinalityLimitedKeysTotal = "cozeloop.cardinality_limited_keys_total"
+
+	// cardinalityMaxKeys is the max number of tag keys tracked, tags of new keys are dropped if exceeded.
+	cardinalityMaxKeys = 1024
+)
+
+// cardinalityExemptKeys are tags which are high-cardinality by nature, never limited.
+var cardinalityExemptKeys = map[string]struct{}{
+	tracespec.Input:       {},
+	tracespec.Output:      {},
+	tracespec.Error:       {},
+	tracespec.CallOptions: {},
+	consts.UserID:         {},
+	consts.MessageID:      {},
+	consts.ThreadID:       {},
+}
+
+var (
+	// cardinalityLimitedValues counts tag values replaced by CardinalityOtherValue in process.
+	cardinalityLimitedValues int64
+	// cardinalityLimitedKeys counts tags dropped because their keys exceed cardinalityMaxKeys in process.
+	cardinalityLimitedKeys int64
+)
+
+// GetCardinalityLimitedValuesTotal returns the count of tag values replaced by CardinalityOtherValue in process.
+func GetCardinalityLimitedValuesTotal() int64 {
+	return atomic.LoadInt64(&cardinalityLimitedValues)
+}
+
+// GetCardinalityLimitedKeysTotal returns the count of tags dropped because their keys exceed the limit in process.
+func GetCardinalityLimitedKeysTotal() int64 {
+	return atomic.LoadInt64(&cardinalityLimitedKeys)
+}
+
+// cardinalityLimiter caps the number of unique string values of each tag key, and the number of tag keys.
+// Values are tracked by their 64-bit hashes, and at most cardinalityMaxKeys keys are tracked, so the memory
+// is bounded. Tracked keys are never evicted, neither by LRU nor by consistent hashing, as evicting a key resets
+// its values, so that a workload rotating keys would never be limited. Instead tags of new keys beyond
+// cardinalityMaxKeys are dropped, which also limits the explosion of keys, such as user ids in tag keys.
+type cardinalityLimiter struct {
+	maxUniqueValues int
+
+	mu   sync.Mutex
+	keys map[string]map[uint64]struct{} // tag key -> hashes of seen string values
+}
+
+func newCardinalityLimiter(maxUniqueValues int) *cardinalityLimiter {
+	if maxUniqueValues <= 0 {
+		return nil
+	}
+	return &cardinalityLimiter{
+		maxUniqueValues: maxUniqueValues,
+		keys:            make(map[string]map[uint64]struct{}),
+	}
+}
+
+// limit returns tags whose string values exceeding the limit are replaced by CardinalityOtherValue, and
+// tags of keys exceeding cardinalityMaxKeys are removed. The input map is not modified.
+func (l *cardinalityLimiter) limit(ctx context.Context, tagKVs map[string]interface{}) map[string]interface{} {
+	var limited map[string]interface{}
+	copyOnWrite := func() {
+		if limited == nil {
+			limited = make(map[string]interface{}, len(tagKVs))
+			for k, v := range tagKVs {
+				limited[k] = v
+			}
+		}
+	}
+	for key, value := range tagKVs {
+		if _, ok := cardinalityExemptKeys[key]; ok {
+			continue
+		}
+		str, isStr := value.(string)
+		if isStr && str == CardinalityOtherValue {
+			isStr = false
+		}
+		keyAllowed, valueAllowed := l.allow(key, str, isStr)
+		switch {
+		case !keyAllowed:
+			copyOnWrite()
+			delete(limited, key)
+			atomic.AddInt64(&cardinalityLimitedKeys, 1)
+			logger.CtxDebugf(ctx, "tag keys exceed limit %d, tag [%s] is dropped", cardinalityMaxKeys, key)
+		case !valueAllowed:
+			copyOnWrite()
+			limited[key] = CardinalityOtherValue
+			atomic.AddInt64(&cardinalityLimitedValues, 1)
+			logger.CtxDebugf(ctx, "unique values of tag [%s] exceed limit %d, replaced by %s", key, l.maxUniqueValues, CardinalityOtherValue)
+		}
+	}
+	if limited == nil {
+		return tagKVs
+	}
+	return limited
+}
+
+// allow records key, and value of key if hasValue. It returns false as keyAllowed if key is new and
+// cardinalityMaxKeys keys are tracked, and false as valueAllowed if value is new and key already has
+// maxUniqueValues values.
+func (l *cardinalityLimiter) allow(key, value string, hasValue bool) (keyAllowed, valueAllowed bool) {
+	var hash uint64
+	if hasValue {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(value))
+		hash = h.Sum64()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	values, ok := l.keys[key]
+	if !ok {
+		if len(l.keys) >= cardinalityMaxKeys {
+			return false, false
+		}
+		values = make(map[uint64]struct{})
+		l.keys[key] = values
+	}
+	if !hasValue {
+		return true, true
+	}
+	if _, ok = values[hash]; ok {
+		return true, true
+	}
+	if len(values) >= l.maxUniqueValues {
+		return true, false
+	}
+	values[hash] = struct{}{}
+	return true, true
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCardinalityLimiter(t *testing.T) {
+	Convey("cardinalityLimiter", t, func() {
+		ctx := context.Background()
+		So(newCardinalityLimiter(0), ShouldBeNil)
+
+		Convey("should replace new values exceeding the limit", func() {
+			l := newCardinalityLimiter(2)
+			total := GetCardinalityLimitedValuesTotal()
+			So(l.limit(ctx, map[string]interface{}{"tenant": "a"})["tenant"], ShouldEqual, "a")
+			So(l.limit(ctx, map[string]interface{}{"tenant": "b"})["tenant"], ShouldEqual, "b")
+
+			tags := map[string]interface{}{"tenant": "c", "count": 1}
+			limited := l.limit(ctx, tags)
+			So(limited["tenant"], ShouldEqual, CardinalityOtherValue)
+			So(limited["count"], ShouldEqual, 1)
+			So(tags["tenant"], ShouldEqual, "c")
+			So(GetCardinalityLimitedValuesTotal(), ShouldEqual, total+1)
+
+			// seen values are still allowed
+			So(l.limit(ctx, map[string]interface{}{"tenant": "a"})["tenant"], ShouldEqual, "a")
+			So(l.limit(ctx, map[string]interface{}{"region": "c"})["region"], ShouldEqual, "c")
+		})
+
+		Convey("should not limit exempt keys", func() {
+			l := newCardinalityLimiter(1)
+			for i := 0; i < 3; i++ {
+				userID := fmt.Sprintf("user%d", i)
+				So(l.limit(ctx, map[string]interface{}{consts.UserID: userID})[consts.UserID], ShouldEqual, userID)
+			}
+		})
+
+		Convey("should drop tags of new keys beyond max keys", func() {
+			l := newCardinalityLimiter(1)
+			for i := 0; i < cardinalityMaxKeys; i++ {
+				key := fmt.Sprintf("key%d", i)
+				So(l.limit(ctx, map[string]interface{}{key: "value"})[key], ShouldEqual, "value")
+			}
+			total := GetCardinalityLimitedKeysTotal()
+			limited := l.limit(ctx, map[string]interface{}{"new_key": "value", "count_key": 1, "key0": "value"})
+			So(limited, ShouldResemble, map[string]interface{}{"key0": "value"})
+			So(GetCardinalityLimitedKeysTotal(), ShouldEqual, total+2)
+			So(len(l.keys), ShouldEqual, cardinalityMaxKeys)
+		})
+
+		Convey("should keep limiting values when keys are rotated", func() {
+			l := newCardinalityLimiter(1)
+			So(l.limit(ctx, map[string]interface{}{"tenant": "a"})["tenant"], ShouldEqual, "a")
+			for i := 0; i < 2*cardinalityMaxKeys; i++ {
+				key := fmt.Sprintf("key%d", i)
+				l.limit(ctx, map[string]interface{}{key: "value"})
+			}
+			// tenant is never forgotten, so its values are still limited
+			So(l.limit(ctx, map[string]interface{}{"tenant": "b"})["tenant"], ShouldEqual, CardinalityOtherValue)
+			So(len(l.keys), ShouldEqual, cardinalityMaxKeys)
+		})
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"runtime"
+	"strings"
+
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+)
+
+const (
+	sdkModulePath = "github.com/alva-ai/cozeloop-go"
+	// max frames unwound to find the caller of SDK, SDK frames are at most a few levels deep
+	codeLocationMaxDepth = 32
+)
+
+// SetCodeLocation sets the function, file path and line of the first caller outside the SDK.
+func (s *Span) SetCodeLocation(ctx context.Context) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	function, file, line, ok := callerLocation()
+	if !ok {
+		return
+	}
+	s.SetTags(ctx, map[string]interface{}{
+		tracespec.CodeFunction: function,
+		tracespec.CodeFilepath: file,
+		tracespec.CodeLineNo:   line,
+	})
+}
+
+// callerLocation returns the first frame outside the SDK, frames of test files are considered outside.
+func callerLocation() (function, file string, line int, ok bool) {
+	var pcs [codeLocationMaxDepth]uintptr
+	n := runtime.Callers(2, pcs[:]) // skip runtime.Callers and callerLocation
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !isSDKFrame(frame) {
+			return frame.Function, frame.File, frame.Line, true
+		}
+		if !more {
+			return "", "", 0, false
+		}
+	}
+}
+
+func isSDKFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	return strings.HasPrefix(frame.Function, sdkModulePath+"/") || strings.HasPrefix(frame.Function, sdkModulePath+".")
+}
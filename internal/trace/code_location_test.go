@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSetCodeLocation(t *testing.T) {
+	Convey("SetCodeLocation", t, func() {
+		ctx := context.Background()
+
+		Convey("should record the caller", func() {
+			s := &Span{TagMap: make(map[string]interface{})}
+			_, _, line, _ := runtime.Caller(0)
+			s.SetCodeLocation(ctx)
+			tags := s.GetTagMap()
+			So(filepath.Base(tags[tracespec.CodeFilepath].(string)), ShouldEqual, "code_location_test.go")
+			So(tags[tracespec.CodeLineNo], ShouldEqual, line+1)
+			So(tags[tracespec.CodeFunction], ShouldStartWith, sdkModulePath+"/internal/trace.TestSetCodeLocation")
+		})
+
+		Convey("should skip frames of sdk on StartSpan", func() {
+			p := &Provider{
+				opt:           &Options{WorkspaceID: "ws", CodeLocationOnAllSpans: true},
+				spanProcessor: noopSpanProcessor{},
+			}
+			_, span, err := p.StartSpan(ctx, "root", "agent", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			tags := span.GetTagMap()
+			So(filepath.Base(tags[tracespec.CodeFilepath].(string)), ShouldEqual, "code_location_test.go")
+			So(tags[tracespec.CodeFunction], ShouldNotContainSubstring, "StartSpan")
+		})
+
+		Convey("should not set code location by default", func() {
+			p := &Provider{
+				opt:           &Options{WorkspaceID: "ws"},
+				spanProcessor: noopSpanProcessor{},
+			}
+			_, span, _ := p.StartSpan(ctx, "root", "agent", StartSpanOptions{})
+			So(span.GetTagMap(), ShouldNotContainKey, tracespec.CodeFunction)
+		})
+	})
+}
+
+func TestIsSDKFrame(t *testing.T) {
+	Convey("isSDKFrame", t, func() {
+		So(isSDKFrame(runtime.Frame{Function: sdkModulePath + ".(*loopClient).StartSpan", File: "client.go"}), ShouldBeTrue)
+		So(isSDKFrame(runtime.Frame{Function: sdkModulePath + "/internal/trace.(*Provider).StartSpan", File: "trace.go"}), ShouldBeTrue)
+		So(isSDKFrame(runtime.Frame{Function: sdkModulePath + "-ext/pkg.Run", File: "run.go"}), ShouldBeFalse)
+		So(isSDKFrame(runtime.Frame{Function: "main.main", File: "main.go"}), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+)
+
+var _ Exporter = (*DebugExporter)(nil)
+
+// DebugExporter writes human-readable span summaries to a writer, such as stderr, for development.
+// Spans of the same trace in a batch are printed as a tree by their parent ids.
+type DebugExporter struct {
+	w      io.Writer
+	colors bool
+	mu     sync.Mutex
+}
+
+// DebugOption is used to set options for DebugExporter.
+type DebugOption func(e *DebugExporter)
+
+// WithColors set whether to write ANSI colors. Default is true, disable it for non-terminal outputs.
+func WithColors(enable bool) DebugOption {
+	return func(e *DebugExporter) {
+		e.colors = enable
+	}
+}
+
+// NewDebugExporter creates a DebugExporter writing to w, which is os.Stderr if nil.
+func NewDebugExporter(w io.Writer, opts ...DebugOption) *DebugExporter {
+	if w == nil {
+		w = os.Stderr
+	}
+	e := &DebugExporter{
+		w:      w,
+		colors: true,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	return e
+}
+
+func (e *DebugExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	// group spans by trace, and index children by parent id
+	var traceIDs []string
+	traceSpans := make(map[string][]*entity.UploadSpan)
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		if _, ok := traceSpans[span.TraceID]; !ok {
+			traceIDs = append(traceIDs, span.TraceID)
+		}
+		traceSpans[span.TraceID] = append(traceSpans[span.TraceID], span)
+	}
+
+	sb := &strings.Builder{}
+	for _, traceID := range traceIDs {
+		group := traceSpans[traceID]
+		spanIDs := make(map[string]struct{}, len(group))
+		for _, span := range group {
+			spanIDs[span.SpanID] = struct{}{}
+		}
+		children := make(map[string][]*entity.UploadSpan)
+		var roots []*entity.UploadSpan
+		for _, span := range group {
+			// spans whose parent is not in the batch are printed as roots
+			if _, ok := spanIDs[span.ParentID]; ok && span.ParentID != span.SpanID {
+				children[span.ParentID] = append(children[span.ParentID], span)
+			} else {
+				roots = append(roots, span)
+			}
+		}
+
+		sb.WriteString(e.color(ansiDim, "trace "+traceID))
+		sb.WriteString("\n")
+		visited := make(map[*entity.UploadSpan]struct{}, len(group))
+		sortSpansByStartTime(roots)
+		for i, root := range roots {
+			e.writeSpanTree(sb, root, children, "", i == len(roots)-1, visited)
+		}
+		// spans in parent cycle are not reachable from roots
+		for _, span := range group {
+			if _, ok := visited[span]; !ok {
+				e.writeSpanTree(sb, span, children, "", true, visited)
+			}
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := io.WriteString(e.w, sb.String())
+	return err
+}
+
+func (e *DebugExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return nil
+}
+
+func (e *DebugExporter) writeSpanTree(sb *strings.Builder, span *entity.UploadSpan, children map[string][]*entity.UploadSpan,
+	prefix string, last bool, visited map[*entity.UploadSpan]struct{},
+) {
+	if _, ok := visited[span]; ok {
+		return
+	}
+	visited[span] = struct{}{}
+
+	branch, childPrefix := "├─ ", prefix+"│  "
+	if last {
+		branch, childPrefix = "└─ ", prefix+"   "
+	}
+	sb.WriteString(prefix)
+	sb.WriteString(branch)
+	e.writeSpanSummary(sb, span)
+	sb.WriteString("\n")
+
+	spanChildren := children[span.SpanID]
+	sortSpansByStartTime(spanChildren)
+	for i, child := range spanChildren {
+		e.writeSpanTree(sb, child, children, childPrefix, i == len(spanChildren)-1, visited)
+	}
+}
+
+// writeSpanSummary writes the summary of span, such as: name (model) 1.2s tags=3
+func (e *DebugExporter) writeSpanSummary(sb *strings.Builder, span *entity.UploadSpan) {
+	sb.WriteString(e.color(ansiBold, span.SpanName))
+	if span.SpanType != "" {
+		sb.WriteString(" ")
+		sb.WriteString(e.color(ansiDim, "("+span.SpanType+")"))
+	}
+
+	duration := (time.Duration(span.DurationMicros) * time.Microsecond).String()
+	if span.StatusCode != 0 {
+		sb.WriteString(" ")
+		sb.WriteString(e.color(ansiRed, fmt.Sprintf("%s status=%d", duration, span.StatusCode)))
+	} else {
+		sb.WriteString(" ")
+		sb.WriteString(e.color(ansiGreen, duration))
+	}
+
+	tagCount := len(span.TagsString) + len(span.TagsLong) + len(span.TagsDouble) + len(span.TagsBool)
+	sb.WriteString(fmt.Sprintf(" tags=%d", tagCount))
+}
+
+func (e *DebugExporter) color(code, s string) string {
+	if !e.colors {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func sortSpansByStartTime(spans []*entity.UploadSpan) {
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].StartedATMicros < spans[j].StartedATMicros
+	})
+}
+
+// isTerminal reports whether f is a character device, such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDebugExporter(t *testing.T) {
+	Convey("DebugExporter", t, func() {
+		ctx := context.Background()
+		spans := []*entity.UploadSpan{
+			{TraceID: "trace1", SpanID: "child2", ParentID: "root", SpanName: "tool", SpanType: "tool", StartedATMicros: 3, DurationMicros: 1500, StatusCode: 1},
+			{TraceID: "trace1", SpanID: "root", ParentID: "0", SpanName: "agent", SpanType: "agent", StartedATMicros: 1, DurationMicros: 2000000},
+			{TraceID: "trace1", SpanID: "child1", ParentID: "root", SpanName: "llm", SpanType: "model", StartedATMicros: 2, DurationMicros: 1000,
+				TagsString: map[string]string{"model_name": "gpt"}, TagsLong: map[string]int64{"input_tokens": 10}},
+			{TraceID: "trace1", SpanID: "grandchild", ParentID: "child1", SpanName: "retry", StartedATMicros: 2},
+		}
+
+		Convey("should print spans as tree without colors", func() {
+			buf := &bytes.Buffer{}
+			e := NewDebugExporter(buf, WithColors(false))
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(buf.String(), ShouldEqual, "trace trace1\n"+
+				"└─ agent (agent) 2s tags=0\n"+
+				"   ├─ llm (model) 1ms tags=2\n"+
+				"   │  └─ retry 0s tags=0\n"+
+				"   └─ tool (tool) 1.5ms status=1 tags=0\n")
+		})
+
+		Convey("should print colors", func() {
+			buf := &bytes.Buffer{}
+			e := NewDebugExporter(buf)
+			So(e.ExportSpans(ctx, spans[1:2]), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, ansiBold+"agent"+ansiReset)
+			So(buf.String(), ShouldContainSubstring, ansiGreen+"2s"+ansiReset)
+
+			buf.Reset()
+			So(e.ExportSpans(ctx, spans[:1]), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, ansiRed+"1.5ms status=1"+ansiReset)
+		})
+
+		Convey("should print spans in parent cycle", func() {
+			buf := &bytes.Buffer{}
+			e := NewDebugExporter(buf, WithColors(false))
+			So(e.ExportSpans(ctx, []*entity.UploadSpan{
+				{TraceID: "trace1", SpanID: "a", ParentID: "b", SpanName: "a"},
+				{TraceID: "trace1", SpanID: "b", ParentID: "a", SpanName: "b"},
+			}), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, "└─ a")
+			So(buf.String(), ShouldContainSubstring, "└─ b")
+		})
+	})
+}
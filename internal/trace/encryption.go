@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+	"github.com/alva-ai/cozeloop-go/internal/util"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+)
+
+// EncryptedValuePrefix is the prefix of encrypted field value, which is formatted as enc:<keyID>:<base64 ciphertext>.
+const EncryptedValuePrefix = "enc:"
+
+// Encryptor encrypts and decrypts span fields.
+type Encryptor interface {
+	Encrypt(plaintext []byte) (ciphertext []byte, err error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// FieldEncryption encrypts input, output and tags of TagKeys before span is exported.
+type FieldEncryption struct {
+	KeyID     string
+	Encryptor Encryptor
+	TagKeys   []string
+}
+
+// Fingerprint identifies the encryption config without exposing the key. Encryptors built by NewAESEncryptor
+// with the same key share the fingerprint, other encryptors are identified by their address.
+func (f *FieldEncryption) Fingerprint() string {
+	if f == nil {
+		return ""
+	}
+	encryptor := fmt.Sprintf("%p", f.Encryptor)
+	if e, ok := f.Encryptor.(*AESEncryptor); ok && e != nil {
+		encryptor = e.keyFingerprint
+	}
+	h := sha256.New()
+	h.Write([]byte(f.KeyID + "\n" + encryptor + "\n" + strings.Join(f.TagKeys, ",")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// encryptUploadSpan encrypts fields of span and text files of input and output in place.
+// Fields failed to encrypt are cleared, so that plaintext is never exported.
+func (f *FieldEncryption) encryptUploadSpan(ctx context.Context, span *entity.UploadSpan, files []*entity.UploadFile) {
+	span.Input = f.encryptField(ctx, tracespec.Input, span.Input)
+	span.Output = f.encryptField(ctx, tracespec.Output, span.Output)
+	for _, key := range f.TagKeys {
+		var value string
+		if v, ok := span.TagsString[key]; ok {
+			value = v
+		} else if v, ok := span.TagsLong[key]; ok {
+			value = util.Stringify(v)
+		} else if v, ok := span.TagsDouble[key]; ok {
+			value = util.Stringify(v)
+		} else if v, ok := span.TagsBool[key]; ok {
+			value = util.Stringify(v)
+		} else {
+			continue
+		}
+		// encrypted value is always string
+		delete(span.TagsLong, key)
+		delete(span.TagsDouble, key)
+		delete(span.TagsBool, key)
+		if span.TagsString == nil {
+			span.TagsString = make(map[string]string)
+		}
+		span.TagsString[key] = f.encryptField(ctx, key, value)
+	}
+	for _, file := range files {
+		if file != nil && file.FileType == fileTypeText && (file.TagKey == tracespec.Input || file.TagKey == tracespec.Output) {
+			file.Data = f.encryptField(ctx, file.TagKey, file.Data)
+		}
+	}
+}
+
+func (f *FieldEncryption) encryptField(ctx context.Context, key, value string) string {
+	if value == "" {
+		return value
+	}
+	ciphertext, err := f.Encryptor.Encrypt([]byte(value))
+	if err != nil {
+		logger.CtxErrorf(ctx, "encrypt field [%s] failed, the field is cleared, err: %v", key, err)
+		return ""
+	}
+	return EncryptedValuePrefix + f.KeyID + ":" + base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+// DecryptFieldValue decrypts the field value encrypted by FieldEncryption, and returns the key id and plaintext.
+func DecryptFieldValue(value string, encryptor Encryptor) (keyID, plaintext string, err error) {
+	if !strings.HasPrefix(value, EncryptedValuePrefix) {
+		return "", "", consts.ErrInvalidParam.Wrap(fmt.Errorf("value is not encrypted"))
+	}
+	// base64 never contains ':', so key id may contain it
+	value = strings.TrimPrefix(value, EncryptedValuePrefix)
+	index := strings.LastIndex(value, ":")
+	if index < 0 {
+		return "", "", consts.ErrInvalidParam.Wrap(fmt.Errorf("invalid encrypted value"))
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(value[index+1:])
+	if err != nil {
+		return "", "", consts.ErrInvalidParam.Wrap(fmt.Errorf("invalid encrypted value: %w", err))
+	}
+	data, err := encryptor.Decrypt(ciphertext)
+	if err != nil {
+		return "", "", err
+	}
+	return value[:index], string(data), nil
+}
+
+var _ Encryptor = (*AESEncryptor)(nil)
+
+// AESEncryptor is an Encryptor using AES-256-GCM, the random nonce is prepended to ciphertext.
+type AESEncryptor struct {
+	aead cipher.AEAD
+	// keyFingerprint is the SHA-256 of key, so that the key itself is not kept outside of aead.
+	keyFingerprint string
+}
+
+// NewAESEncryptor creates an AESEncryptor with 32-byte key.
+func NewAESEncryptor(key []byte) (*AESEncryptor, error) {
+	if len(key) != 32 {
+		return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("key of AES-256 must be 32 bytes, got %d", len(key)))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, consts.ErrInvalidParam.Wrap(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, consts.ErrInvalidParam.Wrap(err)
+	}
+	sum := sha256.Sum256(key)
+	return &AESEncryptor{aead: aead, keyFingerprint: hex.EncodeToString(sum[:])}, nil
+}
+
+func (e *AESEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return e.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (e *AESEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	nonceSize := e.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("ciphertext is too short"))
+	}
+	return e.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type failedEncryptor struct{}
+
+func (failedEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	return nil, errors.New("kms unavailable")
+}
+func (failedEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return nil, errors.New("kms unavailable")
+}
+
+func TestAESEncryptor(t *testing.T) {
+	Convey("AESEncryptor", t, func() {
+		encryptor, err := NewAESEncryptor(bytes.Repeat([]byte{1}, 32))
+		So(err, ShouldBeNil)
+
+		ciphertext, err := encryptor.Encrypt([]byte("hello"))
+		So(err, ShouldBeNil)
+		So(string(ciphertext), ShouldNotContainSubstring, "hello")
+		plaintext, err := encryptor.Decrypt(ciphertext)
+		So(err, ShouldBeNil)
+		So(string(plaintext), ShouldEqual, "hello")
+
+		// nonce is random
+		another, err := encryptor.Encrypt([]byte("hello"))
+		So(err, ShouldBeNil)
+		So(another, ShouldNotResemble, ciphertext)
+
+		_, err = encryptor.Decrypt([]byte("short"))
+		So(err, ShouldNotBeNil)
+		_, err = NewAESEncryptor([]byte("short key"))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestFieldEncryption(t *testing.T) {
+	Convey("FieldEncryption", t, func() {
+		ctx := context.Background()
+		encryptor, err := NewAESEncryptor(bytes.Repeat([]byte{1}, 32))
+		So(err, ShouldBeNil)
+		span := &entity.UploadSpan{
+			Input:      "my secret question",
+			Output:     "",
+			TagsString: map[string]string{"user.email": "a@example.com", "model_name": "gpt"},
+			TagsLong:   map[string]int64{"user.age": 30},
+		}
+		files := []*entity.UploadFile{
+			{TagKey: "input", FileType: fileTypeText, Data: "large secret input"},
+			{TagKey: "input", FileType: fileTypeImage, Data: "image"},
+		}
+
+		Convey("should encrypt input, output, tags and text files", func() {
+			f := &FieldEncryption{KeyID: "key:v1", Encryptor: encryptor, TagKeys: []string{"user.email", "user.age", "not.exist"}}
+			f.encryptUploadSpan(ctx, span, files)
+
+			So(span.Input, ShouldStartWith, "enc:key:v1:")
+			keyID, plaintext, err := DecryptFieldValue(span.Input, encryptor)
+			So(err, ShouldBeNil)
+			So(keyID, ShouldEqual, "key:v1")
+			So(plaintext, ShouldEqual, "my secret question")
+			So(span.Output, ShouldEqual, "")
+
+			_, plaintext, err = DecryptFieldValue(span.TagsString["user.email"], encryptor)
+			So(err, ShouldBeNil)
+			So(plaintext, ShouldEqual, "a@example.com")
+			_, plaintext, err = DecryptFieldValue(span.TagsString["user.age"], encryptor)
+			So(err, ShouldBeNil)
+			So(plaintext, ShouldEqual, "30")
+			So(span.TagsLong, ShouldNotContainKey, "user.age")
+			So(span.TagsString, ShouldNotContainKey, "not.exist")
+			So(span.TagsString["model_name"], ShouldEqual, "gpt")
+
+			_, plaintext, err = DecryptFieldValue(files[0].Data, encryptor)
+			So(err, ShouldBeNil)
+			So(plaintext, ShouldEqual, "large secret input")
+			So(files[1].Data, ShouldEqual, "image")
+		})
+
+		Convey("should clear fields failed to encrypt", func() {
+			f := &FieldEncryption{KeyID: "v1", Encryptor: failedEncryptor{}, TagKeys: []string{"user.email"}}
+			f.encryptUploadSpan(ctx, span, files)
+			So(span.Input, ShouldEqual, "")
+			So(span.TagsString["user.email"], ShouldEqual, "")
+			So(strings.Contains(files[0].Data, "secret"), ShouldBeFalse)
+		})
+
+		Convey("should fail to decrypt invalid value", func() {
+			_, _, err := DecryptFieldValue("plaintext", encryptor)
+			So(err, ShouldNotBeNil)
+			_, _, err = DecryptFieldValue("enc:v1", encryptor)
+			So(err, ShouldNotBeNil)
+			_, _, err = DecryptFieldValue("enc:v1:!!!", encryptor)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
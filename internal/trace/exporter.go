@@ -6,9 +6,12 @@ package trace
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/alva-ai/cozeloop-go/entity"
 	"github.com/alva-ai/cozeloop-go/internal/consts"
@@ -16,16 +19,127 @@ import (
 	"github.com/alva-ai/cozeloop-go/internal/logger"
 	model2 "github.com/alva-ai/cozeloop-go/internal/trace/model"
 	"github.com/alva-ai/cozeloop-go/internal/util"
+	"github.com/alva-ai/cozeloop-go/security"
 	"github.com/alva-ai/cozeloop-go/spec/tracespec"
 )
 
+// Exporter exports finished spans and their large files.
+// Spans passed to ExportSpans are owned by the caller, and may be cleared and reused for later spans after
+// ExportSpans returns if span pooling is enabled. Exporters retaining spans after ExportSpans returns, such as
+// recording or queueing them, must copy them, e.g. by CloneUploadSpan.
 type Exporter interface {
 	ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error
 	ExportFiles(ctx context.Context, files []*entity.UploadFile) error
 }
 
+// AckFunc confirms that exported spans are received, spans are considered delivered only if it returns nil.
+type AckFunc func() error
+
+// AckExporter is an Exporter which supports acknowledgment of exported spans, for at-least-once delivery.
+// Spans are kept by span processor until AckFunc returned by ExportSpansWithAck is called successfully.
+type AckExporter interface {
+	Exporter
+	ExportSpansWithAck(ctx context.Context, spans []*entity.UploadSpan) (AckFunc, error)
+}
+
+// PartialExportError is returned by Exporter if only some of spans or files failed to export. The others are
+// considered delivered, and span processor retries only FailedSpans or FailedFiles, so they are not sent twice.
+// Spans are matched by trace id and span id, files are matched by TosKey.
+type PartialExportError struct {
+	Err         error
+	FailedSpans []*entity.UploadSpan
+	FailedFiles []*entity.UploadFile
+}
+
+func (e *PartialExportError) Error() string {
+	return fmt.Sprintf("failed to export %d spans and %d files: %v", len(e.FailedSpans), len(e.FailedFiles), e.Err)
+}
+
+func (e *PartialExportError) Unwrap() error {
+	return e.Err
+}
+
+// mergeExportErrors merges errors of exporting the same spans or files by several exporters. The result is
+// a PartialExportError only if all errors are partial, whose failed spans and files are the union of them.
+func mergeExportErrors(errs []error) error {
+	var merged *PartialExportError
+	failedSpans := make(map[string]bool)
+	failedFiles := make(map[string]bool)
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var partialErr *PartialExportError
+		if !errors.As(err, &partialErr) {
+			return err
+		}
+		if merged == nil {
+			merged = &PartialExportError{Err: partialErr.Err}
+		}
+		for _, span := range partialErr.FailedSpans {
+			if key := uploadSpanKey(span); !failedSpans[key] {
+				failedSpans[key] = true
+				merged.FailedSpans = append(merged.FailedSpans, span)
+			}
+		}
+		for _, file := range partialErr.FailedFiles {
+			if !failedFiles[file.TosKey] {
+				failedFiles[file.TosKey] = true
+				merged.FailedFiles = append(merged.FailedFiles, file)
+			}
+		}
+	}
+	if merged == nil {
+		return nil
+	}
+	return merged
+}
+
+// uploadSpanKey identifies the span in PartialExportError.
+func uploadSpanKey(span *entity.UploadSpan) string {
+	return span.TraceID + "/" + span.SpanID
+}
+
+// spanDropper is implemented by exporters keeping spans until they are acknowledged, such as PersistentQueueExporter,
+// which are notified of spans dropped by span processor after the retry fails or the retry queue is full.
+type spanDropper interface {
+	dropSpans(ctx context.Context, spans []*entity.UploadSpan)
+}
+
+// dropSpans notifies exporter of spans dropped by span processor if it is spanDropper.
+func dropSpans(ctx context.Context, exporter Exporter, spans []*Span) {
+	dropper, ok := exporter.(spanDropper)
+	if !ok || len(spans) == 0 {
+		return
+	}
+	uploadSpans := make([]*entity.UploadSpan, 0, len(spans))
+	for _, span := range spans {
+		uploadSpans = append(uploadSpans, &entity.UploadSpan{TraceID: span.GetTraceID(), SpanID: span.GetSpanID()})
+	}
+	dropper.dropSpans(ctx, uploadSpans)
+}
+
+// exportSpansWithAck exports spans and calls AckFunc if exporter is AckExporter,
+// otherwise spans are considered delivered if ExportSpans succeeds.
+func exportSpansWithAck(ctx context.Context, exporter Exporter, spans []*entity.UploadSpan) error {
+	ackExporter, ok := exporter.(AckExporter)
+	if !ok {
+		return exporter.ExportSpans(ctx, spans)
+	}
+	ack, err := ackExporter.ExportSpansWithAck(ctx, spans)
+	if err != nil {
+		return err
+	}
+	if ack == nil {
+		return nil
+	}
+	return ack()
+}
+
 const (
 	KeyTemplateLargeText     = "%s_%s_%s_%s_large_text"
+	KeyTemplateLargeInput    = "%s/%s/%s"
+	LargeInputRefPrefix      = "file:"
 	KeyTemplateMultiModality = "%s_%s_%s_%s_%s"
 
 	fileTypeText  = "text"
@@ -36,11 +150,33 @@ const (
 	pathUploadFile  = "/v1/loop/files/upload"
 )
 
-var _ Exporter = (*SpanExporter)(nil)
+var _ AckExporter = (*SpanExporter)(nil)
 
 type SpanExporter struct {
-	client     *httpclient.Client
-	uploadPath UploadPath
+	client          *httpclient.Client
+	uploadPath      UploadPath
+	signatureSecret []byte
+}
+
+// ExporterOption is used to set options for SpanExporter, which exports spans to cozeloop server by default.
+type ExporterOption func(e *SpanExporter)
+
+// WithExportSignature set the secret to sign exported span batches with HMAC-SHA256, the signature with timestamp
+// is sent in X-Cozeloop-Signature header, and can be verified by security.VerifySignature.
+func WithExportSignature(secret []byte) ExporterOption {
+	return func(e *SpanExporter) {
+		e.signatureSecret = secret
+	}
+}
+
+// WithTLSConfig set tls config used to export spans to cozeloop server, such as client certificate for mutual TLS.
+// The exporter sends requests through a transport using config, instead of the http client set by WithHTTPClient.
+func WithTLSConfig(config *tls.Config) ExporterOption {
+	return func(e *SpanExporter) {
+		if config != nil && e.client != nil {
+			e.client = e.client.CloneWithHTTPClient(httpclient.NewTLSHTTPClient(config))
+		}
+	}
 }
 
 type UploadPath struct {
@@ -69,12 +205,29 @@ func (e *SpanExporter) ExportFiles(ctx context.Context, files []*entity.UploadFi
 	return nil
 }
 
-func (e *SpanExporter) ExportSpans(ctx context.Context, ss []*entity.UploadSpan) (err error) {
+func (e *SpanExporter) ExportSpans(ctx context.Context, ss []*entity.UploadSpan) error {
+	_, err := e.ExportSpansWithAck(ctx, ss)
+	return err
+}
+
+// ExportSpansWithAck returns AckFunc once the server responds 200 OK with code 0, which means spans are received.
+func (e *SpanExporter) ExportSpansWithAck(ctx context.Context, ss []*entity.UploadSpan) (AckFunc, error) {
+	if err := e.exportSpans(ctx, ss); err != nil {
+		return nil, err
+	}
+	return func() error { return nil }, nil
+}
+
+func (e *SpanExporter) exportSpans(ctx context.Context, ss []*entity.UploadSpan) (err error) {
 	if len(ss) == 0 {
 		return
 	}
 	resp := httpclient.BaseResponse{}
-	err = e.client.Post(ctx, e.uploadPath.spanUploadPath, UploadSpanData{ss}, &resp)
+	if len(e.signatureSecret) > 0 {
+		err = e.postSigned(ctx, UploadSpanData{ss}, &resp)
+	} else {
+		err = e.client.Post(ctx, e.uploadPath.spanUploadPath, UploadSpanData{ss}, &resp)
+	}
 	if err != nil {
 		return consts.NewError(fmt.Sprintf("export spans fail, span count: [%d]", len(ss))).Wrap(err)
 	}
@@ -85,7 +238,19 @@ func (e *SpanExporter) ExportSpans(ctx context.Context, ss []*entity.UploadSpan)
 	return
 }
 
-func transferToUploadSpanAndFile(ctx context.Context, spans []*Span) ([]*entity.UploadSpan, []*entity.UploadFile) {
+// postSigned posts the serialized data with its signature, the body is marshaled here so that
+// the signature is computed over exactly the bytes sent.
+func (e *SpanExporter) postSigned(ctx context.Context, data UploadSpanData, resp httpclient.OpenAPIResponse) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return consts.ErrInternal.Wrap(err)
+	}
+	headers := map[string]string{security.SignatureHeader: security.Sign(e.signatureSecret, body, time.Now())}
+	return e.client.PostWithHeaders(ctx, e.uploadPath.spanUploadPath, json.RawMessage(body), headers, resp)
+}
+
+// transferToUploadSpanAndFile converts spans to UploadSpans, which are taken from pool if it is not nil.
+func transferToUploadSpanAndFile(ctx context.Context, spans []*Span, pool *uploadSpanPool) ([]*entity.UploadSpan, []*entity.UploadFile) {
 	resSpan := make([]*entity.UploadSpan, 0, len(spans))
 	resFile := make([]*entity.UploadFile, 0, len(spans))
 
@@ -95,17 +260,19 @@ func transferToUploadSpanAndFile(ctx context.Context, spans []*Span) ([]*entity.
 			logger.CtxErrorf(ctx, "parseInputOutput failed, err: %v", err)
 			continue
 		}
+		spanUploadFile = append(spanUploadFile, span.getModalInputFiles()...)
 		objectStorageByte, err := transferObjectStorage(spanUploadFile)
 		if err != nil {
 			logger.CtxErrorf(ctx, "transferObjectStorage failed, err: %v", err)
 			continue
 		}
 
-		resFile = append(resFile, spanUploadFile...)
-
-		tagStrM, tagLongM, tagDoubleM, tagBoolM := parseTag(span.TagMap, false)
-		systemTagStrM, systemTagLongM, systemTagDoubleM, _ := parseTag(span.SystemTagMap, true)
-		resSpan = append(resSpan, &entity.UploadSpan{
+		uploadSpan := pool.get()
+		tagStrM, tagLongM, tagDoubleM, tagBoolM := parseTagInto(span.TagMap, false,
+			uploadSpan.TagsString, uploadSpan.TagsLong, uploadSpan.TagsDouble, uploadSpan.TagsBool)
+		systemTagStrM, systemTagLongM, systemTagDoubleM, _ := parseTagInto(span.SystemTagMap, true,
+			uploadSpan.SystemTagsString, uploadSpan.SystemTagsLong, uploadSpan.SystemTagsDouble, nil)
+		*uploadSpan = entity.UploadSpan{
 			StartedATMicros:  span.GetStartTime().UnixMicro(),
 			LogID:            span.GetLogID(),
 			SpanID:           span.GetSpanID(),
@@ -127,21 +294,42 @@ func transferToUploadSpanAndFile(ctx context.Context, spans []*Span) ([]*entity.
 			TagsLong:         tagLongM,
 			TagsDouble:       tagDoubleM,
 			TagsBool:         tagBoolM,
-		})
+			Annotations:      span.GetAnnotations(),
+		}
+		if span.fieldEncryption != nil {
+			span.fieldEncryption.encryptUploadSpan(ctx, uploadSpan, spanUploadFile)
+		}
+		resSpan = append(resSpan, uploadSpan)
+		resFile = append(resFile, spanUploadFile...)
 	}
 
 	return resSpan, resFile
 }
 
 func parseTag(spanTag map[string]interface{}, isSystemTag bool) (map[string]string, map[string]int64, map[string]float64, map[string]bool) {
+	return parseTagInto(spanTag, isSystemTag, nil, nil, nil, nil)
+}
+
+// parseTagInto is the same as parseTag, but writes tags into the given maps of pooled UploadSpan if not nil.
+func parseTagInto(spanTag map[string]interface{}, isSystemTag bool, vStrMap map[string]string, vLongMap map[string]int64,
+	vDoubleMap map[string]float64, vBoolMap map[string]bool,
+) (map[string]string, map[string]int64, map[string]float64, map[string]bool) {
 	if len(spanTag) == 0 {
-		return nil, nil, nil, nil
+		return vStrMap, vLongMap, vDoubleMap, vBoolMap
 	}
 
-	vStrMap := make(map[string]string)
-	vLongMap := make(map[string]int64)
-	vDoubleMap := make(map[string]float64)
-	vBoolMap := make(map[string]bool)
+	if vStrMap == nil {
+		vStrMap = make(map[string]string)
+	}
+	if vLongMap == nil {
+		vLongMap = make(map[string]int64)
+	}
+	if vDoubleMap == nil {
+		vDoubleMap = make(map[string]float64)
+	}
+	if vBoolMap == nil {
+		vBoolMap = make(map[string]bool)
+	}
 	for key, value := range spanTag {
 		if key == tracespec.Input || key == tracespec.Output {
 			continue
@@ -236,14 +424,12 @@ func convertInput(ctx context.Context, spanKey string, span *Span) (valueRes str
 		}
 		valueRes = string(tempV)
 
-		// If the content is still too long, truncate it, and
-		// decide whether to report the oversized content based on the UltraLargeReport option.
-		if len(valueRes) > consts.MaxBytesOfOneTagValueOfInputOutput {
-			var f *entity.UploadFile
-			valueRes, f = transferText(valueRes, span, spanKey)
-			if f != nil {
-				uploadFile = append(uploadFile, f)
-			}
+		// If the content is larger than the input file threshold, export it as a file. If it is still too long,
+		// truncate it, and decide whether to report the oversized content based on the UltraLargeReport option.
+		var f *entity.UploadFile
+		valueRes, f = transferText(valueRes, span, spanKey)
+		if f != nil {
+			uploadFile = append(uploadFile, f)
 		}
 	}
 
@@ -287,14 +473,12 @@ func convertOutput(ctx context.Context, spanKey string, span *Span) (valueRes st
 		}
 		valueRes = string(tempV)
 
-		// If the content is still too long, truncate it, and
-		// decide whether to report the oversized content based on the UltraLargeReport option.
-		if len(valueRes) > consts.MaxBytesOfOneTagValueOfInputOutput {
-			var f *entity.UploadFile
-			valueRes, f = transferText(valueRes, span, spanKey)
-			if f != nil {
-				uploadFile = append(uploadFile, f)
-			}
+		// If the content is larger than the input file threshold, export it as a file. If it is still too long,
+		// truncate it, and decide whether to report the oversized content based on the UltraLargeReport option.
+		var f *entity.UploadFile
+		valueRes, f = transferText(valueRes, span, spanKey)
+		if f != nil {
+			uploadFile = append(uploadFile, f)
 		}
 	}
 
@@ -388,6 +572,19 @@ func transferText(src string, span *Span, tagKey string) (string, *entity.Upload
 		return "", nil
 	}
 
+	if span.inputFileThreshold > 0 && len(src) > span.inputFileThreshold {
+		// key := "traceid/spanid/tagkey", value is replaced with reference of file
+		key := fmt.Sprintf(KeyTemplateLargeInput, span.GetTraceID(), span.GetSpanID(), tagKey)
+		return LargeInputRefPrefix + key, &entity.UploadFile{
+			TosKey:     key,
+			Data:       src,
+			UploadType: entity.UploadTypeLong,
+			TagKey:     tagKey,
+			FileType:   fileTypeText,
+			SpaceID:    span.GetSpaceID(),
+		}
+	}
+
 	if !span.UltraLargeReport() {
 		return src, nil
 	}
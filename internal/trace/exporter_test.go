@@ -5,11 +5,28 @@ package trace
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	. "github.com/bytedance/mockey"
 	"github.com/alva-ai/cozeloop-go/entity"
 	"github.com/alva-ai/cozeloop-go/internal/httpclient"
+	"github.com/alva-ai/cozeloop-go/security"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -23,3 +40,164 @@ func Test_ExportSpans(t *testing.T) {
 		So(err, ShouldBeNil)
 	})
 }
+
+// writeTestCert issues a certificate signed by ca (self-signed if ca is nil), and writes it and its key as PEM files.
+func writeTestCert(dir, name string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	So(err, ShouldBeNil)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		ca, caKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	So(err, ShouldBeNil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	So(err, ShouldBeNil)
+	So(os.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600), ShouldBeNil)
+	So(os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600), ShouldBeNil)
+	cert, err := x509.ParseCertificate(der)
+	So(err, ShouldBeNil)
+	return cert, key
+}
+
+func Test_ExportSpansWithMTLS(t *testing.T) {
+	ctx := context.Background()
+	spans := []*entity.UploadSpan{{SpanID: "span1"}}
+
+	Convey("Test export spans to mTLS server", t, func() {
+		dir := t.TempDir()
+		ca, caKey := writeTestCert(dir, "ca", nil, nil)
+		writeTestCert(dir, "server", ca, caKey)
+		writeTestCert(dir, "client", ca, caKey)
+
+		serverConfig, err := httpclient.NewTLSConfigFromFiles(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt"))
+		So(err, ShouldBeNil)
+		So(serverConfig.ClientAuth, ShouldEqual, tls.RequireAndVerifyClientCert)
+		var clientCN string
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientCN = r.TLS.PeerCertificates[0].Subject.CommonName
+			_, _ = w.Write([]byte(`{"code":0,"msg":"success"}`))
+		}))
+		server.TLS = serverConfig
+		server.StartTLS()
+		defer server.Close()
+
+		clientConfig, err := httpclient.NewTLSConfigFromFiles(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"), filepath.Join(dir, "ca.crt"))
+		So(err, ShouldBeNil)
+		exporter := &SpanExporter{
+			client:     httpclient.NewClient(server.URL, http.DefaultClient, httpclient.NewTokenAuth("token"), nil),
+			uploadPath: UploadPath{spanUploadPath: pathIngestTrace},
+		}
+		// handshake fails with http client not trusting the CA
+		So(exporter.ExportSpans(ctx, spans), ShouldNotBeNil)
+
+		WithTLSConfig(clientConfig)(exporter)
+		So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+		So(clientCN, ShouldEqual, "client")
+
+		// handshake fails without client certificate
+		clientConfig.Certificates = nil
+		exporter.client = httpclient.NewClient(server.URL, http.DefaultClient, httpclient.NewTokenAuth("token"), nil)
+		WithTLSConfig(clientConfig)(exporter)
+		So(exporter.ExportSpans(ctx, spans), ShouldNotBeNil)
+
+		_, err = httpclient.NewTLSConfigFromFiles(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"), filepath.Join(dir, "client.key"))
+		So(err, ShouldNotBeNil)
+		_, err = httpclient.NewTLSConfigFromFiles(filepath.Join(dir, "not-exist.crt"), filepath.Join(dir, "client.key"), filepath.Join(dir, "ca.crt"))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func Test_ExportSpansWithSignature(t *testing.T) {
+	ctx := context.Background()
+	spans := []*entity.UploadSpan{{SpanID: "span1"}}
+
+	Convey("Test export spans with signature", t, func() {
+		secret := []byte("secret")
+		var verified bool
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			verified = security.VerifySignature(secret, body, r.Header.Get(security.SignatureHeader))
+			_, _ = w.Write([]byte(`{"code":0,"msg":"success"}`))
+		}))
+		defer server.Close()
+
+		exporter := &SpanExporter{
+			client:     httpclient.NewClient(server.URL, http.DefaultClient, httpclient.NewTokenAuth("token"), nil),
+			uploadPath: UploadPath{spanUploadPath: pathIngestTrace},
+		}
+		WithExportSignature(secret)(exporter)
+		So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+		So(verified, ShouldBeTrue)
+
+		WithExportSignature([]byte("other"))(exporter)
+		So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+		So(verified, ShouldBeFalse)
+	})
+}
+
+func Test_TransferLargeInputAsFile(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test input/output exceeding threshold exported as file", t, func() {
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws", InputFileThreshold: 8},
+			spanProcessor: noopSpanProcessor{},
+		}
+		_, span, err := p.StartSpan(ctx, "llm", "model", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		span.SetInput(ctx, "a long long document")
+		span.SetOutput(ctx, "short")
+
+		uploadSpans, uploadFiles := transferToUploadSpanAndFile(ctx, []*Span{span}, nil)
+		So(len(uploadSpans), ShouldEqual, 1)
+		var files []*entity.UploadFile
+		for _, f := range uploadFiles {
+			if f != nil {
+				files = append(files, f)
+			}
+		}
+		So(len(files), ShouldEqual, 1)
+		tosKey := span.GetTraceID() + "/" + span.GetSpanID() + "/input"
+		So(files[0].TosKey, ShouldEqual, tosKey)
+		So(files[0].Data, ShouldEqual, "a long long document")
+		So(uploadSpans[0].Input, ShouldEqual, "file:"+tosKey)
+		So(uploadSpans[0].Output, ShouldEqual, "short")
+		So(uploadSpans[0].ObjectStorage, ShouldContainSubstring, tosKey)
+	})
+
+	Convey("Test multi-modality input exceeding threshold exported as file", t, func() {
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws", InputFileThreshold: 8},
+			spanProcessor: noopSpanProcessor{},
+		}
+		_, span, err := p.StartSpan(ctx, "llm", "model", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		input := `{"messages":[{"role":"user","parts":[{"type":"text","text":"a long long document"}]}]}`
+		span.SetInput(ctx, input)
+		span.SetMultiModalityMap(tracespec.Input)
+
+		uploadSpans, uploadFiles := transferToUploadSpanAndFile(ctx, []*Span{span}, nil)
+		So(len(uploadSpans), ShouldEqual, 1)
+		tosKey := span.GetTraceID() + "/" + span.GetSpanID() + "/input"
+		So(uploadSpans[0].Input, ShouldEqual, "file:"+tosKey)
+		var files []*entity.UploadFile
+		for _, f := range uploadFiles {
+			if f != nil && f.TosKey == tosKey {
+				files = append(files, f)
+			}
+		}
+		So(len(files), ShouldEqual, 1)
+		So(files[0].Data, ShouldEqual, input)
+	})
+}
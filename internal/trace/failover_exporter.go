@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+var _ Exporter = (*FailoverExporter)(nil)
+
+// FailoverExporter exports to the primary exporter, and fails over to the secondary exporter
+// if the primary returns a retryable error or times out.
+type FailoverExporter struct {
+	primary        Exporter
+	secondary      Exporter
+	primaryTimeout time.Duration
+	cooldown       time.Duration
+
+	failoverUntil int64 // unix nano, use secondary directly before it
+}
+
+// FailoverOption is used to set options for FailoverExporter.
+type FailoverOption func(e *FailoverExporter)
+
+// WithFailoverPrimaryTimeout set the timeout of primary exporter, fail over to secondary if exceeded.
+// Default is 30s. Note that the timed out primary export is not canceled if it ignores ctx,
+// so the batch may be exported by both exporters.
+func WithFailoverPrimaryTimeout(d time.Duration) FailoverOption {
+	return func(e *FailoverExporter) {
+		e.primaryTimeout = d
+	}
+}
+
+// WithFailoverCooldown set the duration to keep using secondary after a failover event,
+// which avoids thundering herd on primary recovery. Default is 0, means trying primary every time.
+func WithFailoverCooldown(d time.Duration) FailoverOption {
+	return func(e *FailoverExporter) {
+		e.cooldown = d
+	}
+}
+
+// NewFailoverExporter creates a new FailoverExporter with primary and secondary exporters.
+func NewFailoverExporter(primary, secondary Exporter, opts ...FailoverOption) *FailoverExporter {
+	e := &FailoverExporter{
+		primary:        primary,
+		secondary:      secondary,
+		primaryTimeout: consts.DefaultUploadTimeout,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	return e
+}
+
+func (e *FailoverExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	return e.export(ctx, "spans", func(ctx context.Context, exporter Exporter) error {
+		return exporter.ExportSpans(ctx, spans)
+	})
+}
+
+func (e *FailoverExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+	return e.export(ctx, "files", func(ctx context.Context, exporter Exporter) error {
+		return exporter.ExportFiles(ctx, files)
+	})
+}
+
+func (e *FailoverExporter) export(ctx context.Context, kind string, fn func(ctx context.Context, exporter Exporter) error) error {
+	if e.primary == nil {
+		return e.exportSecondary(ctx, fn)
+	}
+	if e.secondary == nil {
+		return fn(ctx, e.primary)
+	}
+	if time.Now().UnixNano() < atomic.LoadInt64(&e.failoverUntil) {
+		return e.exportSecondary(ctx, fn)
+	}
+
+	err := e.exportPrimary(ctx, fn)
+	if err == nil || !isRetryableExportError(err) {
+		return err
+	}
+
+	logger.CtxWarnf(ctx, "failover exporter: failed to export %s to primary, fail over to secondary: %v", kind, err)
+	if e.cooldown > 0 {
+		atomic.StoreInt64(&e.failoverUntil, time.Now().Add(e.cooldown).UnixNano())
+	}
+	return e.exportSecondary(ctx, fn)
+}
+
+func (e *FailoverExporter) exportPrimary(ctx context.Context, fn func(ctx context.Context, exporter Exporter) error) error {
+	if e.primaryTimeout <= 0 {
+		return fn(ctx, e.primary)
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.primaryTimeout)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn(ctx, e.primary)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *FailoverExporter) exportSecondary(ctx context.Context, fn func(ctx context.Context, exporter Exporter) error) error {
+	if e.secondary == nil {
+		return nil
+	}
+	return fn(ctx, e.secondary)
+}
+
+// isRetryableExportError returns false for errors which fail on any endpoint, such as invalid param or 4xx response.
+func isRetryableExportError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, consts.ErrInvalidParam) {
+		return false
+	}
+	var remoteErr *consts.RemoteServiceError
+	if errors.As(err, &remoteErr) {
+		code := remoteErr.HttpCode
+		return code == 0 || code >= http.StatusInternalServerError ||
+			code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// funcExporter is an Exporter calling the given function, for testing
+type funcExporter struct {
+	exportSpans func(ctx context.Context, spans []*entity.UploadSpan) error
+	calls       int
+}
+
+func (e *funcExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	e.calls++
+	if e.exportSpans == nil {
+		return nil
+	}
+	return e.exportSpans(ctx, spans)
+}
+
+func (e *funcExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return nil
+}
+
+func TestFailoverExporter(t *testing.T) {
+	Convey("FailoverExporter", t, func() {
+		ctx := context.Background()
+		spans := []*entity.UploadSpan{{SpanID: "span1"}}
+		secondary := &funcExporter{}
+
+		Convey("should use primary if succeeded", func() {
+			primary := &funcExporter{}
+			e := NewFailoverExporter(primary, secondary)
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(primary.calls, ShouldEqual, 1)
+			So(secondary.calls, ShouldEqual, 0)
+		})
+
+		Convey("should fail over on retryable error", func() {
+			primary := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+				return consts.NewRemoteServiceError(503, 0, "unavailable", "")
+			}}
+			e := NewFailoverExporter(primary, secondary)
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(secondary.calls, ShouldEqual, 1)
+		})
+
+		Convey("should not fail over on non-retryable error", func() {
+			primary := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+				return fmt.Errorf("bad request: %w", consts.NewRemoteServiceError(400, 0, "bad request", ""))
+			}}
+			e := NewFailoverExporter(primary, secondary)
+			So(e.ExportSpans(ctx, spans), ShouldNotBeNil)
+			So(secondary.calls, ShouldEqual, 0)
+		})
+
+		Convey("should fail over when primary times out", func() {
+			primary := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+				time.Sleep(200 * time.Millisecond)
+				return nil
+			}}
+			e := NewFailoverExporter(primary, secondary, WithFailoverPrimaryTimeout(10*time.Millisecond))
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(secondary.calls, ShouldEqual, 1)
+		})
+
+		Convey("should keep using secondary during cooldown", func() {
+			primary := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+				return errors.New("connection refused")
+			}}
+			e := NewFailoverExporter(primary, secondary, WithFailoverCooldown(time.Hour))
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(primary.calls, ShouldEqual, 1)
+			So(secondary.calls, ShouldEqual, 2)
+		})
+	})
+}
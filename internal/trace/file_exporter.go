@@ -4,8 +4,13 @@
 package trace
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -14,29 +19,98 @@ import (
 	"time"
 
 	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/consts"
 	"github.com/alva-ai/cozeloop-go/internal/logger"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
 )
 
 const (
-	DefaultLocalExportPath = "./cozeloop_traces.md"
+	DefaultLocalExportPath     = "./cozeloop_traces.md"
+	DefaultLocalJSONExportPath = "./cozeloop_traces.json"
+
+	fileExporterChunkSize = 64 * 1024
+	traceSectionPrefix    = "# Trace: "
+
+	// spanMarkerPrefix starts the html comment lines which enclose markdown of each span, lines of content with
+	// the prefix are escaped by markdownWriter, so that span boundaries are never faked or hidden by content.
+	spanMarkerPrefix      = "<!-- cozeloop:"
+	spanStartMarkerPrefix = spanMarkerPrefix + "span "
+	spanMarkerSuffix      = " -->"
+	spanEndMarker         = spanMarkerPrefix + "span-end" + spanMarkerSuffix
+
+	// chatMessageFormatRoleContent is the value of system tag consts.ChatMessageFormat set by
+	// StartSpanOptions.ChatMessageFormat, chat messages of the span are written as `**role**: content`.
+	chatMessageFormatRoleContent = "role_content"
 )
 
 var _ Exporter = (*FileExporter)(nil)
 
-// FileExporter exports spans to a local markdown file
+// FileExporter exports spans to a local markdown file, spans are written to the opened file by WriterExporter.
 type FileExporter struct {
 	filePath string
+	writer   WriterExporter // formats spans, its writer is not used
+	gzip     bool           // each batch is appended as a gzip member, the file is a valid multi-member gzip stream
+	locked   bool           // hold an exclusive file lock while writing each batch, set by LockedFileExporter
+	lockWait time.Duration  // max time to wait for the file lock
 	mu       sync.Mutex
 }
 
+// FileExporterOption is used to set options for FileExporter.
+type FileExporterOption func(e *FileExporter)
+
+// WithStreamingWrite set whether to write markdown of each span to the file directly through a fixed size buffer,
+// instead of building the whole markdown of span in memory first. Default is false.
+func WithStreamingWrite(enable bool) FileExporterOption {
+	return func(e *FileExporter) {
+		e.writer.streamingWrite = enable
+	}
+}
+
+// WithGzipCompression set FileExporter to compress the file with gzip, and `.gz` is appended to the file path
+// if absent. Each batch of spans is written as a complete gzip member, so the file can be read by zcat at any time.
+func WithGzipCompression() FileExporterOption {
+	return func(e *FileExporter) {
+		e.gzip = true
+	}
+}
+
+func withNDJSONFormat() FileExporterOption {
+	return func(e *FileExporter) {
+		e.writer.ndjson = true
+	}
+}
+
 // NewFileExporter creates a new FileExporter with the given file path
-func NewFileExporter(filePath string) *FileExporter {
+func NewFileExporter(filePath string, opts ...FileExporterOption) *FileExporter {
 	if filePath == "" {
 		filePath = DefaultLocalExportPath
 	}
-	return &FileExporter{
+	e := &FileExporter{
 		filePath: filePath,
 	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	if e.gzip && !strings.HasSuffix(e.filePath, ".gz") {
+		e.filePath += ".gz"
+	}
+	return e
+}
+
+// NewGzipJSONFileExporter creates a FileExporter writing spans as gzip compressed NDJSON, one UploadSpan per line,
+// which can be read by `zcat file.json.gz | jq`. Default path is ./cozeloop_traces.json.gz if filePath is empty.
+func NewGzipJSONFileExporter(filePath string) *FileExporter {
+	if filePath == "" {
+		filePath = DefaultLocalJSONExportPath
+	}
+	return NewFileExporter(filePath, withNDJSONFormat(), WithGzipCompression())
+}
+
+// FilePath returns the path of exported file, which has `.gz` suffix if compressed.
+func (e *FileExporter) FilePath() string {
+	return e.filePath
 }
 
 // ExportSpans writes spans to the markdown file
@@ -65,16 +139,32 @@ func (e *FileExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSp
 	}
 	defer f.Close()
 
-	// Write spans to file
-	for _, span := range spans {
-		if span == nil {
-			continue
-		}
-		md := e.spanToMarkdown(span)
-		if _, err := f.WriteString(md); err != nil {
-			logger.CtxErrorf(ctx, "failed to write span to file: %v", err)
+	// Lock file against writes of other processes
+	if e.locked {
+		if err = lockFile(ctx, f, e.lockWait); err != nil {
+			logger.CtxErrorf(ctx, "failed to lock trace file: %v", err)
 			return err
 		}
+		defer unlockFile(f)
+	}
+
+	// Write spans to file
+	var w io.Writer = f
+	var gw *gzip.Writer
+	if e.gzip {
+		gw = gzip.NewWriter(f)
+		w = gw
+	}
+	err = e.writer.writeSpans(w, spans)
+	if gw != nil {
+		// close the gzip member of this batch even on error, so that the file is still readable
+		if closeErr := gw.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		logger.CtxErrorf(ctx, "failed to write span to file: %v", err)
+		return err
 	}
 
 	logger.CtxDebugf(ctx, "exported %d spans to file: %s", len(spans), e.filePath)
@@ -88,12 +178,172 @@ func (e *FileExporter) ExportFiles(ctx context.Context, files []*entity.UploadFi
 	return nil
 }
 
-// spanToMarkdown converts a span to markdown format
-func (e *FileExporter) spanToMarkdown(span *entity.UploadSpan) string {
-	var sb strings.Builder
+// otelUserIDTagKey is the user id tag key of OpenTelemetry semantic conventions.
+const otelUserIDTagKey = "user.id"
+
+// DeleteSpansByUserID removes spans whose user_id tag (set by SetUserID) or user.id tag equals userID from the file,
+// for GDPR right-to-erasure requests. Spans are located by the markers written around each span, which carry the
+// user ids of span, so content of spans is never parsed. Spans written without markers by earlier versions are
+// not recognized. The file is read and written in chunks, and replaced atomically by renaming a temp file,
+// so that it is never left partially written. Exporting is blocked during the operation.
+// Gzip compressed files are supported, but NDJSON files created by NewGzipJSONFileExporter are not.
+func (e *FileExporter) DeleteSpansByUserID(ctx context.Context, userID string) (deletedCount int, err error) {
+	if userID == "" {
+		return 0, consts.ErrInvalidParam.Wrap(fmt.Errorf("userID is empty"))
+	}
+	if e.writer.ndjson {
+		return 0, consts.ErrInvalidParam.Wrap(fmt.Errorf("deleting spans from NDJSON file is not supported"))
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	src, err := os.Open(e.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(e.filePath), filepath.Base(e.filePath)+".tmp*")
+	if err != nil {
+		return 0, err
+	}
+	renamed := false
+	defer func() {
+		if !renamed {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	var in io.Reader = src
+	var out io.Writer = tmp
+	var gw *gzip.Writer
+	if e.gzip {
+		gr, err := gzip.NewReader(src)
+		if err != nil {
+			if err == io.EOF { // empty file
+				return 0, nil
+			}
+			return 0, err
+		}
+		defer gr.Close()
+		in = gr
+		gw = gzip.NewWriter(tmp)
+		out = gw
+	}
+	r := bufio.NewReaderSize(in, fileExporterChunkSize)
+	w := bufio.NewWriterSize(out, fileExporterChunkSize)
+	// content outside spans and each span section are buffered, only one span is in memory at a time
+	var section strings.Builder
+	var matched, inSpan bool
+	flushSection := func() error {
+		defer section.Reset()
+		if matched {
+			deletedCount++
+			matched = false
+			return nil
+		}
+		_, err := w.WriteString(section.String())
+		return err
+	}
+	for {
+		line, readErr := r.ReadString('\n')
+		if line != "" {
+			trimmed := strings.TrimRight(line, "\r\n")
+			if strings.HasPrefix(trimmed, spanStartMarkerPrefix) {
+				if err = flushSection(); err != nil {
+					return 0, err
+				}
+				inSpan = true
+				matched = spanMarkerHasUserID(trimmed, userID)
+			}
+			section.WriteString(line)
+			if inSpan && trimmed == spanEndMarker {
+				if err = flushSection(); err != nil {
+					return 0, err
+				}
+				inSpan = false
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return 0, readErr
+		}
+	}
+	if err = flushSection(); err != nil {
+		return 0, err
+	}
+	if deletedCount == 0 {
+		return 0, nil
+	}
+
+	if err = w.Flush(); err != nil {
+		return 0, err
+	}
+	if gw != nil {
+		if err = gw.Close(); err != nil {
+			return 0, err
+		}
+	}
+	if err = tmp.Chmod(info.Mode().Perm()); err != nil {
+		return 0, err
+	}
+	if err = tmp.Sync(); err != nil {
+		return 0, err
+	}
+	if err = tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err = os.Rename(tmp.Name(), e.filePath); err != nil {
+		return 0, err
+	}
+	renamed = true
+	logger.CtxInfof(ctx, "deleted %d spans of user from file: %s", deletedCount, e.filePath)
+	return deletedCount, nil
+}
+
+// spanStartMarker returns the marker line written before markdown of span, which carries the user ids of span
+// in JSON. Html characters are escaped by json.Marshal, so the marker never contains spanMarkerSuffix early.
+func spanStartMarker(span *entity.UploadSpan) string {
+	userIDs := make(map[string]string)
+	for _, key := range []string{consts.UserID, otelUserIDTagKey} {
+		if v, ok := span.TagsString[key]; ok {
+			userIDs[key] = v
+		}
+	}
+	data, _ := json.Marshal(userIDs)
+	return spanStartMarkerPrefix + string(data) + spanMarkerSuffix
+}
+
+// spanMarkerHasUserID reports whether the start marker line carries userID as user_id or user.id.
+func spanMarkerHasUserID(marker string, userID string) bool {
+	data := strings.TrimSuffix(strings.TrimPrefix(marker, spanStartMarkerPrefix), spanMarkerSuffix)
+	userIDs := make(map[string]string)
+	if err := json.Unmarshal([]byte(data), &userIDs); err != nil {
+		return false
+	}
+	return userIDs[consts.UserID] == userID || userIDs[otelUserIDTagKey] == userID
+}
+
+// spanToMarkdown writes a span to w in markdown format enclosed by span markers, and returns the first error of writing
+func spanToMarkdown(w io.Writer, span *entity.UploadSpan) error {
+	sb := &markdownWriter{w: w}
+	chatFormat := span.SystemTagsString[consts.ChatMessageFormat] == chatMessageFormatRoleContent
+
+	sb.writeMarker(spanStartMarker(span))
 
 	// Header with trace info
-	sb.WriteString(fmt.Sprintf("# Trace: %s\n\n", span.TraceID))
+	sb.WriteString(fmt.Sprintf("%s%s\n\n", traceSectionPrefix, span.TraceID))
 
 	// Span section
 	sb.WriteString(fmt.Sprintf("## Span: %s\n\n", span.SpanName))
@@ -118,6 +368,11 @@ func (e *FileExporter) spanToMarkdown(span *entity.UploadSpan) string {
 	}
 	sb.WriteString(fmt.Sprintf("- **Status:** %s (%d)\n", statusText, span.StatusCode))
 
+	// Token usage
+	if tokens := formatTokenUsage(span.TagsLong); tokens != "" {
+		sb.WriteString(fmt.Sprintf("- **Tokens:** %s\n", tokens))
+	}
+
 	// Service and workspace info
 	if span.ServiceName != "" {
 		sb.WriteString(fmt.Sprintf("- **Service:** %s\n", span.ServiceName))
@@ -134,17 +389,33 @@ func (e *FileExporter) spanToMarkdown(span *entity.UploadSpan) string {
 	// Input section
 	if span.Input != "" {
 		sb.WriteString("### Input\n\n")
-		sb.WriteString("```\n")
-		sb.WriteString(truncateString(span.Input, 2000))
-		sb.WriteString("\n```\n\n")
+		writeContent(sb, span.Input, chatFormat)
 	}
 
 	// Output section
 	if span.Output != "" {
 		sb.WriteString("### Output\n\n")
-		sb.WriteString("```\n")
-		sb.WriteString(truncateString(span.Output, 2000))
-		sb.WriteString("\n```\n\n")
+		writeContent(sb, span.Output, chatFormat)
+	}
+
+	// Tokens by part section
+	if parts := parseTokensByPart(span.TagsString); len(parts) > 0 {
+		sb.WriteString("### Tokens by Part\n\n")
+		sb.WriteString("| Part | Tokens |\n")
+		sb.WriteString("|------|--------|\n")
+		for _, part := range parts {
+			sb.WriteString(fmt.Sprintf("| %s | %d |\n", escapeMarkdown(part.Part), part.Tokens))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Search snippets section
+	if snippets := parseSearchSnippets(span.TagsString); len(snippets) > 0 {
+		sb.WriteString("### Search Snippets\n\n")
+		for i, snippet := range snippets {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, escapeMarkdown(snippet)))
+		}
+		sb.WriteString("\n")
 	}
 
 	// Tags section
@@ -157,7 +428,7 @@ func (e *FileExporter) spanToMarkdown(span *entity.UploadSpan) string {
 		sb.WriteString("|-----|-------|\n")
 
 		// String tags
-		writeTagsToTable(&sb, span.TagsString)
+		writeTagsToTable(sb, span.TagsString)
 
 		// Long tags
 		for k, v := range span.TagsLong {
@@ -186,7 +457,7 @@ func (e *FileExporter) spanToMarkdown(span *entity.UploadSpan) string {
 		sb.WriteString("| Key | Value |\n")
 		sb.WriteString("|-----|-------|\n")
 
-		writeTagsToTable(&sb, span.SystemTagsString)
+		writeTagsToTable(sb, span.SystemTagsString)
 
 		for k, v := range span.SystemTagsLong {
 			sb.WriteString(fmt.Sprintf("| %s | %d |\n", escapeMarkdown(k), v))
@@ -199,14 +470,111 @@ func (e *FileExporter) spanToMarkdown(span *entity.UploadSpan) string {
 		sb.WriteString("\n")
 	}
 
+	// Annotations section
+	if len(span.Annotations) > 0 {
+		sb.WriteString("### Annotations\n\n")
+		for _, annotation := range span.Annotations {
+			ts := time.UnixMicro(annotation.Timestamp)
+			sb.WriteString(fmt.Sprintf("- %s %s\n", ts.Format("2006-01-02 15:04:05.000"), escapeMarkdown(annotation.Message)))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Citations section
+	writeCitationsToTable(sb, span.TagsString[tracespec.RAGCitations])
+
+	// Retrieved documents section
+	writeDocumentsToTable(sb, span.TagsString[tracespec.RAGDocuments])
+
+	// Tool schema section
+	writeToolSchema(sb, span.TagsString[tracespec.ToolSchema])
+
+	// Function call sections
+	writeFunctionCall(sb, span)
+
 	// Separator
 	sb.WriteString("---\n\n")
+	sb.writeMarker(spanEndMarker)
+
+	return sb.err
+}
+
+// markdownWriter writes strings to w, and keeps the first error so that it is checked once after writing a span.
+// Lines starting with spanMarkerPrefix are escaped with a backslash, only writeMarker writes span markers.
+type markdownWriter struct {
+	w       io.Writer
+	err     error
+	midLine bool // the last written string does not end with a newline
+}
 
-	return sb.String()
+func (mw *markdownWriter) WriteString(s string) {
+	if mw.err != nil || s == "" {
+		return
+	}
+	if strings.Contains(s, spanMarkerPrefix) {
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			if (i > 0 || !mw.midLine) && strings.HasPrefix(line, spanMarkerPrefix) {
+				lines[i] = "\\" + line
+			}
+		}
+		s = strings.Join(lines, "\n")
+	}
+	_, mw.err = io.WriteString(mw.w, s)
+	mw.midLine = s[len(s)-1] != '\n'
+}
+
+// writeMarker writes a span marker line without escaping.
+func (mw *markdownWriter) writeMarker(marker string) {
+	if mw.err != nil {
+		return
+	}
+	if mw.midLine {
+		marker = "\n" + marker
+	}
+	_, mw.err = io.WriteString(mw.w, marker+"\n")
+	mw.midLine = false
+}
+
+// writeContent writes input or output in a code block, or as `**role**: content` of each message
+// if chatFormat is set and content is a JSON array of chat messages.
+func writeContent(sb *markdownWriter, content string, chatFormat bool) {
+	if chatFormat {
+		if messages, ok := parseChatMessages(content); ok {
+			for _, m := range messages {
+				role := m.Role
+				if m.Name != "" {
+					role += " (" + m.Name + ")"
+				}
+				sb.WriteString(fmt.Sprintf("**%s**: %s\n\n", escapeMarkdown(role), truncateString(m.Content, 2000)))
+			}
+			return
+		}
+	}
+	sb.WriteString("```\n")
+	sb.WriteString(truncateString(content, 2000))
+	sb.WriteString("\n```\n\n")
+}
+
+// parseChatMessages parses content as a non-empty JSON array of chat messages with roles.
+func parseChatMessages(content string) ([]tracespec.ChatMessage, bool) {
+	if !strings.HasPrefix(content, "[") {
+		return nil, false
+	}
+	var messages []tracespec.ChatMessage
+	if err := json.Unmarshal([]byte(content), &messages); err != nil || len(messages) == 0 {
+		return nil, false
+	}
+	for _, m := range messages {
+		if m.Role == "" {
+			return nil, false
+		}
+	}
+	return messages, true
 }
 
 // writeTagsToTable writes string tags to markdown table in sorted order
-func writeTagsToTable(sb *strings.Builder, tags map[string]string) {
+func writeTagsToTable(sb *markdownWriter, tags map[string]string) {
 	if len(tags) == 0 {
 		return
 	}
@@ -219,13 +587,116 @@ func writeTagsToTable(sb *strings.Builder, tags map[string]string) {
 	sort.Strings(keys)
 
 	for _, k := range keys {
-		v := tags[k]
-		// Truncate long values for readability
-		displayValue := truncateString(v, 100)
+		displayValue := tags[k]
+		// Truncate long values for readability, except user id which is matched exactly by DeleteSpansByUserID
+		if k != consts.UserID && k != otelUserIDTagKey {
+			displayValue = truncateString(displayValue, 100)
+		}
 		sb.WriteString(fmt.Sprintf("| %s | %s |\n", escapeMarkdown(k), escapeMarkdown(displayValue)))
 	}
 }
 
+// writeToolSchema writes tool schema set by SetToolSchema as JSON code block, which is indented if valid JSON
+func writeToolSchema(sb *markdownWriter, value string) {
+	if value == "" {
+		return
+	}
+	sb.WriteString("### Tool Schema\n\n")
+	sb.WriteString("```json\n")
+	sb.WriteString(indentJSON(value))
+	sb.WriteString("\n```\n\n")
+}
+
+// writeFunctionCall writes function call and its result set by SetFunctionCall and SetFunctionCallResult
+func writeFunctionCall(sb *markdownWriter, span *entity.UploadSpan) {
+	if name, ok := span.TagsString[tracespec.FunctionName]; ok {
+		sb.WriteString("### Function Call\n\n")
+		sb.WriteString(fmt.Sprintf("- **Name:** %s\n", name))
+		if callID := span.TagsString[tracespec.FunctionCallID]; callID != "" {
+			sb.WriteString(fmt.Sprintf("- **Call ID:** %s\n", callID))
+		}
+		sb.WriteString("\n")
+		if arguments := span.TagsString[tracespec.FunctionArguments]; arguments != "" {
+			sb.WriteString("```json\n")
+			sb.WriteString(indentJSON(arguments))
+			sb.WriteString("\n```\n\n")
+		}
+	}
+
+	if result, ok := span.TagsString[tracespec.FunctionResult]; ok {
+		if span.TagsBool[tracespec.FunctionIsError] {
+			sb.WriteString("### Function Result (error)\n\n")
+		} else {
+			sb.WriteString("### Function Result\n\n")
+		}
+		if _, ok := span.TagsString[tracespec.FunctionName]; !ok && span.TagsString[tracespec.FunctionCallID] != "" {
+			sb.WriteString(fmt.Sprintf("- **Call ID:** %s\n\n", span.TagsString[tracespec.FunctionCallID]))
+		}
+		sb.WriteString("```\n")
+		sb.WriteString(truncateString(result, 2000))
+		sb.WriteString("\n```\n\n")
+	}
+}
+
+// indentJSON returns value indented if it is valid JSON, or as is
+func indentJSON(value string) string {
+	indented := &bytes.Buffer{}
+	if err := json.Indent(indented, []byte(value), "", "  "); err != nil {
+		return value
+	}
+	return indented.String()
+}
+
+// writeCitationsToTable writes citations set by SetCitations to markdown table, skipped if not valid JSON
+func writeCitationsToTable(sb *markdownWriter, value string) {
+	if value == "" {
+		return
+	}
+	var citations []tracespec.Citation
+	if err := json.Unmarshal([]byte(value), &citations); err != nil || len(citations) == 0 {
+		return
+	}
+
+	sb.WriteString("### Citations\n\n")
+	sb.WriteString("| Document ID | Source | Relevance Score | Excerpt |\n")
+	sb.WriteString("|-------------|--------|-----------------|---------|\n")
+	for _, citation := range citations {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %.4f | %s |\n",
+			escapeMarkdown(citation.DocumentID),
+			escapeMarkdown(citation.Source),
+			citation.RelevanceScore,
+			escapeMarkdown(truncateString(citation.Excerpt, 100))))
+	}
+	sb.WriteString("\n")
+}
+
+// writeDocumentsToTable writes retrieved documents set by SetDocumentContext as a table
+func writeDocumentsToTable(sb *markdownWriter, value string) {
+	if value == "" {
+		return
+	}
+	var docs []tracespec.DocumentContext
+	if err := json.Unmarshal([]byte(value), &docs); err != nil || len(docs) == 0 {
+		return
+	}
+
+	sb.WriteString("### Retrieved Documents\n\n")
+	sb.WriteString("| Title | Source | Tokens | Relevance Score |\n")
+	sb.WriteString("|-------|--------|--------|-----------------|\n")
+	for _, doc := range docs {
+		title := doc.Title
+		if title == "" {
+			title = doc.ID
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %d | %.4f |\n",
+			escapeMarkdown(truncateString(title, 100)),
+			escapeMarkdown(doc.Source),
+			doc.TokenCount,
+			doc.RelevanceScore))
+	}
+	sb.WriteString("\n")
+}
+
 // formatDuration formats a duration in human readable format
 func formatDuration(d time.Duration) string {
 	if d < time.Millisecond {
@@ -240,6 +711,50 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%.2fm", d.Minutes())
 }
 
+// formatTokenUsage formats input, output and reasoning tokens, such as "input: 10, output: 20, reasoning: 5".
+func formatTokenUsage(tags map[string]int64) string {
+	var parts []string
+	for _, item := range []struct {
+		name string
+		key  string
+	}{
+		{"input", tracespec.InputTokens},
+		{"output", tracespec.OutputTokens},
+		{"reasoning", tracespec.LLMReasoningTokens},
+	} {
+		if v, ok := tags[item.key]; ok {
+			parts = append(parts, fmt.Sprintf("%s: %d", item.name, v))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseTokensByPart parses llm.tokens_by_part set by Span.SetTokenUsageByPart, nil if it is absent or truncated.
+func parseTokensByPart(tags map[string]string) []tracespec.TokenUsagePart {
+	value, ok := tags[tracespec.TokensByPart]
+	if !ok {
+		return nil
+	}
+	var parts []tracespec.TokenUsagePart
+	if err := json.Unmarshal([]byte(value), &parts); err != nil {
+		return nil
+	}
+	return parts
+}
+
+// parseSearchSnippets parses search.snippets set by Span.SetSearchResultSnippets, nil if it is absent or truncated.
+func parseSearchSnippets(tags map[string]string) []string {
+	value, ok := tags[tracespec.SearchSnippets]
+	if !ok {
+		return nil
+	}
+	var snippets []string
+	if err := json.Unmarshal([]byte(value), &snippets); err != nil {
+		return nil
+	}
+	return snippets
+}
+
 // truncateString truncates a string to maxLen characters
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -4,7 +4,11 @@
 package trace
 
 import (
+	"bufio"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,6 +16,8 @@ import (
 	"time"
 
 	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -152,6 +158,474 @@ func TestFileExporter_ExportSpans(t *testing.T) {
 			_, err = os.Stat(filePath)
 			So(err, ShouldBeNil)
 		})
+
+		Convey("should write annotations section", func() {
+			filePath := filepath.Join(t.TempDir(), "traces.md")
+			exporter := NewFileExporter(filePath)
+
+			spans := []*entity.UploadSpan{
+				{
+					TraceID:         "trace1",
+					SpanID:          "span1",
+					SpanName:        "test",
+					SpanType:        "test",
+					StartedATMicros: time.Now().UnixMicro(),
+					Annotations: []entity.SpanAnnotation{
+						{Timestamp: time.Now().UnixMicro(), Message: "cache miss, fetching from db"},
+					},
+				},
+			}
+
+			err := exporter.ExportSpans(ctx, spans)
+			So(err, ShouldBeNil)
+
+			content, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldContainSubstring, "### Annotations")
+			So(string(content), ShouldContainSubstring, "cache miss, fetching from db")
+		})
+
+		Convey("should write citations table", func() {
+			filePath := filepath.Join(t.TempDir(), "traces.md")
+			exporter := NewFileExporter(filePath)
+
+			spans := []*entity.UploadSpan{
+				{
+					TraceID:         "trace1",
+					SpanID:          "span1",
+					SpanName:        "test",
+					SpanType:        "test",
+					StartedATMicros: time.Now().UnixMicro(),
+					TagsString: map[string]string{
+						tracespec.RAGCitations: `[{"document_id":"doc1","source":"https://example.com/a|b","relevance_score":0.92,"excerpt":"first line\nsecond line"}]`,
+					},
+				},
+			}
+
+			err := exporter.ExportSpans(ctx, spans)
+			So(err, ShouldBeNil)
+
+			content, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldContainSubstring, "### Citations")
+			So(string(content), ShouldContainSubstring, "| doc1 | https://example.com/a\\|b | 0.9200 | first line second line |")
+		})
+
+		Convey("should write retrieved documents as table", func() {
+			filePath := filepath.Join(t.TempDir(), "traces.md")
+			exporter := NewFileExporter(filePath)
+
+			spans := []*entity.UploadSpan{
+				{
+					TraceID:         "trace1",
+					SpanID:          "span1",
+					SpanName:        "test",
+					SpanType:        "test",
+					StartedATMicros: time.Now().UnixMicro(),
+					TagsString: map[string]string{
+						tracespec.RAGDocuments: `[{"id":"doc1","title":"Refund Policy","source":"kb/refund.md","chunk_index":2,"token_count":120,"relevance_score":0.87},{"id":"doc2","chunk_index":0,"token_count":30,"relevance_score":0.5}]`,
+					},
+				},
+			}
+
+			err := exporter.ExportSpans(ctx, spans)
+			So(err, ShouldBeNil)
+
+			content, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldContainSubstring, "### Retrieved Documents")
+			So(string(content), ShouldContainSubstring, "| Refund Policy | kb/refund.md | 120 | 0.8700 |")
+			So(string(content), ShouldContainSubstring, "| doc2 |  | 30 | 0.5000 |")
+		})
+
+		Convey("should write token usage in summary", func() {
+			filePath := filepath.Join(t.TempDir(), "traces.md")
+			exporter := NewFileExporter(filePath)
+
+			spans := []*entity.UploadSpan{
+				{
+					TraceID:         "trace1",
+					SpanID:          "span1",
+					SpanName:        "test",
+					SpanType:        "model",
+					StartedATMicros: time.Now().UnixMicro(),
+					TagsLong: map[string]int64{
+						tracespec.InputTokens:        10,
+						tracespec.OutputTokens:       20,
+						tracespec.LLMReasoningTokens: 5,
+					},
+				},
+			}
+
+			err := exporter.ExportSpans(ctx, spans)
+			So(err, ShouldBeNil)
+
+			content, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldContainSubstring, "- **Tokens:** input: 10, output: 20, reasoning: 5\n")
+		})
+
+		Convey("should write function call and result", func() {
+			filePath := filepath.Join(t.TempDir(), "traces.md")
+			exporter := NewFileExporter(filePath)
+
+			spans := []*entity.UploadSpan{
+				{
+					TraceID:         "trace1",
+					SpanID:          "span1",
+					SpanName:        "test",
+					SpanType:        "tool",
+					StartedATMicros: time.Now().UnixMicro(),
+					TagsString: map[string]string{
+						tracespec.FunctionName:      "get_weather",
+						tracespec.FunctionArguments: `{"city":"Paris"}`,
+						tracespec.FunctionCallID:    "call_1",
+						tracespec.FunctionResult:    "timeout",
+					},
+					TagsBool: map[string]bool{
+						tracespec.FunctionIsError: true,
+					},
+				},
+			}
+
+			err := exporter.ExportSpans(ctx, spans)
+			So(err, ShouldBeNil)
+
+			content, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldContainSubstring, "### Function Call\n\n- **Name:** get_weather\n- **Call ID:** call_1\n\n"+
+				"```json\n{\n  \"city\": \"Paris\"\n}\n```\n\n")
+			So(string(content), ShouldContainSubstring, "### Function Result (error)\n\n```\ntimeout\n```\n\n")
+		})
+
+		Convey("should write tool schema as json code block", func() {
+			filePath := filepath.Join(t.TempDir(), "traces.md")
+			exporter := NewFileExporter(filePath)
+
+			spans := []*entity.UploadSpan{
+				{
+					TraceID:         "trace1",
+					SpanID:          "span1",
+					SpanName:        "test",
+					SpanType:        "tool",
+					StartedATMicros: time.Now().UnixMicro(),
+					TagsString: map[string]string{
+						tracespec.ToolSchema: `{"type":"object","properties":{"city":{"type":"string"}}}`,
+					},
+				},
+			}
+
+			err := exporter.ExportSpans(ctx, spans)
+			So(err, ShouldBeNil)
+
+			content, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldContainSubstring, "### Tool Schema\n\n```json\n{\n  \"type\": \"object\",\n")
+		})
+	})
+}
+
+func TestFileExporter_StreamingWrite(t *testing.T) {
+	Convey("FileExporter with streaming write", t, func() {
+		ctx := context.Background()
+		spans := []*entity.UploadSpan{
+			{
+				TraceID:         "trace1",
+				SpanID:          "span1",
+				SpanName:        "llm",
+				SpanType:        "model",
+				StartedATMicros: time.Now().UnixMicro(),
+				DurationMicros:  1000,
+				Input:           strings.Repeat("input ", 20000),
+				Output:          "output",
+				TagsString:      map[string]string{"model_name": "gpt"},
+			},
+			nil,
+			{TraceID: "trace1", SpanID: "span2", SpanName: "tool", StatusCode: 1},
+		}
+
+		bufferedPath := filepath.Join(t.TempDir(), "buffered.md")
+		So(NewFileExporter(bufferedPath).ExportSpans(ctx, spans), ShouldBeNil)
+		streamingPath := filepath.Join(t.TempDir(), "streaming.md")
+		exporter := NewFileExporter(streamingPath, WithStreamingWrite(true))
+		So(exporter.writer.streamingWrite, ShouldBeTrue)
+		So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+
+		buffered, err := os.ReadFile(bufferedPath)
+		So(err, ShouldBeNil)
+		streaming, err := os.ReadFile(streamingPath)
+		So(err, ShouldBeNil)
+		So(string(streaming), ShouldEqual, string(buffered))
+		So(string(streaming), ShouldContainSubstring, "## Span: tool")
+	})
+}
+
+func TestFileExporter_ChatMessageFormat(t *testing.T) {
+	Convey("FileExporter with chat message format", t, func() {
+		ctx := context.Background()
+		spans := []*entity.UploadSpan{{
+			TraceID:  "trace1",
+			SpanID:   "span1",
+			SpanName: "chat",
+			Input:    `[{"role":"system","content":"be helpful"},{"role":"user","content":"hi","name":"alice"}]`,
+			Output:   `{"content":"hello"}`,
+			SystemTagsString: map[string]string{
+				consts.ChatMessageFormat: chatMessageFormatRoleContent,
+			},
+		}}
+		path := filepath.Join(t.TempDir(), "chat.md")
+		So(NewFileExporter(path).ExportSpans(ctx, spans), ShouldBeNil)
+
+		data, err := os.ReadFile(path)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldContainSubstring, "### Input\n\n**system**: be helpful\n\n**user (alice)**: hi\n\n")
+		So(string(data), ShouldContainSubstring, "### Output\n\n```\n{\"content\":\"hello\"}\n```")
+
+		Convey("should write raw JSON without chat message format", func() {
+			spans[0].SystemTagsString = nil
+			rawPath := filepath.Join(t.TempDir(), "raw.md")
+			So(NewFileExporter(rawPath).ExportSpans(ctx, spans), ShouldBeNil)
+			data, err := os.ReadFile(rawPath)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldContainSubstring, "```\n"+spans[0].Input+"\n```")
+		})
+	})
+}
+
+func TestFileExporter_TokensByPart(t *testing.T) {
+	Convey("FileExporter renders tokens by part as table", t, func() {
+		ctx := context.Background()
+		spans := []*entity.UploadSpan{{
+			TraceID:    "trace1",
+			SpanID:     "span1",
+			SpanName:   "llm",
+			TagsString: map[string]string{tracespec.TokensByPart: `[{"part":"system","tokens":100},{"part":"user","tokens":20}]`},
+			TagsLong:   map[string]int64{tracespec.InputTokens: 120},
+		}}
+		path := filepath.Join(t.TempDir(), "tokens.md")
+		So(NewFileExporter(path).ExportSpans(ctx, spans), ShouldBeNil)
+
+		data, err := os.ReadFile(path)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldContainSubstring, "### Tokens by Part\n\n| Part | Tokens |\n|------|--------|\n| system | 100 |\n| user | 20 |\n")
+	})
+}
+
+func TestFileExporter_SearchSnippets(t *testing.T) {
+	Convey("FileExporter renders search snippets as numbered list", t, func() {
+		ctx := context.Background()
+		spans := []*entity.UploadSpan{{
+			TraceID:    "trace1",
+			SpanID:     "span1",
+			SpanName:   "web_search",
+			SpanType:   tracespec.VSearchSpanType,
+			TagsString: map[string]string{tracespec.SearchSnippets: `["first result","second\nresult"]`},
+		}}
+		path := filepath.Join(t.TempDir(), "search.md")
+		So(NewFileExporter(path).ExportSpans(ctx, spans), ShouldBeNil)
+
+		data, err := os.ReadFile(path)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldContainSubstring, "### Search Snippets\n\n1. first result\n2. second result\n\n")
+	})
+}
+
+func readGzipFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	return string(data), err
+}
+
+func TestFileExporter_GzipCompression(t *testing.T) {
+	Convey("FileExporter with gzip compression", t, func() {
+		ctx := context.Background()
+		spans := []*entity.UploadSpan{
+			{TraceID: "trace1", SpanID: "span1", SpanName: "first", TagsString: map[string]string{"user_id": "u1"}},
+			{TraceID: "trace1", SpanID: "span2", SpanName: "second"},
+		}
+
+		Convey("should append .gz suffix and write a gzip member per batch", func() {
+			dir := t.TempDir()
+			exporter := NewFileExporter(filepath.Join(dir, "traces.md"), WithGzipCompression())
+			So(exporter.FilePath(), ShouldEqual, filepath.Join(dir, "traces.md.gz"))
+			So(NewFileExporter(filepath.Join(dir, "traces.md.gz"), WithGzipCompression()).FilePath(), ShouldEqual, exporter.FilePath())
+
+			So(exporter.ExportSpans(ctx, spans[:1]), ShouldBeNil)
+			So(exporter.ExportSpans(ctx, spans[1:]), ShouldBeNil)
+			content, err := readGzipFile(exporter.FilePath())
+			So(err, ShouldBeNil)
+			So(content, ShouldContainSubstring, "**Span ID:** span1")
+			So(content, ShouldContainSubstring, "**Span ID:** span2")
+
+			deleted, err := exporter.DeleteSpansByUserID(ctx, "u1")
+			So(err, ShouldBeNil)
+			So(deleted, ShouldEqual, 1)
+			content, err = readGzipFile(exporter.FilePath())
+			So(err, ShouldBeNil)
+			So(content, ShouldNotContainSubstring, "span1")
+			So(content, ShouldContainSubstring, "**Span ID:** span2")
+		})
+
+		Convey("NewGzipJSONFileExporter should write NDJSON", func() {
+			So(NewGzipJSONFileExporter("").FilePath(), ShouldEqual, DefaultLocalJSONExportPath+".gz")
+			exporter := NewGzipJSONFileExporter(filepath.Join(t.TempDir(), "traces.json"))
+			So(exporter.ExportSpans(ctx, spans[:1]), ShouldBeNil)
+			So(exporter.ExportSpans(ctx, append(spans[1:], nil)), ShouldBeNil)
+
+			content, err := readGzipFile(exporter.FilePath())
+			So(err, ShouldBeNil)
+			var spanIDs []string
+			scanner := bufio.NewScanner(strings.NewReader(content))
+			for scanner.Scan() {
+				span := &entity.UploadSpan{}
+				So(json.Unmarshal(scanner.Bytes(), span), ShouldBeNil)
+				spanIDs = append(spanIDs, span.SpanID)
+			}
+			So(spanIDs, ShouldResemble, []string{"span1", "span2"})
+
+			_, err = exporter.DeleteSpansByUserID(ctx, "u1")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestFileExporter_DeleteSpansByUserID(t *testing.T) {
+	Convey("FileExporter.DeleteSpansByUserID", t, func() {
+		ctx := context.Background()
+		filePath := filepath.Join(t.TempDir(), "traces.md")
+		exporter := NewFileExporter(filePath)
+
+		Convey("should remove spans of user and keep others", func() {
+			spans := []*entity.UploadSpan{
+				{TraceID: "trace1", SpanID: "span1", SpanName: "first", TagsString: map[string]string{"user_id": "u1"}},
+				// a line like span header in input should not split span
+				{TraceID: "trace1", SpanID: "span2", SpanName: "second", Input: "# Trace: fake\n| user_id | u1 |",
+					TagsString: map[string]string{"user_id": "u2"}},
+				{TraceID: "trace2", SpanID: "span3", SpanName: "third", TagsString: map[string]string{"user.id": "u1"}},
+				{TraceID: "trace3", SpanID: "span4", SpanName: "fourth"},
+			}
+			So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+
+			deleted, err := exporter.DeleteSpansByUserID(ctx, "u1")
+			So(err, ShouldBeNil)
+			So(deleted, ShouldEqual, 2)
+
+			content, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldNotContainSubstring, "span1")
+			So(string(content), ShouldNotContainSubstring, "span3")
+			So(string(content), ShouldContainSubstring, "**Span ID:** span2")
+			So(string(content), ShouldContainSubstring, "**Span ID:** span4")
+			So(strings.Count(string(content), "---\n\n"), ShouldEqual, 2)
+
+			// exporting still works after file is replaced
+			So(exporter.ExportSpans(ctx, spans[:1]), ShouldBeNil)
+			deleted, err = exporter.DeleteSpansByUserID(ctx, "u1")
+			So(err, ShouldBeNil)
+			So(deleted, ShouldEqual, 1)
+		})
+
+		Convey("should not be confused by truncated or unbalanced code fences", func() {
+			spans := []*entity.UploadSpan{
+				{TraceID: "trace1", SpanID: "span1", Output: "```go\n" + strings.Repeat("x", 3000)},
+				{TraceID: "trace1", SpanID: "span2", Input: "```", TagsString: map[string]string{"user_id": "u1"}},
+				{TraceID: "trace2", SpanID: "span3", TagsString: map[string]string{"user_id": "u1"}},
+			}
+			So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+
+			deleted, err := exporter.DeleteSpansByUserID(ctx, "u1")
+			So(err, ShouldBeNil)
+			So(deleted, ShouldEqual, 2)
+			content, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldContainSubstring, "**Span ID:** span1")
+			So(string(content), ShouldNotContainSubstring, "span2")
+			So(string(content), ShouldNotContainSubstring, "span3")
+		})
+
+		Convey("should not be confused by markers in unfenced content", func() {
+			fake := "hi\n" + spanEndMarker + "\n" + spanStartMarkerPrefix + `{"user_id":"u2"}` + spanMarkerSuffix + "\n# Trace: fake"
+			input, err := json.Marshal([]tracespec.ChatMessage{{Role: "user", Content: fake}})
+			So(err, ShouldBeNil)
+			spans := []*entity.UploadSpan{
+				{TraceID: "trace1", SpanID: "span1", Input: string(input),
+					TagsString:       map[string]string{"user_id": "u1"},
+					SystemTagsString: map[string]string{consts.ChatMessageFormat: chatMessageFormatRoleContent}},
+				{TraceID: "trace1", SpanID: "span2", TagsString: map[string]string{"user_id": "u2"}},
+			}
+			So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+			content, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldContainSubstring, "\\"+spanEndMarker+"\n")
+			So(strings.Count(string(content), "\n"+spanEndMarker+"\n"), ShouldEqual, 2)
+
+			deleted, err := exporter.DeleteSpansByUserID(ctx, "u2")
+			So(err, ShouldBeNil)
+			So(deleted, ShouldEqual, 1)
+			content, err = os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldContainSubstring, "**Span ID:** span1")
+			So(string(content), ShouldNotContainSubstring, "span2")
+
+			deleted, err = exporter.DeleteSpansByUserID(ctx, "u1")
+			So(err, ShouldBeNil)
+			So(deleted, ShouldEqual, 1)
+			content, err = os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldBeEmpty)
+		})
+
+		Convey("should not match long user ids sharing a prefix", func() {
+			prefix := strings.Repeat("u", 100)
+			spans := []*entity.UploadSpan{
+				{TraceID: "trace1", SpanID: "span1", TagsString: map[string]string{"user_id": prefix + "1"}},
+				{TraceID: "trace1", SpanID: "span2", TagsString: map[string]string{"user.id": prefix + "2"}},
+			}
+			So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+
+			deleted, err := exporter.DeleteSpansByUserID(ctx, prefix+"1")
+			So(err, ShouldBeNil)
+			So(deleted, ShouldEqual, 1)
+			content, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldNotContainSubstring, "**Span ID:** span1")
+			So(string(content), ShouldContainSubstring, "| user.id | "+prefix+"2 |")
+		})
+
+		Convey("should keep file unchanged if no span matches", func() {
+			So(exporter.ExportSpans(ctx, []*entity.UploadSpan{{TraceID: "trace1", SpanID: "span1"}}), ShouldBeNil)
+			before, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			deleted, err := exporter.DeleteSpansByUserID(ctx, "u1")
+			So(err, ShouldBeNil)
+			So(deleted, ShouldEqual, 0)
+			after, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(after), ShouldEqual, string(before))
+			entries, err := os.ReadDir(filepath.Dir(filePath))
+			So(err, ShouldBeNil)
+			So(len(entries), ShouldEqual, 1)
+		})
+
+		Convey("should return 0 if file not exist", func() {
+			deleted, err := exporter.DeleteSpansByUserID(ctx, "u1")
+			So(err, ShouldBeNil)
+			So(deleted, ShouldEqual, 0)
+		})
+
+		Convey("should fail with empty user id", func() {
+			_, err := exporter.DeleteSpansByUserID(ctx, "")
+			So(err, ShouldNotBeNil)
+		})
 	})
 }
 
@@ -170,6 +644,50 @@ func TestFileExporter_ExportFiles(t *testing.T) {
 	})
 }
 
+func TestFileExporter_PlanningTree(t *testing.T) {
+	Convey("FileExporter renders planning nodes of each trace as ASCII tree", t, func() {
+		ctx := context.Background()
+		node := func(traceID, id, parentID string, score float64, pruned, selected bool) *entity.UploadSpan {
+			tagsString := map[string]string{tracespec.PlanNodeID: id}
+			if parentID != "" {
+				tagsString[tracespec.PlanParentNodeID] = parentID
+			}
+			return &entity.UploadSpan{
+				TraceID:    traceID,
+				SpanID:     id,
+				SpanName:   "thought",
+				SpanType:   tracespec.VPlanningSpanType,
+				TagsString: tagsString,
+				TagsDouble: map[string]float64{tracespec.PlanScore: score},
+				TagsBool:   map[string]bool{tracespec.PlanPruned: pruned, tracespec.PlanSelectedForExpansion: selected},
+			}
+		}
+		spans := []*entity.UploadSpan{
+			node("trace1", "root", "", 0.5, false, true),
+			node("trace1", "a", "root", 0.8, false, true),
+			node("trace1", "b", "root", 0.2, true, false),
+			node("trace1", "a1", "a", 0.9, false, false),
+			node("trace2", "x", "missing", 0.1, false, false),
+			{TraceID: "trace1", SpanID: "other", SpanName: "llm"},
+		}
+
+		for _, opts := range [][]FileExporterOption{nil, {WithStreamingWrite(true)}} {
+			path := filepath.Join(t.TempDir(), "plan.md")
+			So(NewFileExporter(path, opts...).ExportSpans(ctx, spans), ShouldBeNil)
+
+			data, err := os.ReadFile(path)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldContainSubstring, "### Planning Tree: trace1\n\n```\n"+
+				"root (score: 0.50) [selected]\n"+
+				"├── a (score: 0.80) [selected]\n"+
+				"│   └── a1 (score: 0.90)\n"+
+				"└── b (score: 0.20) [pruned]\n"+
+				"```\n")
+			So(string(data), ShouldContainSubstring, "### Planning Tree: trace2\n\n```\nx (score: 0.10)\n```\n")
+		}
+	})
+}
+
 func TestFormatDuration(t *testing.T) {
 	Convey("formatDuration", t, func() {
 		Convey("microseconds", func() {
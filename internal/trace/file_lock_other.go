@@ -0,0 +1,17 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package trace
+
+import (
+	"errors"
+	"os"
+)
+
+func tryLockFile(f *os.File) (bool, error) {
+	return false, errors.New("file lock is not supported on this platform")
+}
+
+func unlockFile(f *os.File) {}
@@ -0,0 +1,185 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+const (
+	DefaultGanttExportPath = "./cozeloop_timeline.md"
+
+	ganttBarWidth      = 60
+	ganttMaxLabelWidth = 32
+	ganttSlowThreshold = time.Minute
+	ganttSlowMark      = "⚠"
+)
+
+var _ Exporter = (*GanttMarkdownExporter)(nil)
+
+// GanttMarkdownExporter exports spans to a local markdown file as timelines, one per trace in each batch.
+// Each span is drawn as a bar at its start relative to the earliest span of the trace, so that
+// parallel spans are shown as overlapping bars. Spans longer than 1 minute are marked with ⚠.
+type GanttMarkdownExporter struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+// NewGanttMarkdownExporter creates a new GanttMarkdownExporter with the given file path.
+func NewGanttMarkdownExporter(filePath string) *GanttMarkdownExporter {
+	if filePath == "" {
+		filePath = DefaultGanttExportPath
+	}
+	return &GanttMarkdownExporter{
+		filePath: filePath,
+	}
+}
+
+// ExportSpans appends timelines of spans to the markdown file. Spans of a trace exported in different
+// batches are rendered as separate timelines.
+func (e *GanttMarkdownExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	var traceIDs []string
+	traceSpans := make(map[string][]*entity.UploadSpan)
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		if _, ok := traceSpans[span.TraceID]; !ok {
+			traceIDs = append(traceIDs, span.TraceID)
+		}
+		traceSpans[span.TraceID] = append(traceSpans[span.TraceID], span)
+	}
+	if len(traceIDs) == 0 {
+		return nil
+	}
+
+	sb := &strings.Builder{}
+	for _, traceID := range traceIDs {
+		writeGanttChart(sb, traceID, traceSpans[traceID])
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	dir := filepath.Dir(e.filePath)
+	if dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			logger.CtxErrorf(ctx, "failed to create directory for timeline file: %v", err)
+			return err
+		}
+	}
+	f, err := os.OpenFile(e.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		logger.CtxErrorf(ctx, "failed to open timeline file: %v", err)
+		return err
+	}
+	defer f.Close()
+	if _, err = f.WriteString(sb.String()); err != nil {
+		logger.CtxErrorf(ctx, "failed to write timeline to file: %v", err)
+		return err
+	}
+	logger.CtxDebugf(ctx, "exported timelines of %d traces to file: %s", len(traceIDs), e.filePath)
+	return nil
+}
+
+// ExportFiles is a no-op, files are not rendered in timelines.
+func (e *GanttMarkdownExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return nil
+}
+
+// writeGanttChart writes the timeline of spans of one trace, spans are sorted by start time,
+// and indented by their depth in the trace.
+func writeGanttChart(sb *strings.Builder, traceID string, spans []*entity.UploadSpan) {
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].StartedATMicros < spans[j].StartedATMicros
+	})
+	start, end := spans[0].StartedATMicros, spans[0].StartedATMicros
+	parents := make(map[string]string, len(spans))
+	for _, span := range spans {
+		if span.StartedATMicros < start {
+			start = span.StartedATMicros
+		}
+		if spanEnd := span.StartedATMicros + span.DurationMicros; spanEnd > end {
+			end = spanEnd
+		}
+		parents[span.SpanID] = span.ParentID
+	}
+	total := end - start
+	if total <= 0 {
+		total = 1
+	}
+
+	labels := make([]string, len(spans))
+	labelWidth := 0
+	for i, span := range spans {
+		labels[i] = strings.Repeat("  ", ganttSpanDepth(span, parents)) + ganttSpanName(span.SpanName)
+		if width := utf8.RuneCountInString(labels[i]); width > labelWidth {
+			labelWidth = width
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("# Timeline: %s\n\n", traceID))
+	sb.WriteString(fmt.Sprintf("**Start Time:** %s  \n", time.UnixMicro(start).Format(time.RFC3339Nano)))
+	sb.WriteString(fmt.Sprintf("**Total Duration:** %s\n\n", time.Duration(end-start)*time.Microsecond))
+	sb.WriteString("```text\n")
+	for i, span := range spans {
+		offset := int((span.StartedATMicros - start) * ganttBarWidth / total)
+		length := int(span.DurationMicros * ganttBarWidth / total)
+		if length < 1 {
+			length = 1
+		}
+		if offset+length > ganttBarWidth {
+			offset = ganttBarWidth - length
+		}
+		duration := time.Duration(span.DurationMicros) * time.Microsecond
+		sb.WriteString(labels[i])
+		sb.WriteString(strings.Repeat(" ", labelWidth-utf8.RuneCountInString(labels[i])))
+		sb.WriteString(" |")
+		sb.WriteString(strings.Repeat(" ", offset))
+		sb.WriteString(strings.Repeat("█", length))
+		sb.WriteString(strings.Repeat(" ", ganttBarWidth-offset-length))
+		sb.WriteString(fmt.Sprintf("| +%s %s", time.Duration(span.StartedATMicros-start)*time.Microsecond, duration))
+		if duration > ganttSlowThreshold {
+			sb.WriteString(" " + ganttSlowMark)
+		}
+		if span.StatusCode != 0 {
+			sb.WriteString(fmt.Sprintf(" [error %d]", span.StatusCode))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("```\n\n---\n\n")
+}
+
+// ganttSpanName returns span name in one line, which is truncated to ganttMaxLabelWidth runes.
+func ganttSpanName(name string) string {
+	name = strings.ReplaceAll(name, "\n", " ")
+	if utf8.RuneCountInString(name) <= ganttMaxLabelWidth {
+		return name
+	}
+	return string([]rune(name)[:ganttMaxLabelWidth-1]) + "…"
+}
+
+// ganttSpanDepth returns the depth of span among spans of the batch, ancestors not in the batch are ignored.
+func ganttSpanDepth(span *entity.UploadSpan, parents map[string]string) int {
+	depth := 0
+	for parentID := span.ParentID; depth < len(parents); depth++ {
+		next, ok := parents[parentID]
+		if !ok {
+			break
+		}
+		parentID = next
+	}
+	return depth
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGanttMarkdownExporter(t *testing.T) {
+	Convey("GanttMarkdownExporter", t, func() {
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "timeline", "timeline.md")
+		e := NewGanttMarkdownExporter(path)
+		start := time.Now().UnixMicro()
+		spans := []*entity.UploadSpan{
+			{TraceID: "trace1", SpanID: "tool1", ParentID: "root", SpanName: "tool_a", StartedATMicros: start + 1000000, DurationMicros: 2000000},
+			{TraceID: "trace1", SpanID: "root", ParentID: "0", SpanName: "agent", StartedATMicros: start, DurationMicros: 6000000},
+			{TraceID: "trace1", SpanID: "tool2", ParentID: "root", SpanName: "tool_b", StartedATMicros: start + 2000000, DurationMicros: 4000000, StatusCode: 1},
+			{TraceID: "trace2", SpanID: "slow", SpanName: "slow", StartedATMicros: start, DurationMicros: int64(2 * time.Minute / time.Microsecond)},
+		}
+		So(e.ExportSpans(ctx, spans), ShouldBeNil)
+
+		data, err := os.ReadFile(path)
+		So(err, ShouldBeNil)
+		content := string(data)
+		So(content, ShouldContainSubstring, "# Timeline: trace1")
+		So(content, ShouldContainSubstring, "# Timeline: trace2")
+		So(content, ShouldContainSubstring, "**Total Duration:** 6s")
+		So(content, ShouldContainSubstring, "agent    |"+strings.Repeat("█", 60)+"| +0s 6s\n")
+		So(content, ShouldContainSubstring, "  tool_a |"+strings.Repeat(" ", 10)+strings.Repeat("█", 20)+strings.Repeat(" ", 30)+"| +1s 2s\n")
+		So(content, ShouldContainSubstring, "  tool_b |"+strings.Repeat(" ", 20)+strings.Repeat("█", 40)+"| +2s 4s [error 1]\n")
+		So(content, ShouldContainSubstring, "| +0s 2m0s "+ganttSlowMark+"\n")
+		So(strings.Index(content, "tool_a"), ShouldBeLessThan, strings.Index(content, "tool_b"))
+
+		So(ganttSpanName(strings.Repeat("a", 40)), ShouldEqual, strings.Repeat("a", 31)+"…")
+		So(e.ExportSpans(ctx, nil), ShouldBeNil)
+	})
+}
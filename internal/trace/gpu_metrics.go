@@ -0,0 +1,187 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	"golang.org/x/sync/singleflight"
+)
+
+// GPUMetricsCollector queries the current metrics of GPU, which are set on model spans on finish.
+type GPUMetricsCollector interface {
+	CollectGPUMetrics(ctx context.Context) (tracespec.GPUMetrics, error)
+}
+
+var _ GPUMetricsCollector = (*NvidiaSMICollector)(nil)
+
+const nvidiaSMIQuery = "index,utilization.gpu,memory.used,memory.total,power.draw,temperature.gpu"
+
+// DefaultNvidiaSMISampleInterval is the default interval to run nvidia-smi, readings are reused within it.
+const DefaultNvidiaSMISampleInterval = time.Second
+
+// nvidiaSMITimeout bounds each run of nvidia-smi, which may hang when the driver is unhealthy.
+const nvidiaSMITimeout = 2 * time.Second
+
+// NvidiaSMICollector collects metrics of a GPU by running the nvidia-smi command line tool. It does not use NVML
+// bindings, so it does not require cgo, but each run forks a process. So a reading is reused by spans finished
+// within the sample interval, and concurrent collections share the same run, which is bounded by a 2s timeout.
+type NvidiaSMICollector struct {
+	deviceID       int
+	sampleInterval time.Duration
+	// run executes nvidia-smi with args and returns its output, replaced in tests
+	run func(ctx context.Context, args ...string) ([]byte, error)
+
+	sf        singleflight.Group
+	mu        sync.Mutex // guards the last reading, not held while running nvidia-smi
+	sampledAt time.Time
+	metrics   tracespec.GPUMetrics
+	err       error
+}
+
+// NvidiaSMIOption is used to set options for NvidiaSMICollector.
+type NvidiaSMIOption func(c *NvidiaSMICollector)
+
+// WithNvidiaSMISampleInterval set the interval to run nvidia-smi, readings are reused within it.
+// Default is 1s, 0 means running nvidia-smi on each collection.
+func WithNvidiaSMISampleInterval(d time.Duration) NvidiaSMIOption {
+	return func(c *NvidiaSMICollector) {
+		if d >= 0 {
+			c.sampleInterval = d
+		}
+	}
+}
+
+// NewNvidiaSMICollector creates a NvidiaSMICollector of the GPU of deviceID, nvidia-smi must be in PATH.
+func NewNvidiaSMICollector(deviceID int, opts ...NvidiaSMIOption) *NvidiaSMICollector {
+	c := &NvidiaSMICollector{
+		deviceID:       deviceID,
+		sampleInterval: DefaultNvidiaSMISampleInterval,
+		run: func(ctx context.Context, args ...string) ([]byte, error) {
+			return exec.CommandContext(ctx, "nvidia-smi", args...).Output()
+		},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	return c
+}
+
+// CollectGPUMetrics returns the last reading if it is sampled within the sample interval, otherwise runs nvidia-smi.
+// Errors are reused as readings, so that nvidia-smi is not run on each span finish if it is missing.
+// The run is shared by concurrent collections and is not canceled by ctx, but CollectGPUMetrics returns
+// ctx.Err() if ctx is done before the run finishes.
+func (c *NvidiaSMICollector) CollectGPUMetrics(ctx context.Context) (tracespec.GPUMetrics, error) {
+	c.mu.Lock()
+	if !c.sampledAt.IsZero() && time.Since(c.sampledAt) < c.sampleInterval {
+		metrics, err := c.metrics, c.err
+		c.mu.Unlock()
+		return metrics, err
+	}
+	c.mu.Unlock()
+
+	ch := c.sf.DoChan("nvidia-smi", func() (interface{}, error) {
+		metrics, err := c.sample()
+		return metrics, err
+	})
+	select {
+	case res := <-ch:
+		return res.Val.(tracespec.GPUMetrics), res.Err
+	case <-ctx.Done():
+		return tracespec.GPUMetrics{}, ctx.Err()
+	}
+}
+
+// sample runs nvidia-smi within nvidiaSMITimeout and saves the reading.
+func (c *NvidiaSMICollector) sample() (tracespec.GPUMetrics, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), nvidiaSMITimeout)
+	defer cancel()
+	var metrics tracespec.GPUMetrics
+	out, err := c.run(ctx, "--query-gpu="+nvidiaSMIQuery, "--format=csv,noheader,nounits", "-i", strconv.Itoa(c.deviceID))
+	if err != nil {
+		err = fmt.Errorf("run nvidia-smi: %w", err)
+	} else {
+		metrics, err = parseNvidiaSMIOutput(string(out))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics, c.err, c.sampledAt = metrics, err, time.Now()
+	return metrics, err
+}
+
+// parseNvidiaSMIOutput parses the first line of nvidia-smi csv output, such as `0, 45, 1024, 16384, 70.50, 60`.
+// Fields not supported by the device, which are `[N/A]`, are left zero.
+func parseNvidiaSMIOutput(out string) (tracespec.GPUMetrics, error) {
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(out), "\n", 2)[0])
+	fields := strings.Split(line, ",")
+	if len(fields) != 6 {
+		return tracespec.GPUMetrics{}, fmt.Errorf("unexpected nvidia-smi output: %q", line)
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	deviceID, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return tracespec.GPUMetrics{}, fmt.Errorf("unexpected nvidia-smi output: %q", line)
+	}
+	parseFloat := func(s string) float64 {
+		v, _ := strconv.ParseFloat(s, 64)
+		return v
+	}
+	return tracespec.GPUMetrics{
+		DeviceID:           deviceID,
+		UtilizationPercent: parseFloat(fields[1]),
+		MemoryUsedMB:       int64(parseFloat(fields[2])),
+		MemoryTotalMB:      int64(parseFloat(fields[3])),
+		PowerWatts:         parseFloat(fields[4]),
+		Temperature:        parseFloat(fields[5]),
+	}, nil
+}
+
+func gpuMetricsTags(metrics tracespec.GPUMetrics) map[string]interface{} {
+	return map[string]interface{}{
+		tracespec.GPUDeviceID:      metrics.DeviceID,
+		tracespec.GPUUtilization:   metrics.UtilizationPercent,
+		tracespec.GPUMemoryUsedMB:  metrics.MemoryUsedMB,
+		tracespec.GPUMemoryTotalMB: metrics.MemoryTotalMB,
+		tracespec.GPUPowerWatts:    metrics.PowerWatts,
+		tracespec.GPUTemperature:   metrics.Temperature,
+	}
+}
+
+// setGPUMetricsInfo sets metrics collected by gpuMetricsCollector on model spans, unless they are
+// already set by SetGPUMetrics.
+func (s *Span) setGPUMetricsInfo(ctx context.Context) {
+	if s.gpuMetricsCollector == nil || s.GetSpanType() != tracespec.VModelSpanType {
+		return
+	}
+	s.lock.RLock()
+	_, ok := s.TagMap[tracespec.GPUDeviceID]
+	s.lock.RUnlock()
+	if ok {
+		return
+	}
+	metrics, err := s.gpuMetricsCollector.CollectGPUMetrics(ctx)
+	if err != nil {
+		logger.CtxWarnf(ctx, "failed to collect gpu metrics: %v", err)
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for key, value := range gpuMetricsTags(metrics) {
+		s.setTagItem(ctx, key, value)
+	}
+}
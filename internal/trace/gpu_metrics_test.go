@@ -0,0 +1,136 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type mockGPUMetricsCollector struct {
+	metrics tracespec.GPUMetrics
+	err     error
+	calls   int
+}
+
+func (c *mockGPUMetricsCollector) CollectGPUMetrics(ctx context.Context) (tracespec.GPUMetrics, error) {
+	c.calls++
+	return c.metrics, c.err
+}
+
+func TestGPUMetrics(t *testing.T) {
+	Convey("GPU metrics", t, func() {
+		ctx := context.Background()
+
+		Convey("NvidiaSMICollector should parse output of nvidia-smi", func() {
+			c := NewNvidiaSMICollector(1, WithNvidiaSMISampleInterval(0))
+			var gotArgs []string
+			c.run = func(ctx context.Context, args ...string) ([]byte, error) {
+				gotArgs = args
+				return []byte("1, 45, 1024, 16384, [N/A], 60\n"), nil
+			}
+			metrics, err := c.CollectGPUMetrics(ctx)
+			So(err, ShouldBeNil)
+			So(gotArgs, ShouldContain, "-i")
+			So(gotArgs[len(gotArgs)-1], ShouldEqual, "1")
+			So(metrics, ShouldResemble, tracespec.GPUMetrics{
+				DeviceID:           1,
+				UtilizationPercent: 45,
+				MemoryUsedMB:       1024,
+				MemoryTotalMB:      16384,
+				Temperature:        60,
+			})
+
+			_, err = parseNvidiaSMIOutput("No devices were found")
+			So(err, ShouldNotBeNil)
+			c.run = func(ctx context.Context, args ...string) ([]byte, error) {
+				return nil, errors.New("not found")
+			}
+			_, err = c.CollectGPUMetrics(ctx)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("NvidiaSMICollector should reuse reading within sample interval", func() {
+			c := NewNvidiaSMICollector(0, WithNvidiaSMISampleInterval(time.Hour))
+			runs := 0
+			c.run = func(ctx context.Context, args ...string) ([]byte, error) {
+				runs++
+				return nil, errors.New("not found")
+			}
+			for i := 0; i < 3; i++ {
+				_, err := c.CollectGPUMetrics(ctx)
+				So(err, ShouldNotBeNil)
+			}
+			So(runs, ShouldEqual, 1)
+
+			c.sampledAt = time.Now().Add(-time.Hour)
+			_, _ = c.CollectGPUMetrics(ctx)
+			So(runs, ShouldEqual, 2)
+		})
+
+		Convey("NvidiaSMICollector should bound runs by timeout and not block readers", func() {
+			c := NewNvidiaSMICollector(0, WithNvidiaSMISampleInterval(time.Hour))
+			started := make(chan struct{})
+			var deadline time.Time
+			c.run = func(ctx context.Context, args ...string) ([]byte, error) {
+				deadline, _ = ctx.Deadline()
+				close(started)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			done := make(chan error, 1)
+			go func() {
+				_, err := c.CollectGPUMetrics(context.Background())
+				done <- err
+			}()
+			<-started
+			So(time.Until(deadline), ShouldBeLessThanOrEqualTo, nvidiaSMITimeout)
+
+			// the mutex is not held during the run, so a canceled caller returns at once
+			canceledCtx, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err := c.CollectGPUMetrics(canceledCtx)
+			So(err, ShouldEqual, context.Canceled)
+
+			So(<-done, ShouldNotBeNil)
+			_, err = c.CollectGPUMetrics(ctx)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("should set metrics of collector on model spans on finish", func() {
+			collector := &mockGPUMetricsCollector{metrics: tracespec.GPUMetrics{DeviceID: 2, UtilizationPercent: 80.5, PowerWatts: 250}}
+			p := &Provider{
+				opt:           &Options{WorkspaceID: "ws", GPUMetricsCollector: collector},
+				spanProcessor: noopSpanProcessor{},
+			}
+			_, model, _ := p.StartSpan(ctx, "inference", tracespec.VModelSpanType, StartSpanOptions{})
+			model.Finish(ctx)
+			tags := model.GetTagMap()
+			So(tags[tracespec.GPUDeviceID], ShouldEqual, 2)
+			So(tags[tracespec.GPUUtilization], ShouldEqual, 80.5)
+			So(tags[tracespec.GPUPowerWatts], ShouldEqual, 250)
+
+			_, tool, _ := p.StartSpan(ctx, "tool", tracespec.VToolSpanType, StartSpanOptions{})
+			tool.Finish(ctx)
+			So(tool.GetTagMap()[tracespec.GPUDeviceID], ShouldBeNil)
+
+			_, manual, _ := p.StartSpan(ctx, "inference", tracespec.VModelSpanType, StartSpanOptions{})
+			manual.SetGPUMetrics(ctx, tracespec.GPUMetrics{DeviceID: 3, MemoryUsedMB: 512})
+			manual.Finish(ctx)
+			So(manual.GetTagMap()[tracespec.GPUDeviceID], ShouldEqual, 3)
+			So(manual.GetTagMap()[tracespec.GPUMemoryUsedMB], ShouldEqual, 512)
+			So(collector.calls, ShouldEqual, 1)
+
+			collector.err = errors.New("nvml error")
+			_, failed, _ := p.StartSpan(ctx, "inference", tracespec.VModelSpanType, StartSpanOptions{})
+			failed.Finish(ctx)
+			So(failed.GetTagMap()[tracespec.GPUDeviceID], ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const RedactedValue = "[REDACTED]"
+
+var sensitiveKeyRegexp = regexp.MustCompile(`(?i)password|passwd|token|secret|api_?key|authorization`)
+
+// DefaultHTTPBodyRedactor replaces values of sensitive keys in JSON body with [REDACTED], such as password, token
+// and secret. Body which is not JSON is returned as is. If contentType is empty, body is treated as JSON if it's valid.
+// Body of multiple JSON values, such as NDJSON, is redacted value by value, and the values are re-encoded one per
+// line. If data after the first value is not valid JSON, the whole body is replaced with [REDACTED].
+func DefaultHTTPBodyRedactor(body []byte, contentType string) []byte {
+	if contentType != "" && !strings.Contains(strings.ToLower(contentType), "json") {
+		return body
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return body
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	var values []interface{}
+	redacted := false
+	for {
+		var v interface{}
+		err := decoder.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if len(values) == 0 {
+				return body
+			}
+			// sensitive values may be in the data which can not be parsed
+			return []byte(RedactedValue)
+		}
+		if redactJSONValue(v) {
+			redacted = true
+		}
+		values = append(values, v)
+	}
+	if !redacted {
+		return body
+	}
+
+	var buf bytes.Buffer
+	for i, v := range values {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return []byte(RedactedValue)
+		}
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.Write(encoded)
+	}
+	return buf.Bytes()
+}
+
+// redactJSONValue redacts values of sensitive keys in place, returns whether any value is redacted.
+func redactJSONValue(v interface{}) bool {
+	redacted := false
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			if sensitiveKeyRegexp.MatchString(key) {
+				val[key] = RedactedValue
+				redacted = true
+				continue
+			}
+			if redactJSONValue(item) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for _, item := range val {
+			if redactJSONValue(item) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDefaultHTTPBodyRedactor(t *testing.T) {
+	Convey("DefaultHTTPBodyRedactor", t, func() {
+		Convey("should redact sensitive keys in nested JSON", func() {
+			body := []byte(`{"user":"bob","Password":"p","auth":{"access_token":"t","n":1.50},"items":[{"client_secret":"s"}]}`)
+			So(string(DefaultHTTPBodyRedactor(body, "application/json; charset=utf-8")), ShouldEqual,
+				`{"Password":"[REDACTED]","auth":{"access_token":"[REDACTED]","n":1.50},"items":[{"client_secret":"[REDACTED]"}],"user":"bob"}`)
+		})
+
+		Convey("should keep body as is if nothing is redacted", func() {
+			body := []byte(`{"b":1, "a":2}`)
+			So(string(DefaultHTTPBodyRedactor(body, "")), ShouldEqual, string(body))
+		})
+
+		Convey("should redact every value of multiple JSON values", func() {
+			body := []byte("{\"user\":\"bob\"}\n{\"token\":\"t\"}\n[{\"secret\":\"s\"}]\n")
+			So(string(DefaultHTTPBodyRedactor(body, "application/x-ndjson")), ShouldEqual,
+				"{\"user\":\"bob\"}\n{\"token\":\"[REDACTED]\"}\n[{\"secret\":\"[REDACTED]\"}]")
+			body = []byte(`{"user":"bob"} {"user":"alice"}`)
+			So(string(DefaultHTTPBodyRedactor(body, "")), ShouldEqual, string(body))
+		})
+
+		Convey("should redact whole body if trailing data is not JSON", func() {
+			body := []byte(`{"user":"bob"} password=p`)
+			So(string(DefaultHTTPBodyRedactor(body, "application/json")), ShouldEqual, RedactedValue)
+			body = []byte(`{"user":"bob"}{"password":`)
+			So(string(DefaultHTTPBodyRedactor(body, "")), ShouldEqual, RedactedValue)
+		})
+
+		Convey("should keep non-JSON body as is", func() {
+			So(string(DefaultHTTPBodyRedactor([]byte(`password=p`), "application/x-www-form-urlencoded")), ShouldEqual, `password=p`)
+			So(string(DefaultHTTPBodyRedactor([]byte(`{"password":`), "")), ShouldEqual, `{"password":`)
+			So(string(DefaultHTTPBodyRedactor([]byte(`plain text`), "")), ShouldEqual, `plain text`)
+		})
+	})
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+)
+
+const (
+	// defaultFileLockTimeout is the default max time to wait for the file lock of LockedFileExporter.
+	defaultFileLockTimeout = 5 * time.Second
+	// fileLockRetryInterval is the interval of retrying to acquire the file lock.
+	fileLockRetryInterval = 10 * time.Millisecond
+)
+
+var _ Exporter = (*LockedFileExporter)(nil)
+
+// LockedFileExporter exports spans to a local markdown file like FileExporter, and holds an exclusive file lock
+// while writing each batch, so that processes sharing the file, such as on NFS, do not corrupt it. The lock is
+// flock on Unix and LockFileEx on Windows, and the in-process mutex of FileExporter is still held.
+type LockedFileExporter struct {
+	file *FileExporter
+}
+
+// LockedFileExporterOption is used to set options for LockedFileExporter.
+type LockedFileExporterOption func(e *LockedFileExporter)
+
+// WithFileLockTimeout set the max time to wait for the file lock, the export fails if the lock is not acquired
+// in time. Default is 5s.
+func WithFileLockTimeout(timeout time.Duration) LockedFileExporterOption {
+	return func(e *LockedFileExporter) {
+		if timeout > 0 {
+			e.file.lockWait = timeout
+		}
+	}
+}
+
+// NewLockedFileExporter creates a LockedFileExporter with the given file path.
+// Default path is ./cozeloop_traces.md if filePath is empty.
+func NewLockedFileExporter(filePath string, opts ...LockedFileExporterOption) *LockedFileExporter {
+	e := &LockedFileExporter{
+		file: NewFileExporter(filePath),
+	}
+	e.file.locked = true
+	e.file.lockWait = defaultFileLockTimeout
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	return e
+}
+
+// FilePath returns the path of exported file.
+func (e *LockedFileExporter) FilePath() string {
+	return e.file.FilePath()
+}
+
+func (e *LockedFileExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	return e.file.ExportSpans(ctx, spans)
+}
+
+// ExportFiles is a no-op, files of spans are not written.
+func (e *LockedFileExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return nil
+}
+
+// lockFile acquires the exclusive lock of f, retrying until timeout or ctx is done.
+func lockFile(ctx context.Context, f *os.File, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			return consts.NewError(fmt.Sprintf("lock file[%s] fail", f.Name())).Wrap(err)
+		}
+		if ok {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return consts.NewError(fmt.Sprintf("lock file[%s] timeout after %s", f.Name(), timeout))
+		}
+		select {
+		case <-ctx.Done():
+			return consts.NewError(fmt.Sprintf("lock file[%s] fail", f.Name())).Wrap(ctx.Err())
+		case <-time.After(fileLockRetryInterval):
+		}
+	}
+}
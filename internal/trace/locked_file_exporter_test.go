@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLockedFileExporter(t *testing.T) {
+	Convey("LockedFileExporter", t, func() {
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "traces.md")
+		exporter := NewLockedFileExporter(path, WithFileLockTimeout(50*time.Millisecond))
+		So(exporter.FilePath(), ShouldEqual, path)
+		So(exporter.file.lockWait, ShouldEqual, 50*time.Millisecond)
+		spans := []*entity.UploadSpan{{TraceID: "trace1", SpanID: "span1", SpanName: "llm"}}
+
+		Convey("should write spans when the file is not locked", func() {
+			So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+			data, err := os.ReadFile(path)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldContainSubstring, "## Span: llm")
+		})
+
+		Convey("should fail after timeout when the file is locked by others", func() {
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+			So(err, ShouldBeNil)
+			defer f.Close()
+			ok, err := tryLockFile(f)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+
+			start := time.Now()
+			err = exporter.ExportSpans(ctx, spans)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "timeout")
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+
+			unlockFile(f)
+			So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+		})
+	})
+}
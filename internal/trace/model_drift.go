@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+// modelDriftDetector keeps the last fingerprint of each model, and calls callback when it changes.
+type modelDriftDetector struct {
+	fingerprints sync.Map // model name -> *modelFingerprint
+	callback     func(model, oldFP, newFP string)
+}
+
+type modelFingerprint struct {
+	mu          sync.Mutex
+	fingerprint string
+}
+
+func newModelDriftDetector(callback func(model, oldFP, newFP string)) *modelDriftDetector {
+	if callback == nil {
+		return nil
+	}
+	return &modelDriftDetector{callback: callback}
+}
+
+// observe records fingerprint of model, and calls callback synchronously if it is different from the last one.
+// Returns whether drift is detected. The first fingerprint of a model is not treated as drift.
+func (d *modelDriftDetector) observe(model, fingerprint string) bool {
+	v, loaded := d.fingerprints.LoadOrStore(model, &modelFingerprint{fingerprint: fingerprint})
+	if !loaded {
+		return false
+	}
+	last := v.(*modelFingerprint)
+	last.mu.Lock()
+	oldFP := last.fingerprint
+	last.fingerprint = fingerprint
+	last.mu.Unlock()
+	if oldFP == fingerprint {
+		return false
+	}
+	d.call(model, oldFP, fingerprint)
+	return true
+}
+
+func (d *modelDriftDetector) call(model, oldFP, newFP string) {
+	defer func() {
+		if e := recover(); e != nil {
+			const size = 64 << 10
+			buf := make([]byte, size)
+			buf = buf[:runtime.Stack(buf, false)]
+			logger.CtxErrorf(context.Background(), "model drift callback panic: %s: %s", e, buf)
+		}
+	}()
+	d.callback(model, oldFP, newFP)
+}
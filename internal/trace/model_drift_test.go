@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestModelDriftDetector(t *testing.T) {
+	Convey("modelDriftDetector", t, func() {
+		ctx := context.Background()
+		So(newModelDriftDetector(nil), ShouldBeNil)
+
+		var drifts [][3]string
+		callback := func(model, oldFP, newFP string) {
+			drifts = append(drifts, [3]string{model, oldFP, newFP})
+		}
+
+		Convey("should call callback when fingerprint of the same model changes", func() {
+			d := newModelDriftDetector(callback)
+			So(d.observe("gpt-4o", "fp_1"), ShouldBeFalse)
+			So(d.observe("gpt-4o", "fp_1"), ShouldBeFalse)
+			So(d.observe("gpt-4o-mini", "fp_2"), ShouldBeFalse)
+			So(d.observe("gpt-4o", "fp_3"), ShouldBeTrue)
+			So(d.observe("gpt-4o", "fp_3"), ShouldBeFalse)
+			So(drifts, ShouldResemble, [][3]string{{"gpt-4o", "fp_1", "fp_3"}})
+		})
+
+		Convey("should recover panic in callback", func() {
+			d := newModelDriftDetector(func(model, oldFP, newFP string) { panic("boom") })
+			d.observe("gpt-4o", "fp_1")
+			So(func() { d.observe("gpt-4o", "fp_2") }, ShouldNotPanic)
+		})
+
+		Convey("should check fingerprint of span on finish", func() {
+			p := &Provider{
+				opt:                &Options{WorkspaceID: "ws"},
+				spanProcessor:      noopSpanProcessor{},
+				modelDriftDetector: newModelDriftDetector(callback),
+			}
+			finishSpan := func(model, fingerprint string) {
+				_, span, err := p.StartSpan(ctx, "llm_call", tracespec.VModelSpanType, StartSpanOptions{})
+				So(err, ShouldBeNil)
+				span.SetModelName(ctx, model)
+				span.SetModelFingerprint(ctx, fingerprint)
+				So(span.GetTagMap()[tracespec.ModelFingerprint], ShouldEqual, fingerprint)
+				span.Finish(ctx)
+			}
+			finishSpan("gpt-4o", "fp_1")
+			finishSpan("", "fp_2")
+			finishSpan("gpt-4o", "fp_2")
+			So(drifts, ShouldResemble, [][3]string{{"gpt-4o", "fp_1", "fp_2"}})
+		})
+	})
+}
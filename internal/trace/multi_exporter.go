@@ -33,45 +33,43 @@ func NewMultiExporter(exporters ...Exporter) *MultiExporter {
 }
 
 // ExportSpans exports spans to all wrapped exporters
-// It continues exporting even if one exporter fails, but returns the first error encountered
+// It continues exporting even if one exporter fails, and returns the first error which is not a PartialExportError,
+// or the merged PartialExportError if all errors are partial
 func (m *MultiExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
 	if len(m.exporters) == 0 {
 		return nil
 	}
 
-	var firstErr error
+	var errs []error
 	for _, exporter := range m.exporters {
 		if err := exporter.ExportSpans(ctx, spans); err != nil {
 			logger.CtxErrorf(ctx, "multi-exporter: failed to export spans: %v", err)
-			if firstErr == nil {
-				firstErr = err
-			}
+			errs = append(errs, err)
 			// Continue to try other exporters even if one fails
 		}
 	}
 
-	return firstErr
+	return mergeExportErrors(errs)
 }
 
 // ExportFiles exports files to all wrapped exporters
-// It continues exporting even if one exporter fails, but returns the first error encountered
+// It continues exporting even if one exporter fails, and returns the first error which is not a PartialExportError,
+// or the merged PartialExportError if all errors are partial
 func (m *MultiExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
 	if len(m.exporters) == 0 {
 		return nil
 	}
 
-	var firstErr error
+	var errs []error
 	for _, exporter := range m.exporters {
 		if err := exporter.ExportFiles(ctx, files); err != nil {
 			logger.CtxErrorf(ctx, "multi-exporter: failed to export files: %v", err)
-			if firstErr == nil {
-				firstErr = err
-			}
+			errs = append(errs, err)
 			// Continue to try other exporters even if one fails
 		}
 	}
 
-	return firstErr
+	return mergeExportErrors(errs)
 }
 
 // AddExporter adds an exporter to the multi-exporter
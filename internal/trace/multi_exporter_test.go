@@ -105,6 +105,20 @@ func TestMultiExporter_ExportSpans(t *testing.T) {
 			So(err, ShouldNotBeNil)
 			So(err.Error(), ShouldEqual, "first error")
 		})
+
+		Convey("should merge partial errors, and prefer other errors", func() {
+			spans := []*entity.UploadSpan{{TraceID: "t", SpanID: "s1"}, {TraceID: "t", SpanID: "s2"}}
+			exp1 := &mockExporter{exportSpansErr: &PartialExportError{Err: errors.New("first error"), FailedSpans: spans[:1]}}
+			exp2 := &mockExporter{exportSpansErr: &PartialExportError{Err: errors.New("second error"), FailedSpans: spans}}
+			err := NewMultiExporter(exp1, exp2).ExportSpans(ctx, spans)
+			var partialErr *PartialExportError
+			So(errors.As(err, &partialErr), ShouldBeTrue)
+			So(partialErr.FailedSpans, ShouldResemble, spans)
+
+			exp3 := &mockExporter{exportSpansErr: errors.New("third error")}
+			err = NewMultiExporter(exp1, exp3).ExportSpans(ctx, spans)
+			So(err.Error(), ShouldEqual, "third error")
+		})
 	})
 }
 
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+)
+
+var _ SpanProcessor = (*multiSpanProcessor)(nil)
+
+// multiSpanProcessor calls span processors in order, the exporting processor is called last
+// so that spans are exported after other processors.
+type multiSpanProcessor struct {
+	processors []SpanProcessor
+}
+
+// newMultiSpanProcessor returns exporter directly if there are no other processors.
+func newMultiSpanProcessor(processors []SpanProcessor, exporter SpanProcessor) SpanProcessor {
+	all := make([]SpanProcessor, 0, len(processors)+1)
+	for _, p := range processors {
+		if p != nil {
+			all = append(all, p)
+		}
+	}
+	if len(all) == 0 {
+		return exporter
+	}
+	return &multiSpanProcessor{
+		processors: append(all, exporter),
+	}
+}
+
+func (m *multiSpanProcessor) OnStart(ctx context.Context, s ReadWriteSpan) {
+	for _, p := range m.processors {
+		p.OnStart(ctx, s)
+	}
+}
+
+func (m *multiSpanProcessor) OnEnd(ctx context.Context, s ReadOnlySpan) {
+	for _, p := range m.processors {
+		p.OnEnd(ctx, s)
+	}
+}
+
+// Shutdown shuts down all processors, and returns the first error.
+func (m *multiSpanProcessor) Shutdown(ctx context.Context) error {
+	var firstErr error
+	for _, p := range m.processors {
+		if err := p.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ForceFlush flushes all processors, and returns the first error.
+func (m *multiSpanProcessor) ForceFlush(ctx context.Context) error {
+	var firstErr error
+	for _, p := range m.processors {
+		if err := p.ForceFlush(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
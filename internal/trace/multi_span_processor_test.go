@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// recordSpanProcessor records processed spans, and sets tag `env` on start
+type recordSpanProcessor struct {
+	name     string
+	calls    *[]string
+	ended    []ReadOnlySpan
+	flushErr error
+}
+
+func (p *recordSpanProcessor) OnStart(ctx context.Context, s ReadWriteSpan) {
+	*p.calls = append(*p.calls, p.name+".OnStart")
+	s.SetTags(ctx, map[string]interface{}{"env": "test"})
+}
+
+func (p *recordSpanProcessor) OnEnd(ctx context.Context, s ReadOnlySpan) {
+	*p.calls = append(*p.calls, p.name+".OnEnd")
+	p.ended = append(p.ended, s)
+}
+
+func (p *recordSpanProcessor) Shutdown(ctx context.Context) error { return nil }
+
+func (p *recordSpanProcessor) ForceFlush(ctx context.Context) error { return p.flushErr }
+
+func TestMultiSpanProcessor(t *testing.T) {
+	Convey("multiSpanProcessor", t, func() {
+		ctx := context.Background()
+		exporter := noopSpanProcessor{}
+		So(newMultiSpanProcessor(nil, exporter), ShouldEqual, exporter)
+		So(newMultiSpanProcessor([]SpanProcessor{nil}, exporter), ShouldEqual, exporter)
+
+		var calls []string
+		first := &recordSpanProcessor{name: "first", calls: &calls}
+		second := &recordSpanProcessor{name: "second", calls: &calls, flushErr: errors.New("flush failed")}
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws"},
+			spanProcessor: newMultiSpanProcessor([]SpanProcessor{first, second}, exporter),
+		}
+
+		_, span, err := p.StartSpan(ctx, "llm", "model", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		So(span.GetTagMap()["env"], ShouldEqual, "test")
+		span.Finish(ctx)
+
+		So(calls, ShouldResemble, []string{"first.OnStart", "second.OnStart", "first.OnEnd", "second.OnEnd"})
+		So(len(second.ended), ShouldEqual, 1)
+		So(second.ended[0].GetSpanID(), ShouldEqual, span.GetSpanID())
+		So(second.ended[0].GetSpanName(), ShouldEqual, "llm")
+		So(p.spanProcessor.ForceFlush(ctx), ShouldBeError, "flush failed")
+		So(p.spanProcessor.Shutdown(ctx), ShouldBeNil)
+	})
+}
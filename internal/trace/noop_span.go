@@ -5,47 +5,112 @@ package trace
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/alva-ai/cozeloop-go/entity"
 	"github.com/alva-ai/cozeloop-go/spec/tracespec"
 )
 
-var DefaultNoopSpan = &noopSpan{}
+var DefaultNoopSpan = &NoopSpan{}
 
-type noopSpan struct{}
+// NoopSpan is a span which does nothing, used when span is not sampled or client is unavailable.
+type NoopSpan struct{}
 
 // implement of commonSpanSetter
-func (n noopSpan) SetInput(ctx context.Context, input interface{})                       {}
-func (n noopSpan) SetOutput(ctx context.Context, output interface{})                     {}
-func (n noopSpan) SetError(ctx context.Context, err error)                               {}
-func (n noopSpan) SetStatusCode(ctx context.Context, code int)                           {}
-func (n noopSpan) SetUserID(ctx context.Context, userID string)                          {}
-func (n noopSpan) SetUserIDBaggage(ctx context.Context, userID string)                   {}
-func (n noopSpan) SetMessageID(ctx context.Context, messageID string)                    {}
-func (n noopSpan) SetMessageIDBaggage(ctx context.Context, messageID string)             {}
-func (n noopSpan) SetThreadID(ctx context.Context, threadID string)                      {}
-func (n noopSpan) SetThreadIDBaggage(ctx context.Context, threadID string)               {}
-func (n noopSpan) SetPrompt(ctx context.Context, prompt entity.Prompt)                   {}
-func (n noopSpan) SetModelProvider(ctx context.Context, modelProvider string)            {}
-func (n noopSpan) SetModelName(ctx context.Context, modelName string)                    {}
-func (n noopSpan) SetModelCallOptions(ctx context.Context, modelCallOptions interface{}) {}
-func (n noopSpan) SetInputTokens(ctx context.Context, inputTokens int)                   {}
-func (n noopSpan) SetOutputTokens(ctx context.Context, outputTokens int)                 {}
-func (n noopSpan) SetStartTimeFirstResp(ctx context.Context, startTimeFirstResp int64)   {}
-func (n noopSpan) SetRuntime(ctx context.Context, runtime tracespec.Runtime)             {}
-func (n noopSpan) SetServiceName(ctx context.Context, serviceName string)                {}
-func (n noopSpan) SetLogID(ctx context.Context, logID string)                            {}
-func (n noopSpan) SetFinishTime(finishTime time.Time)                                    {}
-func (n noopSpan) SetSystemTags(ctx context.Context, systemTags map[string]interface{})  {}
-func (n noopSpan) SetDeploymentEnv(ctx context.Context, deploymentEnv string)            {}
+func (n NoopSpan) SetInput(ctx context.Context, input interface{})                    {}
+func (n NoopSpan) SetSystemPrompt(ctx context.Context, prompt string)                 {}
+func (n NoopSpan) SetOutputFormat(ctx context.Context, format tracespec.OutputFormat) {}
+func (n NoopSpan) SetSelfConsistencyVotes(ctx context.Context, votes []tracespec.SelfConsistencyVote) {
+}
+func (n NoopSpan) SetTokenUsageByPart(ctx context.Context, parts []tracespec.TokenUsagePart) {}
+func (n NoopSpan) SetCodeLocation(ctx context.Context)                                       {}
+func (n NoopSpan) SetAgentRound(ctx context.Context, round, maxRounds int)                   {}
+func (n NoopSpan) SetPlanningStep(ctx context.Context, step tracespec.PlanningStep)          {}
+func (n NoopSpan) SetAgentMemory(ctx context.Context, mem tracespec.AgentMemory)             {}
+func (n NoopSpan) SetCustomAgentMemory(ctx context.Context, mem interface{})                 {}
+func (n NoopSpan) SetOutput(ctx context.Context, output interface{})                         {}
+func (n NoopSpan) SetHTTPRequestBody(ctx context.Context, body []byte, contentType string)   {}
+func (n NoopSpan) SetHTTPResponseBody(ctx context.Context, body []byte, statusCode int)      {}
+func (n NoopSpan) SetError(ctx context.Context, err error)                                   {}
+func (n NoopSpan) SetStatusCode(ctx context.Context, code int)                               {}
+func (n NoopSpan) SetUserID(ctx context.Context, userID string)                              {}
+func (n NoopSpan) SetUserIDBaggage(ctx context.Context, userID string)                       {}
+func (n NoopSpan) SetMessageID(ctx context.Context, messageID string)                        {}
+func (n NoopSpan) SetMessageIDBaggage(ctx context.Context, messageID string)                 {}
+func (n NoopSpan) SetThreadID(ctx context.Context, threadID string)                          {}
+func (n NoopSpan) SetThreadIDBaggage(ctx context.Context, threadID string)                   {}
+func (n NoopSpan) SetPrompt(ctx context.Context, prompt entity.Prompt)                       {}
+func (n NoopSpan) SetModelProvider(ctx context.Context, modelProvider string)                {}
+func (n NoopSpan) SetModelName(ctx context.Context, modelName string)                        {}
+func (n NoopSpan) SetFallbackModel(ctx context.Context, name, provider, reason string)       {}
+func (n NoopSpan) SetModelCallOptions(ctx context.Context, modelCallOptions interface{})     {}
+func (n NoopSpan) SetInputTokens(ctx context.Context, inputTokens int)                       {}
+func (n NoopSpan) SetOutputTokens(ctx context.Context, outputTokens int)                     {}
+func (n NoopSpan) SetCacheHit(ctx context.Context, hit bool)                                 {}
+func (n NoopSpan) SetCachedInputTokens(ctx context.Context, cachedInputTokens int)           {}
+func (n NoopSpan) SetCacheReadInputTokens(ctx context.Context, cacheReadInputTokens int)     {}
+func (n NoopSpan) SetPromptCacheTokens(ctx context.Context, created, read int)               {}
+func (n NoopSpan) SetReasoningTokens(ctx context.Context, reasoningTokens int)               {}
+func (n NoopSpan) SetModelParameters(ctx context.Context, param tracespec.ModelParameters)   {}
+func (n NoopSpan) SetTokenBudget(ctx context.Context, inputBudget, outputBudget int)         {}
+func (n NoopSpan) SetCitations(ctx context.Context, citations []tracespec.Citation)          {}
+func (n NoopSpan) SetLatencyBudget(ctx context.Context, budget time.Duration)                {}
+func (n NoopSpan) SetMultiModalInputs(ctx context.Context, param []tracespec.ModalInput)     {}
+func (n NoopSpan) SetToolSchema(ctx context.Context, schema json.RawMessage)                 {}
+func (n NoopSpan) SetOutputSchema(ctx context.Context, schema json.RawMessage, name string)  {}
+func (n NoopSpan) ValidateOutput(ctx context.Context, schema json.RawMessage) error          { return nil }
+func (n NoopSpan) SetStartTimeFirstResp(ctx context.Context, startTimeFirstResp int64)       {}
+func (n NoopSpan) SetRuntime(ctx context.Context, runtime tracespec.Runtime)                 {}
+func (n NoopSpan) SetServiceName(ctx context.Context, serviceName string)                    {}
+func (n NoopSpan) SetLogID(ctx context.Context, logID string)                                {}
+func (n NoopSpan) SetFinishTime(finishTime time.Time)                                        {}
+func (n NoopSpan) SetSystemTags(ctx context.Context, systemTags map[string]interface{})      {}
+func (n NoopSpan) SetDeploymentEnv(ctx context.Context, deploymentEnv string)                {}
+
+func (n NoopSpan) SetEvaluationResult(ctx context.Context, r tracespec.EvaluationResult)            {}
+func (n NoopSpan) SetFineTuningMetadata(ctx context.Context, m tracespec.FineTuningMeta)            {}
+func (n NoopSpan) SetFunctionCall(ctx context.Context, call tracespec.FunctionCall)                 {}
+func (n NoopSpan) SetFunctionCallResult(ctx context.Context, r tracespec.FunctionCallResult)        {}
+func (n NoopSpan) SetVectorSearchResults(ctx context.Context, r []tracespec.VectorSearchResult)     {}
+func (n NoopSpan) SetRerankResult(ctx context.Context, model string, in, out int, scores []float64) {}
+func (n NoopSpan) SetVectorDBSystem(ctx context.Context, system string)                             {}
+func (n NoopSpan) SetVectorDBOperation(ctx context.Context, op string)                              {}
+func (n NoopSpan) SetVectorDBIndexName(ctx context.Context, name string)                            {}
+func (n NoopSpan) SetVectorDBNamespace(ctx context.Context, namespace string)                       {}
+func (n NoopSpan) SetVectorDBQueryVector(ctx context.Context, dims int)                             {}
+func (n NoopSpan) SetSearchQuery(ctx context.Context, query string)                                 {}
+func (n NoopSpan) SetSearchEngine(ctx context.Context, engine string)                               {}
+func (n NoopSpan) SetSearchResultCount(ctx context.Context, total, returned int)                    {}
+func (n NoopSpan) SetSearchResultSnippets(ctx context.Context, snippets []string)                   {}
+func (n NoopSpan) SetContextWindow(ctx context.Context, used, limit int)                            {}
+func (n NoopSpan) SetContextCompression(ctx context.Context, m string, o, c int, r float64)         {}
+func (n NoopSpan) SetHallucinationScore(ctx context.Context, score float64, grounded, total int)    {}
+func (n NoopSpan) SetGuardrailResult(ctx context.Context, r tracespec.GuardrailResult)              {}
+func (n NoopSpan) SetDocumentContext(ctx context.Context, docs []tracespec.DocumentContext)         {}
+func (n NoopSpan) SetHypotheticalDocument(ctx context.Context, doc string)                          {}
+func (n NoopSpan) SetEmbeddingCacheResult(ctx context.Context, hit bool, cached, recomputed int)    {}
+func (n NoopSpan) SetCacheCheckpointTime(ctx context.Context, t time.Time)                          {}
+func (n NoopSpan) SetRetrievalFilter(ctx context.Context, filter map[string]interface{})            {}
+func (n NoopSpan) SetChainStep(ctx context.Context, stepIndex int, stepName string, totalSteps int) {}
+func (n NoopSpan) SetConversationHistory(ctx context.Context, m []tracespec.ConversationMessage)    {}
+func (n NoopSpan) SetInputMessages(ctx context.Context, m []tracespec.ChatMessage)                  {}
+func (n NoopSpan) SetOutputMessages(ctx context.Context, m []tracespec.ChatMessage)                 {}
+func (n NoopSpan) SetModelFingerprint(ctx context.Context, fingerprint string)                      {}
+func (n NoopSpan) SetGPUMetrics(ctx context.Context, metrics tracespec.GPUMetrics)                  {}
+func (n NoopSpan) SetRetryContext(ctx context.Context, attempt, maxAttempts int, backoff time.Duration) {
+}
 
 // implement of Span
-func (n noopSpan) SetTags(ctx context.Context, tagKVs map[string]interface{})     {}
-func (n noopSpan) SetBaggage(ctx context.Context, baggageItems map[string]string) {}
-func (n noopSpan) GetBaggage() map[string]string                                  { return nil }
-func (n noopSpan) Finish(ctx context.Context)                                     {}
-func (n noopSpan) GetTraceID() string                                             { return "" }
-func (n noopSpan) GetSpanID() string                                              { return "" }
-func (n noopSpan) GetStartTime() time.Time                                        { return time.Time{} }
-func (n noopSpan) ToHeader() (map[string]string, error)                           { return nil, nil }
+func (n NoopSpan) SetTags(ctx context.Context, tagKVs map[string]interface{})     {}
+func (n NoopSpan) SetBaggage(ctx context.Context, baggageItems map[string]string) {}
+func (n NoopSpan) GetBaggage() map[string]string                                  { return nil }
+func (n NoopSpan) Finish(ctx context.Context)                                     {}
+func (n NoopSpan) GetTraceID() string                                             { return "" }
+func (n NoopSpan) GetSpanID() string                                              { return "" }
+func (n NoopSpan) GetStartTime() time.Time                                        { return time.Time{} }
+func (n NoopSpan) GetSystemPrompt(ctx context.Context) (string, bool)             { return "", false }
+func (n NoopSpan) Annotate(ctx context.Context, message string)                   {}
+func (n NoopSpan) CheckBudget(ctx context.Context) (int, int, bool)               { return 0, 0, false }
+func (n NoopSpan) EffectiveCost(cost, reasoningCost float64) float64              { return 0 }
+func (n NoopSpan) ToHeader() (map[string]string, error)                           { return nil, nil }
@@ -0,0 +1,189 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+)
+
+// SetOutputSchema sets the name and compacted JSON schema enforced on model output, the schema is truncated
+// like other tags if too long.
+func (s *Span) SetOutputSchema(ctx context.Context, schema json.RawMessage, schemaName string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := map[string]interface{}{
+		tracespec.OutputSchema: compactJSON(schema),
+	}
+	if schemaName != "" {
+		tagMap[tracespec.OutputSchemaName] = schemaName
+	}
+	s.SetTags(ctx, tagMap)
+}
+
+// ValidateOutput validates the output of span against schema, and sets llm.schema_validation_failed.
+// Output of string is parsed as JSON, other output is validated as its JSON encoding.
+// Only keywords type, enum, properties, required, additionalProperties and items are supported.
+func (s *Span) ValidateOutput(ctx context.Context, schema json.RawMessage) error {
+	if s == nil || s.isSpanFinished() {
+		return nil
+	}
+	var schemaValue interface{}
+	if err := json.Unmarshal(schema, &schemaValue); err != nil {
+		return consts.ErrInvalidParam.Wrap(fmt.Errorf("invalid schema: %w", err))
+	}
+	s.lock.RLock()
+	output, ok := s.TagMap[tracespec.Output]
+	s.lock.RUnlock()
+	if !ok {
+		return consts.ErrInvalidParam.Wrap(fmt.Errorf("output is not set"))
+	}
+
+	err := validateOutput(output, schemaValue)
+	s.SetTags(ctx, oneTag(tracespec.SchemaValidationFailed, err != nil))
+	return err
+}
+
+func validateOutput(output, schema interface{}) error {
+	var data []byte
+	switch o := output.(type) {
+	case string:
+		data = []byte(o)
+	case []byte:
+		data = o
+	default:
+		var err error
+		if data, err = json.Marshal(o); err != nil {
+			return fmt.Errorf("output is not valid JSON: %w", err)
+		}
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("output is not valid JSON: %w", err)
+	}
+	return validateJSONSchema(schema, value, "$")
+}
+
+// validateJSONSchema validates value decoded by encoding/json against a subset of JSON schema.
+func validateJSONSchema(schema, value interface{}, path string) error {
+	if allowed, ok := schema.(bool); ok {
+		if !allowed {
+			return fmt.Errorf("%s: not allowed", path)
+		}
+		return nil
+	}
+	sc, ok := schema.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if t, ok := sc["type"]; ok && !matchSchemaType(t, value) {
+		return fmt.Errorf("%s: expected type %v", path, t)
+	}
+	if enum, ok := sc["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: value is not in enum", path)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required, ok := sc["required"].([]interface{}); ok {
+			for _, r := range required {
+				if name, ok := r.(string); ok {
+					if _, ok := v[name]; !ok {
+						return fmt.Errorf("%s: missing required property %q", path, name)
+					}
+				}
+			}
+		}
+		properties, _ := sc["properties"].(map[string]interface{})
+		// validate in sorted order for a stable error
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			propertySchema, ok := properties[k]
+			if !ok {
+				propertySchema, ok = sc["additionalProperties"]
+			}
+			if !ok {
+				continue
+			}
+			if err := validateJSONSchema(propertySchema, v[k], path+"."+k); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if items, ok := sc["items"]; ok {
+			for i, item := range v {
+				if err := validateJSONSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matchSchemaType returns whether value matches type of schema, which is a type name or an array of type names.
+func matchSchemaType(t, value interface{}) bool {
+	switch tt := t.(type) {
+	case string:
+		return matchTypeName(tt, value)
+	case []interface{}:
+		for _, name := range tt {
+			if n, ok := name.(string); ok && matchTypeName(n, value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func matchTypeName(name string, value interface{}) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return true
+	}
+}
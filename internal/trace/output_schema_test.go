@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOutputSchema(t *testing.T) {
+	Convey("output schema", t, func() {
+		ctx := context.Background()
+		schema := json.RawMessage(`{
+			"type": "object",
+			"required": ["name", "age"],
+			"properties": {
+				"name": {"type": "string"},
+				"age": {"type": "integer"},
+				"tags": {"type": "array", "items": {"enum": ["a", "b"]}}
+			},
+			"additionalProperties": false
+		}`)
+		s := &Span{TagMap: make(map[string]interface{})}
+
+		Convey("SetOutputSchema sets compacted schema and name", func() {
+			s.SetOutputSchema(ctx, schema, "Person")
+			tags := s.GetTagMap()
+			So(tags[tracespec.OutputSchemaName], ShouldEqual, "Person")
+			So(tags[tracespec.OutputSchema], ShouldStartWith, `{"type":"object","required":["name","age"]`)
+		})
+
+		Convey("ValidateOutput passes conforming output", func() {
+			s.SetOutput(ctx, `{"name": "alice", "age": 30, "tags": ["a"]}`)
+			So(s.ValidateOutput(ctx, schema), ShouldBeNil)
+			So(s.GetTagMap()[tracespec.SchemaValidationFailed], ShouldEqual, false)
+
+			s.SetOutput(ctx, map[string]interface{}{"name": "bob", "age": 20})
+			So(s.ValidateOutput(ctx, schema), ShouldBeNil)
+		})
+
+		Convey("ValidateOutput reports violations", func() {
+			cases := map[string]string{
+				`{"name": "alice"}`:                          `$: missing required property "age"`,
+				`{"name": "alice", "age": 1.5}`:              `$.age: expected type integer`,
+				`{"name": "alice", "age": 1, "tags": ["c"]}`: `$.tags[0]: value is not in enum`,
+				`{"name": "alice", "age": 1, "extra": 1}`:    `$.extra: not allowed`,
+				`[]`: `$: expected type object`,
+			}
+			for output, msg := range cases {
+				s.SetOutput(ctx, output)
+				err := s.ValidateOutput(ctx, schema)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, msg)
+				So(s.GetTagMap()[tracespec.SchemaValidationFailed], ShouldEqual, true)
+			}
+
+			s.SetOutput(ctx, "not json")
+			So(s.ValidateOutput(ctx, schema), ShouldNotBeNil)
+		})
+
+		Convey("ValidateOutput rejects invalid params", func() {
+			So(s.ValidateOutput(ctx, schema), ShouldNotBeNil)
+			s.SetOutput(ctx, "{}")
+			So(s.ValidateOutput(ctx, json.RawMessage(`{`)), ShouldNotBeNil)
+			So(s.GetTagMap(), ShouldNotContainKey, tracespec.SchemaValidationFailed)
+		})
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+var _ Exporter = (*PayloadLimitExporter)(nil)
+
+// payloadEnvelopeSize is the size of `{"spans":[]}` of UploadSpanData, which wraps spans in request body.
+const payloadEnvelopeSize = len(`{"spans":[]}`)
+
+// PayloadLimitExporter splits span batches into sub-batches whose JSON payload does not exceed maxBytes,
+// and exports them by inner one by one, for gateways rejecting large requests.
+type PayloadLimitExporter struct {
+	inner    Exporter
+	maxBytes int
+
+	droppedSpans int64
+}
+
+// NewPayloadLimitExporter creates a PayloadLimitExporter. The payload is the JSON of UploadSpanData sent by
+// the default exporter. Spans exceeding maxBytes by themselves are dropped. maxBytes less than or equal to 0
+// means no limit.
+func NewPayloadLimitExporter(inner Exporter, maxBytes int) *PayloadLimitExporter {
+	return &PayloadLimitExporter{
+		inner:    inner,
+		maxBytes: maxBytes,
+	}
+}
+
+// ExportSpans exports spans in sub-batches, it continues exporting even if one sub-batch fails, and returns
+// a PartialExportError with spans of failed sub-batches, so that the exported sub-batches are not retried.
+func (e *PayloadLimitExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	if e.maxBytes <= 0 {
+		return e.inner.ExportSpans(ctx, spans)
+	}
+
+	var firstErr error
+	var failed []*entity.UploadSpan
+	export := func(batch []*entity.UploadSpan) {
+		if len(batch) == 0 {
+			return
+		}
+		err := e.inner.ExportSpans(ctx, batch)
+		if err == nil {
+			return
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		var partialErr *PartialExportError
+		if errors.As(err, &partialErr) {
+			failed = append(failed, partialErr.FailedSpans...)
+		} else {
+			failed = append(failed, batch...)
+		}
+	}
+	batch := make([]*entity.UploadSpan, 0, len(spans))
+	batchSize := payloadEnvelopeSize
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		data, err := json.Marshal(span)
+		if err != nil {
+			logger.CtxWarnf(ctx, "failed to marshal span[%s], it is dropped: %v", span.SpanID, err)
+			atomic.AddInt64(&e.droppedSpans, 1)
+			continue
+		}
+		spanSize := len(data)
+		if payloadEnvelopeSize+spanSize > e.maxBytes {
+			logger.CtxWarnf(ctx, "payload of span[%s] exceeds limit, size: %d, limit: %d, it is dropped",
+				span.SpanID, payloadEnvelopeSize+spanSize, e.maxBytes)
+			atomic.AddInt64(&e.droppedSpans, 1)
+			continue
+		}
+		if len(batch) > 0 {
+			spanSize++ // separator of spans
+		}
+		if batchSize+spanSize > e.maxBytes {
+			export(batch)
+			batch = make([]*entity.UploadSpan, 0, len(spans))
+			batchSize = payloadEnvelopeSize
+			spanSize = len(data)
+		}
+		batch = append(batch, span)
+		batchSize += spanSize
+	}
+	export(batch)
+	if firstErr == nil {
+		return nil
+	}
+	return &PartialExportError{Err: firstErr, FailedSpans: failed}
+}
+
+func (e *PayloadLimitExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return e.inner.ExportFiles(ctx, files)
+}
+
+// DroppedSpans returns the number of spans dropped because their payload exceeds the limit by themselves.
+func (e *PayloadLimitExporter) DroppedSpans() int64 {
+	return atomic.LoadInt64(&e.droppedSpans)
+}
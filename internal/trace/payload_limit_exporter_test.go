@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPayloadLimitExporter(t *testing.T) {
+	Convey("PayloadLimitExporter", t, func() {
+		ctx := context.Background()
+		var batches [][]*entity.UploadSpan
+		inner := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+			batches = append(batches, spans)
+			return nil
+		}}
+		spans := make([]*entity.UploadSpan, 0, 10)
+		for i := 0; i < 10; i++ {
+			spans = append(spans, &entity.UploadSpan{SpanID: fmt.Sprintf("span%d", i), Input: strings.Repeat("a", 100)})
+		}
+		payloadSize := func(batch []*entity.UploadSpan) int {
+			data, _ := json.Marshal(UploadSpanData{Spans: batch})
+			return len(data)
+		}
+
+		Convey("should split spans into sub-batches under the limit", func() {
+			maxBytes := payloadSize(spans[:3])
+			e := NewPayloadLimitExporter(inner, maxBytes)
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(len(batches), ShouldEqual, 4)
+			var exported []*entity.UploadSpan
+			for _, batch := range batches {
+				So(payloadSize(batch), ShouldBeLessThanOrEqualTo, maxBytes)
+				exported = append(exported, batch...)
+			}
+			So(len(batches[0]), ShouldEqual, 3)
+			So(exported, ShouldResemble, spans)
+		})
+
+		Convey("should drop spans exceeding the limit by themselves", func() {
+			large := &entity.UploadSpan{SpanID: "large", Input: strings.Repeat("a", 10000)}
+			e := NewPayloadLimitExporter(inner, 1000)
+			So(e.ExportSpans(ctx, []*entity.UploadSpan{spans[0], large, spans[1]}), ShouldBeNil)
+			So(batches, ShouldResemble, [][]*entity.UploadSpan{{spans[0], spans[1]}})
+			So(e.DroppedSpans(), ShouldEqual, 1)
+		})
+
+		Convey("should export all spans without limit", func() {
+			So(NewPayloadLimitExporter(inner, 0).ExportSpans(ctx, spans), ShouldBeNil)
+			So(batches, ShouldResemble, [][]*entity.UploadSpan{spans})
+		})
+
+		Convey("should export all sub-batches and return spans of failed sub-batches", func() {
+			inner.exportSpans = func(ctx context.Context, spans []*entity.UploadSpan) error {
+				batches = append(batches, spans)
+				if len(batches) == 1 {
+					return nil
+				}
+				return errors.New("request entity too large")
+			}
+			e := NewPayloadLimitExporter(inner, payloadSize(spans[:4]))
+			err := e.ExportSpans(ctx, spans)
+			So(len(batches), ShouldEqual, 3)
+			var partialErr *PartialExportError
+			So(errors.As(err, &partialErr), ShouldBeTrue)
+			So(partialErr.Err.Error(), ShouldEqual, "request entity too large")
+			So(partialErr.FailedSpans, ShouldResemble, spans[4:])
+		})
+
+		Convey("should return failed spans of partially failed sub-batches", func() {
+			inner.exportSpans = func(ctx context.Context, spans []*entity.UploadSpan) error {
+				batches = append(batches, spans)
+				return &PartialExportError{Err: errors.New("bad gateway"), FailedSpans: spans[:1]}
+			}
+			e := NewPayloadLimitExporter(inner, payloadSize(spans[:5]))
+			var partialErr *PartialExportError
+			So(errors.As(e.ExportSpans(ctx, spans), &partialErr), ShouldBeTrue)
+			So(partialErr.FailedSpans, ShouldResemble, []*entity.UploadSpan{spans[0], spans[5]})
+		})
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+// PersistentQueue persists spans until they are acknowledged by exporter, so that spans not delivered
+// before process exits are re-exported when the next client is created. Spans are saved when their batch is
+// exported, not when they are finished, so spans waiting in the span queue are lost if the process crashes.
+// Load is called once when the client is created, before any span of the client is saved.
+type PersistentQueue interface {
+	// Save persists spans before they are exported. Spans with the same trace id and span id are overwritten.
+	Save(ctx context.Context, spans []*entity.UploadSpan) error
+	// Remove deletes spans after they are acknowledged.
+	Remove(ctx context.Context, spans []*entity.UploadSpan) error
+	// Load returns spans which are saved but not removed.
+	Load(ctx context.Context) ([]*entity.UploadSpan, error)
+}
+
+var _ PersistentQueue = (*FilePersistentQueue)(nil)
+
+const persistentSpanFileExt = ".json"
+
+// spanFileNameReplacer replaces path separators in custom trace id and span id.
+var spanFileNameReplacer = strings.NewReplacer("/", "_", "\\", "_")
+
+// FilePersistentQueue is a PersistentQueue which stores each span as a JSON file in a directory.
+type FilePersistentQueue struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFilePersistentQueue creates a FilePersistentQueue storing spans in dir, which is created if not exist.
+func NewFilePersistentQueue(dir string) (*FilePersistentQueue, error) {
+	if dir == "" {
+		return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("dir is empty"))
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, consts.NewError(fmt.Sprintf("create persistent queue dir[%s] fail", dir)).Wrap(err)
+	}
+	return &FilePersistentQueue{dir: dir}, nil
+}
+
+func (q *FilePersistentQueue) Save(ctx context.Context, spans []*entity.UploadSpan) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		data, err := json.Marshal(span)
+		if err != nil {
+			return consts.NewError(fmt.Sprintf("marshal span[%s] fail", span.SpanID)).Wrap(err)
+		}
+		// write to temp file and rename, so that a crash never leaves a partial file
+		path := q.spanPath(span)
+		tmpPath := path + ".tmp"
+		if err = os.WriteFile(tmpPath, data, 0o644); err != nil {
+			return consts.NewError(fmt.Sprintf("save span[%s] fail", span.SpanID)).Wrap(err)
+		}
+		if err = os.Rename(tmpPath, path); err != nil {
+			return consts.NewError(fmt.Sprintf("save span[%s] fail", span.SpanID)).Wrap(err)
+		}
+	}
+	return nil
+}
+
+func (q *FilePersistentQueue) Remove(ctx context.Context, spans []*entity.UploadSpan) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		if err := os.Remove(q.spanPath(span)); err != nil && !os.IsNotExist(err) {
+			return consts.NewError(fmt.Sprintf("remove span[%s] fail", span.SpanID)).Wrap(err)
+		}
+	}
+	return nil
+}
+
+func (q *FilePersistentQueue) Load(ctx context.Context) ([]*entity.UploadSpan, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, consts.NewError(fmt.Sprintf("read persistent queue dir[%s] fail", q.dir)).Wrap(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), persistentSpanFileExt) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	spans := make([]*entity.UploadSpan, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(q.dir, name))
+		if err != nil {
+			logger.CtxWarnf(ctx, "read persisted span[%s] fail, err: %v", name, err)
+			continue
+		}
+		span := &entity.UploadSpan{}
+		if err = json.Unmarshal(data, span); err != nil {
+			logger.CtxWarnf(ctx, "unmarshal persisted span[%s] fail, err: %v", name, err)
+			continue
+		}
+		spans = append(spans, span)
+	}
+	return spans, nil
+}
+
+func (q *FilePersistentQueue) spanPath(span *entity.UploadSpan) string {
+	return filepath.Join(q.dir, spanFileNameReplacer.Replace(span.TraceID+"_"+span.SpanID)+persistentSpanFileExt)
+}
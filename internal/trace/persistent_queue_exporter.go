@@ -0,0 +1,384 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+	"github.com/alva-ai/cozeloop-go/internal/util"
+)
+
+var (
+	_ AckExporter = (*PersistentQueueExporter)(nil)
+	_ spanDropper = (*PersistentQueueExporter)(nil)
+)
+
+const (
+	walSegmentPrefix = "wal-"
+	walSegmentExt    = ".bin"
+	walRecordHeader  = 8 // 4 bytes payload length + 4 bytes crc32 of payload
+
+	DefaultPQMaxBytes = 512 * 1024 * 1024 // 512MB
+
+	// pqReplayTimeout bounds the replay of segments left by previous process.
+	pqReplayTimeout = time.Minute
+)
+
+// PQOption is used to set options for PersistentQueueExporter.
+type PQOption func(e *PersistentQueueExporter)
+
+// WithPQSync set whether to fsync WAL segment before forwarding spans to inner exporter. Default is true.
+// Disabling it improves performance, but spans may be lost if the machine crashes.
+func WithPQSync(enable bool) PQOption {
+	return func(e *PersistentQueueExporter) {
+		e.sync = enable
+	}
+}
+
+// WithPQMaxBytes set the max total size of WAL segments. When exceeded, spans are forwarded without WAL, which
+// happens only if the inner exporter keeps failing.
+// Default is 512MB.
+func WithPQMaxBytes(maxBytes int64) PQOption {
+	return func(e *PersistentQueueExporter) {
+		if maxBytes > 0 {
+			e.maxBytes = maxBytes
+		}
+	}
+}
+
+// walSegment is a WAL file written by one export, which is deleted once all its spans are acknowledged.
+type walSegment struct {
+	path    string
+	size    int64
+	pending map[string]struct{}
+}
+
+// PersistentQueueExporter writes spans to WAL segments before forwarding them to inner exporter,
+// and deletes the segments after spans are acknowledged, so that spans survive process crashes.
+// Spans are written once, retries of spans already in WAL do not write new segments, and spans dropped
+// by span processor after the retry fails are released as if acknowledged.
+type PersistentQueueExporter struct {
+	dir      string
+	inner    Exporter
+	sync     bool
+	maxBytes int64
+
+	mu        sync.Mutex
+	seq       uint64
+	totalSize int64
+	segments  []*walSegment
+	written   map[string]*walSegment // span key -> segment where the span is pending
+
+	replayDone chan struct{} // closed when replay of segments left by previous process finishes
+}
+
+// NewPersistentQueueExporter creates a PersistentQueueExporter writing WAL segments to dir/wal-*.bin.
+// Spans in segments left by previous process are replayed to inner exporter in background within 1 minute,
+// segments failed to replay are kept for the next time.
+func NewPersistentQueueExporter(dir string, inner Exporter, opts ...PQOption) (*PersistentQueueExporter, error) {
+	if dir == "" {
+		return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("dir is empty"))
+	}
+	if inner == nil {
+		return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("inner exporter is nil"))
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, consts.NewError(fmt.Sprintf("create wal dir[%s] fail", dir)).Wrap(err)
+	}
+	e := &PersistentQueueExporter{
+		dir:        dir,
+		inner:      inner,
+		sync:       true,
+		maxBytes:   DefaultPQMaxBytes,
+		written:    make(map[string]*walSegment),
+		replayDone: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	paths, err := e.scanSegments()
+	if err != nil {
+		return nil, err
+	}
+	util.GoSafe(context.Background(), func() {
+		defer close(e.replayDone)
+		ctx, cancel := context.WithTimeout(context.Background(), pqReplayTimeout)
+		defer cancel()
+		e.replay(ctx, paths)
+	})
+	return e, nil
+}
+
+func (e *PersistentQueueExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	ack, err := e.ExportSpansWithAck(ctx, spans)
+	if err != nil {
+		return err
+	}
+	return ack()
+}
+
+// ExportSpansWithAck writes spans not yet in WAL to a segment and forwards them to inner exporter.
+// The segment is deleted once AckFunc succeeds for all of its spans, which may be exported by later retries.
+func (e *PersistentQueueExporter) ExportSpansWithAck(ctx context.Context, spans []*entity.UploadSpan) (AckFunc, error) {
+	if len(spans) == 0 {
+		return func() error { return nil }, nil
+	}
+	if err := e.writeSegment(spans); err != nil {
+		// not block the export if disk is unavailable
+		logger.CtxWarnf(ctx, "write wal segment fail, spans are exported without wal, err: %v", err)
+	}
+
+	innerAck, err := e.exportInner(ctx, spans)
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		if innerAck != nil {
+			if err := innerAck(); err != nil {
+				return err
+			}
+		}
+		e.ack(ctx, spans)
+		return nil
+	}, nil
+}
+
+// dropSpans releases spans dropped by span processor, segments are deleted once all of their spans are
+// acknowledged or dropped, so that WAL does not grow while the inner exporter keeps failing.
+func (e *PersistentQueueExporter) dropSpans(ctx context.Context, spans []*entity.UploadSpan) {
+	logger.CtxWarnf(ctx, "%d spans are dropped after retry, release them from wal", len(spans))
+	e.ack(ctx, spans)
+}
+
+func (e *PersistentQueueExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return e.inner.ExportFiles(ctx, files)
+}
+
+func (e *PersistentQueueExporter) exportInner(ctx context.Context, spans []*entity.UploadSpan) (AckFunc, error) {
+	if ackExporter, ok := e.inner.(AckExporter); ok {
+		return ackExporter.ExportSpansWithAck(ctx, spans)
+	}
+	return nil, e.inner.ExportSpans(ctx, spans)
+}
+
+// writeSegment writes spans not yet in WAL to a new WAL segment, each record is length and crc32 of payload
+// followed by the JSON-encoded span.
+func (e *PersistentQueueExporter) writeSegment(spans []*entity.UploadSpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var buf []byte
+	pending := make(map[string]struct{}, len(spans))
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		key := walSpanKey(span)
+		if _, ok := e.written[key]; ok {
+			continue
+		}
+		if _, ok := pending[key]; ok {
+			continue
+		}
+		payload, err := json.Marshal(span)
+		if err != nil {
+			return consts.NewError(fmt.Sprintf("marshal span[%s] fail", span.SpanID)).Wrap(err)
+		}
+		header := make([]byte, walRecordHeader)
+		binary.BigEndian.PutUint32(header[:4], uint32(len(payload)))
+		binary.BigEndian.PutUint32(header[4:], crc32.ChecksumIEEE(payload))
+		buf = append(buf, header...)
+		buf = append(buf, payload...)
+		pending[key] = struct{}{}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if e.totalSize+int64(len(buf)) > e.maxBytes {
+		return consts.NewError(fmt.Sprintf("wal size exceeds limit %d", e.maxBytes))
+	}
+	e.seq++
+	path := filepath.Join(e.dir, fmt.Sprintf("%s%020d%s", walSegmentPrefix, e.seq, walSegmentExt))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return consts.NewError(fmt.Sprintf("create wal segment[%s] fail", path)).Wrap(err)
+	}
+	defer f.Close()
+	if _, err = f.Write(buf); err != nil {
+		return consts.NewError(fmt.Sprintf("write wal segment[%s] fail", path)).Wrap(err)
+	}
+	if e.sync {
+		if err = f.Sync(); err != nil {
+			return consts.NewError(fmt.Sprintf("sync wal segment[%s] fail", path)).Wrap(err)
+		}
+	}
+	e.addSegment(&walSegment{path: path, size: int64(len(buf)), pending: pending})
+	return nil
+}
+
+// addSegment tracks segment until its spans are acknowledged, e.mu must be held.
+func (e *PersistentQueueExporter) addSegment(segment *walSegment) {
+	e.segments = append(e.segments, segment)
+	e.totalSize += segment.size
+	for key := range segment.pending {
+		e.written[key] = segment
+	}
+}
+
+// ack marks spans as acknowledged, and deletes segments whose spans are all acknowledged.
+func (e *PersistentQueueExporter) ack(ctx context.Context, spans []*entity.UploadSpan) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		key := walSpanKey(span)
+		if segment, ok := e.written[key]; ok {
+			delete(segment.pending, key)
+			delete(e.written, key)
+		}
+	}
+	remaining := e.segments[:0]
+	for _, segment := range e.segments {
+		if len(segment.pending) > 0 {
+			remaining = append(remaining, segment)
+			continue
+		}
+		if err := os.Remove(segment.path); err != nil && !os.IsNotExist(err) {
+			logger.CtxWarnf(ctx, "delete wal segment[%s] fail, err: %v", segment.path, err)
+		}
+		e.totalSize -= segment.size
+	}
+	for i := len(remaining); i < len(e.segments); i++ {
+		e.segments[i] = nil
+	}
+	e.segments = remaining
+}
+
+// scanSegments returns paths of segments left by previous process in order, and continues their sequence.
+func (e *PersistentQueueExporter) scanSegments() ([]string, error) {
+	entries, err := os.ReadDir(e.dir)
+	if err != nil {
+		return nil, consts.NewError(fmt.Sprintf("read wal dir[%s] fail", e.dir)).Wrap(err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, walSegmentPrefix) || !strings.HasSuffix(name, walSegmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, walSegmentPrefix), walSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		if seq > e.seq {
+			e.seq = seq
+		}
+		paths = append(paths, filepath.Join(e.dir, name))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// replay loads segments of paths, and exports spans of them to inner exporter until ctx is done.
+func (e *PersistentQueueExporter) replay(ctx context.Context, paths []string) {
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			logger.CtxWarnf(ctx, "replay wal segments stopped, err: %v", ctx.Err())
+			return
+		}
+		spans, size, err := readWALSegment(path)
+		if err != nil {
+			logger.CtxWarnf(ctx, "read wal segment[%s] fail, err: %v", path, err)
+			continue
+		}
+		segment := &walSegment{path: path, size: size, pending: make(map[string]struct{}, len(spans))}
+		for _, span := range spans {
+			segment.pending[walSpanKey(span)] = struct{}{}
+		}
+		e.mu.Lock()
+		e.addSegment(segment)
+		e.mu.Unlock()
+		if len(spans) == 0 {
+			e.ack(ctx, nil)
+			continue
+		}
+
+		logger.CtxInfof(ctx, "replay %d spans from wal segment[%s]", len(spans), path)
+		innerAck, err := e.exportInner(ctx, spans)
+		if err == nil && innerAck != nil {
+			err = innerAck()
+		}
+		if err != nil {
+			logger.CtxWarnf(ctx, "replay wal segment[%s] fail, err: %v", path, err)
+			continue
+		}
+		e.ack(ctx, spans)
+	}
+}
+
+// readWALSegment reads spans from WAL segment. Records after a truncated or corrupted one are ignored,
+// which may be caused by crash during writing.
+func readWALSegment(path string) ([]*entity.UploadSpan, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	r := bufio.NewReader(f)
+	header := make([]byte, walRecordHeader)
+	var spans []*entity.UploadSpan
+	for {
+		if _, err = io.ReadFull(r, header); err != nil {
+			break
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		if length > info.Size() {
+			break
+		}
+		payload := make([]byte, length)
+		if _, err = io.ReadFull(r, payload); err != nil {
+			break
+		}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:]) {
+			break
+		}
+		span := &entity.UploadSpan{}
+		if err = json.Unmarshal(payload, span); err != nil {
+			break
+		}
+		spans = append(spans, span)
+	}
+	return spans, info.Size(), nil
+}
+
+func walSpanKey(span *entity.UploadSpan) string {
+	return span.TraceID + "_" + span.SpanID
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func walSegments(dir string) []string {
+	paths, err := filepath.Glob(filepath.Join(dir, "wal-*.bin"))
+	So(err, ShouldBeNil)
+	return paths
+}
+
+func TestPersistentQueueExporter(t *testing.T) {
+	Convey("PersistentQueueExporter", t, func() {
+		ctx := context.Background()
+		dir := t.TempDir()
+		spans := []*entity.UploadSpan{
+			{TraceID: "trace1", SpanID: "span1"},
+			{TraceID: "trace1", SpanID: "span2"},
+		}
+		failed := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+			return errors.New("unavailable")
+		}}
+
+		Convey("should delete wal segment after export succeeds", func() {
+			inner := &funcExporter{}
+			e, err := NewPersistentQueueExporter(dir, inner)
+			So(err, ShouldBeNil)
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(inner.calls, ShouldEqual, 1)
+			So(walSegments(dir), ShouldBeEmpty)
+		})
+
+		Convey("should keep wal segment until acknowledged", func() {
+			inner := &ackExporter{ackErr: errors.New("not acked")}
+			e, err := NewPersistentQueueExporter(dir, inner)
+			So(err, ShouldBeNil)
+			ack, err := e.ExportSpansWithAck(ctx, spans)
+			So(err, ShouldBeNil)
+			So(ack(), ShouldNotBeNil)
+			So(len(walSegments(dir)), ShouldEqual, 1)
+
+			inner.ackErr = nil
+			So(ack(), ShouldBeNil)
+			So(walSegments(dir), ShouldBeEmpty)
+		})
+
+		Convey("should delete wal segments after retry succeeds", func() {
+			e, err := NewPersistentQueueExporter(dir, failed)
+			So(err, ShouldBeNil)
+			So(e.ExportSpans(ctx, spans), ShouldNotBeNil)
+			// spans already in wal are not written again by retries
+			So(e.ExportSpans(ctx, spans[:1]), ShouldNotBeNil)
+			So(len(walSegments(dir)), ShouldEqual, 1)
+			So(e.ExportSpans(ctx, append(spans[:1:1], &entity.UploadSpan{TraceID: "trace1", SpanID: "span3"})), ShouldNotBeNil)
+			So(len(walSegments(dir)), ShouldEqual, 2)
+
+			e.inner = &funcExporter{}
+			So(e.ExportSpans(ctx, spans[:1]), ShouldBeNil)
+			So(len(walSegments(dir)), ShouldEqual, 2)
+			So(e.ExportSpans(ctx, spans[1:]), ShouldBeNil)
+			So(len(walSegments(dir)), ShouldEqual, 1)
+			So(e.ExportSpans(ctx, []*entity.UploadSpan{{TraceID: "trace1", SpanID: "span3"}}), ShouldBeNil)
+			So(walSegments(dir), ShouldBeEmpty)
+			So(e.totalSize, ShouldEqual, 0)
+		})
+
+		Convey("should delete wal segments of dropped spans", func() {
+			e, err := NewPersistentQueueExporter(dir, failed)
+			So(err, ShouldBeNil)
+			So(e.ExportSpans(ctx, spans), ShouldNotBeNil)
+			So(len(walSegments(dir)), ShouldEqual, 1)
+
+			dropSpans(ctx, e, []*Span{
+				{SpanContext: SpanContext{TraceID: "trace1", SpanID: "span1"}},
+				{SpanContext: SpanContext{TraceID: "trace1", SpanID: "span2"}},
+			})
+			So(walSegments(dir), ShouldBeEmpty)
+			So(e.totalSize, ShouldEqual, 0)
+		})
+
+		Convey("should replay in background within timeout", func() {
+			e, err := NewPersistentQueueExporter(dir, failed)
+			So(err, ShouldBeNil)
+			So(e.ExportSpans(ctx, spans), ShouldNotBeNil)
+
+			release := make(chan struct{})
+			blocked := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+				<-release
+				return nil
+			}}
+			e, err = NewPersistentQueueExporter(dir, blocked)
+			So(err, ShouldBeNil)
+			So(len(walSegments(dir)), ShouldEqual, 1)
+			close(release)
+			<-e.replayDone
+			So(walSegments(dir), ShouldBeEmpty)
+		})
+
+		Convey("should replay unacknowledged wal segments on startup", func() {
+			e, err := NewPersistentQueueExporter(dir, failed)
+			So(err, ShouldBeNil)
+			So(e.ExportSpans(ctx, spans), ShouldNotBeNil)
+			segments := walSegments(dir)
+			So(len(segments), ShouldEqual, 1)
+
+			// a record partially written by crash is ignored
+			f, err := os.OpenFile(segments[0], os.O_APPEND|os.O_WRONLY, 0o644)
+			So(err, ShouldBeNil)
+			_, err = f.Write([]byte{0, 0, 1})
+			So(err, ShouldBeNil)
+			So(f.Close(), ShouldBeNil)
+
+			// replay fails, segment is kept
+			e, err = NewPersistentQueueExporter(dir, failed)
+			So(err, ShouldBeNil)
+			<-e.replayDone
+			So(len(walSegments(dir)), ShouldEqual, 1)
+
+			var replayed []*entity.UploadSpan
+			inner := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+				replayed = append(replayed, spans...)
+				return nil
+			}}
+			e, err = NewPersistentQueueExporter(dir, inner)
+			So(err, ShouldBeNil)
+			<-e.replayDone
+			So(len(replayed), ShouldEqual, 2)
+			So(replayed[0].SpanID, ShouldEqual, "span1")
+			So(walSegments(dir), ShouldBeEmpty)
+
+			// sequence continues after replayed segments
+			e.inner = failed
+			So(e.ExportSpans(ctx, spans), ShouldNotBeNil)
+			So(filepath.Base(walSegments(dir)[0]), ShouldEqual, "wal-00000000000000000002.bin")
+		})
+
+		Convey("should export without wal if exceeds max bytes", func() {
+			e, err := NewPersistentQueueExporter(dir, failed, WithPQMaxBytes(10), WithPQSync(false))
+			So(err, ShouldBeNil)
+			So(e.ExportSpans(ctx, spans), ShouldNotBeNil)
+			So(walSegments(dir), ShouldBeEmpty)
+		})
+
+		Convey("should fail with invalid params", func() {
+			_, err := NewPersistentQueueExporter("", &funcExporter{})
+			So(err, ShouldNotBeNil)
+			_, err = NewPersistentQueueExporter(dir, nil)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
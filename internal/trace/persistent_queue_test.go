@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// ackExporter is an AckExporter whose AckFunc returns ackErr, for testing
+type ackExporter struct {
+	funcExporter
+	ackErr error
+	acked  int
+}
+
+func (e *ackExporter) ExportSpansWithAck(ctx context.Context, spans []*entity.UploadSpan) (AckFunc, error) {
+	if err := e.ExportSpans(ctx, spans); err != nil {
+		return nil, err
+	}
+	return func() error {
+		e.acked++
+		return e.ackErr
+	}, nil
+}
+
+func TestFilePersistentQueue(t *testing.T) {
+	Convey("FilePersistentQueue", t, func() {
+		ctx := context.Background()
+		q, err := NewFilePersistentQueue(t.TempDir())
+		So(err, ShouldBeNil)
+
+		spans := []*entity.UploadSpan{
+			{TraceID: "trace1", SpanID: "span1", SpanName: "first"},
+			{TraceID: "trace1", SpanID: "span2", SpanName: "second"},
+		}
+		So(q.Save(ctx, spans), ShouldBeNil)
+		loaded, err := q.Load(ctx)
+		So(err, ShouldBeNil)
+		So(len(loaded), ShouldEqual, 2)
+		So(loaded[0].SpanName, ShouldEqual, "first")
+
+		So(q.Remove(ctx, spans[:1]), ShouldBeNil)
+		So(q.Remove(ctx, spans[:1]), ShouldBeNil)
+		loaded, err = q.Load(ctx)
+		So(err, ShouldBeNil)
+		So(len(loaded), ShouldEqual, 1)
+		So(loaded[0].SpanID, ShouldEqual, "span2")
+
+		_, err = NewFilePersistentQueue("")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestExportSpansWithAck(t *testing.T) {
+	Convey("exportSpansWithAck", t, func() {
+		ctx := context.Background()
+		spans := []*entity.UploadSpan{{TraceID: "trace1", SpanID: "span1"}}
+
+		Convey("should call AckFunc of AckExporter", func() {
+			e := &ackExporter{}
+			So(exportSpansWithAck(ctx, e, spans), ShouldBeNil)
+			So(e.acked, ShouldEqual, 1)
+
+			e.ackErr = errors.New("not acked")
+			So(exportSpansWithAck(ctx, e, spans), ShouldNotBeNil)
+		})
+
+		Convey("should use ExportSpans of Exporter", func() {
+			e := &funcExporter{}
+			So(exportSpansWithAck(ctx, e, spans), ShouldBeNil)
+			So(e.calls, ShouldEqual, 1)
+		})
+	})
+}
+
+func TestReexportPersistedSpans(t *testing.T) {
+	Convey("reexportPersistedSpans", t, func() {
+		ctx := context.Background()
+		q, err := NewFilePersistentQueue(t.TempDir())
+		So(err, ShouldBeNil)
+		spans := []*entity.UploadSpan{
+			{TraceID: "trace1", SpanID: "span1"},
+			{TraceID: "trace1", SpanID: "span2"},
+			{TraceID: "trace1", SpanID: "span3"},
+		}
+		So(q.Save(ctx, spans), ShouldBeNil)
+		persisted, err := q.Load(ctx)
+		So(err, ShouldBeNil)
+
+		Convey("should keep spans not acknowledged", func() {
+			e := &ackExporter{ackErr: errors.New("not acked")}
+			reexportPersistedSpans(ctx, e, q, persisted, 2)
+			So(e.calls, ShouldEqual, 2)
+			loaded, err := q.Load(ctx)
+			So(err, ShouldBeNil)
+			So(len(loaded), ShouldEqual, 3)
+		})
+
+		Convey("should remove spans acknowledged", func() {
+			e := &ackExporter{}
+			reexportPersistedSpans(ctx, e, q, persisted, 2)
+			So(e.acked, ShouldEqual, 2)
+			loaded, err := q.Load(ctx)
+			So(err, ShouldBeNil)
+			So(len(loaded), ShouldEqual, 0)
+		})
+
+		Convey("should not re-export spans saved after loaded", func() {
+			So(q.Save(ctx, []*entity.UploadSpan{{TraceID: "trace2", SpanID: "span4"}}), ShouldBeNil)
+			var exported []string
+			e := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+				for _, span := range spans {
+					exported = append(exported, span.SpanID)
+				}
+				return nil
+			}}
+			reexportPersistedSpans(ctx, e, q, persisted, 2)
+			So(exported, ShouldResemble, []string{"span1", "span2", "span3"})
+			loaded, err := q.Load(ctx)
+			So(err, ShouldBeNil)
+			So(len(loaded), ShouldEqual, 1)
+			So(loaded[0].SpanID, ShouldEqual, "span4")
+		})
+	})
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+)
+
+func (s *Span) SetPlanningStep(ctx context.Context, step tracespec.PlanningStep) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := map[string]interface{}{
+		tracespec.PlanNodeID:               step.NodeID,
+		tracespec.PlanDepth:                step.Depth,
+		tracespec.PlanScore:                step.Score,
+		tracespec.PlanPruned:               step.Pruned,
+		tracespec.PlanSelectedForExpansion: step.SelectedForExpansion,
+	}
+	if step.ParentNodeID != "" {
+		tagMap[tracespec.PlanParentNodeID] = step.ParentNodeID
+	}
+	s.SetTags(ctx, tagMap)
+}
+
+// planningNode is a node of planning tree built from spans with plan.node_id.
+type planningNode struct {
+	id       string
+	parentID string
+	span     *entity.UploadSpan
+	children []*planningNode
+}
+
+// writePlanningTrees writes planning nodes of each trace in spans as an ASCII tree, in order of appearance.
+// Nodes whose parent is not in spans are written as roots. It returns the first error of writing.
+func writePlanningTrees(f io.Writer, spans []*entity.UploadSpan) error {
+	w := &markdownWriter{w: f}
+	var traceIDs []string
+	nodesByTrace := make(map[string][]*planningNode)
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		id, ok := span.TagsString[tracespec.PlanNodeID]
+		if !ok {
+			continue
+		}
+		if _, ok := nodesByTrace[span.TraceID]; !ok {
+			traceIDs = append(traceIDs, span.TraceID)
+		}
+		nodesByTrace[span.TraceID] = append(nodesByTrace[span.TraceID], &planningNode{
+			id:       id,
+			parentID: span.TagsString[tracespec.PlanParentNodeID],
+			span:     span,
+		})
+	}
+
+	for _, traceID := range traceIDs {
+		nodes := nodesByTrace[traceID]
+		byID := make(map[string]*planningNode, len(nodes))
+		for _, node := range nodes {
+			if _, ok := byID[node.id]; !ok {
+				byID[node.id] = node
+			}
+		}
+		var roots []*planningNode
+		for _, node := range nodes {
+			if parent, ok := byID[node.parentID]; ok && node.parentID != node.id && byID[node.id] == node {
+				parent.children = append(parent.children, node)
+			} else {
+				roots = append(roots, node)
+			}
+		}
+
+		w.WriteString(fmt.Sprintf("### Planning Tree: %s\n\n```\n", traceID))
+		visited := make(map[*planningNode]bool, len(nodes))
+		for _, root := range roots {
+			writePlanningNode(w, root, "", "", visited)
+		}
+		w.WriteString("```\n\n")
+	}
+	return w.err
+}
+
+func writePlanningNode(w *markdownWriter, node *planningNode, prefix, childPrefix string, visited map[*planningNode]bool) {
+	if visited[node] {
+		return
+	}
+	visited[node] = true
+	w.WriteString(prefix + formatPlanningNode(node) + "\n")
+	for i, child := range node.children {
+		if i == len(node.children)-1 {
+			writePlanningNode(w, child, childPrefix+"└── ", childPrefix+"    ", visited)
+		} else {
+			writePlanningNode(w, child, childPrefix+"├── ", childPrefix+"│   ", visited)
+		}
+	}
+}
+
+// formatPlanningNode formats node as `id (score: 0.80) [selected]`.
+func formatPlanningNode(node *planningNode) string {
+	sb := &strings.Builder{}
+	sb.WriteString(strings.ReplaceAll(node.id, "\n", " "))
+	if score, ok := node.span.TagsDouble[tracespec.PlanScore]; ok {
+		sb.WriteString(fmt.Sprintf(" (score: %.2f)", score))
+	}
+	if node.span.TagsBool[tracespec.PlanSelectedForExpansion] {
+		sb.WriteString(" [selected]")
+	}
+	if node.span.TagsBool[tracespec.PlanPruned] {
+		sb.WriteString(" [pruned]")
+	}
+	return sb.String()
+}
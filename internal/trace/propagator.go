@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"net/textproto"
+
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+)
+
+// Propagator injects span context into carrier and extracts span context from carrier,
+// which is used to link spans across process boundaries.
+type Propagator interface {
+	// Inject writes the span context of the current span in ctx into header.
+	// If there is no span in ctx, the remote span context extracted before is used.
+	Inject(ctx context.Context, header map[string]string)
+	// Extract reads span context from header and returns a ctx carrying it.
+	// Spans started from the returned ctx without local parent will be children of the remote span.
+	Extract(ctx context.Context, header map[string]string) context.Context
+}
+
+type remoteSpanContextKey struct{}
+
+type samplingDecisionKey struct{}
+
+// ContextWithRemoteSpanContext returns a copy of ctx carrying the span context of a remote parent span.
+func ContextWithRemoteSpanContext(ctx context.Context, sc *SpanContext) context.Context {
+	if sc == nil || sc.TraceID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteSpanContextKey{}, sc)
+}
+
+// RemoteSpanContextFromContext returns the remote span context in ctx, nil if not exist.
+func RemoteSpanContextFromContext(ctx context.Context) *SpanContext {
+	sc, ok := ctx.Value(remoteSpanContextKey{}).(*SpanContext)
+	if !ok {
+		return nil
+	}
+	return sc
+}
+
+// ContextWithSamplingDecision returns a copy of ctx carrying the sampling decision of upstream.
+// If sampled is false, spans started from ctx are dropped.
+func ContextWithSamplingDecision(ctx context.Context, sampled bool) context.Context {
+	return context.WithValue(ctx, samplingDecisionKey{}, sampled)
+}
+
+// SamplingDecisionFromContext returns the sampling decision in ctx, ok is false if not exist.
+func SamplingDecisionFromContext(ctx context.Context) (sampled bool, ok bool) {
+	sampled, ok = ctx.Value(samplingDecisionKey{}).(bool)
+	return sampled, ok
+}
+
+// spanContextFromContext returns the span context of current span in ctx, fall back to remote span context.
+func spanContextFromContext(ctx context.Context) *SpanContext {
+	if s, ok := ctx.Value(loopSpanKey{}).(*Span); ok && s != nil {
+		return &SpanContext{
+			SpanID:  s.GetSpanID(),
+			TraceID: s.GetTraceID(),
+			Baggage: s.GetBaggage(),
+		}
+	}
+	return RemoteSpanContextFromContext(ctx)
+}
+
+// canonicalHeader returns a copy of header with canonical MIME header keys.
+func canonicalHeader(h map[string]string) map[string]string {
+	header := make(map[string]string, len(h))
+	for key, value := range h {
+		header[textproto.CanonicalMIMEHeaderKey(key)] = value
+	}
+	return header
+}
+
+// loopPropagator propagates span context in cozeloop header format, the same as Span.ToHeader.
+type loopPropagator struct{}
+
+// NewLoopPropagator returns a Propagator using cozeloop header format.
+func NewLoopPropagator() Propagator {
+	return loopPropagator{}
+}
+
+func (p loopPropagator) Inject(ctx context.Context, header map[string]string) {
+	sc := spanContextFromContext(ctx)
+	if sc == nil || header == nil {
+		return
+	}
+	// for W3C, sampled by default
+	header[consts.TraceContextHeaderParent] = fmt.Sprintf("%02x-%s-%s-%02x", consts.GlobalTraceVersion, sc.TraceID, sc.SpanID, 1)
+	if baggage := toHeaderBaggage(sc.Baggage); baggage != "" {
+		header[consts.TraceContextHeaderBaggage] = baggage
+	}
+}
+
+func (p loopPropagator) Extract(ctx context.Context, header map[string]string) context.Context {
+	return ContextWithRemoteSpanContext(ctx, FromHeader(ctx, header))
+}
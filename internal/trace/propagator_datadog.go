@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+	"github.com/alva-ai/cozeloop-go/internal/util"
+)
+
+const (
+	DatadogTraceIDHeader          = "X-Datadog-Trace-Id"
+	DatadogParentIDHeader         = "X-Datadog-Parent-Id"
+	DatadogSamplingPriorityHeader = "X-Datadog-Sampling-Priority"
+)
+
+// datadogPropagator propagates span context in Datadog APM header format.
+// Datadog trace id is uint64 decimal string, which is zero-padded to 32 hex chars trace id,
+// and only the lower 64 bits of trace id are injected.
+type datadogPropagator struct{}
+
+// NewDatadogPropagator returns a Propagator using Datadog APM header format.
+func NewDatadogPropagator() Propagator {
+	return datadogPropagator{}
+}
+
+func (p datadogPropagator) Inject(ctx context.Context, header map[string]string) {
+	sc := spanContextFromContext(ctx)
+	if sc == nil || header == nil {
+		return
+	}
+	if len(sc.TraceID) != 32 || !util.IsValidHexStr(sc.TraceID) {
+		logger.CtxWarnf(ctx, "failed to inject datadog header: invalid trace id: %s", sc.TraceID)
+		return
+	}
+	traceID, err := strconv.ParseUint(sc.TraceID[16:], 16, 64)
+	if err != nil {
+		logger.CtxWarnf(ctx, "failed to inject datadog header: %v", err)
+		return
+	}
+	// span id may be customized, generate a new one if it is not 16 hex chars
+	spanID := sc.SpanID
+	if len(spanID) != 16 || !util.IsValidHexStr(spanID) {
+		spanID = util.Gen16CharID()
+	}
+	parentID, err := strconv.ParseUint(spanID, 16, 64)
+	if err != nil {
+		logger.CtxWarnf(ctx, "failed to inject datadog header: %v", err)
+		return
+	}
+	priority := "1"
+	if sampled, ok := SamplingDecisionFromContext(ctx); ok && !sampled {
+		priority = "0"
+	}
+
+	header[DatadogTraceIDHeader] = strconv.FormatUint(traceID, 10)
+	header[DatadogParentIDHeader] = strconv.FormatUint(parentID, 10)
+	header[DatadogSamplingPriorityHeader] = priority
+}
+
+func (p datadogPropagator) Extract(ctx context.Context, header map[string]string) context.Context {
+	h := canonicalHeader(header)
+	traceIDStr, ok := h[DatadogTraceIDHeader]
+	if !ok || traceIDStr == "" {
+		return ctx
+	}
+	traceID, err := strconv.ParseUint(traceIDStr, 10, 64)
+	if err != nil || traceID == 0 {
+		logger.CtxWarnf(ctx, "failed to parse datadog header: invalid trace id: %s", traceIDStr)
+		return ctx
+	}
+
+	sc := &SpanContext{
+		TraceID: fmt.Sprintf("%032x", traceID),
+	}
+	if parentIDStr, ok := h[DatadogParentIDHeader]; ok {
+		if parentID, err := strconv.ParseUint(parentIDStr, 10, 64); err == nil && parentID != 0 {
+			sc.SpanID = fmt.Sprintf("%016x", parentID)
+		}
+	}
+	// priority: -1 user reject, 0 auto reject, 1 auto keep, 2 user keep
+	if priorityStr, ok := h[DatadogSamplingPriorityHeader]; ok {
+		if priority, err := strconv.Atoi(priorityStr); err == nil {
+			ctx = ContextWithSamplingDecision(ctx, priority > 0)
+		}
+	}
+
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
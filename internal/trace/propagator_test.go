@@ -0,0 +1,266 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestXRayPropagator(t *testing.T) {
+	Convey("XRayPropagator", t, func() {
+		ctx := context.Background()
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws"},
+			spanProcessor: noopSpanProcessor{},
+		}
+		xray := NewXRayPropagator()
+
+		Convey("extract should convert x-ray trace id and parent", func() {
+			header := map[string]string{
+				"x-amzn-trace-id": "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1",
+			}
+			ctx = xray.Extract(ctx, header)
+			sampled, ok := SamplingDecisionFromContext(ctx)
+			So(ok, ShouldBeTrue)
+			So(sampled, ShouldBeTrue)
+
+			_, span, err := p.StartSpan(ctx, "server", "test", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			So(span, ShouldNotBeNil)
+			So(span.GetTraceID(), ShouldEqual, "5759e988bd862e3fe1be46a994272793")
+			So(span.GetParentID(), ShouldEqual, "53995c3f42cd8ad8")
+		})
+
+		Convey("extract with Sampled=0 should drop spans", func() {
+			header := map[string]string{
+				XRayTraceHeader: "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0",
+			}
+			ctx = xray.Extract(ctx, header)
+			_, span, err := p.StartSpan(ctx, "server", "test", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			So(span, ShouldBeNil)
+
+			_, span, err = p.StartSpan(ctx, "new_trace", "test", StartSpanOptions{StartNewTrace: true})
+			So(err, ShouldBeNil)
+			So(span, ShouldNotBeNil)
+		})
+
+		Convey("extract should ignore invalid header", func() {
+			header := map[string]string{
+				XRayTraceHeader: "Root=1-xyz-123;Parent=53995c3f42cd8ad8",
+			}
+			So(RemoteSpanContextFromContext(xray.Extract(ctx, header)), ShouldBeNil)
+		})
+
+		Convey("extract should return ctx unchanged if root is missing or invalid", func() {
+			for _, value := range []string{
+				"Sampled=0;Root=1-xyz-123;Parent=53995c3f42cd8ad8",
+				"Sampled=0;Parent=53995c3f42cd8ad8",
+			} {
+				extracted := xray.Extract(ctx, map[string]string{XRayTraceHeader: value})
+				So(extracted, ShouldEqual, ctx)
+				_, ok := SamplingDecisionFromContext(extracted)
+				So(ok, ShouldBeFalse)
+			}
+		})
+
+		Convey("inject should write current span in x-ray format", func() {
+			spanCtx, span, err := p.StartSpan(ctx, "client", "test", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			header := make(map[string]string)
+			xray.Inject(spanCtx, header)
+			traceID := span.GetTraceID()
+			So(header[XRayTraceHeader], ShouldEqual,
+				"Root=1-"+traceID[:8]+"-"+traceID[8:]+";Parent="+span.GetSpanID()+";Sampled=1")
+
+			// round trip
+			sc := RemoteSpanContextFromContext(xray.Extract(ctx, header))
+			So(sc, ShouldNotBeNil)
+			So(sc.TraceID, ShouldEqual, traceID)
+			So(sc.SpanID, ShouldEqual, span.GetSpanID())
+		})
+	})
+}
+
+func TestLoopPropagator(t *testing.T) {
+	Convey("loopPropagator round trip", t, func() {
+		ctx := context.Background()
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws"},
+			spanProcessor: noopSpanProcessor{},
+		}
+		spanCtx, span, err := p.StartSpan(ctx, "client", "test", StartSpanOptions{Baggage: map[string]string{"k": "v"}})
+		So(err, ShouldBeNil)
+
+		propagator := NewLoopPropagator()
+		header := make(map[string]string)
+		propagator.Inject(spanCtx, header)
+
+		_, child, err := p.StartSpan(propagator.Extract(ctx, header), "server", "test", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		So(child.GetTraceID(), ShouldEqual, span.GetTraceID())
+		So(child.GetParentID(), ShouldEqual, span.GetSpanID())
+		So(child.GetBaggage()["k"], ShouldEqual, "v")
+	})
+}
+
+func TestDatadogPropagator(t *testing.T) {
+	Convey("datadogPropagator", t, func() {
+		ctx := context.Background()
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws"},
+			spanProcessor: noopSpanProcessor{},
+		}
+		datadog := NewDatadogPropagator()
+
+		Convey("extract should zero-pad trace id and convert parent id", func() {
+			header := map[string]string{
+				"x-datadog-trace-id":          "1234567890123456789",
+				"x-datadog-parent-id":         "255",
+				"x-datadog-sampling-priority": "2",
+			}
+			ctx = datadog.Extract(ctx, header)
+			sampled, ok := SamplingDecisionFromContext(ctx)
+			So(ok, ShouldBeTrue)
+			So(sampled, ShouldBeTrue)
+
+			_, span, err := p.StartSpan(ctx, "server", "test", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			So(span.GetTraceID(), ShouldEqual, "0000000000000000112210f47de98115")
+			So(span.GetParentID(), ShouldEqual, "00000000000000ff")
+		})
+
+		Convey("extract with priority 0 should drop spans", func() {
+			header := map[string]string{
+				DatadogTraceIDHeader:          "1234567890123456789",
+				DatadogParentIDHeader:         "255",
+				DatadogSamplingPriorityHeader: "0",
+			}
+			_, span, err := p.StartSpan(datadog.Extract(ctx, header), "server", "test", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			So(span, ShouldBeNil)
+		})
+
+		Convey("extract should ignore invalid trace id", func() {
+			header := map[string]string{
+				DatadogTraceIDHeader: "abc",
+			}
+			So(RemoteSpanContextFromContext(datadog.Extract(ctx, header)), ShouldBeNil)
+		})
+
+		Convey("inject should write lower 64 bits in decimal", func() {
+			spanCtx, _, err := p.StartSpan(ctx, "client", "test", StartSpanOptions{
+				TraceID: "0000000000000000112210f47de98115",
+				SpanID:  "00000000000000ff",
+			})
+			So(err, ShouldBeNil)
+			header := make(map[string]string)
+			datadog.Inject(spanCtx, header)
+			So(header[DatadogTraceIDHeader], ShouldEqual, "1234567890123456789")
+			So(header[DatadogParentIDHeader], ShouldEqual, "255")
+			So(header[DatadogSamplingPriorityHeader], ShouldEqual, "1")
+		})
+	})
+}
+
+func TestW3CPropagator(t *testing.T) {
+	Convey("w3cPropagator", t, func() {
+		ctx := context.Background()
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws"},
+			spanProcessor: noopSpanProcessor{},
+		}
+		w3c := NewW3CPropagator()
+
+		Convey("round trip should keep trace id, parent and baggage", func() {
+			spanCtx, span, err := p.StartSpan(ctx, "client", "test", StartSpanOptions{Baggage: map[string]string{"k": "v"}})
+			So(err, ShouldBeNil)
+			header := make(map[string]string)
+			w3c.Inject(spanCtx, header)
+			So(header[W3CTraceParentHeader], ShouldEqual, "00-"+span.GetTraceID()+"-"+span.GetSpanID()+"-01")
+
+			_, child, err := p.StartSpan(w3c.Extract(ctx, header), "server", "test", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			So(child.GetTraceID(), ShouldEqual, span.GetTraceID())
+			So(child.GetParentID(), ShouldEqual, span.GetSpanID())
+			So(child.GetBaggage()["k"], ShouldEqual, "v")
+		})
+
+		Convey("extract with unsampled flags should drop spans", func() {
+			header := map[string]string{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00"}
+			_, span, err := p.StartSpan(w3c.Extract(ctx, header), "server", "test", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			So(span, ShouldBeNil)
+		})
+	})
+}
+
+func TestB3Propagator(t *testing.T) {
+	Convey("b3Propagator", t, func() {
+		ctx := context.Background()
+		b3 := NewB3Propagator()
+
+		Convey("extract multiple headers should pad 64-bit trace id", func() {
+			header := map[string]string{
+				"X-B3-TraceId": "463ac35c9f6413ad",
+				"X-B3-SpanId":  "a2fb4a1d1a96d312",
+				"X-B3-Sampled": "1",
+			}
+			sc := RemoteSpanContextFromContext(b3.Extract(ctx, header))
+			So(sc, ShouldNotBeNil)
+			So(sc.TraceID, ShouldEqual, "0000000000000000463ac35c9f6413ad")
+			So(sc.SpanID, ShouldEqual, "a2fb4a1d1a96d312")
+		})
+
+		Convey("extract single header", func() {
+			header := map[string]string{"b3": "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-0-05e3ac9a4f6e3b90"}
+			ctx = b3.Extract(ctx, header)
+			sc := RemoteSpanContextFromContext(ctx)
+			So(sc.TraceID, ShouldEqual, "80f198ee56343ba864fe8b2a57d3eff7")
+			So(sc.SpanID, ShouldEqual, "e457b5a2e4d86bd1")
+			sampled, ok := SamplingDecisionFromContext(ctx)
+			So(ok, ShouldBeTrue)
+			So(sampled, ShouldBeFalse)
+		})
+
+		Convey("extract should ignore invalid header", func() {
+			So(RemoteSpanContextFromContext(b3.Extract(ctx, map[string]string{B3TraceIDHeader: "xyz"})), ShouldBeNil)
+		})
+	})
+}
+
+func TestCompositePropagator(t *testing.T) {
+	Convey("compositePropagator", t, func() {
+		ctx := context.Background()
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws"},
+			spanProcessor: noopSpanProcessor{},
+		}
+		composite := NewCompositePropagator(NewLoopPropagator(), NewW3CPropagator(), NewB3Propagator())
+
+		Convey("inject should write headers of all propagators", func() {
+			spanCtx, _, err := p.StartSpan(ctx, "client", "test", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			header := make(map[string]string)
+			composite.Inject(spanCtx, header)
+			So(header, ShouldContainKey, W3CTraceParentHeader)
+			So(header, ShouldContainKey, B3TraceIDHeader)
+			So(len(header), ShouldBeGreaterThanOrEqualTo, 5)
+		})
+
+		Convey("extract should use the first propagator which finds span context", func() {
+			header := map[string]string{
+				B3TraceIDHeader: "80f198ee56343ba864fe8b2a57d3eff7",
+				B3SpanIDHeader:  "e457b5a2e4d86bd1",
+			}
+			sc := RemoteSpanContextFromContext(composite.Extract(ctx, header))
+			So(sc, ShouldNotBeNil)
+			So(sc.TraceID, ShouldEqual, "80f198ee56343ba864fe8b2a57d3eff7")
+			So(RemoteSpanContextFromContext(composite.Extract(ctx, map[string]string{})), ShouldBeNil)
+		})
+	})
+}
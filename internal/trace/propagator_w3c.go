@@ -0,0 +1,160 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+	"github.com/alva-ai/cozeloop-go/internal/util"
+)
+
+const (
+	W3CTraceParentHeader = "Traceparent"
+	W3CBaggageHeader     = "Baggage"
+
+	B3TraceIDHeader = "X-B3-Traceid"
+	B3SpanIDHeader  = "X-B3-Spanid"
+	B3SampledHeader = "X-B3-Sampled"
+	B3SingleHeader  = "B3"
+)
+
+// w3cPropagator propagates span context in W3C Trace Context format (traceparent)
+// and W3C Baggage format (baggage), see https://www.w3.org/TR/trace-context/
+type w3cPropagator struct{}
+
+// NewW3CPropagator returns a Propagator using W3C Trace Context and Baggage header format.
+func NewW3CPropagator() Propagator {
+	return w3cPropagator{}
+}
+
+func (p w3cPropagator) Inject(ctx context.Context, header map[string]string) {
+	sc := spanContextFromContext(ctx)
+	if sc == nil || header == nil {
+		return
+	}
+	flags := 1
+	if sampled, ok := SamplingDecisionFromContext(ctx); ok && !sampled {
+		flags = 0
+	}
+	header[W3CTraceParentHeader] = fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, flags)
+	if baggage := toHeaderBaggage(sc.Baggage); baggage != "" {
+		header[W3CBaggageHeader] = baggage
+	}
+}
+
+func (p w3cPropagator) Extract(ctx context.Context, header map[string]string) context.Context {
+	h := canonicalHeader(header)
+	traceParent, ok := h[W3CTraceParentHeader]
+	if !ok || traceParent == "" {
+		return ctx
+	}
+	traceID, spanID, err := fromHeaderParent(traceParent)
+	if err != nil {
+		logger.CtxWarnf(ctx, "failed to parse traceparent header: %v", err)
+		return ctx
+	}
+	sc := &SpanContext{TraceID: traceID, SpanID: spanID}
+	if baggage, ok := h[W3CBaggageHeader]; ok {
+		sc.Baggage = fromHeaderBaggage(baggage)
+	}
+	// the last field of traceparent is trace flags, the lowest bit of which is sampled flag
+	splits := strings.Split(traceParent, "-")
+	if flags, err := strconv.ParseUint(splits[len(splits)-1], 16, 8); err == nil {
+		ctx = ContextWithSamplingDecision(ctx, flags&1 == 1)
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// b3Propagator propagates span context in Zipkin B3 format, see https://github.com/openzipkin/b3-propagation.
+// It injects multiple headers, and extracts both single header and multiple headers.
+type b3Propagator struct{}
+
+// NewB3Propagator returns a Propagator using Zipkin B3 header format.
+func NewB3Propagator() Propagator {
+	return b3Propagator{}
+}
+
+func (p b3Propagator) Inject(ctx context.Context, header map[string]string) {
+	sc := spanContextFromContext(ctx)
+	if sc == nil || header == nil {
+		return
+	}
+	sampled := "1"
+	if decision, ok := SamplingDecisionFromContext(ctx); ok && !decision {
+		sampled = "0"
+	}
+	header[B3TraceIDHeader] = sc.TraceID
+	header[B3SpanIDHeader] = sc.SpanID
+	header[B3SampledHeader] = sampled
+}
+
+func (p b3Propagator) Extract(ctx context.Context, header map[string]string) context.Context {
+	h := canonicalHeader(header)
+	traceID, spanID, sampled := h[B3TraceIDHeader], h[B3SpanIDHeader], h[B3SampledHeader]
+	// single header: {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}, or only {SamplingState}
+	if single, ok := h[B3SingleHeader]; ok && single != "" {
+		splits := strings.Split(single, "-")
+		switch len(splits) {
+		case 1:
+			traceID, spanID, sampled = "", "", splits[0]
+		case 2:
+			traceID, spanID, sampled = splits[0], splits[1], ""
+		default:
+			traceID, spanID, sampled = splits[0], splits[1], splits[2]
+		}
+	}
+
+	switch sampled {
+	case "0", "false":
+		ctx = ContextWithSamplingDecision(ctx, false)
+	case "1", "true", "d":
+		ctx = ContextWithSamplingDecision(ctx, true)
+	}
+	// 64-bit trace id is left-padded to 128-bit
+	if len(traceID) == 16 {
+		traceID = strings.Repeat("0", 16) + traceID
+	}
+	if len(traceID) != 32 || !util.IsValidHexStr(traceID) || len(spanID) != 16 || !util.IsValidHexStr(spanID) {
+		if traceID != "" {
+			logger.CtxWarnf(ctx, "failed to parse b3 header: invalid trace id %s or span id %s", traceID, spanID)
+		}
+		return ctx
+	}
+	return ContextWithRemoteSpanContext(ctx, &SpanContext{TraceID: traceID, SpanID: spanID})
+}
+
+// compositePropagator combines multiple propagators.
+type compositePropagator struct {
+	propagators []Propagator
+}
+
+// NewCompositePropagator returns a Propagator which injects header of all propagators,
+// and extracts span context by the first propagator which finds one.
+func NewCompositePropagator(propagators ...Propagator) Propagator {
+	return compositePropagator{propagators: propagators}
+}
+
+func (p compositePropagator) Inject(ctx context.Context, header map[string]string) {
+	for _, propagator := range p.propagators {
+		if propagator != nil {
+			propagator.Inject(ctx, header)
+		}
+	}
+}
+
+func (p compositePropagator) Extract(ctx context.Context, header map[string]string) context.Context {
+	for _, propagator := range p.propagators {
+		if propagator == nil {
+			continue
+		}
+		if extracted := propagator.Extract(ctx, header); RemoteSpanContextFromContext(extracted) != RemoteSpanContextFromContext(ctx) {
+			return extracted
+		}
+	}
+	return ctx
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+	"github.com/alva-ai/cozeloop-go/internal/util"
+)
+
+const (
+	// XRayTraceHeader is the header of AWS X-Ray trace context,
+	// e.g. Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1
+	XRayTraceHeader = "X-Amzn-Trace-Id"
+
+	xrayRootKey     = "Root"
+	xrayParentKey   = "Parent"
+	xraySampledKey  = "Sampled"
+	xrayVersion     = "1"
+	xrayEpochLength = 8
+	xrayRandLength  = 24
+)
+
+// xrayPropagator propagates span context in AWS X-Ray header format.
+// X-Ray trace id (1-<8 hex epoch>-<24 hex random>) is converted to/from the 32 hex chars trace id,
+// and X-Ray segment id is the 16 hex chars span id.
+type xrayPropagator struct{}
+
+// NewXRayPropagator returns a Propagator using AWS X-Ray header format.
+func NewXRayPropagator() Propagator {
+	return xrayPropagator{}
+}
+
+func (p xrayPropagator) Inject(ctx context.Context, header map[string]string) {
+	sc := spanContextFromContext(ctx)
+	if sc == nil || header == nil {
+		return
+	}
+	root, err := toXRayTraceID(sc.TraceID)
+	if err != nil {
+		logger.CtxWarnf(ctx, "failed to inject x-ray header: %v", err)
+		return
+	}
+	// X-Ray segment id must be 16 hex chars, generate a new one if span id is customized
+	segmentID := strings.ToLower(sc.SpanID)
+	if len(segmentID) != 16 || !util.IsValidHexStr(segmentID) {
+		segmentID = util.Gen16CharID()
+	}
+	sampled := "1"
+	if s, ok := SamplingDecisionFromContext(ctx); ok && !s {
+		sampled = "0"
+	}
+	header[XRayTraceHeader] = fmt.Sprintf("%s=%s;%s=%s;%s=%s", xrayRootKey, root, xrayParentKey, segmentID, xraySampledKey, sampled)
+}
+
+// Extract returns ctx unchanged if Root is missing or invalid, fields are validated before ctx is changed.
+func (p xrayPropagator) Extract(ctx context.Context, header map[string]string) context.Context {
+	value, ok := canonicalHeader(header)[XRayTraceHeader]
+	if !ok || value == "" {
+		return ctx
+	}
+
+	var root, parent, sampled string
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case xrayRootKey:
+			root = kv[1]
+		case xrayParentKey:
+			parent = kv[1]
+		case xraySampledKey:
+			sampled = kv[1]
+		}
+	}
+	if root == "" {
+		logger.CtxWarnf(ctx, "failed to parse x-ray header: missing %s", xrayRootKey)
+		return ctx
+	}
+	traceID, err := fromXRayTraceID(root)
+	if err != nil {
+		logger.CtxWarnf(ctx, "failed to parse x-ray header: %v", err)
+		return ctx
+	}
+	sc := &SpanContext{TraceID: traceID}
+	if len(parent) == 16 && util.IsValidHexStr(parent) {
+		sc.SpanID = strings.ToLower(parent)
+	}
+
+	// Sampled=? means the decision is deferred to downstream
+	switch sampled {
+	case "0":
+		ctx = ContextWithSamplingDecision(ctx, false)
+	case "1":
+		ctx = ContextWithSamplingDecision(ctx, true)
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// toXRayTraceID converts 32 hex chars trace id to X-Ray trace id.
+func toXRayTraceID(traceID string) (string, error) {
+	if len(traceID) != xrayEpochLength+xrayRandLength || !util.IsValidHexStr(traceID) {
+		return "", fmt.Errorf("invalid trace id: %s", traceID)
+	}
+	traceID = strings.ToLower(traceID)
+	return fmt.Sprintf("%s-%s-%s", xrayVersion, traceID[:xrayEpochLength], traceID[xrayEpochLength:]), nil
+}
+
+// fromXRayTraceID converts X-Ray trace id to 32 hex chars trace id.
+func fromXRayTraceID(root string) (string, error) {
+	splits := strings.Split(root, "-")
+	if len(splits) != 3 || splits[0] != xrayVersion ||
+		len(splits[1]) != xrayEpochLength || len(splits[2]) != xrayRandLength {
+		return "", fmt.Errorf("invalid x-ray trace id: %s", root)
+	}
+	traceID := strings.ToLower(splits[1] + splits[2])
+	if !util.IsValidHexStr(traceID) || traceID == "00000000000000000000000000000000" {
+		return "", fmt.Errorf("invalid x-ray trace id: %s", root)
+	}
+	return traceID, nil
+}
@@ -39,21 +39,49 @@ type QueueManager interface {
 	ForceFlush(ctx context.Context) error
 }
 
+// OverflowStrategy is the behavior of enqueuing spans to export when the span queue is full.
+type OverflowStrategy int
+
+const (
+	// OverflowDrop drops the span and counts it by DroppedSpansTotal, which never blocks Span.Finish. It is the default.
+	OverflowDrop OverflowStrategy = iota
+	// OverflowBlock blocks Span.Finish until the queue has space, the context is done or the client is closed.
+	OverflowBlock
+)
+
+const (
+	// DroppedSpansTotal is the name of counter of spans dropped because the span queue is full.
+	DroppedSpansTotal = "cozeloop.dropped_spans_total"
+)
+
+// droppedSpans counts spans dropped because the span queue is full in process.
+var droppedSpans int64
+
+// GetDroppedSpansTotal returns the count of spans dropped because the span queue is full in process.
+func GetDroppedSpansTotal() int64 {
+	return atomic.LoadInt64(&droppedSpans)
+}
+
 type batchQueueManagerOptions struct {
 	queueName              string
-	maxQueueLength         int
+	maxQueueLength         int // rounded up to a power of two
+	overflowStrategy       OverflowStrategy
 	batchTimeout           time.Duration
 	maxExportBatchLength   int
 	maxExportBatchByteSize int
+	traceGroupMaxAge       time.Duration // hold spans until local root span of trace is finished, 0 means disabled
 
 	exportFunc           exportFunc
+	dropFunc             func(ctx context.Context, item interface{}) // called with items dropped because the queue is full
 	finishEventProcessor func(ctx context.Context, info *consts.FinishEventInfo)
 }
 
 func newBatchQueueManager(o batchQueueManagerOptions) *BatchQueueManager {
 	bsp := &BatchQueueManager{
 		o:          o,
-		queue:      make(chan interface{}, o.maxQueueLength),
+		queue:      newRingBuffer(o.maxQueueLength),
+		notify:     make(chan struct{}, 1),
+		space:      make(chan struct{}, 1),
 		dropped:    0,
 		batch:      make([]interface{}, 0, o.maxExportBatchLength),
 		batchMutex: sync.Mutex{},
@@ -65,6 +93,9 @@ func newBatchQueueManager(o batchQueueManagerOptions) *BatchQueueManager {
 		stopCh:     make(chan struct{}),
 		stopped:    0,
 	}
+	if o.traceGroupMaxAge > 0 {
+		bsp.traceGrouper = newTraceGrouper(o.traceGroupMaxAge)
+	}
 
 	bsp.stopWait.Add(1)
 	util.GoSafe(context.Background(), func() {
@@ -80,7 +111,9 @@ func newBatchQueueManager(o batchQueueManagerOptions) *BatchQueueManager {
 type BatchQueueManager struct {
 	o batchQueueManagerOptions
 
-	queue   chan interface{}
+	queue   *ringBuffer
+	notify  chan struct{} // signaled after items are pushed, to wake up the consumer
+	space   chan struct{} // signaled after items are popped, to wake up producers blocked on full queue
 	dropped uint32
 
 	batch         []interface{}
@@ -88,6 +121,9 @@ type BatchQueueManager struct {
 	batchMutex    sync.Mutex
 	sizeMutex     sync.RWMutex
 	timer         *time.Timer
+	// hold spans until local root span of trace is finished, nil means disabled. Held spans are counted
+	// against the capacity of queue, and their byte size is counted when they are released into batch.
+	traceGrouper *traceGrouper
 
 	exportFunc func(ctx context.Context, s []interface{})
 
@@ -108,31 +144,137 @@ func (b *BatchQueueManager) processQueue() {
 			return
 		case <-b.timer.C:
 			if len(b.batch) > 0 {
-				logger.CtxDebugf(ctx, "%s time out, span length: %d, queue length: %d", b.o.queueName, len(b.batch), len(b.queue))
+				logger.CtxDebugf(ctx, "%s time out, span length: %d, queue length: %d", b.o.queueName, len(b.batch), b.queue.len())
 			}
-			b.doExport(ctx)
-		case sd := <-b.queue:
-			if ffs, ok := sd.(forceFlushSpan); ok {
-				close(ffs.flushed)
-				continue
-			}
-			b.batchMutex.Lock()
-			b.batch = append(b.batch, sd)
-			shouldExport := b.isShouldExport()
-			b.batchMutex.Unlock()
-			if shouldExport {
-				if !b.timer.Stop() { // timer reset, need stop first
-					select {
-					case <-b.timer.C:
-					default:
-					}
-				}
-				logger.CtxDebugf(ctx, "%s batch out, span length: %d, queue length: %d", b.o.queueName, len(b.batch), len(b.queue))
-
-				b.doExport(ctx)
+			if b.traceGrouper != nil {
+				b.appendReleased(ctx, b.traceGrouper.expire(time.Now()))
 			}
+			b.doExport(ctx)
+		case <-b.notify:
+			b.consumeQueue(ctx)
+		}
+	}
+}
+
+// consumeQueue pops all items in queue into batch, and exports the batch once it is full.
+// It is only called by the consumer goroutine.
+func (b *BatchQueueManager) consumeQueue(ctx context.Context) {
+	for {
+		sd, ok := b.pop()
+		if !ok {
+			return
+		}
+		if ffs, ok := sd.(forceFlushSpan); ok {
+			// items before forceFlushSpan are all in batch
+			b.flushTraceGroups(ctx)
+			b.doExport(ctx)
+			close(ffs.flushed)
+			continue
+		}
+		if b.traceGrouper != nil {
+			b.appendReleased(ctx, b.traceGrouper.add(sd, time.Now()))
+			continue
+		}
+		b.batchMutex.Lock()
+		b.batch = append(b.batch, sd)
+		shouldExport := b.isShouldExport()
+		b.batchMutex.Unlock()
+		if shouldExport {
+			b.exportFullBatch(ctx)
+		}
+	}
+}
+
+// exportFullBatch exports the batch which reaches maxExportBatchLength or maxExportBatchByteSize.
+func (b *BatchQueueManager) exportFullBatch(ctx context.Context) {
+	if !b.timer.Stop() { // timer reset, need stop first
+		select {
+		case <-b.timer.C:
+		default:
+		}
+	}
+	logger.CtxDebugf(ctx, "%s batch out, span length: %d, queue length: %d", b.o.queueName, len(b.batch), b.queue.len())
+
+	b.doExport(ctx)
+}
+
+// appendReleased appends items released by traceGrouper to batch one by one, and exports the batch whenever
+// it is full, so that spans of a large trace are split into batches by the limits.
+func (b *BatchQueueManager) appendReleased(ctx context.Context, items []interface{}) {
+	if len(items) == 0 {
+		return
+	}
+	signal(b.space) // released spans no longer take the capacity of queue
+	for _, sd := range items {
+		b.batchMutex.Lock()
+		b.batch = append(b.batch, sd)
+		if span, ok := sd.(*Span); ok {
+			b.sizeMutex.Lock()
+			b.batchByteSize += span.bytesSize
+			b.sizeMutex.Unlock()
+		}
+		shouldExport := b.isShouldExport()
+		b.batchMutex.Unlock()
+		if shouldExport {
+			b.exportFullBatch(ctx)
+		}
+	}
+}
+
+// flushTraceGroups moves spans held by traceGrouper into batch.
+func (b *BatchQueueManager) flushTraceGroups(ctx context.Context) {
+	if b.traceGrouper == nil {
+		return
+	}
+	b.appendReleased(ctx, b.traceGrouper.flushAll())
+}
+
+func (b *BatchQueueManager) pop() (interface{}, bool) {
+	sd, ok := b.queue.pop()
+	if ok {
+		signal(b.space)
+	}
+	return sd, ok
+}
+
+// push adds sd to queue and wakes up the consumer, returns false if the queue is full.
+func (b *BatchQueueManager) push(sd interface{}) bool {
+	// spans held by traceGrouper take the capacity of queue, except for forceFlushSpan which releases them
+	if _, ok := sd.(forceFlushSpan); !ok && b.traceGrouper != nil &&
+		b.queue.len()+b.traceGrouper.heldSpans() >= b.queue.capacity() {
+		return false
+	}
+	if !b.queue.push(sd) {
+		return false
+	}
+	signal(b.notify)
+	return true
+}
+
+// pushBlocking waits until sd is added to queue, returns false if ctx is done or the queue is shut down.
+func (b *BatchQueueManager) pushBlocking(ctx context.Context, sd interface{}) bool {
+	for !b.push(sd) {
+		select {
+		case <-b.space:
+		case <-ctx.Done():
+			signal(b.space) // pass the signal to other blocked producers
+			return false
+		case <-b.stopCh:
+			return false
 		}
 	}
+	if b.queue.len() < b.queue.capacity() {
+		signal(b.space) // there may be space for other blocked producers
+	}
+	return true
+}
+
+// signal wakes up the waiter of ch without blocking, ch should be buffered by 1.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
 }
 
 func (b *BatchQueueManager) isShouldExport() bool {
@@ -152,27 +294,29 @@ func (b *BatchQueueManager) isShouldExport() bool {
 func (b *BatchQueueManager) drainQueue(ctx context.Context) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	b.flushTraceGroups(ctx)
 	for {
-		select {
-		case sd := <-b.queue:
-			if _, ok := sd.(forceFlushSpan); ok {
-				continue
-			}
-			b.batchMutex.Lock()
-			b.batch = append(b.batch, sd)
-			shouldExport := len(b.batch) == b.o.maxExportBatchLength
-			b.batchMutex.Unlock()
-
-			if shouldExport {
-				b.doExport(ctx)
-			}
-		case <-ctx.Done():
+		if ctx.Err() != nil {
 			return
-		default:
+		}
+		sd, ok := b.pop()
+		if !ok {
 			// There are no more enqueued spans. Make final export.
 			b.doExport(ctx)
 			return
 		}
+		if ffs, ok := sd.(forceFlushSpan); ok {
+			close(ffs.flushed)
+			continue
+		}
+		b.batchMutex.Lock()
+		b.batch = append(b.batch, sd)
+		shouldExport := len(b.batch) == b.o.maxExportBatchLength
+		b.batchMutex.Unlock()
+
+		if shouldExport {
+			b.doExport(ctx)
+		}
 	}
 }
 
@@ -202,21 +346,34 @@ func (b *BatchQueueManager) Enqueue(ctx context.Context, sd interface{}, byteSiz
 	eventType := consts.SpanFinishEventFileQueueEntryRate
 	var detailMsg string
 	var isFail bool
-	select {
-	case b.queue <- sd:
+	var enqueued bool
+	if b.o.overflowStrategy == OverflowBlock {
+		enqueued = b.pushBlocking(ctx, sd)
+	} else {
+		enqueued = b.push(sd)
+	}
+	if enqueued && b.traceGrouper == nil {
 		b.sizeMutex.Lock()
 		b.batchByteSize += byteSize
 		b.sizeMutex.Unlock()
-		detailMsg = fmt.Sprintf("%s enqueue, queue length: %d", b.o.queueName, len(b.queue))
-	default: // queue is full, not block, drop
+	}
+	if enqueued {
+		detailMsg = fmt.Sprintf("%s enqueue, queue length: %d", b.o.queueName, b.queue.len())
+	} else { // queue is full, drop
 		detailMsg = fmt.Sprintf("%s queue is full, dropped item", b.o.queueName)
 		isFail = true
 		atomic.AddUint32(&b.dropped, 1)
+		if b.o.dropFunc != nil {
+			b.o.dropFunc(ctx, sd)
+		}
 	}
 
 	switch b.o.queueName {
 	case queueNameSpan, queueNameSpanRetry:
 		eventType = consts.SpanFinishEventSpanQueueEntryRate
+		if !enqueued {
+			atomic.AddInt64(&droppedSpans, 1)
+		}
 		span, ok := sd.(*Span)
 		if ok {
 			extraParams = &consts.FinishEventInfoExtra{
@@ -237,18 +394,13 @@ func (b *BatchQueueManager) Enqueue(ctx context.Context, sd interface{}, byteSiz
 	return
 }
 
-func (b *BatchQueueManager) enqueueBlockOnQueueFull(ctx context.Context, sd interface{}, byteSize int64) {
+func (b *BatchQueueManager) enqueueBlockOnQueueFull(ctx context.Context, sd interface{}, byteSize int64) bool {
 	// Do not enqueue spans after Shutdown.
 	if atomic.LoadInt32(&b.stopped) != 0 {
-		return
+		return false
 	}
 
-	select {
-	case b.queue <- sd:
-		return
-	case <-ctx.Done():
-		return
-	}
+	return b.pushBlocking(ctx, sd)
 }
 
 func (b *BatchQueueManager) Shutdown(ctx context.Context) error {
@@ -286,14 +438,13 @@ func (b *BatchQueueManager) ForceFlush(ctx context.Context) error {
 	}
 
 	flushCh := make(chan struct{})
-	b.enqueueBlockOnQueueFull(ctx, forceFlushSpan{flushed: flushCh}, 0) // must enqueue
+	if !b.enqueueBlockOnQueueFull(ctx, forceFlushSpan{flushed: flushCh}, 0) { // must enqueue
+		return ctx.Err()
+	}
 	select {
-	case <-flushCh: // wait until span is drained into batch before forceFlushSpan
+	case <-flushCh: // wait until spans before forceFlushSpan are exported by the consumer
 	case <-ctx.Done():
 		return ctx.Err()
 	}
-
-	var err error
-	b.drainQueue(ctx)
-	return err
+	return nil
 }
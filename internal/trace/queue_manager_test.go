@@ -15,11 +15,11 @@ import (
 func Test_GetBatchSpanProcessor(t *testing.T) {
 	ctx := context.Background()
 	httpClient := &httpclient.Client{}
-	spanQM := NewBatchSpanProcessor(nil, httpClient, nil, nil, nil, nil)
+	spanQM := NewBatchSpanProcessor(nil, httpClient, nil, nil, nil, nil, false)
 
 	PatchConvey("Test GetBatchSpanProcessor", t, func() {
 		PatchConvey("Test with valid inputs", func() {
-			spanQM.OnSpanEnd(ctx, &Span{})
+			spanQM.OnEnd(ctx, &Span{})
 			err := spanQM.ForceFlush(ctx)
 			So(err, ShouldBeNil)
 		})
@@ -0,0 +1,237 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	"github.com/alva-ai/cozeloop-go/internal/httpclient"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+const (
+	RelayWorkspaceHeader = "X-Cozeloop-Workspace-Id"
+
+	relayPathSpans = "/v1/spans"
+	relayPathFiles = "/v1/files"
+)
+
+var _ Exporter = (*RelayExporter)(nil)
+
+// RelayExporter forwards spans and files as JSON to a relay proxy, which uploads them to cozeloop server.
+// It is used in environments with network egress controls. Data of files is base64 encoded.
+type RelayExporter struct {
+	proxyURL               string
+	httpClient             httpclient.HTTPClient
+	tlsConfig              *tls.Config
+	authHeaderKey          string
+	authHeaderValue        string
+	forwardWorkspaceHeader bool
+}
+
+// RelayOption is used to set options for RelayExporter.
+type RelayOption func(e *RelayExporter)
+
+// WithRelayAuthHeader set the auth header sent to relay proxy, such as Authorization: Bearer xxx.
+func WithRelayAuthHeader(key, value string) RelayOption {
+	return func(e *RelayExporter) {
+		e.authHeaderKey = key
+		e.authHeaderValue = value
+	}
+}
+
+// WithRelayTLSConfig set tls config used to connect relay proxy. It is ignored if WithRelayHTTPClient is set.
+func WithRelayTLSConfig(config *tls.Config) RelayOption {
+	return func(e *RelayExporter) {
+		e.tlsConfig = config
+	}
+}
+
+// WithRelayHTTPClient set http client used to connect relay proxy.
+func WithRelayHTTPClient(client httpclient.HTTPClient) RelayOption {
+	return func(e *RelayExporter) {
+		e.httpClient = client
+	}
+}
+
+// WithForwardWorkspaceHeader set whether to copy workspace id into X-Cozeloop-Workspace-Id header.
+// If enabled, spans and files of different workspaces are sent in separate requests.
+func WithForwardWorkspaceHeader(enable bool) RelayOption {
+	return func(e *RelayExporter) {
+		e.forwardWorkspaceHeader = enable
+	}
+}
+
+// NewRelayExporter creates a new RelayExporter, which POSTs spans to proxyURL/v1/spans and files to proxyURL/v1/files.
+func NewRelayExporter(proxyURL string, opts ...RelayOption) *RelayExporter {
+	e := &RelayExporter{
+		proxyURL: strings.TrimRight(strings.TrimSpace(proxyURL), "/"),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	if e.httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if e.tlsConfig != nil {
+			transport.TLSClientConfig = e.tlsConfig
+		}
+		e.httpClient = &http.Client{
+			Transport: transport,
+			Timeout:   consts.DefaultUploadTimeout,
+		}
+	}
+	return e
+}
+
+func (e *RelayExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	if !e.forwardWorkspaceHeader {
+		return e.post(ctx, relayPathSpans, "", spans)
+	}
+
+	var workspaceIDs []string
+	groups := make(map[string][]*entity.UploadSpan)
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		if _, ok := groups[span.WorkspaceID]; !ok {
+			workspaceIDs = append(workspaceIDs, span.WorkspaceID)
+		}
+		groups[span.WorkspaceID] = append(groups[span.WorkspaceID], span)
+	}
+	// every group is posted, only spans of failed groups are returned to be retried
+	var firstErr error
+	var failed []*entity.UploadSpan
+	delivered := false
+	for _, workspaceID := range workspaceIDs {
+		if err := e.post(ctx, relayPathSpans, workspaceID, groups[workspaceID]); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed = append(failed, groups[workspaceID]...)
+		} else {
+			delivered = true
+		}
+	}
+	if firstErr == nil || !delivered {
+		return firstErr
+	}
+	return &PartialExportError{Err: firstErr, FailedSpans: failed}
+}
+
+func (e *RelayExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+	if !e.forwardWorkspaceHeader {
+		return e.post(ctx, relayPathFiles, "", toRelayFiles(files))
+	}
+
+	var workspaceIDs []string
+	groups := make(map[string][]*entity.UploadFile)
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		if _, ok := groups[file.SpaceID]; !ok {
+			workspaceIDs = append(workspaceIDs, file.SpaceID)
+		}
+		groups[file.SpaceID] = append(groups[file.SpaceID], file)
+	}
+	// every group is posted, only files of failed groups are returned to be retried
+	var firstErr error
+	var failed []*entity.UploadFile
+	delivered := false
+	for _, workspaceID := range workspaceIDs {
+		if err := e.post(ctx, relayPathFiles, workspaceID, toRelayFiles(groups[workspaceID])); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed = append(failed, groups[workspaceID]...)
+		} else {
+			delivered = true
+		}
+	}
+	if firstErr == nil || !delivered {
+		return firstErr
+	}
+	return &PartialExportError{Err: firstErr, FailedFiles: failed}
+}
+
+// relayFile is the JSON wire format of entity.UploadFile posted to relay proxy.
+type relayFile struct {
+	TosKey     string            `json:"tos_key"`
+	Data       string            `json:"data"` // base64 encoded, as data of images and files is binary
+	UploadType entity.UploadType `json:"upload_type"`
+	TagKey     string            `json:"tag_key"`
+	Name       string            `json:"name"`
+	FileType   string            `json:"file_type"`
+	SpaceID    string            `json:"space_id"`
+}
+
+func toRelayFiles(files []*entity.UploadFile) []*relayFile {
+	res := make([]*relayFile, 0, len(files))
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		res = append(res, &relayFile{
+			TosKey:     file.TosKey,
+			Data:       base64.StdEncoding.EncodeToString([]byte(file.Data)),
+			UploadType: file.UploadType,
+			TagKey:     file.TagKey,
+			Name:       file.Name,
+			FileType:   file.FileType,
+			SpaceID:    file.SpaceID,
+		})
+	}
+	return res
+}
+
+func (e *RelayExporter) post(ctx context.Context, path, workspaceID string, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.proxyURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.authHeaderKey != "" {
+		req.Header.Set(e.authHeaderKey, e.authHeaderValue)
+	}
+	if workspaceID != "" {
+		req.Header.Set(RelayWorkspaceHeader, workspaceID)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		logger.CtxErrorf(ctx, "relay exporter: failed to post %s: %v", path, err)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := io.ReadAll(resp.Body)
+		err = consts.NewRemoteServiceError(resp.StatusCode, 0, string(respBody), resp.Header.Get(consts.LogIDHeader))
+		logger.CtxErrorf(ctx, "relay exporter: failed to post %s: %v", path, err)
+		return err
+	}
+	logger.CtxDebugf(ctx, "relay exporter: posted %s to %s", path, e.proxyURL)
+	return nil
+}
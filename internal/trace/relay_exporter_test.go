@@ -0,0 +1,112 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRelayExporter(t *testing.T) {
+	Convey("RelayExporter", t, func() {
+		ctx := context.Background()
+		var mu sync.Mutex
+		requests := make(map[string][]*http.Request)
+		bodies := make(map[string][][]byte)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			defer mu.Unlock()
+			requests[r.URL.Path] = append(requests[r.URL.Path], r)
+			bodies[r.URL.Path] = append(bodies[r.URL.Path], body)
+		}))
+		defer server.Close()
+
+		spans := []*entity.UploadSpan{
+			{SpanID: "span1", WorkspaceID: "ws1"},
+			{SpanID: "span2", WorkspaceID: "ws2"},
+		}
+
+		Convey("should post spans and files in batch", func() {
+			exporter := NewRelayExporter(server.URL+"/", WithRelayAuthHeader("Authorization", "Bearer token"))
+			So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+			So(exporter.ExportFiles(ctx, []*entity.UploadFile{{TosKey: "key", SpaceID: "ws1"}}), ShouldBeNil)
+
+			So(len(requests["/v1/spans"]), ShouldEqual, 1)
+			So(requests["/v1/spans"][0].Header.Get("Authorization"), ShouldEqual, "Bearer token")
+			So(requests["/v1/spans"][0].Header.Get(RelayWorkspaceHeader), ShouldBeEmpty)
+			var got []*entity.UploadSpan
+			So(json.Unmarshal(bodies["/v1/spans"][0], &got), ShouldBeNil)
+			So(len(got), ShouldEqual, 2)
+			So(len(requests["/v1/files"]), ShouldEqual, 1)
+		})
+
+		Convey("should split batch by workspace when forwarding workspace header", func() {
+			exporter := NewRelayExporter(server.URL, WithForwardWorkspaceHeader(true))
+			So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+
+			So(len(requests["/v1/spans"]), ShouldEqual, 2)
+			workspaces := []string{
+				requests["/v1/spans"][0].Header.Get(RelayWorkspaceHeader),
+				requests["/v1/spans"][1].Header.Get(RelayWorkspaceHeader),
+			}
+			So(workspaces, ShouldContain, "ws1")
+			So(workspaces, ShouldContain, "ws2")
+		})
+
+		Convey("should post binary data of files in base64", func() {
+			data := string([]byte{0x89, 'P', 'N', 'G', 0xff, 0x00})
+			exporter := NewRelayExporter(server.URL)
+			So(exporter.ExportFiles(ctx, []*entity.UploadFile{{TosKey: "key", Data: data, SpaceID: "ws1"}}), ShouldBeNil)
+
+			var got []map[string]interface{}
+			So(json.Unmarshal(bodies["/v1/files"][0], &got), ShouldBeNil)
+			So(got[0]["tos_key"], ShouldEqual, "key")
+			So(got[0]["space_id"], ShouldEqual, "ws1")
+			decoded, err := base64.StdEncoding.DecodeString(got[0]["data"].(string))
+			So(err, ShouldBeNil)
+			So(string(decoded), ShouldEqual, data)
+		})
+
+		Convey("should return failed groups only when forwarding workspace header", func() {
+			partialServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get(RelayWorkspaceHeader) == "ws2" {
+					w.WriteHeader(http.StatusBadGateway)
+				}
+			}))
+			defer partialServer.Close()
+			exporter := NewRelayExporter(partialServer.URL, WithForwardWorkspaceHeader(true))
+
+			var partialErr *PartialExportError
+			So(errors.As(exporter.ExportSpans(ctx, spans), &partialErr), ShouldBeTrue)
+			So(partialErr.FailedSpans, ShouldResemble, spans[1:])
+
+			files := []*entity.UploadFile{{TosKey: "key1", SpaceID: "ws1"}, {TosKey: "key2", SpaceID: "ws2"}}
+			So(errors.As(exporter.ExportFiles(ctx, files), &partialErr), ShouldBeTrue)
+			So(partialErr.FailedFiles, ShouldResemble, files[1:])
+
+			err := exporter.ExportSpans(ctx, spans[1:])
+			So(err, ShouldNotBeNil)
+			So(errors.As(err, &partialErr), ShouldBeFalse)
+		})
+
+		Convey("should return error on failed response", func() {
+			failServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			defer failServer.Close()
+			So(NewRelayExporter(failServer.URL).ExportSpans(ctx, spans), ShouldNotBeNil)
+		})
+	})
+}
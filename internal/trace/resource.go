@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/internal/httpclient"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+// resource system tag keys
+const (
+	ResourceCloudProvider = "cloud.provider"
+	ResourceCloudRegion   = "cloud.region"
+	ResourceFaasName      = "faas.name"
+	ResourceFaasVersion   = "faas.version"
+)
+
+const (
+	gcpMetadataBaseURL = "http://metadata.google.internal/computeMetadata/v1"
+	gcpMetadataTimeout = 500 * time.Millisecond
+	gcpProvider        = "gcp"
+
+	// env injected by Cloud Run
+	gcpCloudRunServiceEnv  = "K_SERVICE"
+	gcpCloudRunRevisionEnv = "K_REVISION"
+)
+
+// DetectGCPResource queries the GCP metadata server and returns resource tags of Cloud Run / GCE.
+// Returns nil if the metadata server is unreachable.
+func DetectGCPResource(ctx context.Context, client httpclient.HTTPClient) map[string]string {
+	return detectGCPResource(ctx, client, gcpMetadataBaseURL)
+}
+
+func detectGCPResource(ctx context.Context, client httpclient.HTTPClient, baseURL string) map[string]string {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ctx, cancel := context.WithTimeout(ctx, gcpMetadataTimeout)
+	defer cancel()
+
+	// region of Cloud Run is like projects/123456/regions/us-central1,
+	// fall back to zone of GCE which is like projects/123456/zones/us-central1-a
+	region, err := getGCPMetadata(ctx, client, baseURL, "instance/region")
+	if err != nil {
+		zone, zoneErr := getGCPMetadata(ctx, client, baseURL, "instance/zone")
+		if zoneErr != nil {
+			logger.CtxDebugf(ctx, "gcp metadata server is unavailable, skip resource detection: %v", zoneErr)
+			return nil
+		}
+		zone = zone[strings.LastIndex(zone, "/")+1:]
+		if idx := strings.LastIndex(zone, "-"); idx > 0 {
+			region = zone[:idx]
+		}
+	} else {
+		region = region[strings.LastIndex(region, "/")+1:]
+	}
+
+	res := map[string]string{
+		ResourceCloudProvider: gcpProvider,
+	}
+	if region != "" {
+		res[ResourceCloudRegion] = region
+	}
+	if name := os.Getenv(gcpCloudRunServiceEnv); name != "" {
+		res[ResourceFaasName] = name
+	}
+	if version := os.Getenv(gcpCloudRunRevisionEnv); version != "" {
+		res[ResourceFaasVersion] = version
+	}
+	return res
+}
+
+func getGCPMetadata(ctx context.Context, client httpclient.HTTPClient, baseURL, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDetectGCPResource(t *testing.T) {
+	Convey("detectGCPResource", t, func() {
+		ctx := context.Background()
+
+		Convey("should detect cloud run resource", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Metadata-Flavor") != "Google" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				switch r.URL.Path {
+				case "/instance/region":
+					_, _ = w.Write([]byte("projects/123456/regions/us-central1"))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			os.Setenv(gcpCloudRunServiceEnv, "my-service")
+			os.Setenv(gcpCloudRunRevisionEnv, "my-service-00001-abc")
+			defer os.Unsetenv(gcpCloudRunServiceEnv)
+			defer os.Unsetenv(gcpCloudRunRevisionEnv)
+
+			res := detectGCPResource(ctx, server.Client(), server.URL)
+			So(res, ShouldResemble, map[string]string{
+				ResourceCloudProvider: "gcp",
+				ResourceCloudRegion:   "us-central1",
+				ResourceFaasName:      "my-service",
+				ResourceFaasVersion:   "my-service-00001-abc",
+			})
+		})
+
+		Convey("should fall back to zone of gce", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/instance/zone" {
+					_, _ = w.Write([]byte("projects/123456/zones/asia-east1-b"))
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer server.Close()
+
+			res := detectGCPResource(ctx, server.Client(), server.URL)
+			So(res[ResourceCloudRegion], ShouldEqual, "asia-east1")
+		})
+
+		Convey("should return nil if metadata server is unreachable", func() {
+			server := httptest.NewServer(http.NotFoundHandler())
+			server.Close()
+			So(detectGCPResource(ctx, nil, server.URL), ShouldBeNil)
+		})
+	})
+}
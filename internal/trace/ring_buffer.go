@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"sync/atomic"
+)
+
+// ringBufferSlot is a slot of ringBuffer. seq is the position the slot is ready for: pos means it is free to
+// write at pos, pos+1 means the item written at pos is ready to read.
+type ringBufferSlot struct {
+	seq  uint64
+	item interface{}
+}
+
+// ringBuffer is a bounded lock-free multi-producer single-consumer queue, producers claim positions by
+// compare-and-swap. pop must only be called by one goroutine at a time.
+type ringBuffer struct {
+	mask  uint64
+	slots []ringBufferSlot
+	head  uint64 // next position to write
+	tail  uint64 // next position to read
+}
+
+// newRingBuffer creates a ringBuffer whose size is size rounded up to a power of two.
+func newRingBuffer(size int) *ringBuffer {
+	capacity := uint64(1)
+	for capacity < uint64(size) {
+		capacity <<= 1
+	}
+	r := &ringBuffer{
+		mask:  capacity - 1,
+		slots: make([]ringBufferSlot, capacity),
+	}
+	for i := range r.slots {
+		r.slots[i].seq = uint64(i)
+	}
+	return r
+}
+
+// push adds item to the buffer, returns false if the buffer is full.
+func (r *ringBuffer) push(item interface{}) bool {
+	for {
+		pos := atomic.LoadUint64(&r.head)
+		slot := &r.slots[pos&r.mask]
+		seq := atomic.LoadUint64(&slot.seq)
+		switch diff := int64(seq - pos); {
+		case diff == 0:
+			if atomic.CompareAndSwapUint64(&r.head, pos, pos+1) {
+				slot.item = item
+				atomic.StoreUint64(&slot.seq, pos+1)
+				return true
+			}
+		case diff < 0:
+			// the slot is not read since the last round
+			return false
+		}
+		// the position is claimed by another producer, retry with the new head
+	}
+}
+
+// pop removes and returns the oldest item, ok is false if the buffer is empty, or the oldest item
+// is claimed but not written yet.
+func (r *ringBuffer) pop() (item interface{}, ok bool) {
+	pos := atomic.LoadUint64(&r.tail)
+	slot := &r.slots[pos&r.mask]
+	if int64(atomic.LoadUint64(&slot.seq)-(pos+1)) < 0 {
+		return nil, false
+	}
+	item = slot.item
+	slot.item = nil
+	atomic.StoreUint64(&slot.seq, pos+r.mask+1)
+	atomic.StoreUint64(&r.tail, pos+1)
+	return item, true
+}
+
+// len returns the approximate number of items in the buffer.
+func (r *ringBuffer) len() int {
+	// load tail first, so that it is never after the loaded head
+	tail := atomic.LoadUint64(&r.tail)
+	return int(atomic.LoadUint64(&r.head) - tail)
+}
+
+// capacity returns the max number of items in the buffer.
+func (r *ringBuffer) capacity() int {
+	return len(r.slots)
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRingBuffer(t *testing.T) {
+	Convey("ringBuffer", t, func() {
+		Convey("size is rounded up to a power of two", func() {
+			So(newRingBuffer(1).capacity(), ShouldEqual, 1)
+			So(newRingBuffer(5).capacity(), ShouldEqual, 8)
+			So(newRingBuffer(1024).capacity(), ShouldEqual, 1024)
+		})
+
+		Convey("push fails when full and pops in order", func() {
+			r := newRingBuffer(4)
+			for i := 0; i < 4; i++ {
+				So(r.push(i), ShouldBeTrue)
+			}
+			So(r.push(4), ShouldBeFalse)
+			So(r.len(), ShouldEqual, 4)
+			for round := 0; round < 3; round++ {
+				item, ok := r.pop()
+				So(ok, ShouldBeTrue)
+				So(r.push(item), ShouldBeTrue)
+			}
+			var items []interface{}
+			for item, ok := r.pop(); ok; item, ok = r.pop() {
+				items = append(items, item)
+			}
+			So(items, ShouldResemble, []interface{}{3, 0, 1, 2})
+			So(r.len(), ShouldEqual, 0)
+		})
+
+		Convey("concurrent producers", func() {
+			const producers, perProducer = 8, 1000
+			r := newRingBuffer(64)
+			wg := sync.WaitGroup{}
+			for p := 0; p < producers; p++ {
+				wg.Add(1)
+				go func(p int) {
+					defer wg.Done()
+					for i := 0; i < perProducer; i++ {
+						for !r.push(p*perProducer + i) {
+							time.Sleep(time.Microsecond)
+						}
+					}
+				}(p)
+			}
+			seen := make(map[int]bool, producers*perProducer)
+			last := make([]int, producers)
+			for i := range last {
+				last[i] = -1
+			}
+			ordered := true
+			for len(seen) < producers*perProducer {
+				item, ok := r.pop()
+				if !ok {
+					continue
+				}
+				v := item.(int)
+				seen[v] = true
+				if v%perProducer <= last[v/perProducer] {
+					ordered = false
+				}
+				last[v/perProducer] = v % perProducer
+			}
+			wg.Wait()
+			So(len(seen), ShouldEqual, producers*perProducer)
+			So(ordered, ShouldBeTrue)
+		})
+	})
+}
+
+func TestBatchQueueManagerOverflow(t *testing.T) {
+	Convey("BatchQueueManager on full queue", t, func() {
+		ctx := context.Background()
+		exported := make(chan []interface{}, 16)
+		block := make(chan struct{})
+		newQM := func(strategy OverflowStrategy) *BatchQueueManager {
+			return newBatchQueueManager(batchQueueManagerOptions{
+				queueName:              queueNameSpan,
+				batchTimeout:           time.Hour,
+				maxQueueLength:         2,
+				overflowStrategy:       strategy,
+				maxExportBatchLength:   1,
+				maxExportBatchByteSize: DefaultMaxExportBatchByteSize,
+				exportFunc: func(ctx context.Context, s []interface{}) {
+					<-block
+					exported <- s
+				},
+			})
+		}
+
+		Convey("drop", func() {
+			qm := newQM(OverflowDrop)
+			before := GetDroppedSpansTotal()
+			// the first item is held by the blocked export, the next two fill the queue
+			qm.Enqueue(ctx, 0, 0)
+			So(waitForCondition(func() bool { return qm.queue.len() == 0 }), ShouldBeTrue)
+			qm.Enqueue(ctx, 1, 0)
+			qm.Enqueue(ctx, 2, 0)
+			qm.Enqueue(ctx, 3, 0)
+			So(GetDroppedSpansTotal()-before, ShouldEqual, 1)
+			close(block)
+			So(qm.ForceFlush(ctx), ShouldBeNil)
+			So(len(exported), ShouldEqual, 3)
+			So(qm.Shutdown(ctx), ShouldBeNil)
+		})
+
+		Convey("block", func() {
+			qm := newQM(OverflowBlock)
+			before := GetDroppedSpansTotal()
+			qm.Enqueue(ctx, 0, 0)
+			So(waitForCondition(func() bool { return qm.queue.len() == 0 }), ShouldBeTrue)
+			qm.Enqueue(ctx, 1, 0)
+			qm.Enqueue(ctx, 2, 0)
+			done := make(chan struct{})
+			go func() {
+				qm.Enqueue(ctx, 3, 0)
+				close(done)
+			}()
+			select {
+			case <-done:
+				t.Fatal("enqueue on full queue is not blocked")
+			case <-time.After(50 * time.Millisecond):
+			}
+			close(block)
+			<-done
+			So(qm.ForceFlush(ctx), ShouldBeNil)
+			So(len(exported), ShouldEqual, 4)
+			So(GetDroppedSpansTotal(), ShouldEqual, before)
+			So(qm.Shutdown(ctx), ShouldBeNil)
+		})
+
+		Convey("blocked enqueue returns on context done", func() {
+			qm := newQM(OverflowBlock)
+			qm.Enqueue(ctx, 0, 0)
+			So(waitForCondition(func() bool { return qm.queue.len() == 0 }), ShouldBeTrue)
+			qm.Enqueue(ctx, 1, 0)
+			qm.Enqueue(ctx, 2, 0)
+			cctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			before := GetDroppedSpansTotal()
+			qm.Enqueue(cctx, 3, 0)
+			So(GetDroppedSpansTotal()-before, ShouldEqual, 1)
+			close(block)
+			So(qm.Shutdown(ctx), ShouldBeNil)
+			So(len(exported), ShouldEqual, 3)
+		})
+	})
+}
+
+func waitForCondition(cond func() bool) bool {
+	for i := 0; i < 1000; i++ {
+		if cond() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+)
+
+const (
+	// SamplerTypeRatio keeps spans of a ratio of traces, created by NewRatioSampler.
+	SamplerTypeRatio = "ratio"
+	// SamplerTypeErrorAware keeps spans with errors regardless of the base sampler, created by NewErrorAwareSampler.
+	SamplerTypeErrorAware = "error_aware"
+	// SamplerTypeCustom is the type of samplers implemented by users.
+	SamplerTypeCustom = "custom"
+)
+
+// Sampler decides whether spans of a trace are kept when its local root span is started.
+// Child spans follow the decision of their parent.
+type Sampler interface {
+	ShouldSample(ctx context.Context, traceID, spanName, spanType string) bool
+}
+
+// typedSampler is implemented by builtin samplers to name them in sampling decision log.
+type typedSampler interface {
+	samplerType() string
+}
+
+func getSamplerType(s Sampler) string {
+	if ts, ok := s.(typedSampler); ok {
+		return ts.samplerType()
+	}
+	return SamplerTypeCustom
+}
+
+type ratioSampler struct {
+	bound uint64
+	ratio float64
+}
+
+// NewRatioSampler creates a Sampler keeping traces of ratio in [0, 1], by hash of trace id,
+// so that services sampling with the same ratio keep the same traces.
+func NewRatioSampler(ratio float64) Sampler {
+	switch {
+	case ratio <= 0:
+		return &ratioSampler{bound: 0, ratio: 0}
+	case ratio >= 1:
+		return &ratioSampler{bound: math.MaxUint64, ratio: 1}
+	default:
+		return &ratioSampler{bound: uint64(ratio * math.MaxUint64), ratio: ratio}
+	}
+}
+
+func (s *ratioSampler) ShouldSample(ctx context.Context, traceID, spanName, spanType string) bool {
+	if s.ratio >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(traceID))
+	return mix64(h.Sum64()) < s.bound
+}
+
+// mix64 spreads fnv hash of similar trace ids, which differ in few low bits, over the whole range.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func (s *ratioSampler) samplerType() string {
+	return SamplerTypeRatio
+}
+
+// errorAwareSampler keeps spans dropped by base sampler until they are finished, so that spans with errors
+// can be kept. See NewErrorAwareSampler.
+type errorAwareSampler struct {
+	base Sampler
+}
+
+// NewErrorAwareSampler creates a Sampler keeping all spans with errors regardless of base sampler.
+// As errors are unknown when spans are started, spans dropped by base are still recorded, and only exported if
+// they have error when finished, which requires the drop filter to be enabled by Options.ErrorAwareDropFilter.
+// Without the filter, spans dropped by base are dropped on start, unless started under a span with error.
+func NewErrorAwareSampler(base Sampler) Sampler {
+	return &errorAwareSampler{base: base}
+}
+
+// ShouldSample always returns true, use shouldDropUnlessError for the decision of base sampler.
+func (s *errorAwareSampler) ShouldSample(ctx context.Context, traceID, spanName, spanType string) bool {
+	return true
+}
+
+// shouldDropUnlessError returns true if the span is dropped by base sampler, and should be dropped
+// on finish unless it has error.
+func (s *errorAwareSampler) shouldDropUnlessError(ctx context.Context, traceID, spanName, spanType string) bool {
+	return s.base != nil && !s.base.ShouldSample(ctx, traceID, spanName, spanType)
+}
+
+func (s *errorAwareSampler) samplerType() string {
+	return SamplerTypeErrorAware
+}
+
+// samplingRatio returns the ratio of traces kept by s, false if s is not a ratio sampler or wraps one.
+func samplingRatio(s Sampler) (float64, bool) {
+	switch sampler := s.(type) {
+	case *ratioSampler:
+		return sampler.ratio, true
+	case *errorAwareSampler:
+		return samplingRatio(sampler.base)
+	default:
+		return 0, false
+	}
+}
+
+// setSamplingWeightInfo sets the sampling weight decided on start of local root span, 0 means not set.
+func (s *Span) setSamplingWeightInfo(ctx context.Context) {
+	if s.samplingWeight <= 0 {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.setTagItem(ctx, tracespec.SamplingWeight, s.samplingWeight)
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRatioSampler(t *testing.T) {
+	Convey("ratioSampler", t, func() {
+		ctx := context.Background()
+		So(NewRatioSampler(0).ShouldSample(ctx, "trace", "", ""), ShouldBeFalse)
+		So(NewRatioSampler(1).ShouldSample(ctx, "trace", "", ""), ShouldBeTrue)
+
+		s := NewRatioSampler(0.25)
+		kept := 0
+		for i := 0; i < 10000; i++ {
+			traceID := fmt.Sprintf("%032x", i)
+			if s.ShouldSample(ctx, traceID, "", "") {
+				kept++
+			}
+			// the decision is the same for the same trace
+			So(s.ShouldSample(ctx, traceID, "other", ""), ShouldEqual, s.ShouldSample(ctx, traceID, "", ""))
+		}
+		So(kept, ShouldBeBetween, 2200, 2800)
+		So(getSamplerType(s), ShouldEqual, SamplerTypeRatio)
+	})
+}
+
+func TestErrorAwareSampler(t *testing.T) {
+	Convey("errorAwareSampler", t, func() {
+		ctx := context.Background()
+		var calls []string
+		processor := &recordSpanProcessor{name: "p", calls: &calls}
+		p := &Provider{
+			opt: &Options{
+				WorkspaceID:          "ws",
+				Sampler:              NewErrorAwareSampler(NewRatioSampler(0)),
+				ErrorAwareDropFilter: true,
+			},
+			spanProcessor: processor,
+		}
+		endedNames := func() []string {
+			var names []string
+			for _, s := range processor.ended {
+				names = append(names, s.GetSpanName())
+			}
+			return names
+		}
+
+		Convey("spans without error are dropped on finish", func() {
+			rootCtx, root, _ := p.StartSpan(ctx, "root", "agent", StartSpanOptions{})
+			So(root, ShouldNotBeNil)
+			_, ok := root.GetTagMap()["error"]
+			So(ok, ShouldBeFalse)
+			_, child, _ := p.StartSpan(rootCtx, "ok_child", "tool", StartSpanOptions{})
+			child.Finish(ctx)
+			_, failed, _ := p.StartSpan(rootCtx, "failed_child", "tool", StartSpanOptions{})
+			failed.SetError(ctx, errors.New("timeout"))
+			failed.Finish(ctx)
+			root.Finish(ctx)
+			So(endedNames(), ShouldResemble, []string{"failed_child"})
+		})
+
+		Convey("spans under span with error are kept", func() {
+			rootCtx, root, _ := p.StartSpan(ctx, "root", "agent", StartSpanOptions{})
+			root.SetError(ctx, errors.New("failed"))
+			_, child, _ := p.StartSpan(rootCtx, "child", "tool", StartSpanOptions{})
+			child.Finish(ctx)
+			root.Finish(ctx)
+			So(endedNames(), ShouldResemble, []string{"child", "root"})
+		})
+
+		Convey("spans are dropped on start without drop filter", func() {
+			p.opt.ErrorAwareDropFilter = false
+			rootCtx, root, _ := p.StartSpan(ctx, "root", "agent", StartSpanOptions{})
+			So(root, ShouldBeNil)
+			_, child, _ := p.StartSpan(rootCtx, "child", "tool", StartSpanOptions{})
+			So(child, ShouldBeNil)
+		})
+
+		Convey("spans are kept if sampled by base", func() {
+			p.opt.Sampler = NewErrorAwareSampler(NewRatioSampler(1))
+			_, root, _ := p.StartSpan(ctx, "root", "agent", StartSpanOptions{})
+			root.Finish(ctx)
+			So(endedNames(), ShouldResemble, []string{"root"})
+		})
+	})
+}
+
+func TestSamplingWeightTag(t *testing.T) {
+	Convey("sampling weight tag", t, func() {
+		ctx := context.Background()
+		var calls []string
+		processor := &recordSpanProcessor{name: "p", calls: &calls}
+		p := &Provider{
+			opt: &Options{
+				WorkspaceID:       "ws",
+				Sampler:           NewRatioSampler(1),
+				SamplingWeightTag: true,
+			},
+			spanProcessor: processor,
+		}
+		weights := func() []interface{} {
+			var res []interface{}
+			for _, s := range processor.ended {
+				res = append(res, s.GetTagMap()[tracespec.SamplingWeight])
+			}
+			return res
+		}
+
+		Convey("spans sampled by ratio have weight of 1/ratio", func() {
+			p.opt.Sampler = &ratioSampler{bound: math.MaxUint64, ratio: 0.25}
+			rootCtx, root, _ := p.StartSpan(ctx, "root", "agent", StartSpanOptions{})
+			_, child, _ := p.StartSpan(rootCtx, "child", "tool", StartSpanOptions{})
+			child.Finish(ctx)
+			root.Finish(ctx)
+			So(weights(), ShouldResemble, []interface{}{4.0, 4.0})
+		})
+
+		Convey("spans kept for error have weight 1", func() {
+			p.opt.Sampler = NewErrorAwareSampler(NewRatioSampler(0))
+			p.opt.ErrorAwareDropFilter = true
+			_, root, _ := p.StartSpan(ctx, "root", "agent", StartSpanOptions{})
+			root.SetError(ctx, errors.New("failed"))
+			root.Finish(ctx)
+			So(weights(), ShouldResemble, []interface{}{1.0})
+		})
+
+		Convey("weight is not set if disabled", func() {
+			p.opt.SamplingWeightTag = false
+			_, root, _ := p.StartSpan(ctx, "root", "agent", StartSpanOptions{})
+			root.Finish(ctx)
+			So(processor.ended[0].GetTagMap(), ShouldNotContainKey, tracespec.SamplingWeight)
+		})
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+const (
+	SamplingDecisionKeep = "keep"
+	SamplingDecisionDrop = "drop"
+
+	// SamplerTypeAlwaysOn keeps spans not matched by other samplers.
+	SamplerTypeAlwaysOn = "always_on"
+	// SamplerTypeParentBased follows the decision of local parent span, or upstream set by ContextWithSamplingDecision.
+	SamplerTypeParentBased = "parent_based"
+	// SamplerTypeSpanQuota drops spans exceeding MaxSpansPerTrace.
+	SamplerTypeSpanQuota = "span_quota"
+)
+
+// SamplingLogExporter writes one JSON line for each sampling decision made on StartSpan,
+// which shows why spans are kept or dropped.
+type SamplingLogExporter struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+type samplingLogRecord struct {
+	TraceID     string `json:"trace_id"`
+	SpanName    string `json:"span_name"`
+	SpanType    string `json:"span_type"`
+	SamplerType string `json:"sampler_type"`
+	Decision    string `json:"decision"`
+	Reason      string `json:"reason"`
+}
+
+// NewSamplingLogExporter creates a SamplingLogExporter writing to w, such as os.Stderr.
+func NewSamplingLogExporter(w io.Writer) *SamplingLogExporter {
+	return &SamplingLogExporter{w: w}
+}
+
+// logDecision writes the decision of span, errors are logged but not returned, as they should not affect StartSpan.
+func (e *SamplingLogExporter) logDecision(ctx context.Context, record samplingLogRecord) {
+	if e == nil || e.w == nil {
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		logger.CtxWarnf(ctx, "failed to marshal sampling decision: %v", err)
+		return
+	}
+	data = append(data, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err = e.w.Write(data); err != nil {
+		logger.CtxWarnf(ctx, "failed to write sampling decision: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSamplingLogExporter(t *testing.T) {
+	Convey("SamplingLogExporter", t, func() {
+		ctx := context.Background()
+		buf := &bytes.Buffer{}
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws", SamplingLogExporter: NewSamplingLogExporter(buf)},
+			spanProcessor: noopSpanProcessor{},
+			spanQuota:     newSpanQuota(2),
+		}
+		records := func() []samplingLogRecord {
+			var res []samplingLogRecord
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				record := samplingLogRecord{}
+				So(json.Unmarshal([]byte(line), &record), ShouldBeNil)
+				res = append(res, record)
+			}
+			return res
+		}
+
+		rootCtx, root, err := p.StartSpan(ctx, "root", "agent", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		_, _, _ = p.StartSpan(rootCtx, "child", "tool", StartSpanOptions{})
+		_, dropped, _ := p.StartSpan(rootCtx, "exceeded", "tool", StartSpanOptions{})
+		So(dropped, ShouldBeNil)
+		_, dropped, _ = p.StartSpan(ContextWithSamplingDecision(ctx, false), "unsampled", "tool", StartSpanOptions{})
+		So(dropped, ShouldBeNil)
+
+		So(records(), ShouldResemble, []samplingLogRecord{
+			{TraceID: root.GetTraceID(), SpanName: "root", SpanType: "agent", SamplerType: SamplerTypeAlwaysOn, Decision: SamplingDecisionKeep, Reason: "no sampling rule matched"},
+			{TraceID: root.GetTraceID(), SpanName: "child", SpanType: "tool", SamplerType: SamplerTypeParentBased, Decision: SamplingDecisionKeep, Reason: "parent span is sampled"},
+			{TraceID: root.GetTraceID(), SpanName: "exceeded", SpanType: "tool", SamplerType: SamplerTypeSpanQuota, Decision: SamplingDecisionDrop, Reason: "span count of trace exceeds limit 2"},
+			{SpanName: "unsampled", SpanType: "tool", SamplerType: SamplerTypeParentBased, Decision: SamplingDecisionDrop, Reason: "upstream sampling decision is not sampled"},
+		})
+
+		Convey("nil exporter is a no-op", func() {
+			var e *SamplingLogExporter
+			So(func() { e.logDecision(ctx, samplingLogRecord{}) }, ShouldNotPanic)
+		})
+	})
+}
@@ -4,12 +4,15 @@
 package trace
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/textproto"
 	"net/url"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -26,6 +29,15 @@ import (
 const (
 	spanUnFinished = 0
 	spanFinished   = 1
+
+	// vectorMetadataValueMaxChar is the max characters of each metadata value set by SetVectorSearchResults.
+	vectorMetadataValueMaxChar = 256
+	// searchSnippetMaxChar is the max characters of each snippet set by SetSearchResultSnippets.
+	searchSnippetMaxChar = 512
+	// hallucinationDetectedMsg is the error of spans of hallucination score above the threshold.
+	hallucinationDetectedMsg = "hallucination detected"
+	// invalidJSONOutputMsg is the error of spans of output format json whose output is not valid JSON.
+	invalidJSONOutputMsg = "output is not valid JSON"
 )
 
 type SpanContext struct {
@@ -70,12 +82,57 @@ type Span struct {
 	multiModalityKeyMap    map[string]struct{}
 	ultraLargeReportKeyMap map[string]struct{}
 	ultraLargeReport       bool
+	inputFileThreshold     int // input/output larger than it are exported as files, 0 means disabled
 	spanProcessor          SpanProcessor
 	flags                  byte  // for W3C, useless now
 	isFinished             int32 // avoid executing finish repeatedly.
 	lock                   sync.RWMutex
 	bytesSize              int64            // bytes size of span, note: it is an estimated value, may not be accurate.
 	tagTruncateConf        *TagTruncateConf // tag truncate byte conf
+	spanQuota              *spanQuota       // open span quota of trace, nil means no limit
+	annotations            []entity.SpanAnnotation
+	httpBodyRedactor       func(body []byte, contentType string) []byte
+	tagKeyValidator        func(key string) error // reject tags whose key is invalid, nil means no validation
+	fieldEncryption        *FieldEncryption       // encrypt fields before export, nil means no encryption
+	// default latency budget of span types, checked on finish
+	latencyBudgets map[string]time.Duration
+	// replace tag values exceeding cardinality limit, nil means no limit
+	cardinalityLimiter *cardinalityLimiter
+	// upload content of inputs set by SetMultiModalInputs as files
+	uploadMultiModalContent bool
+	modalInputFiles         []*entity.UploadFile
+	maxConversationMessages int
+	maxVectorResults        int
+	hallucinationThreshold  float64
+	chainParentName         string
+	// name is not given on start, which can be replaced by SetChainStep
+	defaultName bool
+	// forget seen span names of trace on finish, only set for local root span
+	collisionWarner *spanNameCollisionWarner
+	// the span has no parent span in this process, its parent may be remote
+	localRoot bool
+	// spans grouped by GroupSpans, whose statistics are set on finish
+	groupedSpans []*Span
+	// callbacks called with finished span, nil means no callback
+	finishHook *spanFinishHook
+	// stack of StartSpan, only captured if leak detection is enabled
+	startStack []byte
+	// compare model fingerprint with the last span of the same model on finish, nil means disabled
+	modelDriftDetector *modelDriftDetector
+	// collect gpu metrics of model spans on finish, nil means disabled
+	gpuMetricsCollector GPUMetricsCollector
+	// dropped on finish unless it has error, set by error aware sampler
+	dropUnlessError bool
+	// set as sampling.weight on finish, 0 means not set
+	samplingWeight float64
+	// discount of each prompt cache read token by model name, used by EffectiveCost
+	cacheDiscounts map[string]float64
+	// ratio of context window used above which llm.context_window_warning is set, 0 means default
+	contextWindowThreshold float64
+	// min ratio of context compression below which context.compression_below_min is set, 0 means no threshold
+	compressionMinRatio float64
+	// adapt memory of custom type passed to SetCustomAgentMemory, nil means ignoring it
+	memorySerializer func(mem interface{}) tracespec.AgentMemory
 }
 
 type TagTruncateConf struct {
@@ -123,9 +180,31 @@ func (s *Span) GetParentID() string {
 	if s == nil {
 		return ""
 	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
 	return s.ParentSpanID
 }
 
+// setParentID moves the span under another parent, used by GroupSpans.
+func (s *Span) setParentID(parentID string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ParentSpanID = parentID
+}
+
+func (s *Span) setLocalRoot(localRoot bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.localRoot = localRoot
+}
+
+// isLocalRoot returns whether the span has no parent span in this process.
+func (s *Span) isLocalRoot() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.localRoot
+}
+
 func (s *Span) GetTagMap() map[string]interface{} {
 	if s == nil {
 		return nil
@@ -162,6 +241,8 @@ func (s *Span) GetSpanName() string {
 	if s == nil {
 		return ""
 	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
 	return s.Name
 }
 
@@ -285,6 +366,49 @@ func fromHeaderParent(h string) (traceID, spanID string, err error) {
 	return traceIDTemp, spanIDTemp, nil
 }
 
+func (s *Span) SetSystemPrompt(ctx context.Context, prompt string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.SystemPrompt, prompt))
+}
+
+func (s *Span) SetOutputFormat(ctx context.Context, format tracespec.OutputFormat) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.LLMOutputFormat, string(format)))
+}
+
+// isInvalidJSONOutput returns whether output is string or bytes which is not valid JSON while output format is json.
+func (s *Span) isInvalidJSONOutput(output interface{}) bool {
+	s.lock.RLock()
+	format, _ := s.TagMap[tracespec.LLMOutputFormat].(string)
+	s.lock.RUnlock()
+	if format != string(tracespec.OutputFormatJSON) {
+		return false
+	}
+	switch o := output.(type) {
+	case string:
+		return !json.Valid([]byte(o))
+	case []byte:
+		return !json.Valid(o)
+	default:
+		return false
+	}
+}
+
+// GetSystemPrompt returns the system prompt set by SetSystemPrompt, which may be truncated.
+func (s *Span) GetSystemPrompt(ctx context.Context) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	prompt, ok := s.TagMap[tracespec.SystemPrompt].(string)
+	return prompt, ok
+}
+
 func (s *Span) SetInput(ctx context.Context, input interface{}) {
 	if s == nil || s.isSpanFinished() {
 		return
@@ -447,7 +571,11 @@ func (s *Span) SetOutput(ctx context.Context, output interface{}) {
 		s.lock.Unlock()
 	}
 
-	s.SetTags(ctx, oneTag(tracespec.Output, output))
+	tagMap := oneTag(tracespec.Output, output)
+	if s.isInvalidJSONOutput(output) {
+		tagMap[tracespec.Error] = invalidJSONOutputMsg
+	}
+	s.SetTags(ctx, tagMap)
 }
 
 func deepCopyMessageOfModelOutput(src tracespec.ModelOutput) tracespec.ModelOutput {
@@ -600,6 +728,31 @@ func (s *Span) SetModelName(ctx context.Context, modelName string) {
 	s.SetTags(ctx, oneTag(tracespec.ModelName, modelName))
 }
 
+// SetFallbackModel sets the fallback model, and keeps the model name set before in llm.primary_model.
+func (s *Span) SetFallbackModel(ctx context.Context, fallbackModelName, fallbackModelProvider, reason string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := map[string]interface{}{
+		tracespec.FallbackModel: fallbackModelName,
+	}
+	if fallbackModelProvider != "" {
+		tagMap[tracespec.FallbackProvider] = fallbackModelProvider
+	}
+	if reason != "" {
+		tagMap[tracespec.FallbackReason] = reason
+	}
+	s.lock.RLock()
+	primary, _ := s.TagMap[tracespec.ModelName].(string)
+	_, hasPrimary := s.TagMap[tracespec.PrimaryModel]
+	s.lock.RUnlock()
+	// keep the first primary model if falling back more than once
+	if primary != "" && !hasPrimary {
+		tagMap[tracespec.PrimaryModel] = primary
+	}
+	s.SetTags(ctx, tagMap)
+}
+
 func (s *Span) SetModelCallOptions(ctx context.Context, callOptions interface{}) {
 	if s == nil || s.isSpanFinished() {
 		return
@@ -614,6 +767,66 @@ func (s *Span) SetInputTokens(ctx context.Context, inputTokens int) {
 	s.SetTags(ctx, oneTag(tracespec.InputTokens, inputTokens))
 }
 
+// SetTokenUsageByPart sets input tokens of each message part as JSON, and their total as input tokens.
+func (s *Span) SetTokenUsageByPart(ctx context.Context, parts []tracespec.TokenUsagePart) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	if parts == nil {
+		parts = []tracespec.TokenUsagePart{}
+	}
+	total := 0
+	for _, part := range parts {
+		total += part.Tokens
+	}
+	s.SetTags(ctx, map[string]interface{}{
+		tracespec.TokensByPart: util.ToJSON(parts),
+		tracespec.InputTokens:  total,
+	})
+}
+
+// SetSelfConsistencyVotes summarizes votes of answers, votes of the same answer are merged. Votes are set as JSON
+// in descending order of count, and the least voted are dropped if the JSON array exceeds the size limit of tag value.
+func (s *Span) SetSelfConsistencyVotes(ctx context.Context, votes []tracespec.SelfConsistencyVote) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	merged := make([]tracespec.SelfConsistencyVote, 0, len(votes))
+	index := make(map[string]int, len(votes))
+	total := 0
+	for _, vote := range votes {
+		total += vote.Count
+		if i, ok := index[vote.Answer]; ok {
+			merged[i].Count += vote.Count
+			continue
+		}
+		index[vote.Answer] = len(merged)
+		merged = append(merged, vote)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Count > merged[j].Count
+	})
+
+	tagMap := map[string]interface{}{
+		tracespec.SCUniqueAnswers: len(merged),
+		tracespec.SCTotalVotes:    total,
+	}
+	if len(merged) > 0 {
+		tagMap[tracespec.SCMajorityAnswer] = merged[0].Answer
+		if total > 0 {
+			tagMap[tracespec.SCMajorityConfidence] = float64(merged[0].Count) / float64(total)
+		}
+	}
+	value := util.ToJSON(merged)
+	limit := s.getTagValueSizeLimit(tracespec.SCVotes)
+	for len(value) > limit && len(merged) > 0 {
+		merged = merged[:len(merged)-1]
+		value = util.ToJSON(merged)
+	}
+	tagMap[tracespec.SCVotes] = value
+	s.SetTags(ctx, tagMap)
+}
+
 func (s *Span) SetOutputTokens(ctx context.Context, outputTokens int) {
 	if s == nil || s.isSpanFinished() {
 		return
@@ -621,6 +834,791 @@ func (s *Span) SetOutputTokens(ctx context.Context, outputTokens int) {
 	s.SetTags(ctx, oneTag(tracespec.OutputTokens, outputTokens))
 }
 
+func (s *Span) SetCacheHit(ctx context.Context, hit bool) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.CacheHit, hit))
+}
+
+func (s *Span) SetCachedInputTokens(ctx context.Context, cachedInputTokens int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.CachedInputTokens, cachedInputTokens))
+}
+
+func (s *Span) SetCacheReadInputTokens(ctx context.Context, cacheReadInputTokens int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.CacheReadInputTokens, cacheReadInputTokens))
+}
+
+func (s *Span) SetPromptCacheTokens(ctx context.Context, created, read int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, map[string]interface{}{
+		tracespec.CacheCreationTokens: created,
+		tracespec.CacheReadTokens:     read,
+	})
+}
+
+func (s *Span) SetReasoningTokens(ctx context.Context, reasoningTokens int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.LLMReasoningTokens, reasoningTokens))
+}
+
+// EffectiveCost returns the cost of tokens which are not served from cache, that is
+// (input_tokens - llm.cached_input_tokens + output_tokens - llm.reasoning_tokens) * costPerToken +
+// llm.reasoning_tokens * reasoningCostPerToken.
+// llm.cache_read_input_tokens are not included in input_tokens, so they are not billed either.
+// llm.cache_read_tokens * cacheDiscount is subtracted from the cost, where cacheDiscount is the discount
+// of the model set by Options.PromptCacheDiscounts, the fallback model is used if it is set.
+func (s *Span) EffectiveCost(costPerToken, reasoningCostPerToken float64) float64 {
+	if s == nil {
+		return 0
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	inputTokens := s.getIntTag(tracespec.InputTokens) - s.getIntTag(tracespec.CachedInputTokens)
+	if inputTokens < 0 {
+		inputTokens = 0
+	}
+	reasoningTokens := s.getIntTag(tracespec.LLMReasoningTokens)
+	outputTokens := s.getIntTag(tracespec.OutputTokens) - reasoningTokens
+	if outputTokens < 0 {
+		outputTokens = 0
+	}
+	cost := float64(inputTokens+outputTokens)*costPerToken + float64(reasoningTokens)*reasoningCostPerToken
+	model, _ := s.TagMap[tracespec.ModelName].(string)
+	if fallback, ok := s.TagMap[tracespec.FallbackModel].(string); ok && fallback != "" {
+		model = fallback
+	}
+	if cacheDiscount := s.cacheDiscounts[model]; cacheDiscount > 0 {
+		cost -= float64(s.getIntTag(tracespec.CacheReadTokens)) * cacheDiscount
+		if cost < 0 {
+			cost = 0
+		}
+	}
+	return cost
+}
+
+func (s *Span) SetModelParameters(ctx context.Context, params tracespec.ModelParameters) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tags := make(map[string]interface{})
+	if params.Temperature != 0 {
+		tags[tracespec.Temperature] = params.Temperature
+	}
+	if params.TopP != 0 {
+		tags[tracespec.TopP] = params.TopP
+	}
+	if params.MaxTokens != 0 {
+		tags[tracespec.MaxTokens] = params.MaxTokens
+	}
+	if len(params.Stop) > 0 {
+		tags[tracespec.StopSequences] = util.ToJSON(params.Stop)
+	}
+	if params.FrequencyPenalty != 0 {
+		tags[tracespec.FrequencyPenalty] = params.FrequencyPenalty
+	}
+	if params.PresencePenalty != 0 {
+		tags[tracespec.PresencePenalty] = params.PresencePenalty
+	}
+	if len(tags) > 0 {
+		s.SetTags(ctx, tags)
+	}
+}
+
+func (s *Span) SetTokenBudget(ctx context.Context, inputBudget, outputBudget int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, map[string]interface{}{
+		tracespec.InputTokenBudget:  inputBudget,
+		tracespec.OutputTokenBudget: outputBudget,
+	})
+}
+
+// CheckBudget returns the remaining input and output tokens of budget, which are negative if exceeded.
+// Budget of 0 means unlimited, the remaining is 0 and never exceeded.
+// If exceeded, the span is marked as error and tagged with llm.budget_exceeded.
+func (s *Span) CheckBudget(ctx context.Context) (inputRemaining, outputRemaining int, exceeded bool) {
+	if s == nil {
+		return 0, 0, false
+	}
+	s.lock.RLock()
+	inputBudget, outputBudget := s.getIntTag(tracespec.InputTokenBudget), s.getIntTag(tracespec.OutputTokenBudget)
+	inputTokens, outputTokens := s.getIntTag(tracespec.InputTokens), s.getIntTag(tracespec.OutputTokens)
+	s.lock.RUnlock()
+
+	if inputBudget > 0 {
+		inputRemaining = int(inputBudget - inputTokens)
+		exceeded = inputRemaining < 0
+	}
+	if outputBudget > 0 {
+		outputRemaining = int(outputBudget - outputTokens)
+		exceeded = exceeded || outputRemaining < 0
+	}
+	if exceeded && !s.isSpanFinished() {
+		s.SetTags(ctx, oneTag(tracespec.BudgetExceeded, true))
+		s.SetStatusCode(ctx, consts.StatusCodeErrorDefault)
+	}
+	return inputRemaining, outputRemaining, exceeded
+}
+
+// getIntTag returns the integer value of tag, 0 if not exist. Should be called with lock held.
+func (s *Span) getIntTag(key string) int64 {
+	switch v := s.TagMap[key].(type) {
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	default:
+		return 0
+	}
+}
+
+func (s *Span) SetLatencyBudget(ctx context.Context, budget time.Duration) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.SLOLatencyBudget, budget.Microseconds()))
+}
+
+// SetMultiModalInputs sums bytes of image and audio inputs into tags. If uploading multi-modal content is enabled,
+// the content of image and audio inputs are uploaded as attachments of input when span is exported.
+func (s *Span) SetMultiModalInputs(ctx context.Context, inputs []tracespec.ModalInput) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	var imageBytes, audioBytes int64
+	var files []*entity.UploadFile
+	for i, input := range inputs {
+		size := int64(input.SizeBytes)
+		if size == 0 {
+			size = int64(len(input.Data))
+		}
+		var fileType string
+		switch input.Type {
+		case tracespec.ModalTypeImage:
+			imageBytes += size
+			fileType = fileTypeImage
+		case tracespec.ModalTypeAudio:
+			audioBytes += size
+			fileType = fileTypeFile
+		default:
+			continue
+		}
+		if s.uploadMultiModalContent && len(input.Data) > 0 {
+			// key := "traceid_spanid_tagkey_filetype_randomid"
+			key := fmt.Sprintf(KeyTemplateMultiModality, s.GetTraceID(), s.GetSpanID(), tracespec.Input, fileType, util.Gen16CharID())
+			files = append(files, &entity.UploadFile{
+				TosKey:     key,
+				Data:       string(input.Data),
+				UploadType: entity.UploadTypeMultiModality,
+				TagKey:     tracespec.Input,
+				Name:       fmt.Sprintf("%s_%d.%s", input.Type, i, input.Format),
+				FileType:   fileType,
+				SpaceID:    s.GetSpaceID(),
+			})
+		}
+	}
+
+	s.SetTags(ctx, map[string]interface{}{
+		tracespec.ImageInputBytes: imageBytes,
+		tracespec.AudioInputBytes: audioBytes,
+	})
+	if s.uploadMultiModalContent {
+		s.lock.Lock()
+		s.modalInputFiles = files
+		s.lock.Unlock()
+	}
+}
+
+// getModalInputFiles returns files of inputs set by SetMultiModalInputs.
+func (s *Span) getModalInputFiles() []*entity.UploadFile {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.modalInputFiles
+}
+
+// SetFineTuningMetadata sets the metadata of fine-tuned model, empty fields are not set.
+func (s *Span) SetFineTuningMetadata(ctx context.Context, meta tracespec.FineTuningMeta) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := make(map[string]interface{})
+	if meta.BaseModel != "" {
+		tagMap[tracespec.FTBaseModel] = meta.BaseModel
+	}
+	if meta.FineTunedModelID != "" {
+		tagMap[tracespec.FTModelID] = meta.FineTunedModelID
+	}
+	if meta.TrainingDatasetID != "" {
+		tagMap[tracespec.FTTrainingDatasetID] = meta.TrainingDatasetID
+	}
+	if meta.TrainingSteps != 0 {
+		tagMap[tracespec.FTTrainingSteps] = meta.TrainingSteps
+	}
+	if meta.Epoch != 0 {
+		tagMap[tracespec.FTEpoch] = meta.Epoch
+	}
+	if len(tagMap) == 0 {
+		return
+	}
+	s.SetTags(ctx, tagMap)
+}
+
+// SetContextWindow sets the tokens used of model context window and its utilization. If used exceeds
+// limit * contextWindowThreshold, llm.context_window_warning is set and a warning is logged.
+func (s *Span) SetContextWindow(ctx context.Context, used, limit int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := map[string]interface{}{
+		tracespec.ContextWindowUsed:  used,
+		tracespec.ContextWindowLimit: limit,
+	}
+	if limit > 0 {
+		tagMap[tracespec.ContextWindowUtilization] = float64(used) / float64(limit)
+		threshold := s.contextWindowThreshold
+		if threshold <= 0 {
+			threshold = consts.DefaultContextWindowWarningThreshold
+		}
+		if float64(used) > float64(limit)*threshold {
+			tagMap[tracespec.ContextWindowWarning] = true
+			logger.CtxWarnf(ctx, "context window of span[%s] is nearly full, used: %d, limit: %d, threshold: %v",
+				s.GetSpanName(), used, limit, threshold)
+		}
+	}
+	s.SetTags(ctx, tagMap)
+}
+
+// SetContextCompression sets the result of context compression, ratio is calculated from tokens if it is 0.
+// context.compression_below_min is set if compressionMinRatio is set and the ratio is lower than it.
+func (s *Span) SetContextCompression(ctx context.Context, method string, originalTokens, compressedTokens int, ratio float64) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	if ratio == 0 && compressedTokens > 0 {
+		ratio = float64(originalTokens) / float64(compressedTokens)
+	}
+	tagMap := map[string]interface{}{
+		tracespec.ContextCompressionMethod: method,
+		tracespec.ContextOriginalTokens:    originalTokens,
+		tracespec.ContextCompressedTokens:  compressedTokens,
+		tracespec.ContextCompressionRatio:  ratio,
+	}
+	if s.compressionMinRatio > 0 && ratio < s.compressionMinRatio {
+		tagMap[tracespec.ContextCompressionBelowMin] = true
+		logger.CtxWarnf(ctx, "context compression ratio of span[%s] is lower than expected, ratio: %v, min: %v",
+			s.GetSpanName(), ratio, s.compressionMinRatio)
+	}
+	s.SetTags(ctx, tagMap)
+}
+
+// SetHallucinationScore sets the hallucination score of model output and the number of grounded claims.
+// The span is marked as error if hallucinationThreshold is set and the score is above it.
+func (s *Span) SetHallucinationScore(ctx context.Context, score float64, groundedClaims, totalClaims int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := map[string]interface{}{
+		tracespec.HallucinationScore: score,
+		tracespec.GroundedClaims:     groundedClaims,
+		tracespec.TotalClaims:        totalClaims,
+	}
+	s.SetTags(ctx, tagMap)
+
+	if s.hallucinationThreshold > 0 && score > s.hallucinationThreshold {
+		s.SetError(ctx, errors.New(hallucinationDetectedMsg))
+	}
+}
+
+// SetModelFingerprint sets the fingerprint of model backend returned by provider. If the callback is set by
+// WithModelDriftCallback, it is compared with the fingerprint of the last finished span of the same model on finish.
+func (s *Span) SetModelFingerprint(ctx context.Context, fingerprint string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.ModelFingerprint, fingerprint))
+}
+
+// SetRetryContext sets the attempt of span among retries of a failed operation, and the backoff waited before it.
+func (s *Span) SetRetryContext(ctx context.Context, attempt, maxAttempts int, backoffDuration time.Duration) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, map[string]interface{}{
+		tracespec.RetryAttempt:       attempt,
+		tracespec.RetryMaxAttempts:   maxAttempts,
+		tracespec.RetryBackoffMillis: backoffDuration.Milliseconds(),
+	})
+}
+
+// SetGPUMetrics sets the telemetry of GPU running the workload of span. Metrics collected by the collector
+// set by WithGPUMetricsCollector on finish are skipped if it is set.
+func (s *Span) SetGPUMetrics(ctx context.Context, metrics tracespec.GPUMetrics) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, gpuMetricsTags(metrics))
+}
+
+// SetChainStep sets the position of the span among steps of a sequential pipeline. If the span is started
+// without name, its name is set to `<workflowName>/<stepName>`, where workflowName is the name of span set by
+// WithChainStepParent, or stepName if it is not set.
+func (s *Span) SetChainStep(ctx context.Context, stepIndex int, stepName string, totalSteps int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, map[string]interface{}{
+		tracespec.ChainStepIndex:  stepIndex,
+		tracespec.ChainStepName:   stepName,
+		tracespec.ChainTotalSteps: totalSteps,
+	})
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.defaultName || stepName == "" {
+		return
+	}
+	s.defaultName = false
+	if s.chainParentName != "" {
+		s.Name = s.chainParentName + "/" + stepName
+	} else {
+		s.Name = stepName
+	}
+}
+
+// SetConversationHistory sets messages of multi-turn conversation as input in JSON, overriding any prior input.
+// Only the most recent maxConversationMessages messages are kept if it is set.
+func (s *Span) SetConversationHistory(ctx context.Context, messages []tracespec.ConversationMessage) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	if s.maxConversationMessages > 0 && len(messages) > s.maxConversationMessages {
+		messages = messages[len(messages)-s.maxConversationMessages:]
+	}
+	if messages == nil {
+		messages = []tracespec.ConversationMessage{}
+	}
+	data, err := json.Marshal(messages)
+	if err != nil {
+		logger.CtxErrorf(ctx, "failed to marshal conversation history: %v", err)
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.Input, string(data)))
+}
+
+// SetInputMessages sets messages sent to chat API as input in JSON, overriding any prior input.
+func (s *Span) SetInputMessages(ctx context.Context, messages []tracespec.ChatMessage) {
+	s.setChatMessages(ctx, tracespec.Input, messages)
+}
+
+// SetOutputMessages sets messages returned by chat API as output in JSON, overriding any prior output.
+func (s *Span) SetOutputMessages(ctx context.Context, messages []tracespec.ChatMessage) {
+	s.setChatMessages(ctx, tracespec.Output, messages)
+}
+
+func (s *Span) setChatMessages(ctx context.Context, key string, messages []tracespec.ChatMessage) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	if messages == nil {
+		messages = []tracespec.ChatMessage{}
+	}
+	data, err := json.Marshal(messages)
+	if err != nil {
+		logger.CtxErrorf(ctx, "failed to marshal chat messages: %v", err)
+		return
+	}
+	s.SetTags(ctx, oneTag(key, string(data)))
+}
+
+// SetToolSchema sets the JSON schema of tool definition, which is compacted if it is valid JSON.
+func (s *Span) SetToolSchema(ctx context.Context, schema json.RawMessage) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.ToolSchema, compactJSON(schema)))
+}
+
+// SetFunctionCall sets the function call generated by the model, arguments are compacted if valid JSON.
+func (s *Span) SetFunctionCall(ctx context.Context, call tracespec.FunctionCall) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := map[string]interface{}{
+		tracespec.FunctionName: call.Name,
+	}
+	if len(call.Arguments) > 0 {
+		tagMap[tracespec.FunctionArguments] = compactJSON(call.Arguments)
+	}
+	if call.CallID != "" {
+		tagMap[tracespec.FunctionCallID] = call.CallID
+	}
+	s.SetTags(ctx, tagMap)
+}
+
+// SetFunctionCallResult sets the result of function call.
+func (s *Span) SetFunctionCallResult(ctx context.Context, result tracespec.FunctionCallResult) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := map[string]interface{}{
+		tracespec.FunctionResult:  result.Content,
+		tracespec.FunctionIsError: result.IsError,
+	}
+	if result.CallID != "" {
+		tagMap[tracespec.FunctionCallID] = result.CallID
+	}
+	s.SetTags(ctx, tagMap)
+}
+
+// compactJSON returns raw in compact form, or as is if it is not valid JSON.
+func compactJSON(raw json.RawMessage) string {
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+func (s *Span) SetVectorDBSystem(ctx context.Context, system string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.VectorDBSystem, system))
+}
+
+func (s *Span) SetVectorDBOperation(ctx context.Context, op string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.VectorDBOperation, op))
+}
+
+func (s *Span) SetVectorDBIndexName(ctx context.Context, name string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.VectorDBIndexName, name))
+}
+
+func (s *Span) SetVectorDBNamespace(ctx context.Context, namespace string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.VectorDBNamespace, namespace))
+}
+
+// SetVectorDBQueryVector sets the dimensionality of query vector, the vector itself is not stored.
+func (s *Span) SetVectorDBQueryVector(ctx context.Context, dims int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.VectorDBQueryVectorDims, dims))
+}
+
+// SetRerankResult sets the reranking model and document counts, and summarizes scores by max, mean and min.
+func (s *Span) SetRerankResult(ctx context.Context, model string, inputCount, outputCount int, scores []float64) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := map[string]interface{}{
+		tracespec.RerankModel:               model,
+		tracespec.RerankInputDocumentCount:  inputCount,
+		tracespec.RerankOutputDocumentCount: outputCount,
+	}
+	if len(scores) > 0 {
+		top, bottom, sum := scores[0], scores[0], 0.0
+		for _, score := range scores {
+			if score > top {
+				top = score
+			}
+			if score < bottom {
+				bottom = score
+			}
+			sum += score
+		}
+		tagMap[tracespec.RerankTopScore] = top
+		tagMap[tracespec.RerankMeanScore] = sum / float64(len(scores))
+		tagMap[tracespec.RerankMinScore] = bottom
+	}
+	s.SetTags(ctx, tagMap)
+}
+
+func (s *Span) SetSearchQuery(ctx context.Context, query string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.SearchQuery, query))
+}
+
+func (s *Span) SetSearchEngine(ctx context.Context, engine string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.SearchEngine, engine))
+}
+
+func (s *Span) SetSearchResultCount(ctx context.Context, total, returned int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, map[string]interface{}{
+		tracespec.SearchTotalResults:    total,
+		tracespec.SearchReturnedResults: returned,
+	})
+}
+
+// SetSearchResultSnippets sets snippets of search results in JSON, each snippet is truncated to searchSnippetMaxChar
+// characters, and the last snippets are dropped if the JSON array exceeds the size limit of tag value.
+func (s *Span) SetSearchResultSnippets(ctx context.Context, snippets []string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	truncated := make([]string, 0, len(snippets))
+	for _, snippet := range snippets {
+		truncated = append(truncated, util.TruncateStringByChar(snippet, searchSnippetMaxChar))
+	}
+
+	value := util.ToJSON(truncated)
+	limit := s.getTagValueSizeLimit(tracespec.SearchSnippets)
+	for len(value) > limit && len(truncated) > 0 {
+		truncated = truncated[:len(truncated)-1]
+		value = util.ToJSON(truncated)
+	}
+	s.SetTags(ctx, oneTag(tracespec.SearchSnippets, value))
+}
+
+// SetVectorSearchResults sets the results of similarity search in descending order of score. Only the top
+// maxVectorResults results are kept if it is set, and metadata values are truncated to vectorMetadataValueMaxChar
+// characters. Results of the lowest scores are dropped if the JSON array exceeds the size limit of tag value.
+func (s *Span) SetVectorSearchResults(ctx context.Context, results []tracespec.VectorSearchResult) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	sorted := make([]tracespec.VectorSearchResult, 0, len(results))
+	for _, result := range results {
+		if len(result.Metadata) > 0 {
+			metadata := make(map[string]string, len(result.Metadata))
+			for k, v := range result.Metadata {
+				metadata[k] = util.TruncateStringByChar(v, vectorMetadataValueMaxChar)
+			}
+			result.Metadata = metadata
+		}
+		sorted = append(sorted, result)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Score > sorted[j].Score
+	})
+	if s.maxVectorResults > 0 && len(sorted) > s.maxVectorResults {
+		sorted = sorted[:s.maxVectorResults]
+	}
+
+	value := util.ToJSON(sorted)
+	limit := s.getTagValueSizeLimit(tracespec.VectorSearchResults)
+	for len(value) > limit && len(sorted) > 0 {
+		sorted = sorted[:len(sorted)-1]
+		value = util.ToJSON(sorted)
+	}
+	s.SetTags(ctx, oneTag(tracespec.VectorSearchResults, value))
+}
+
+func (s *Span) SetCitations(ctx context.Context, citations []tracespec.Citation) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	if citations == nil {
+		citations = []tracespec.Citation{}
+	}
+	s.SetTags(ctx, oneTag(tracespec.RAGCitations, util.ToJSON(citations)))
+}
+
+// SetDocumentContext sets the retrieved documents given to the model as context in JSON.
+func (s *Span) SetDocumentContext(ctx context.Context, docs []tracespec.DocumentContext) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	if docs == nil {
+		docs = []tracespec.DocumentContext{}
+	}
+	s.SetTags(ctx, oneTag(tracespec.RAGDocuments, util.ToJSON(docs)))
+}
+
+// SetHypotheticalDocument sets the hypothetical document of HyDE, and marks the retrieval strategy as hyde.
+func (s *Span) SetHypotheticalDocument(ctx context.Context, doc string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, map[string]interface{}{
+		tracespec.RAGHypotheticalDoc: doc,
+		tracespec.RAGStrategy:        tracespec.VRAGStrategyHyDE,
+	})
+}
+
+func (s *Span) SetEmbeddingCacheResult(ctx context.Context, hit bool, cachedCount, recomputedCount int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, map[string]interface{}{
+		tracespec.EmbeddingCacheHit:        hit,
+		tracespec.EmbeddingCachedCount:     cachedCount,
+		tracespec.EmbeddingRecomputedCount: recomputedCount,
+	})
+}
+
+func (s *Span) SetCacheCheckpointTime(ctx context.Context, t time.Time) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetTags(ctx, oneTag(tracespec.EmbeddingCacheCheckpoint, t.UnixMicro()))
+}
+
+// SetRetrievalFilter sets the metadata filter of vector search in compact JSON. Only the sorted keys of filter
+// are set if the JSON exceeds the size limit of tag value.
+func (s *Span) SetRetrievalFilter(ctx context.Context, filter map[string]interface{}) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	value := util.ToJSON(filter)
+	if len(value) <= s.getTagValueSizeLimit(tracespec.RAGRetrievalFilter) {
+		s.SetTags(ctx, oneTag(tracespec.RAGRetrievalFilter, value))
+		return
+	}
+	keys := make([]string, 0, len(filter))
+	for k := range filter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	s.SetTags(ctx, oneTag(tracespec.RAGRetrievalFilterKeys, util.ToJSON(keys)))
+}
+
+// SetEvaluationResult sets the result of evaluation as typed tags, labels are set with key prefix `eval.label.`.
+func (s *Span) SetEvaluationResult(ctx context.Context, result tracespec.EvaluationResult) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := map[string]interface{}{
+		tracespec.EvalScore:  result.Score,
+		tracespec.EvalPassed: result.Passed,
+	}
+	if result.EvaluatorName != "" {
+		tagMap[tracespec.EvalEvaluator] = result.EvaluatorName
+	}
+	if result.MaxScore != 0 {
+		tagMap[tracespec.EvalMaxScore] = result.MaxScore
+	}
+	if result.Rationale != "" {
+		tagMap[tracespec.EvalRationale] = result.Rationale
+	}
+	for k, v := range result.Labels {
+		if k != "" {
+			tagMap[tracespec.EvalLabelPrefix+k] = v
+		}
+	}
+	s.SetTags(ctx, tagMap)
+}
+
+// SetGuardrailResult sets the outcome of a safety guardrail, categories are set as JSON array.
+// The span is marked as error if the guardrail is triggered and the content is blocked.
+func (s *Span) SetGuardrailResult(ctx context.Context, result tracespec.GuardrailResult) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	tagMap := map[string]interface{}{
+		tracespec.GuardrailTriggered: result.Triggered,
+	}
+	if result.GuardrailName != "" {
+		tagMap[tracespec.GuardrailName] = result.GuardrailName
+	}
+	if result.Action != "" {
+		tagMap[tracespec.GuardrailAction_] = string(result.Action)
+	}
+	if len(result.Categories) > 0 {
+		if data, err := json.Marshal(result.Categories); err == nil {
+			tagMap[tracespec.GuardrailCategories] = string(data)
+		}
+	}
+	if result.Confidence != 0 {
+		tagMap[tracespec.GuardrailConfidence] = result.Confidence
+	}
+	s.SetTags(ctx, tagMap)
+
+	if result.Triggered && result.Action == tracespec.GuardrailActionBlock {
+		msg := fmt.Sprintf("content blocked by guardrail [%s]", result.GuardrailName)
+		if len(result.Categories) > 0 {
+			msg += ", categories: " + strings.Join(result.Categories, ",")
+		}
+		s.SetError(ctx, errors.New(msg))
+	}
+}
+
+// SetHTTPRequestBody sets the request body as input, after redacting it by the http body redactor.
+func (s *Span) SetHTTPRequestBody(ctx context.Context, body []byte, contentType string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetInput(ctx, string(s.redactHTTPBody(body, contentType)))
+	if contentType != "" {
+		s.SetTags(ctx, oneTag(tracespec.HTTPRequestContentType, contentType))
+	}
+}
+
+// SetHTTPResponseBody sets the response body as output, after redacting it by the http body redactor.
+func (s *Span) SetHTTPResponseBody(ctx context.Context, body []byte, statusCode int) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.SetOutput(ctx, string(s.redactHTTPBody(body, "")))
+	s.SetTags(ctx, oneTag(tracespec.HTTPStatusCode, statusCode))
+}
+
+func (s *Span) redactHTTPBody(body []byte, contentType string) []byte {
+	if s.httpBodyRedactor != nil {
+		return s.httpBodyRedactor(body, contentType)
+	}
+	return DefaultHTTPBodyRedactor(body, contentType)
+}
+
+func (s *Span) Annotate(ctx context.Context, message string) {
+	if s == nil || s.isSpanFinished() {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.annotations = append(s.annotations, entity.SpanAnnotation{
+		Timestamp: time.Now().UnixMicro(),
+		Message:   message,
+	})
+	s.bytesSize += int64(len(message))
+}
+
+func (s *Span) GetAnnotations() []entity.SpanAnnotation {
+	if s == nil {
+		return nil
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if len(s.annotations) == 0 {
+		return nil
+	}
+	res := make([]entity.SpanAnnotation, len(s.annotations))
+	copy(res, s.annotations)
+	return res
+}
+
 func (s *Span) SetStartTimeFirstResp(ctx context.Context, startTimeFirstResp int64) {
 	if s == nil || s.isSpanFinished() {
 		return
@@ -632,6 +1630,14 @@ func (s *Span) SetTags(ctx context.Context, tagKVs map[string]interface{}) {
 	if s == nil || len(tagKVs) == 0 || s.isSpanFinished() {
 		return
 	}
+	if s.tagKeyValidator != nil {
+		if tagKVs = s.validateTagKeys(ctx, tagKVs); len(tagKVs) == 0 {
+			return
+		}
+	}
+	if s.cardinalityLimiter != nil {
+		tagKVs = s.cardinalityLimiter.limit(ctx, tagKVs)
+	}
 
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -647,6 +1653,19 @@ func (s *Span) SetTags(ctx context.Context, tagKVs map[string]interface{}) {
 	}
 }
 
+// validateTagKeys returns tags whose key passes tagKeyValidator, the input map is not modified.
+func (s *Span) validateTagKeys(ctx context.Context, tagKVs map[string]interface{}) map[string]interface{} {
+	validTagKVs := make(map[string]interface{}, len(tagKVs))
+	for key, value := range tagKVs {
+		if err := s.tagKeyValidator(key); err != nil {
+			logger.CtxWarnf(ctx, "tag [%s] is rejected by validator: %v", key, err)
+			continue
+		}
+		validTagKVs[key] = value
+	}
+	return validTagKVs
+}
+
 func (s *Span) addDefaultTag(ctx context.Context, tagKVs map[string]interface{}) {
 	for key := range tagKVs {
 		switch key {
@@ -832,9 +1851,29 @@ func (s *Span) Finish(ctx context.Context) {
 	if !s.isDoFinish() {
 		return
 	}
+	untrackSpanLeak(s)
+	if s.spanQuota != nil {
+		s.spanQuota.release(s.GetTraceID())
+	}
+	if s.collisionWarner != nil {
+		s.collisionWarner.forget(s.GetTraceID())
+	}
 	s.setSystemTag(ctx)
 	s.setStatInfo(ctx)
-	s.spanProcessor.OnSpanEnd(ctx, s)
+	s.setSLOInfo(ctx)
+	s.setEmbeddingCacheInfo(ctx)
+	s.setGroupInfo(ctx)
+	s.setGPUMetricsInfo(ctx)
+	s.checkModelDrift()
+	if s.dropUnlessError && s.GetStatusCode() == 0 {
+		logger.CtxDebugf(ctx, "span[%s] without error is dropped by error aware sampler", s.GetSpanName())
+		return
+	}
+	s.setSamplingWeightInfo(ctx)
+	if s.finishHook != nil {
+		s.finishHook.call(ctx, s)
+	}
+	s.spanProcessor.OnEnd(ctx, s)
 }
 
 func (s *Span) isDoFinish() bool {
@@ -895,6 +1934,61 @@ func (s *Span) setStatInfo(ctx context.Context) {
 	s.lock.Unlock()
 }
 
+// setSLOInfo compares duration with the latency budget set by SetLatencyBudget, or the default budget
+// of span type if not set, and tags the span if exceeded. Should be called after setStatInfo.
+func (s *Span) setSLOInfo(ctx context.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	budget := s.getIntTag(tracespec.SLOLatencyBudget)
+	if budget <= 0 {
+		defaultBudget, ok := s.latencyBudgets[s.SpanType]
+		if !ok || defaultBudget <= 0 {
+			return
+		}
+		budget = defaultBudget.Microseconds()
+		s.setTagItem(ctx, tracespec.SLOLatencyBudget, budget)
+	}
+	// Duration is in microseconds, see setStatInfo
+	if excess := int64(s.Duration) - budget; excess > 0 {
+		s.setTagItem(ctx, tracespec.SLOViolated, true)
+		s.setTagItem(ctx, tracespec.SLOExcessMicros, excess)
+	}
+}
+
+// setEmbeddingCacheInfo sets latency of cache if all embeddings are served from cache, that is from start to the
+// cache checkpoint, or the duration if checkpoint is not set.
+func (s *Span) setEmbeddingCacheInfo(ctx context.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if hit, _ := s.TagMap[tracespec.EmbeddingCacheHit].(bool); !hit || s.getIntTag(tracespec.EmbeddingRecomputedCount) != 0 {
+		return
+	}
+	// Duration is in microseconds, see setStatInfo
+	duration := int64(s.Duration)
+	if checkpoint := s.getIntTag(tracespec.EmbeddingCacheCheckpoint); checkpoint > 0 {
+		duration = checkpoint - s.StartTime.UnixMicro()
+	}
+	s.setTagItem(ctx, tracespec.EmbeddingCacheOnlyDuration, duration)
+}
+
+// checkModelDrift reports the model fingerprint of span to modelDriftDetector, if both model name
+// and fingerprint are set.
+func (s *Span) checkModelDrift() {
+	if s.modelDriftDetector == nil {
+		return
+	}
+	s.lock.RLock()
+	model, _ := s.TagMap[tracespec.ModelName].(string)
+	fingerprint, _ := s.TagMap[tracespec.ModelFingerprint].(string)
+	s.lock.RUnlock()
+	if model == "" || fingerprint == "" {
+		return
+	}
+	s.modelDriftDetector.observe(model, fingerprint)
+}
+
 func (s *Span) GetStartTime() time.Time {
 	if s == nil {
 		return time.Time{}
@@ -944,11 +2038,15 @@ func (s *Span) ToHeader() (map[string]string, error) {
 }
 
 func (s *Span) toHeaderBaggage() (string, error) {
-	if len(s.Baggage) == 0 {
-		return "", nil
+	return toHeaderBaggage(s.Baggage), nil
+}
+
+func toHeaderBaggage(baggage map[string]string) string {
+	if len(baggage) == 0 {
+		return ""
 	}
 	m := make(map[string]string)
-	for k, v := range s.Baggage {
+	for k, v := range baggage {
 		tempK := k
 		tempV := v
 		// empty key or value is invalid
@@ -956,7 +2054,7 @@ func (s *Span) toHeaderBaggage() (string, error) {
 			m[url.QueryEscape(tempK)] = url.QueryEscape(tempV)
 		}
 	}
-	return util.MapToStringString(m), nil
+	return util.MapToStringString(m)
 }
 
 func (s *Span) toHeaderParent() string {
@@ -995,7 +2093,8 @@ func (s *Span) SetLogID(ctx context.Context, logID string) {
 }
 
 func (s *Span) IsRootSpan() bool {
-	return s.ParentSpanID == "" || s.ParentSpanID == "0"
+	parentID := s.GetParentID()
+	return parentID == "" || parentID == "0"
 }
 
 // SetFinishTime
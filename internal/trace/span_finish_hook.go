@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+	"github.com/alva-ai/cozeloop-go/internal/util"
+)
+
+// spanFinishHook calls callbacks with the finished span, just before it is handed to the span processor.
+type spanFinishHook struct {
+	onFinish      func(span *entity.UploadSpan)
+	onFinishAsync func(span *entity.UploadSpan)
+}
+
+func newSpanFinishHook(onFinish, onFinishAsync func(span *entity.UploadSpan)) *spanFinishHook {
+	if onFinish == nil && onFinishAsync == nil {
+		return nil
+	}
+	return &spanFinishHook{
+		onFinish:      onFinish,
+		onFinishAsync: onFinishAsync,
+	}
+}
+
+// call converts span to UploadSpan as exported, and calls onFinish synchronously and onFinishAsync in
+// a new goroutine with a copy of it. Panics in callbacks are recovered and logged.
+func (h *spanFinishHook) call(ctx context.Context, s *Span) {
+	spans, _ := transferToUploadSpanAndFile(ctx, []*Span{s}, nil)
+	if len(spans) == 0 {
+		return
+	}
+	if h.onFinishAsync != nil {
+		span := CloneUploadSpan(spans[0])
+		util.GoSafe(ctx, func() {
+			h.onFinishAsync(span)
+		})
+	}
+	if h.onFinish != nil {
+		h.callSync(ctx, spans[0])
+	}
+}
+
+func (h *spanFinishHook) callSync(ctx context.Context, span *entity.UploadSpan) {
+	defer func() {
+		if e := recover(); e != nil {
+			const size = 64 << 10
+			buf := make([]byte, size)
+			buf = buf[:runtime.Stack(buf, false)]
+			logger.CtxErrorf(ctx, "span finish callback panic: %s: %s", e, buf)
+		}
+	}()
+	h.onFinish(span)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSpanFinishHook(t *testing.T) {
+	Convey("spanFinishHook", t, func() {
+		ctx := context.Background()
+		So(newSpanFinishHook(nil, nil), ShouldBeNil)
+
+		var finished []*entity.UploadSpan
+		asyncFinished := make(chan *entity.UploadSpan, 1)
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws"},
+			spanProcessor: noopSpanProcessor{},
+			finishHook: newSpanFinishHook(func(span *entity.UploadSpan) {
+				finished = append(finished, span)
+				span.TagsString["mutated"] = "true"
+				panic("callback panic")
+			}, func(span *entity.UploadSpan) {
+				asyncFinished <- span
+			}),
+		}
+
+		_, span, err := p.StartSpan(ctx, "llm", "model", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		span.SetTags(ctx, map[string]interface{}{"model_name": "gpt"})
+		span.SetStatusCode(ctx, 1)
+		So(func() { span.Finish(ctx) }, ShouldNotPanic)
+
+		So(len(finished), ShouldEqual, 1)
+		So(finished[0].SpanName, ShouldEqual, "llm")
+		So(finished[0].StatusCode, ShouldEqual, 1)
+		So(finished[0].TagsString["model_name"], ShouldEqual, "gpt")
+
+		select {
+		case asyncSpan := <-asyncFinished:
+			So(asyncSpan.SpanID, ShouldEqual, span.GetSpanID())
+			So(asyncSpan.TagsString, ShouldNotContainKey, "mutated")
+		case <-time.After(time.Second):
+			So("async callback is not called", ShouldBeEmpty)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	"github.com/alva-ai/cozeloop-go/internal/util"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+)
+
+// spanGroupSummary is the input of group span, a summary of names of grouped spans.
+type spanGroupSummary struct {
+	SpanCount int            `json:"span_count"`
+	SpanNames map[string]int `json:"span_names"` // span name -> count of spans
+}
+
+// GroupSpans creates a synthetic group span under the parent of spans, and moves spans under the group span.
+// Spans must be of the same trace and parent, and not finished. The group span is started by StartSpan as a
+// sibling of spans, so it is checked by span quota, follows the sampling of spans and inherits tags of the first
+// span, and nil is returned if it is dropped. The group span starts at the earliest start time of spans, and
+// its statistics of spans are set when it is finished, so finish it after all grouped spans.
+func (t *Provider) GroupSpans(ctx context.Context, groupName string, spans []*Span) (*Span, error) {
+	if len(spans) == 0 {
+		return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("no spans to group"))
+	}
+	first := spans[0]
+	startTime := time.Time{}
+	summary := spanGroupSummary{SpanNames: make(map[string]int)}
+	for _, span := range spans {
+		if span == nil {
+			return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("nil span to group"))
+		}
+		if span.GetTraceID() != first.GetTraceID() {
+			return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("span[%s] is not in trace[%s]", span.GetSpanID(), first.GetTraceID()))
+		}
+		if span.GetParentID() != first.GetParentID() {
+			return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("span[%s] is not under parent[%s]", span.GetSpanID(), first.GetParentID()))
+		}
+		if span.isSpanFinished() {
+			return nil, consts.ErrInvalidParam.Wrap(fmt.Errorf("span[%s] is already finished", span.GetSpanID()))
+		}
+		if start := span.GetStartTime(); startTime.IsZero() || start.Before(startTime) {
+			startTime = start
+		}
+		summary.SpanCount++
+		summary.SpanNames[span.GetSpanName()]++
+	}
+	if groupName == "" {
+		groupName = "group"
+	}
+
+	// the first span takes the place of parent span in ctx, so that the group span is a child span in StartSpan
+	_, group, err := t.StartSpan(context.WithValue(ctx, loopSpanKey{}, first), groupName, tracespec.VGroupSpanType, StartSpanOptions{
+		StartTime:    startTime,
+		ParentSpanID: first.GetParentID(),
+		TraceID:      first.GetTraceID(),
+		Baggage:      first.GetBaggage(),
+		WorkspaceID:  first.GetSpaceID(),
+	})
+	if err != nil || group == nil {
+		return nil, err
+	}
+	group.groupedSpans = append([]*Span(nil), spans...)
+	group.setLocalRoot(first.isLocalRoot())
+	group.SetTags(ctx, oneTag(tracespec.Input, util.ToJSON(summary)))
+	for _, span := range spans {
+		span.setParentID(group.GetSpanID())
+		span.setLocalRoot(false)
+	}
+	return group, nil
+}
+
+// setGroupInfo sets statistics of grouped spans on the group span, unfinished spans are counted with
+// the duration until now. Should be called after setStatInfo.
+func (s *Span) setGroupInfo(ctx context.Context) {
+	if len(s.groupedSpans) == 0 {
+		return
+	}
+	var errorCount, totalDuration, maxDuration int64
+	now := time.Now()
+	for _, span := range s.groupedSpans {
+		duration := span.GetDuration()
+		if !span.isSpanFinished() {
+			duration = now.Sub(span.GetStartTime()).Microseconds()
+		}
+		totalDuration += duration
+		if duration > maxDuration {
+			maxDuration = duration
+		}
+		if span.GetStatusCode() != 0 {
+			errorCount++
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.setTagItem(ctx, tracespec.GroupSpanCount, int64(len(s.groupedSpans)))
+	s.setTagItem(ctx, tracespec.GroupErrorCount, errorCount)
+	s.setTagItem(ctx, tracespec.GroupTotalDurationMicros, totalDuration)
+	s.setTagItem(ctx, tracespec.GroupMaxDurationMicros, maxDuration)
+}
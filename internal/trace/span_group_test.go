@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProvider_GroupSpans(t *testing.T) {
+	Convey("Provider.GroupSpans", t, func() {
+		ctx := context.Background()
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws"},
+			spanProcessor: noopSpanProcessor{},
+		}
+		rootCtx, root, err := p.StartSpan(ctx, "agent", "agent", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		start := time.Now().Add(-time.Second)
+		var tools []*Span
+		for _, name := range []string{"search", "search", "fetch"} {
+			_, tool, err := p.StartSpan(rootCtx, name, "tool", StartSpanOptions{StartTime: start})
+			So(err, ShouldBeNil)
+			tools = append(tools, tool)
+		}
+
+		Convey("should move spans under group span and set statistics on finish", func() {
+			group, err := p.GroupSpans(ctx, "tools", tools)
+			So(err, ShouldBeNil)
+			So(group.GetTraceID(), ShouldEqual, root.GetTraceID())
+			So(group.GetParentID(), ShouldEqual, root.GetSpanID())
+			So(group.GetSpanType(), ShouldEqual, tracespec.VGroupSpanType)
+			So(group.GetStartTime(), ShouldEqual, start)
+			So(group.GetTagMap()[tracespec.Input], ShouldEqual, `{"span_count":3,"span_names":{"fetch":1,"search":2}}`)
+			for _, tool := range tools {
+				So(tool.GetParentID(), ShouldEqual, group.GetSpanID())
+			}
+
+			tools[0].SetStatusCode(ctx, 1)
+			for _, tool := range tools {
+				tool.SetFinishTime(start.Add(100 * time.Millisecond))
+				tool.Finish(ctx)
+			}
+			tools[2].lock.Lock()
+			tools[2].Duration = 300000
+			tools[2].lock.Unlock()
+			group.Finish(ctx)
+
+			tags := group.GetTagMap()
+			So(tags[tracespec.GroupSpanCount], ShouldEqual, 3)
+			So(tags[tracespec.GroupErrorCount], ShouldEqual, 1)
+			So(tags[tracespec.GroupTotalDurationMicros], ShouldEqual, 500000)
+			So(tags[tracespec.GroupMaxDurationMicros], ShouldEqual, 300000)
+		})
+
+		Convey("should reject invalid spans", func() {
+			_, err := p.GroupSpans(ctx, "tools", nil)
+			So(err, ShouldNotBeNil)
+
+			_, other, _ := p.StartSpan(ctx, "other", "tool", StartSpanOptions{})
+			_, err = p.GroupSpans(ctx, "tools", []*Span{tools[0], other})
+			So(errors.Is(err, consts.ErrInvalidParam), ShouldBeTrue)
+
+			_, nested, _ := p.StartSpan(ctx, "nested", "tool", StartSpanOptions{TraceID: root.GetTraceID(), ParentSpanID: tools[0].GetSpanID()})
+			_, err = p.GroupSpans(ctx, "tools", []*Span{tools[0], nested})
+			So(errors.Is(err, consts.ErrInvalidParam), ShouldBeTrue)
+
+			tools[1].Finish(ctx)
+			_, err = p.GroupSpans(ctx, "tools", tools)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("should check group span by span quota", func() {
+			// spans started before are not counted
+			p.spanQuota = newSpanQuota(1)
+			_, err := p.GroupSpans(ctx, "tools", tools[:1])
+			So(err, ShouldBeNil)
+			group, err := p.GroupSpans(ctx, "tools", tools[1:])
+			So(err, ShouldBeNil)
+			So(group, ShouldBeNil)
+			So(tools[1].GetParentID(), ShouldEqual, root.GetSpanID())
+		})
+
+		Convey("should follow sampling of spans", func() {
+			tools[0].dropUnlessError = true
+			group, err := p.GroupSpans(ctx, "tools", tools)
+			So(err, ShouldBeNil)
+			So(group.dropUnlessError, ShouldBeTrue)
+			So(group.isLocalRoot(), ShouldBeFalse)
+		})
+	})
+}
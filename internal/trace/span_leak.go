@@ -0,0 +1,35 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+// trackSpanLeak captures the stack of StartSpan, and registers a finalizer on span which warns if span is
+// garbage-collected without Finish. The finalizer is removed on Finish.
+func trackSpanLeak(span *Span) {
+	span.startStack = debug.Stack()
+	runtime.SetFinalizer(span, warnSpanLeak)
+}
+
+func warnSpanLeak(s *Span) {
+	if s.isSpanFinished() {
+		return
+	}
+	logger.CtxWarnf(context.Background(), "span is garbage-collected without Finish, name: %s, type: %s, start time: %s, started at:\n%s",
+		s.GetSpanName(), s.GetSpanType(), s.GetStartTime().Format(time.RFC3339Nano), s.startStack)
+}
+
+// untrackSpanLeak removes the finalizer registered by trackSpanLeak.
+func untrackSpanLeak(span *Span) {
+	if span.startStack != nil {
+		runtime.SetFinalizer(span, nil)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type warnRecorder struct {
+	logger.Logger
+	mu    sync.Mutex
+	warns []string
+}
+
+func (r *warnRecorder) CtxWarnf(ctx context.Context, format string, v ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warns = append(r.warns, fmt.Sprintf(format, v...))
+}
+
+func (r *warnRecorder) getWarns() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.warns...)
+}
+
+func TestSpanLeakDetection(t *testing.T) {
+	Convey("span leak detection", t, func() {
+		ctx := context.Background()
+		recorder := &warnRecorder{Logger: logger.GetLogger()}
+		logger.SetLogger(recorder)
+		defer logger.SetLogger(recorder.Logger)
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws", LeakDetection: true},
+			spanProcessor: noopSpanProcessor{},
+		}
+		startSpans := func() {
+			_, leaked, _ := p.StartSpan(ctx, "leaked_span", "tool", StartSpanOptions{})
+			So(leaked.startStack, ShouldNotBeEmpty)
+			_, finished, _ := p.StartSpan(ctx, "finished_span", "tool", StartSpanOptions{})
+			finished.Finish(ctx)
+		}
+		startSpans()
+
+		var warns []string
+		for i := 0; i < 100 && len(warns) == 0; i++ {
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+			warns = recorder.getWarns()
+		}
+		So(warns, ShouldHaveLength, 1)
+		So(warns[0], ShouldContainSubstring, "name: leaked_span, type: tool")
+		So(warns[0], ShouldContainSubstring, "TestSpanLeakDetection")
+
+		Convey("stack is not captured if disabled", func() {
+			p.opt.LeakDetection = false
+			_, span, _ := p.StartSpan(ctx, "span", "tool", StartSpanOptions{})
+			So(span.startStack, ShouldBeNil)
+		})
+	})
+}
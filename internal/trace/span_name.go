@@ -0,0 +1,29 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TemplateSpanNameFormatter returns a span name formatter, which strips non-printable characters
+// and truncates span name to maxLen characters. maxLen <= 0 means no truncation.
+func TemplateSpanNameFormatter(maxLen int) func(name, spanType string) string {
+	return func(name, spanType string) string {
+		var sb strings.Builder
+		count := 0
+		for _, r := range name {
+			if !unicode.IsPrint(r) {
+				continue
+			}
+			if maxLen > 0 && count >= maxLen {
+				break
+			}
+			sb.WriteRune(r)
+			count++
+		}
+		return sb.String()
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+// spanNameCollisionWarner warns when spans of the same parent in a trace have the same name,
+// which makes the trace hard to read.
+type spanNameCollisionWarner struct {
+	traces sync.Map // trace id -> *sync.Map, seen spanNameKey of the trace
+}
+
+type spanNameKey struct {
+	parentID string
+	name     string
+}
+
+func newSpanNameCollisionWarner(enable bool) *spanNameCollisionWarner {
+	if !enable {
+		return nil
+	}
+	return &spanNameCollisionWarner{}
+}
+
+// observe records the name of span, and logs a warning if its parent already has a child with the same name.
+// It returns true if collision is detected.
+func (w *spanNameCollisionWarner) observe(ctx context.Context, traceID, parentID, name string) bool {
+	v, _ := w.traces.LoadOrStore(traceID, &sync.Map{})
+	if _, loaded := v.(*sync.Map).LoadOrStore(spanNameKey{parentID: parentID, name: name}, struct{}{}); !loaded {
+		return false
+	}
+	logger.CtxWarnf(ctx, "span name collision in trace[%s], parent span[%s] has more than one child named [%s], "+
+		"consider setting a distinguishing tag such as llm.call_index by SetTags", traceID, parentID, name)
+	return true
+}
+
+// forget removes the seen names of trace, called when the root span of trace finished.
+func (w *spanNameCollisionWarner) forget(traceID string) {
+	w.traces.Delete(traceID)
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSpanNameCollisionWarner(t *testing.T) {
+	Convey("spanNameCollisionWarner", t, func() {
+		ctx := context.Background()
+		So(newSpanNameCollisionWarner(false), ShouldBeNil)
+
+		Convey("should detect spans of the same parent with the same name", func() {
+			w := newSpanNameCollisionWarner(true)
+			So(w.observe(ctx, "trace1", "root", "llm_call"), ShouldBeFalse)
+			So(w.observe(ctx, "trace1", "root", "tool_call"), ShouldBeFalse)
+			So(w.observe(ctx, "trace1", "other", "llm_call"), ShouldBeFalse)
+			So(w.observe(ctx, "trace2", "root", "llm_call"), ShouldBeFalse)
+			So(w.observe(ctx, "trace1", "root", "llm_call"), ShouldBeTrue)
+		})
+
+		Convey("should forget names when local root span finished", func() {
+			p := &Provider{
+				opt:             &Options{WorkspaceID: "ws"},
+				spanProcessor:   noopSpanProcessor{},
+				collisionWarner: newSpanNameCollisionWarner(true),
+			}
+			rootCtx, root, err := p.StartSpan(ctx, "root", "test", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			_, child, err := p.StartSpan(rootCtx, "llm_call", "model", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			So(child.collisionWarner, ShouldBeNil)
+			So(p.collisionWarner.observe(ctx, root.GetTraceID(), root.GetSpanID(), "llm_call"), ShouldBeTrue)
+
+			child.Finish(ctx)
+			_, ok := p.collisionWarner.traces.Load(root.GetTraceID())
+			So(ok, ShouldBeTrue)
+			root.Finish(ctx)
+			_, ok = p.collisionWarner.traces.Load(root.GetTraceID())
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTemplateSpanNameFormatter(t *testing.T) {
+	Convey("TemplateSpanNameFormatter", t, func() {
+		Convey("should strip non-printable characters", func() {
+			So(TemplateSpanNameFormatter(0)("get\x00_user\n\t", "custom"), ShouldEqual, "get_user")
+		})
+
+		Convey("should truncate by characters", func() {
+			So(TemplateSpanNameFormatter(4)("调用模型接口", "model"), ShouldEqual, "调用模型")
+			So(TemplateSpanNameFormatter(10)("short", "model"), ShouldEqual, "short")
+		})
+
+		Convey("should be applied when starting span", func() {
+			p := &Provider{
+				opt: &Options{
+					WorkspaceID:       "ws",
+					SpanNameFormatter: TemplateSpanNameFormatter(8),
+				},
+				spanProcessor: noopSpanProcessor{},
+			}
+			_, span, err := p.StartSpan(context.Background(), "user_123456789", "custom", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			So(span.GetSpanName(), ShouldEqual, "user_123")
+		})
+	})
+}
@@ -14,13 +14,17 @@ package trace
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"sync/atomic"
 	"time"
 
 	"github.com/alva-ai/cozeloop-go/entity"
 	"github.com/alva-ai/cozeloop-go/internal/consts"
 	"github.com/alva-ai/cozeloop-go/internal/httpclient"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+	"github.com/alva-ai/cozeloop-go/internal/util"
 )
 
 // Defaults for batchQueueManagerOptions.
@@ -39,20 +43,61 @@ const (
 )
 
 type QueueConf struct {
-	SpanQueueLength          int
+	SpanQueueLength          int // rounded up to a power of two
 	SpanMaxExportBatchLength int
+	// OverflowStrategy is the behavior of Span.Finish when the span queue is full. Default is OverflowDrop.
+	OverflowStrategy OverflowStrategy
+	// PersistentQueue persists spans until they are acknowledged by exporter, see AckExporter.
+	// Spans persisted but not acknowledged are re-exported when the next client is created. Spans are persisted
+	// when their batch is exported, so spans still waiting in the span queue are lost if the process crashes.
+	// Default is nil.
+	PersistentQueue PersistentQueue
+	// TraceGroupMaxAge holds finished spans until the local root span of their trace is finished, so that spans of
+	// a trace are exported together. Traces held longer than it are exported without local root span.
+	// Default is 0, which means spans are batched regardless of trace.
+	TraceGroupMaxAge time.Duration
 }
 
 // LocalFileExportOptions configures local file export
 type LocalFileExportOptions struct {
 	Enabled  bool
 	FilePath string
+	// Debug enables printing span summaries to stderr by DebugExporter, in addition to other exporters
+	Debug bool
 }
 
 var _ SpanProcessor = (*BatchSpanProcessor)(nil)
 
+// ReadOnlySpan is the read-only view of a finished span, passed to SpanProcessor.OnEnd.
+type ReadOnlySpan interface {
+	GetSpanID() string
+	GetTraceID() string
+	GetParentID() string
+	GetSpanName() string
+	GetSpanType() string
+	GetSpaceID() string
+	GetStatusCode() int32
+	GetStartTime() time.Time
+	GetDuration() int64 // unit: microseconds
+	GetTagMap() map[string]interface{}
+	GetBaggage() map[string]string
+}
+
+// ReadWriteSpan is a started span which can be modified, passed to SpanProcessor.OnStart.
+type ReadWriteSpan interface {
+	ReadOnlySpan
+	SetTags(ctx context.Context, tagKVs map[string]interface{})
+	SetBaggage(ctx context.Context, baggageItems map[string]string)
+	SetStatusCode(ctx context.Context, code int)
+}
+
+// SpanProcessor is called when spans are started and finished. BatchSpanProcessor is the processor
+// exporting finished spans in batches, other processors can enrich spans on start or inspect them on end.
 type SpanProcessor interface {
-	OnSpanEnd(ctx context.Context, s *Span)
+	// OnStart is called synchronously when a span is started, before it is returned to the caller.
+	OnStart(ctx context.Context, s ReadWriteSpan)
+	// OnEnd is called synchronously when a span is finished, the span must not be modified.
+	OnEnd(ctx context.Context, s ReadOnlySpan)
 	Shutdown(ctx context.Context) error
 	ForceFlush(ctx context.Context) error
 }
@@ -64,6 +109,8 @@ func NewBatchSpanProcessor(
 	finishEventProcessor func(ctx context.Context, info *consts.FinishEventInfo),
 	queueConf *QueueConf,
 	localFileOpts *LocalFileExportOptions,
+	spanPooling bool,
+	exporterOpts ...ExporterOption,
 ) SpanProcessor {
 	var exporter Exporter
 	spanPath := pathIngestTrace
@@ -85,6 +132,11 @@ func NewBatchSpanProcessor(
 			fileUploadPath: filePath,
 		},
 	}
+	for _, opt := range exporterOpts {
+		if opt != nil {
+			opt(serverExporter)
+		}
+	}
 
 	// Determine the final exporter to use
 	if ex != nil {
@@ -98,9 +150,18 @@ func NewBatchSpanProcessor(
 		// Default: just use the server exporter
 		exporter = serverExporter
 	}
+	if localFileOpts != nil && localFileOpts.Debug {
+		exporter = NewMultiExporter(exporter, NewDebugExporter(os.Stderr, WithColors(isTerminal(os.Stderr))))
+	}
 	spanQueueLength := DefaultMaxQueueLength
 	spanMaxExportBatchLength := DefaultMaxExportBatchLength
+	var persistentQueue PersistentQueue
+	var overflowStrategy OverflowStrategy
+	var traceGroupMaxAge time.Duration
 	if queueConf != nil {
+		persistentQueue = queueConf.PersistentQueue
+		overflowStrategy = queueConf.OverflowStrategy
+		traceGroupMaxAge = queueConf.TraceGroupMaxAge
 		if queueConf.SpanQueueLength > 0 {
 			spanQueueLength = queueConf.SpanQueueLength
 		}
@@ -130,6 +191,7 @@ func NewBatchSpanProcessor(
 			finishEventProcessor:   finishEventProcessor,
 		})
 
+	spanPool := newUploadSpanPool(spanPooling)
 	spanRetryQM := newBatchQueueManager(
 		batchQueueManagerOptions{
 			queueName:              queueNameSpanRetry,
@@ -137,8 +199,13 @@ func NewBatchSpanProcessor(
 			maxQueueLength:         DefaultMaxRetryQueueLength,
 			maxExportBatchLength:   MaxRetryExportBatchLength,
 			maxExportBatchByteSize: DefaultMaxExportBatchByteSize,
-			exportFunc:             newExportSpansFunc(exporter, nil, fileQM, finishEventProcessor),
-			finishEventProcessor:   finishEventProcessor,
+			exportFunc:             newExportSpansFunc(exporter, nil, fileQM, persistentQueue, spanPool, finishEventProcessor),
+			dropFunc: func(ctx context.Context, item interface{}) {
+				if span, ok := item.(*Span); ok {
+					dropSpans(ctx, exporter, []*Span{span})
+				}
+			},
+			finishEventProcessor: finishEventProcessor,
 		})
 
 	spanQM := newBatchQueueManager(
@@ -146,12 +213,28 @@ func NewBatchSpanProcessor(
 			queueName:              queueNameSpan,
 			batchTimeout:           time.Duration(DefaultScheduleDelay) * time.Millisecond,
 			maxQueueLength:         spanQueueLength,
+			overflowStrategy:       overflowStrategy,
 			maxExportBatchLength:   spanMaxExportBatchLength,
 			maxExportBatchByteSize: DefaultMaxExportBatchByteSize,
-			exportFunc:             newExportSpansFunc(exporter, spanRetryQM, fileQM, finishEventProcessor),
+			traceGroupMaxAge:       traceGroupMaxAge,
+			exportFunc:             newExportSpansFunc(exporter, spanRetryQM, fileQM, persistentQueue, spanPool, finishEventProcessor),
 			finishEventProcessor:   finishEventProcessor,
 		})
 
+	if persistentQueue != nil {
+		// load before any span is exported, so that only spans left by previous process are re-exported,
+		// not spans saved by this process which are being exported concurrently
+		persisted, err := persistentQueue.Load(context.Background())
+		if err != nil {
+			logger.CtxWarnf(context.Background(), "load spans from persistent queue fail, err: %v", err)
+		}
+		if len(persisted) > 0 {
+			util.GoSafe(context.Background(), func() {
+				reexportPersistedSpans(context.Background(), exporter, persistentQueue, persisted, spanMaxExportBatchLength)
+			})
+		}
+	}
+
 	return &BatchSpanProcessor{
 		spanQM:      spanQM,
 		spanRetryQM: spanRetryQM,
@@ -160,7 +243,7 @@ func NewBatchSpanProcessor(
 	}
 }
 
-// BatchSpanProcessor implements SpanProcessor
+// BatchSpanProcessor implements SpanProcessor, which exports finished spans and their files in batches
 type BatchSpanProcessor struct {
 	spanQM      QueueManager
 	spanRetryQM QueueManager
@@ -172,12 +255,19 @@ type BatchSpanProcessor struct {
 	stopped int32
 }
 
-func (b *BatchSpanProcessor) OnSpanEnd(ctx context.Context, s *Span) {
+func (b *BatchSpanProcessor) OnStart(ctx context.Context, s ReadWriteSpan) {}
+
+// OnEnd enqueues the span to export, spans not started by cozeloop client are ignored.
+func (b *BatchSpanProcessor) OnEnd(ctx context.Context, s ReadOnlySpan) {
 	if atomic.LoadInt32(&b.stopped) != 0 {
 		return
 	}
+	span, ok := s.(*Span)
+	if !ok || span == nil {
+		return
+	}
 
-	b.spanQM.Enqueue(ctx, s, s.bytesSize)
+	b.spanQM.Enqueue(ctx, span, span.bytesSize)
 }
 
 func (b *BatchSpanProcessor) Shutdown(ctx context.Context) error {
@@ -219,6 +309,8 @@ func newExportSpansFunc(
 	exporter Exporter,
 	spanRetryQueue QueueManager,
 	fileQueue QueueManager,
+	persistentQueue PersistentQueue,
+	spanPool *uploadSpanPool,
 	finishEventProcessor func(ctx context.Context, info *consts.FinishEventInfo),
 ) exportFunc {
 	return func(ctx context.Context, l []interface{}) {
@@ -230,22 +322,46 @@ func newExportSpansFunc(
 		}
 		var errMsg string
 		var isFail bool
-		uploadSpans, uploadFiles := transferToUploadSpanAndFile(ctx, spans)
+		// spans are transferred one by one, so that files of delivered spans are known if export fails partially
+		uploadSpans := make([]*entity.UploadSpan, 0, len(spans))
+		uploadFiles := make(map[string][]*entity.UploadFile)
+		for _, span := range spans {
+			us, uf := transferToUploadSpanAndFile(ctx, []*Span{span}, spanPool)
+			if len(us) == 0 {
+				continue
+			}
+			uploadSpans = append(uploadSpans, us[0])
+			uploadFiles[uploadSpanKey(us[0])] = uf
+		}
+		if persistentQueue != nil {
+			if err := persistentQueue.Save(ctx, uploadSpans); err != nil {
+				logger.CtxWarnf(ctx, "save spans to persistent queue fail, err: %v", err)
+			}
+		}
 		before := time.Now()
-		err := exporter.ExportSpans(ctx, uploadSpans)
+		err := exportSpansWithAck(ctx, exporter, uploadSpans)
 		tsMs := time.Now().Sub(before).Milliseconds()
+		failedSpans, deliveredSpans := splitFailedSpans(err, spans, uploadSpans)
+		if len(deliveredSpans) > 0 && persistentQueue != nil {
+			if err := persistentQueue.Remove(ctx, deliveredSpans); err != nil {
+				logger.CtxWarnf(ctx, "remove spans from persistent queue fail, err: %v", err)
+			}
+		}
 		if err != nil { // fail, send to retry queue.
 			if spanRetryQueue != nil {
-				for _, span := range spans {
+				for _, span := range failedSpans {
 					spanRetryQueue.Enqueue(ctx, span, span.bytesSize)
 				}
 				errMsg = fmt.Sprintf("%v, retry later", err.Error())
 			} else {
+				dropSpans(ctx, exporter, failedSpans)
 				errMsg = fmt.Sprintf("%v, retry second time failed", err.Error())
 			}
 			isFail = true
-		} else { // success, send to file queue.
-			for _, file := range uploadFiles {
+		}
+		// files of delivered spans are sent to file queue.
+		for _, uploadSpan := range deliveredSpans {
+			for _, file := range uploadFiles[uploadSpanKey(uploadSpan)] {
 				if file == nil {
 					continue
 				}
@@ -265,6 +381,61 @@ func newExportSpansFunc(
 				},
 			})
 		}
+		if !isFail {
+			// spans are delivered, reuse them for the next batch
+			spanPool.put(uploadSpans)
+		}
+	}
+}
+
+// splitFailedSpans returns spans to retry and upload spans delivered by the result of export. Only spans in
+// PartialExportError are retried if export fails partially.
+func splitFailedSpans(err error, spans []*Span, uploadSpans []*entity.UploadSpan) ([]*Span, []*entity.UploadSpan) {
+	if err == nil {
+		return nil, uploadSpans
+	}
+	var partialErr *PartialExportError
+	if !errors.As(err, &partialErr) {
+		return spans, nil
+	}
+	failedKeys := make(map[string]bool, len(partialErr.FailedSpans))
+	for _, span := range partialErr.FailedSpans {
+		if span != nil {
+			failedKeys[uploadSpanKey(span)] = true
+		}
+	}
+	failed := make([]*Span, 0, len(failedKeys))
+	for _, span := range spans {
+		if failedKeys[span.GetTraceID()+"/"+span.GetSpanID()] {
+			failed = append(failed, span)
+		}
+	}
+	delivered := make([]*entity.UploadSpan, 0, len(uploadSpans))
+	for _, uploadSpan := range uploadSpans {
+		if !failedKeys[uploadSpanKey(uploadSpan)] {
+			delivered = append(delivered, uploadSpan)
+		}
+	}
+	return failed, delivered
+}
+
+// reexportPersistedSpans exports spans loaded from persistent queue, which are left by previous process, and
+// removes them once acknowledged. Spans failed to export are kept for the next time.
+func reexportPersistedSpans(ctx context.Context, exporter Exporter, persistentQueue PersistentQueue, spans []*entity.UploadSpan, batchLength int) {
+	logger.CtxInfof(ctx, "re-export %d spans from persistent queue", len(spans))
+	for start := 0; start < len(spans); start += batchLength {
+		end := start + batchLength
+		if end > len(spans) {
+			end = len(spans)
+		}
+		batch := spans[start:end]
+		if err := exportSpansWithAck(ctx, exporter, batch); err != nil {
+			logger.CtxWarnf(ctx, "re-export spans from persistent queue fail, err: %v", err)
+			continue
+		}
+		if err := persistentQueue.Remove(ctx, batch); err != nil {
+			logger.CtxWarnf(ctx, "remove spans from persistent queue fail, err: %v", err)
+		}
 	}
 }
 
@@ -287,7 +458,7 @@ func newExportFilesFunc(
 		tsMs := time.Now().Sub(before).Milliseconds()
 		if err != nil {
 			if fileRetryQueue != nil {
-				for _, bat := range files {
+				for _, bat := range failedFiles(err, files) {
 					fileRetryQueue.Enqueue(ctx, bat, int64(len(bat.Data)))
 				}
 				errMsg = fmt.Sprintf("%v, retry later", err.Error())
@@ -309,3 +480,25 @@ func newExportFilesFunc(
 		}
 	}
 }
+
+// failedFiles returns files to retry by the error of export, which are the files in PartialExportError
+// if export fails partially.
+func failedFiles(err error, files []*entity.UploadFile) []*entity.UploadFile {
+	var partialErr *PartialExportError
+	if !errors.As(err, &partialErr) {
+		return files
+	}
+	failedKeys := make(map[string]bool, len(partialErr.FailedFiles))
+	for _, file := range partialErr.FailedFiles {
+		if file != nil {
+			failedKeys[file.TosKey] = true
+		}
+	}
+	failed := make([]*entity.UploadFile, 0, len(failedKeys))
+	for _, file := range files {
+		if failedKeys[file.TosKey] {
+			failed = append(failed, file)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// recordQueueManager records enqueued items instead of exporting them
+type recordQueueManager struct {
+	items []interface{}
+}
+
+func (q *recordQueueManager) Enqueue(ctx context.Context, s interface{}, byteSize int64) {
+	q.items = append(q.items, s)
+}
+
+func (q *recordQueueManager) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (q *recordQueueManager) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// dropRecordExporter records spans dropped by span processor
+type dropRecordExporter struct {
+	funcExporter
+	dropped []string
+}
+
+func (e *dropRecordExporter) dropSpans(ctx context.Context, spans []*entity.UploadSpan) {
+	for _, span := range spans {
+		e.dropped = append(e.dropped, uploadSpanKey(span))
+	}
+}
+
+func TestExportFuncPartialExportError(t *testing.T) {
+	Convey("export funcs with PartialExportError", t, func() {
+		ctx := context.Background()
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws"},
+			spanProcessor: noopSpanProcessor{},
+		}
+		_, first, err := p.StartSpan(ctx, "first", "custom", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		_, second, err := p.StartSpan(ctx, "second", "custom", StartSpanOptions{})
+		So(err, ShouldBeNil)
+
+		Convey("should retry only failed spans", func() {
+			retryQueue := &recordQueueManager{}
+			exporter := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+				return &PartialExportError{Err: errors.New("bad gateway"), FailedSpans: spans[1:]}
+			}}
+			export := newExportSpansFunc(exporter, retryQueue, nil, nil, nil, nil)
+			export(ctx, []interface{}{first, second})
+			So(retryQueue.items, ShouldResemble, []interface{}{second})
+		})
+
+		Convey("should retry all spans on other errors", func() {
+			retryQueue := &recordQueueManager{}
+			exporter := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+				return errors.New("bad gateway")
+			}}
+			export := newExportSpansFunc(exporter, retryQueue, nil, nil, nil, nil)
+			export(ctx, []interface{}{first, second})
+			So(retryQueue.items, ShouldResemble, []interface{}{first, second})
+		})
+
+		Convey("should notify exporter of spans dropped after retry", func() {
+			exporter := &dropRecordExporter{funcExporter: funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+				return &PartialExportError{Err: errors.New("bad gateway"), FailedSpans: spans[1:]}
+			}}}
+			export := newExportSpansFunc(exporter, nil, nil, nil, nil, nil)
+			export(ctx, []interface{}{first, second})
+			So(exporter.dropped, ShouldResemble, []string{uploadSpanKey(&entity.UploadSpan{TraceID: second.GetTraceID(), SpanID: second.GetSpanID()})})
+		})
+
+		Convey("should retry only failed files", func() {
+			files := []*entity.UploadFile{{TosKey: "key1"}, {TosKey: "key2"}}
+			retryQueue := &recordQueueManager{}
+			exporter := &mockExporter{exportFilesErr: &PartialExportError{
+				Err:         errors.New("bad gateway"),
+				FailedFiles: []*entity.UploadFile{{TosKey: "key2"}},
+			}}
+			export := newExportFilesFunc(exporter, retryQueue, nil)
+			export(ctx, []interface{}{files[0], files[1]})
+			So(retryQueue.items, ShouldResemble, []interface{}{files[1]})
+		})
+	})
+}
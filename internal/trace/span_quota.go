@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"sync"
+)
+
+// spanQuota limits the number of open spans in one trace, which protects against runaway recursion.
+type spanQuota struct {
+	maxSpans int64
+	mu       sync.Mutex
+	counts   map[string]int64 // trace id -> count of open spans, removed when it drops to 0
+}
+
+func newSpanQuota(maxSpans int) *spanQuota {
+	if maxSpans <= 0 {
+		return nil
+	}
+	return &spanQuota{
+		maxSpans: int64(maxSpans),
+		counts:   make(map[string]int64),
+	}
+}
+
+// acquire occupies one span of the trace quota. It returns the count of open spans
+// including the new one, and false if the count exceeds the limit.
+func (q *spanQuota) acquire(traceID string) (int64, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	count := q.counts[traceID] + 1
+	if count > q.maxSpans {
+		return count, false
+	}
+	q.counts[traceID] = count
+	return count, true
+}
+
+// release gives back one span of the trace quota, called when span finished.
+// Count and removal are updated under the same lock as acquire, so no concurrent acquire is lost.
+func (q *spanQuota) release(traceID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	count, ok := q.counts[traceID]
+	if !ok {
+		return
+	}
+	if count <= 1 {
+		delete(q.counts, traceID)
+		return
+	}
+	q.counts[traceID] = count - 1
+}
+
+func (q *spanQuota) openSpans(traceID string) int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.counts[traceID]
+}
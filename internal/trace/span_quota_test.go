@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// noopSpanProcessor is a SpanProcessor which drops all spans, for testing
+type noopSpanProcessor struct{}
+
+func (p noopSpanProcessor) OnStart(ctx context.Context, s ReadWriteSpan) {}
+func (p noopSpanProcessor) OnEnd(ctx context.Context, s ReadOnlySpan)    {}
+func (p noopSpanProcessor) Shutdown(ctx context.Context) error           { return nil }
+func (p noopSpanProcessor) ForceFlush(ctx context.Context) error         { return nil }
+
+func TestSpanQuota(t *testing.T) {
+	Convey("spanQuota", t, func() {
+		Convey("non-positive limit should disable quota", func() {
+			So(newSpanQuota(0), ShouldBeNil)
+			So(newSpanQuota(-1), ShouldBeNil)
+		})
+
+		Convey("should reject when open spans exceed limit", func() {
+			q := newSpanQuota(2)
+			count, ok := q.acquire("trace1")
+			So(ok, ShouldBeTrue)
+			So(count, ShouldEqual, 1)
+			_, ok = q.acquire("trace1")
+			So(ok, ShouldBeTrue)
+			count, ok = q.acquire("trace1")
+			So(ok, ShouldBeFalse)
+			So(count, ShouldEqual, 3)
+			So(q.openSpans("trace1"), ShouldEqual, 2)
+
+			// other trace is not affected
+			_, ok = q.acquire("trace2")
+			So(ok, ShouldBeTrue)
+		})
+
+		Convey("release should free quota and clean up trace", func() {
+			q := newSpanQuota(1)
+			_, ok := q.acquire("trace1")
+			So(ok, ShouldBeTrue)
+			q.release("trace1")
+			So(q.openSpans("trace1"), ShouldEqual, 0)
+			_, ok = q.acquire("trace1")
+			So(ok, ShouldBeTrue)
+		})
+
+		Convey("concurrent acquire and release should not lose count", func() {
+			q := newSpanQuota(1000)
+			wg := sync.WaitGroup{}
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 200; j++ {
+						if _, ok := q.acquire("trace1"); ok {
+							q.release("trace1")
+						}
+					}
+				}()
+			}
+			_, ok := q.acquire("trace1")
+			So(ok, ShouldBeTrue)
+			wg.Wait()
+			So(q.openSpans("trace1"), ShouldEqual, 1)
+			q.release("trace1")
+			So(q.counts, ShouldBeEmpty)
+		})
+	})
+}
+
+// countSampler counts calls of ShouldSample and keeps all spans, for testing
+type countSampler struct {
+	calls int
+}
+
+func (s *countSampler) ShouldSample(ctx context.Context, traceID, spanName, spanType string) bool {
+	s.calls++
+	return true
+}
+
+func TestProvider_StartSpanWithQuota(t *testing.T) {
+	Convey("Provider.StartSpan with max spans per trace", t, func() {
+		ctx := context.Background()
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws"},
+			spanProcessor: noopSpanProcessor{},
+			spanQuota:     newSpanQuota(2),
+		}
+
+		rootCtx, root, err := p.StartSpan(ctx, "root", "test", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		So(root, ShouldNotBeNil)
+		childCtx, child, err := p.StartSpan(rootCtx, "child", "test", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		So(child, ShouldNotBeNil)
+
+		Convey("nested span exceeding limit should be dropped", func() {
+			_, grandChild, err := p.StartSpan(childCtx, "grand_child", "test", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			So(grandChild, ShouldBeNil)
+		})
+
+		Convey("span exceeding limit should be dropped before side effects", func() {
+			sampler := &countSampler{}
+			p.opt.Sampler = sampler
+			p.opt.InheritedTagKeys = []string{"tenant"}
+			p.cardinalityLimiter = newCardinalityLimiter(10)
+			child.SetTags(ctx, map[string]interface{}{"tenant": "t1"})
+			limiterKeys := len(p.cardinalityLimiter.keys)
+
+			_, grandChild, err := p.StartSpan(childCtx, "grand_child", "test", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			So(grandChild, ShouldBeNil)
+			_, newRoot, err := p.StartSpan(ctx, "new_root", "test", StartSpanOptions{TraceID: root.GetTraceID(), StartNewTrace: true})
+			So(err, ShouldBeNil)
+			So(newRoot, ShouldBeNil)
+			So(sampler.calls, ShouldEqual, 0)
+			So(len(p.cardinalityLimiter.keys), ShouldEqual, limiterKeys)
+		})
+
+		Convey("sequential spans should be allowed after finish", func() {
+			child.Finish(ctx)
+			_, sibling, err := p.StartSpan(rootCtx, "sibling", "test", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			So(sibling, ShouldNotBeNil)
+			So(sibling.GetTraceID(), ShouldEqual, root.GetTraceID())
+		})
+	})
+}
@@ -5,10 +5,12 @@ package trace
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -129,7 +131,7 @@ func Test_Finish(t *testing.T) {
 	httpClient := httpclient.NewClient("", nil, nil, nil)
 	s := &Span{
 		isFinished:    0,
-		spanProcessor: NewBatchSpanProcessor(nil, httpClient, nil, nil, nil, nil),
+		spanProcessor: NewBatchSpanProcessor(nil, httpClient, nil, nil, nil, nil, false),
 		lock:          sync.RWMutex{},
 		TagMap:        make(map[string]interface{}),
 	}
@@ -152,7 +154,7 @@ func Test_Finish(t *testing.T) {
 			consts.StartTimeFirstResp: time.Now().UnixMilli(),
 			tracespec.InputTokens:     101,
 		})
-		Mock(GetMethod(s.spanProcessor, "OnSpanEnd")).Return().Build()
+		Mock(GetMethod(s.spanProcessor, "OnEnd")).Return().Build()
 		s.Finish(ctx)
 		So(s.Duration, ShouldBeGreaterThan, 0)
 		So(s.GetTagMap()[consts.LatencyFirstResp], ShouldBeGreaterThan, 0)
@@ -303,3 +305,899 @@ func getImageBytes(url string) (string, error) {
 
 	return string(imageData), nil
 }
+
+func Test_SetSystemPrompt(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test system prompt is stored apart from input", t, func() {
+		s := newMockSpan()
+		_, ok := s.GetSystemPrompt(ctx)
+		So(ok, ShouldBeFalse)
+
+		s.SetSystemPrompt(ctx, "You are a helpful assistant.")
+		s.SetInput(ctx, "hello")
+		prompt, ok := s.GetSystemPrompt(ctx)
+		So(ok, ShouldBeTrue)
+		So(prompt, ShouldEqual, "You are a helpful assistant.")
+		So(s.GetTagMap()[tracespec.Input], ShouldEqual, "hello")
+	})
+
+	Convey("Test long system prompt is truncated", t, func() {
+		s := newMockSpan()
+		s.SystemTagMap = make(map[string]interface{})
+		s.SetSystemPrompt(ctx, strings.Repeat("a", consts.MaxBytesOfOneTagValueDefault+100))
+		prompt, ok := s.GetSystemPrompt(ctx)
+		So(ok, ShouldBeTrue)
+		So(len(prompt), ShouldBeLessThanOrEqualTo, consts.MaxBytesOfOneTagValueDefault)
+	})
+}
+
+func Test_SpanCacheTags(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test cache tags and effective cost", t, func() {
+		s := newMockSpan()
+		s.SetCacheHit(ctx, true)
+		s.SetInputTokens(ctx, 100)
+		s.SetCachedInputTokens(ctx, 60)
+		s.SetCacheReadInputTokens(ctx, 20)
+		s.SetOutputTokens(ctx, 10)
+
+		tags := s.GetTagMap()
+		So(tags[tracespec.CacheHit], ShouldEqual, true)
+		So(tags[tracespec.CachedInputTokens], ShouldEqual, 60)
+		So(tags[tracespec.CacheReadInputTokens], ShouldEqual, 20)
+		So(s.EffectiveCost(0.5, 0), ShouldEqual, 25)
+
+		s.SetCachedInputTokens(ctx, 200)
+		So(s.EffectiveCost(1, 0), ShouldEqual, 10)
+
+		s.SetReasoningTokens(ctx, 4)
+		So(s.GetTagMap()[tracespec.LLMReasoningTokens], ShouldEqual, 4)
+		So(s.EffectiveCost(1, 2), ShouldEqual, 14)
+	})
+}
+
+func Test_SetPromptCacheTokens(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test prompt cache tokens and discount of model", t, func() {
+		s := newMockSpan()
+		s.cacheDiscounts = map[string]float64{"claude": 0.5}
+		s.SetInputTokens(ctx, 100)
+		s.SetOutputTokens(ctx, 10)
+		s.SetPromptCacheTokens(ctx, 30, 40)
+
+		tags := s.GetTagMap()
+		So(tags[tracespec.CacheCreationTokens], ShouldEqual, 30)
+		So(tags[tracespec.CacheReadTokens], ShouldEqual, 40)
+		So(s.EffectiveCost(1, 0), ShouldEqual, 110)
+
+		s.SetModelName(ctx, "claude")
+		So(s.EffectiveCost(1, 0), ShouldEqual, 90)
+
+		s.cacheDiscounts["claude"] = 10
+		So(s.EffectiveCost(1, 0), ShouldEqual, 0)
+	})
+}
+
+func Test_SetSelfConsistencyVotes(t *testing.T) {
+	ctx := context.Background()
+	votes := []tracespec.SelfConsistencyVote{
+		{Answer: "41", Count: 1},
+		{Answer: "42", Count: 2},
+		{Answer: "43", Count: 1},
+		{Answer: "42", Count: 1},
+	}
+
+	Convey("Test votes are summarized", t, func() {
+		s := newMockSpan()
+		s.SetSelfConsistencyVotes(ctx, votes)
+
+		tags := s.GetTagMap()
+		So(tags[tracespec.SCUniqueAnswers], ShouldEqual, 3)
+		So(tags[tracespec.SCTotalVotes], ShouldEqual, 5)
+		So(tags[tracespec.SCMajorityAnswer], ShouldEqual, "42")
+		So(tags[tracespec.SCMajorityConfidence], ShouldEqual, 0.6)
+		So(tags[tracespec.SCVotes], ShouldEqual, `[{"answer":"42","count":3},{"answer":"41","count":1},{"answer":"43","count":1}]`)
+		So(votes[1].Count, ShouldEqual, 2)
+	})
+
+	Convey("Test least voted answers exceeding size limit are dropped", t, func() {
+		s := newMockSpan()
+		s.tagTruncateConf = &TagTruncateConf{NormalFieldMaxByte: 60}
+		s.SetSelfConsistencyVotes(ctx, votes)
+		So(s.GetTagMap()[tracespec.SCVotes], ShouldEqual, `[{"answer":"42","count":3},{"answer":"41","count":1}]`)
+		So(s.GetTagMap()[tracespec.SCUniqueAnswers], ShouldEqual, 3)
+	})
+}
+
+func Test_SetPlanningStep(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test root node has no parent tag", t, func() {
+		s := newMockSpan()
+		s.SetPlanningStep(ctx, tracespec.PlanningStep{NodeID: "root", Score: 0.5, SelectedForExpansion: true})
+
+		tags := s.GetTagMap()
+		So(tags[tracespec.PlanNodeID], ShouldEqual, "root")
+		So(tags[tracespec.PlanDepth], ShouldEqual, 0)
+		So(tags[tracespec.PlanScore], ShouldEqual, 0.5)
+		So(tags[tracespec.PlanPruned], ShouldBeFalse)
+		So(tags[tracespec.PlanSelectedForExpansion], ShouldBeTrue)
+		So(tags, ShouldNotContainKey, tracespec.PlanParentNodeID)
+	})
+
+	Convey("Test child node", t, func() {
+		s := newMockSpan()
+		s.SetPlanningStep(ctx, tracespec.PlanningStep{NodeID: "a", ParentNodeID: "root", Depth: 1, Pruned: true})
+		So(s.GetTagMap()[tracespec.PlanParentNodeID], ShouldEqual, "root")
+		So(s.GetTagMap()[tracespec.PlanPruned], ShouldBeTrue)
+	})
+}
+
+func Test_SetEmbeddingCacheResult(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test cache only duration is from start to checkpoint", t, func() {
+		s := newMockSpan()
+		s.spanProcessor = noopSpanProcessor{}
+		s.SetEmbeddingCacheResult(ctx, true, 3, 0)
+		s.SetCacheCheckpointTime(ctx, s.StartTime.Add(3*time.Millisecond))
+		s.Finish(ctx)
+
+		tags := s.GetTagMap()
+		So(tags[tracespec.EmbeddingCacheHit], ShouldEqual, true)
+		So(tags[tracespec.EmbeddingCachedCount], ShouldEqual, 3)
+		So(tags[tracespec.EmbeddingRecomputedCount], ShouldEqual, 0)
+		So(tags[tracespec.EmbeddingCacheOnlyDuration], ShouldEqual, 3000)
+	})
+
+	Convey("Test cache only duration is the duration without checkpoint", t, func() {
+		s := newMockSpan()
+		s.spanProcessor = noopSpanProcessor{}
+		s.SetEmbeddingCacheResult(ctx, true, 3, 0)
+		s.FinishTime = s.StartTime.Add(5 * time.Millisecond)
+		s.Finish(ctx)
+		So(s.GetTagMap()[tracespec.EmbeddingCacheOnlyDuration], ShouldEqual, 5000)
+	})
+
+	Convey("Test cache only duration is not set if any embedding is recomputed", t, func() {
+		s := newMockSpan()
+		s.spanProcessor = noopSpanProcessor{}
+		s.SetEmbeddingCacheResult(ctx, true, 3, 1)
+		s.Finish(ctx)
+		So(s.GetTagMap(), ShouldNotContainKey, tracespec.EmbeddingCacheOnlyDuration)
+	})
+}
+
+func Test_SetTokenUsageByPart(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test tokens by part and total input tokens", t, func() {
+		s := newMockSpan()
+		s.SetInputTokens(ctx, 1)
+		s.SetTokenUsageByPart(ctx, []tracespec.TokenUsagePart{
+			{Part: tracespec.VTokenPartSystem, Tokens: 100},
+			{Part: tracespec.VTokenPartUser, Tokens: 20},
+			{Part: tracespec.VTokenPartToolCall, Tokens: 5},
+		})
+		tags := s.GetTagMap()
+		So(tags[tracespec.InputTokens], ShouldEqual, 125)
+		So(tags[tracespec.TokensByPart], ShouldEqual, `[{"part":"system","tokens":100},{"part":"user","tokens":20},{"part":"tool_call","tokens":5}]`)
+	})
+}
+
+func Test_SetFallbackModel(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test fallback model keeps the primary model", t, func() {
+		s := newMockSpan()
+		s.SetModelName(ctx, "gpt-4")
+		s.SetFallbackModel(ctx, "gpt-3.5-turbo", "openai", tracespec.VFallbackReasonRateLimited)
+		s.SetFallbackModel(ctx, "claude", "", tracespec.VFallbackReasonModelUnavailable)
+
+		tags := s.GetTagMap()
+		So(tags[tracespec.ModelName], ShouldEqual, "gpt-4")
+		So(tags[tracespec.PrimaryModel], ShouldEqual, "gpt-4")
+		So(tags[tracespec.FallbackModel], ShouldEqual, "claude")
+		So(tags[tracespec.FallbackProvider], ShouldEqual, "openai")
+		So(tags[tracespec.FallbackReason], ShouldEqual, "model_unavailable")
+	})
+
+	Convey("Test cost uses discount of fallback model", t, func() {
+		s := newMockSpan()
+		s.cacheDiscounts = map[string]float64{"gpt-4": 0.5, "claude": 0.25}
+		s.SetModelName(ctx, "gpt-4")
+		s.SetInputTokens(ctx, 100)
+		s.SetPromptCacheTokens(ctx, 0, 40)
+		So(s.EffectiveCost(1, 0), ShouldEqual, 80)
+
+		s.SetFallbackModel(ctx, "claude", "anthropic", tracespec.VFallbackReasonLatencyBudgetExceeded)
+		So(s.EffectiveCost(1, 0), ShouldEqual, 90)
+	})
+}
+
+func Test_Annotate(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test annotations are recorded in order", t, func() {
+		s := newMockSpan()
+		s.Annotate(ctx, "first")
+		s.Annotate(ctx, "second")
+
+		annotations := s.GetAnnotations()
+		So(len(annotations), ShouldEqual, 2)
+		So(annotations[0].Message, ShouldEqual, "first")
+		So(annotations[1].Message, ShouldEqual, "second")
+		So(annotations[0].Timestamp, ShouldBeGreaterThan, 0)
+	})
+}
+
+func Test_SetHTTPBody(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test http body is redacted by default", t, func() {
+		s := newMockSpan()
+		s.SetHTTPRequestBody(ctx, []byte(`{"token":"abc"}`), "application/json")
+		s.SetHTTPResponseBody(ctx, []byte(`{"secret":"abc"}`), 200)
+
+		So(s.GetTagMap()[tracespec.Input], ShouldEqual, `{"token":"[REDACTED]"}`)
+		So(s.GetTagMap()[tracespec.Output], ShouldEqual, `{"secret":"[REDACTED]"}`)
+		So(s.GetTagMap()[tracespec.HTTPRequestContentType], ShouldEqual, "application/json")
+		So(s.GetTagMap()[tracespec.HTTPStatusCode], ShouldEqual, 200)
+	})
+
+	Convey("Test http body is redacted by custom redactor", t, func() {
+		s := newMockSpan()
+		s.httpBodyRedactor = func(body []byte, contentType string) []byte {
+			return []byte("redacted")
+		}
+		s.SetHTTPRequestBody(ctx, []byte(`{"token":"abc"}`), "")
+		So(s.GetTagMap()[tracespec.Input], ShouldEqual, "redacted")
+	})
+}
+
+func Test_CheckBudget(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test budget is not exceeded", t, func() {
+		s := newMockSpan()
+		s.SetTokenBudget(ctx, 100, 0)
+		s.SetInputTokens(ctx, 40)
+		s.SetOutputTokens(ctx, 1000)
+
+		inputRemaining, outputRemaining, exceeded := s.CheckBudget(ctx)
+		So(inputRemaining, ShouldEqual, 60)
+		So(outputRemaining, ShouldEqual, 0)
+		So(exceeded, ShouldBeFalse)
+		So(s.GetTagMap(), ShouldNotContainKey, tracespec.BudgetExceeded)
+		So(s.StatusCode, ShouldEqual, 0)
+	})
+
+	Convey("Test budget is exceeded", t, func() {
+		s := newMockSpan()
+		s.SetTokenBudget(ctx, 100, 10)
+		s.SetInputTokens(ctx, 40)
+		s.SetOutputTokens(ctx, 20)
+
+		inputRemaining, outputRemaining, exceeded := s.CheckBudget(ctx)
+		So(inputRemaining, ShouldEqual, 60)
+		So(outputRemaining, ShouldEqual, -10)
+		So(exceeded, ShouldBeTrue)
+		So(s.GetTagMap()[tracespec.BudgetExceeded], ShouldEqual, true)
+		So(s.StatusCode, ShouldNotEqual, 0)
+	})
+}
+
+func Test_SetModelParameters(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test only non-zero parameters are set", t, func() {
+		s := newMockSpan()
+		s.SetModelParameters(ctx, tracespec.ModelParameters{
+			Temperature: 0.7,
+			MaxTokens:   1024,
+			Stop:        []string{"\n\n", "END"},
+		})
+		tagMap := s.GetTagMap()
+		So(tagMap[tracespec.Temperature], ShouldEqual, 0.7)
+		So(tagMap[tracespec.MaxTokens], ShouldEqual, 1024)
+		So(tagMap[tracespec.StopSequences], ShouldEqual, `["\n\n","END"]`)
+		So(tagMap, ShouldNotContainKey, tracespec.TopP)
+		So(tagMap, ShouldNotContainKey, tracespec.FrequencyPenalty)
+		So(tagMap, ShouldNotContainKey, tracespec.PresencePenalty)
+	})
+}
+
+func Test_SetCitations(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test citations are stored as JSON array", t, func() {
+		s := newMockSpan()
+		s.SetCitations(ctx, []tracespec.Citation{
+			{DocumentID: "doc1", Source: "https://example.com/doc1", RelevanceScore: 0.9, Excerpt: "excerpt"},
+		})
+		So(s.GetTagMap()[tracespec.RAGCitations], ShouldEqual,
+			`[{"document_id":"doc1","source":"https://example.com/doc1","relevance_score":0.9,"excerpt":"excerpt"}]`)
+	})
+
+	Convey("Test empty citations", t, func() {
+		s := newMockSpan()
+		s.SetCitations(ctx, nil)
+		So(s.GetTagMap()[tracespec.RAGCitations], ShouldEqual, "[]")
+	})
+}
+
+func Test_TagKeyValidator(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test tags with invalid key are rejected", t, func() {
+		s := newMockSpan()
+		s.tagKeyValidator = SnakeCaseValidator()
+		tags := map[string]interface{}{"user_name": "bob", "userName": "bob", "user name": "bob"}
+		s.SetTags(ctx, tags)
+		s.SetModelName(ctx, "gpt")
+
+		So(s.GetTagMap()["user_name"], ShouldEqual, "bob")
+		So(s.GetTagMap(), ShouldNotContainKey, "userName")
+		So(s.GetTagMap(), ShouldNotContainKey, "user name")
+		So(s.GetTagMap()[tracespec.ModelName], ShouldEqual, "gpt")
+		So(len(tags), ShouldEqual, 3)
+	})
+}
+
+func Test_LatencyBudget(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test latency budget is exceeded", t, func() {
+		s := newMockSpan()
+		s.spanProcessor = noopSpanProcessor{}
+		s.SetLatencyBudget(ctx, 2*time.Second)
+		s.SetFinishTime(s.StartTime.Add(3 * time.Second))
+		s.Finish(ctx)
+		So(s.GetTagMap()[tracespec.SLOLatencyBudget], ShouldEqual, int64(2000000))
+		So(s.GetTagMap()[tracespec.SLOViolated], ShouldEqual, true)
+		So(s.GetTagMap()[tracespec.SLOExcessMicros], ShouldEqual, int64(1000000))
+	})
+
+	Convey("Test default latency budget of span type", t, func() {
+		s := newMockSpan()
+		s.spanProcessor = noopSpanProcessor{}
+		s.latencyBudgets = map[string]time.Duration{s.SpanType: 5 * time.Second}
+		s.SetFinishTime(s.StartTime.Add(3 * time.Second))
+		s.Finish(ctx)
+		So(s.GetTagMap()[tracespec.SLOLatencyBudget], ShouldEqual, int64(5000000))
+		So(s.GetTagMap(), ShouldNotContainKey, tracespec.SLOViolated)
+
+		s = newMockSpan()
+		s.spanProcessor = noopSpanProcessor{}
+		s.latencyBudgets = map[string]time.Duration{s.SpanType: 5 * time.Second}
+		s.SetLatencyBudget(ctx, time.Second)
+		s.SetFinishTime(s.StartTime.Add(3 * time.Second))
+		s.Finish(ctx)
+		So(s.GetTagMap()[tracespec.SLOExcessMicros], ShouldEqual, int64(2000000))
+	})
+}
+
+func Test_SetMultiModalInputs(t *testing.T) {
+	ctx := context.Background()
+	inputs := []tracespec.ModalInput{
+		{Type: tracespec.ModalTypeText, SizeBytes: 10},
+		{Type: tracespec.ModalTypeImage, SizeBytes: 100, Format: "png"},
+		{Type: tracespec.ModalTypeImage, Format: "jpeg", Data: []byte("jpeg data")},
+		{Type: tracespec.ModalTypeAudio, Format: "wav", Data: []byte("wav")},
+	}
+
+	Convey("Test sizes are summed without uploading content", t, func() {
+		s := newMockSpan()
+		s.SetMultiModalInputs(ctx, inputs)
+		So(s.GetTagMap()[tracespec.ImageInputBytes], ShouldEqual, 109)
+		So(s.GetTagMap()[tracespec.AudioInputBytes], ShouldEqual, 3)
+		So(s.getModalInputFiles(), ShouldBeEmpty)
+	})
+
+	Convey("Test content is uploaded as attachments of input", t, func() {
+		s := newMockSpan()
+		s.uploadMultiModalContent = true
+		s.SetMultiModalInputs(ctx, inputs)
+
+		uploadSpans, files := transferToUploadSpanAndFile(ctx, []*Span{s}, nil)
+		So(len(files), ShouldEqual, 2)
+		So(files[0].Data, ShouldEqual, "jpeg data")
+		So(files[0].Name, ShouldEqual, "image_2.jpeg")
+		So(files[0].FileType, ShouldEqual, fileTypeImage)
+		So(files[1].Name, ShouldEqual, "audio_3.wav")
+		So(uploadSpans[0].ObjectStorage, ShouldContainSubstring, files[0].TosKey)
+		So(uploadSpans[0].TagsLong[tracespec.ImageInputBytes], ShouldEqual, 109)
+	})
+}
+
+func Test_SetToolSchema(t *testing.T) {
+	ctx := context.Background()
+	Convey("Test tool schema is compacted if valid json", t, func() {
+		s := newMockSpan()
+		s.SetToolSchema(ctx, json.RawMessage(`{"type": "object",  "properties": {}}`))
+		So(s.GetTagMap()[tracespec.ToolSchema], ShouldEqual, `{"type":"object","properties":{}}`)
+	})
+
+	Convey("Test invalid tool schema is set as is", t, func() {
+		s := newMockSpan()
+		s.SetToolSchema(ctx, json.RawMessage(`{"type": `))
+		So(s.GetTagMap()[tracespec.ToolSchema], ShouldEqual, `{"type": `)
+	})
+}
+
+func Test_SetConversationHistory(t *testing.T) {
+	ctx := context.Background()
+	messages := []tracespec.ConversationMessage{
+		{Role: tracespec.VRoleSystem, Content: "be helpful"},
+		{Role: tracespec.VRoleUser, Content: "hi"},
+		{Role: tracespec.VRoleAssistant, Content: "hello"},
+	}
+
+	Convey("Test conversation history overrides input", t, func() {
+		s := newMockSpan()
+		s.SetInput(ctx, "prior input")
+		s.SetConversationHistory(ctx, messages)
+		So(s.GetTagMap()[tracespec.Input], ShouldEqual, `[{"role":"system","content":"be helpful"},{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}]`)
+	})
+
+	Convey("Test only the most recent messages are kept", t, func() {
+		s := newMockSpan()
+		s.maxConversationMessages = 2
+		s.SetConversationHistory(ctx, messages)
+		So(s.GetTagMap()[tracespec.Input], ShouldEqual, `[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}]`)
+	})
+}
+
+func Test_SetOutputFormat(t *testing.T) {
+	ctx := context.Background()
+	Convey("Test output format is set as string tag", t, func() {
+		s := newMockSpan()
+		s.SetOutputFormat(ctx, tracespec.OutputFormatMarkdown)
+		s.SetOutput(ctx, "not json")
+		So(s.GetTagMap()[tracespec.LLMOutputFormat], ShouldEqual, "markdown")
+		So(s.GetTagMap(), ShouldNotContainKey, tracespec.Error)
+	})
+
+	Convey("Test output of json format is validated", t, func() {
+		s := newMockSpan()
+		s.SetOutputFormat(ctx, tracespec.OutputFormatJSON)
+		s.SetOutput(ctx, `{"answer": 42}`)
+		So(s.GetTagMap(), ShouldNotContainKey, tracespec.Error)
+		s.SetOutput(ctx, map[string]int{"answer": 42})
+		So(s.GetTagMap(), ShouldNotContainKey, tracespec.Error)
+		s.SetOutput(ctx, `{"answer": 42`)
+		So(s.GetTagMap()[tracespec.Output], ShouldEqual, `{"answer": 42`)
+		So(s.GetTagMap()[tracespec.Error], ShouldEqual, invalidJSONOutputMsg)
+	})
+}
+
+func Test_SetChatMessages(t *testing.T) {
+	ctx := context.Background()
+	Convey("Test chat messages override input and output", t, func() {
+		s := newMockSpan()
+		s.SetInput(ctx, "prior input")
+		s.SetOutput(ctx, "prior output")
+		s.SetInputMessages(ctx, []tracespec.ChatMessage{
+			{Role: tracespec.VRoleSystem, Content: "be helpful"},
+			{Role: tracespec.VRoleUser, Content: "hi", Name: "alice"},
+		})
+		s.SetOutputMessages(ctx, []tracespec.ChatMessage{{Role: tracespec.VRoleAssistant, Content: "hello"}})
+		So(s.GetTagMap()[tracespec.Input], ShouldEqual, `[{"role":"system","content":"be helpful"},{"role":"user","content":"hi","name":"alice"}]`)
+		So(s.GetTagMap()[tracespec.Output], ShouldEqual, `[{"role":"assistant","content":"hello"}]`)
+	})
+
+	Convey("Test nil messages are set as empty array", t, func() {
+		s := newMockSpan()
+		s.SetOutputMessages(ctx, nil)
+		So(s.GetTagMap()[tracespec.Output], ShouldEqual, `[]`)
+	})
+}
+
+func Test_SetEvaluationResult(t *testing.T) {
+	ctx := context.Background()
+	Convey("Test evaluation result is set as typed tags", t, func() {
+		s := newMockSpan()
+		s.SetEvaluationResult(ctx, tracespec.EvaluationResult{
+			EvaluatorName: "llm_judge",
+			Score:         0.8,
+			MaxScore:      1,
+			Passed:        true,
+			Rationale:     "mostly correct",
+			Labels:        map[string]string{"dataset": "qa"},
+		})
+		tags := s.GetTagMap()
+		So(tags[tracespec.EvalEvaluator], ShouldEqual, "llm_judge")
+		So(tags[tracespec.EvalScore], ShouldEqual, 0.8)
+		So(tags[tracespec.EvalMaxScore], ShouldEqual, 1.0)
+		So(tags[tracespec.EvalPassed], ShouldEqual, true)
+		So(tags[tracespec.EvalRationale], ShouldEqual, "mostly correct")
+		So(tags[tracespec.EvalLabelPrefix+"dataset"], ShouldEqual, "qa")
+	})
+
+	Convey("Test optional fields are omitted", t, func() {
+		s := newMockSpan()
+		s.SetEvaluationResult(ctx, tracespec.EvaluationResult{Score: 0})
+		tags := s.GetTagMap()
+		So(tags[tracespec.EvalScore], ShouldEqual, 0.0)
+		So(tags[tracespec.EvalPassed], ShouldEqual, false)
+		So(tags, ShouldNotContainKey, tracespec.EvalMaxScore)
+		So(tags, ShouldNotContainKey, tracespec.EvalRationale)
+	})
+}
+
+func Test_SetFineTuningMetadata(t *testing.T) {
+	ctx := context.Background()
+	Convey("Test fine-tuning metadata is set as ft tags", t, func() {
+		s := newMockSpan()
+		s.SetFineTuningMetadata(ctx, tracespec.FineTuningMeta{
+			BaseModel:         "gpt-4o-mini",
+			FineTunedModelID:  "ft:gpt-4o-mini:org::abc",
+			TrainingDatasetID: "ds-1",
+			TrainingSteps:     100,
+			Epoch:             2.5,
+		})
+		tags := s.GetTagMap()
+		So(tags[tracespec.FTBaseModel], ShouldEqual, "gpt-4o-mini")
+		So(tags[tracespec.FTModelID], ShouldEqual, "ft:gpt-4o-mini:org::abc")
+		So(tags[tracespec.FTTrainingDatasetID], ShouldEqual, "ds-1")
+		So(tags[tracespec.FTTrainingSteps], ShouldEqual, 100)
+		So(tags[tracespec.FTEpoch], ShouldEqual, 2.5)
+	})
+
+	Convey("Test empty fields are not set", t, func() {
+		s := newMockSpan()
+		s.SetFineTuningMetadata(ctx, tracespec.FineTuningMeta{BaseModel: "gpt-4o-mini"})
+		tags := s.GetTagMap()
+		So(tags[tracespec.FTBaseModel], ShouldEqual, "gpt-4o-mini")
+		So(tags, ShouldNotContainKey, tracespec.FTTrainingSteps)
+		So(tags, ShouldNotContainKey, tracespec.FTEpoch)
+	})
+}
+
+func Test_SetFunctionCall(t *testing.T) {
+	ctx := context.Background()
+	Convey("Test function call and result are set as function tags", t, func() {
+		s := newMockSpan()
+		s.SetFunctionCall(ctx, tracespec.FunctionCall{
+			Name:      "get_weather",
+			Arguments: json.RawMessage(`{"city": "Paris"}`),
+			CallID:    "call_1",
+		})
+		s.SetFunctionCallResult(ctx, tracespec.FunctionCallResult{CallID: "call_1", Content: "sunny", IsError: false})
+		tags := s.GetTagMap()
+		So(tags[tracespec.FunctionName], ShouldEqual, "get_weather")
+		So(tags[tracespec.FunctionArguments], ShouldEqual, `{"city":"Paris"}`)
+		So(tags[tracespec.FunctionCallID], ShouldEqual, "call_1")
+		So(tags[tracespec.FunctionResult], ShouldEqual, "sunny")
+		So(tags[tracespec.FunctionIsError], ShouldEqual, false)
+	})
+}
+
+func Test_SetVectorDBTags(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test vector db tags", t, func() {
+		s := newMockSpan()
+		s.SetVectorDBSystem(ctx, "pinecone")
+		s.SetVectorDBOperation(ctx, "query")
+		s.SetVectorDBIndexName(ctx, "docs")
+		s.SetVectorDBNamespace(ctx, "tenant-1")
+		s.SetVectorDBQueryVector(ctx, 1536)
+
+		tags := s.GetTagMap()
+		So(tags[tracespec.VectorDBSystem], ShouldEqual, "pinecone")
+		So(tags[tracespec.VectorDBOperation], ShouldEqual, "query")
+		So(tags[tracespec.VectorDBIndexName], ShouldEqual, "docs")
+		So(tags[tracespec.VectorDBNamespace], ShouldEqual, "tenant-1")
+		So(tags[tracespec.VectorDBQueryVectorDims], ShouldEqual, 1536)
+	})
+}
+
+func Test_SetRerankResult(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test scores are summarized", t, func() {
+		s := newMockSpan()
+		s.SetRerankResult(ctx, "bge-reranker", 20, 3, []float64{0.5, 0.9, 0.1})
+
+		tags := s.GetTagMap()
+		So(tags[tracespec.RerankModel], ShouldEqual, "bge-reranker")
+		So(tags[tracespec.RerankInputDocumentCount], ShouldEqual, 20)
+		So(tags[tracespec.RerankOutputDocumentCount], ShouldEqual, 3)
+		So(tags[tracespec.RerankTopScore], ShouldEqual, 0.9)
+		So(tags[tracespec.RerankMeanScore], ShouldAlmostEqual, 0.5)
+		So(tags[tracespec.RerankMinScore], ShouldEqual, 0.1)
+	})
+
+	Convey("Test score tags are not set without scores", t, func() {
+		s := newMockSpan()
+		s.SetRerankResult(ctx, "bge-reranker", 20, 0, nil)
+		So(s.GetTagMap(), ShouldNotContainKey, tracespec.RerankTopScore)
+		So(s.GetTagMap(), ShouldNotContainKey, tracespec.RerankMeanScore)
+	})
+}
+
+func Test_SetSearchTags(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test search tags", t, func() {
+		s := newMockSpan()
+		s.SetSearchQuery(ctx, "cozeloop sdk")
+		s.SetSearchEngine(ctx, "bing")
+		s.SetSearchResultCount(ctx, 1200, 2)
+		s.SetSearchResultSnippets(ctx, []string{"first", strings.Repeat("b", 600)})
+
+		tags := s.GetTagMap()
+		So(tags[tracespec.SearchQuery], ShouldEqual, "cozeloop sdk")
+		So(tags[tracespec.SearchEngine], ShouldEqual, "bing")
+		So(tags[tracespec.SearchTotalResults], ShouldEqual, 1200)
+		So(tags[tracespec.SearchReturnedResults], ShouldEqual, 2)
+		So(tags[tracespec.SearchSnippets], ShouldEqual, `["first","`+strings.Repeat("b", searchSnippetMaxChar)+`"]`)
+	})
+
+	Convey("Test snippets exceeding size limit are dropped", t, func() {
+		s := newMockSpan()
+		s.tagTruncateConf = &TagTruncateConf{NormalFieldMaxByte: 20}
+		s.SetSearchResultSnippets(ctx, []string{"first", "second", "third"})
+		So(s.GetTagMap()[tracespec.SearchSnippets], ShouldEqual, `["first","second"]`)
+	})
+}
+
+func Test_SetVectorSearchResults(t *testing.T) {
+	ctx := context.Background()
+	results := []tracespec.VectorSearchResult{
+		{ID: "doc1", Score: 0.5},
+		{ID: "doc2", Score: 0.9, Metadata: map[string]string{"title": strings.Repeat("a", 300)}},
+		{ID: "doc3", Score: 0.7},
+	}
+
+	Convey("Test results are sorted by score and limited to top k", t, func() {
+		s := newMockSpan()
+		s.maxVectorResults = 2
+		s.SetVectorSearchResults(ctx, results)
+		So(s.GetTagMap()[tracespec.VectorSearchResults], ShouldEqual,
+			`[{"id":"doc2","score":0.9,"metadata":{"title":"`+strings.Repeat("a", vectorMetadataValueMaxChar)+`"}},{"id":"doc3","score":0.7}]`)
+		So(results[1].Metadata["title"], ShouldHaveLength, 300)
+	})
+
+	Convey("Test results exceeding size limit are dropped", t, func() {
+		s := newMockSpan()
+		s.tagTruncateConf = &TagTruncateConf{NormalFieldMaxByte: 80}
+		s.SetVectorSearchResults(ctx, results)
+		So(s.GetTagMap()[tracespec.VectorSearchResults], ShouldEqual, `[]`)
+
+		s = newMockSpan()
+		s.tagTruncateConf = &TagTruncateConf{NormalFieldMaxByte: 80}
+		s.SetVectorSearchResults(ctx, results[:1:1])
+		So(s.GetTagMap()[tracespec.VectorSearchResults], ShouldEqual, `[{"id":"doc1","score":0.5}]`)
+	})
+}
+
+func Test_SetChainStep(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test name of span started without name is set", t, func() {
+		s := newMockSpan()
+		s.defaultName = true
+		s.chainParentName = "etl"
+		s.SetChainStep(ctx, 0, "extract", 3)
+		So(s.GetSpanName(), ShouldEqual, "etl/extract")
+		tags := s.GetTagMap()
+		So(tags[tracespec.ChainStepIndex], ShouldEqual, 0)
+		So(tags[tracespec.ChainStepName], ShouldEqual, "extract")
+		So(tags[tracespec.ChainTotalSteps], ShouldEqual, 3)
+
+		s.SetChainStep(ctx, 1, "transform", 3)
+		So(s.GetSpanName(), ShouldEqual, "etl/extract")
+
+		s = newMockSpan()
+		s.defaultName = true
+		s.SetChainStep(ctx, 1, "transform", 3)
+		So(s.GetSpanName(), ShouldEqual, "transform")
+	})
+
+	Convey("Test explicit name of span is kept", t, func() {
+		s := newMockSpan()
+		s.Name = "load_to_db"
+		s.chainParentName = "etl"
+		s.SetChainStep(ctx, 2, "load", 3)
+		So(s.GetSpanName(), ShouldEqual, "load_to_db")
+	})
+}
+
+func Test_SetHallucinationScore(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test span is not marked as error without threshold", t, func() {
+		s := newMockSpan()
+		s.SetHallucinationScore(ctx, 0.9, 3, 10)
+		tags := s.GetTagMap()
+		So(tags[tracespec.HallucinationScore], ShouldEqual, 0.9)
+		So(tags[tracespec.GroundedClaims], ShouldEqual, 3)
+		So(tags[tracespec.TotalClaims], ShouldEqual, 10)
+		So(tags, ShouldNotContainKey, tracespec.Error)
+		So(s.StatusCode, ShouldEqual, 0)
+	})
+
+	Convey("Test span of score above threshold is marked as error", t, func() {
+		s := newMockSpan()
+		s.hallucinationThreshold = 0.5
+		s.SetHallucinationScore(ctx, 0.5, 5, 10)
+		So(s.StatusCode, ShouldEqual, 0)
+
+		s.SetHallucinationScore(ctx, 0.6, 4, 10)
+		So(s.GetTagMap()[tracespec.Error], ShouldEqual, hallucinationDetectedMsg)
+		So(s.GetStatusCode(), ShouldEqual, consts.StatusCodeErrorDefault)
+	})
+
+	Convey("Test status code set before is kept", t, func() {
+		s := newMockSpan()
+		s.hallucinationThreshold = 0.5
+		s.SetStatusCode(ctx, 500)
+		s.SetHallucinationScore(ctx, 0.6, 4, 10)
+		So(s.GetTagMap()[tracespec.Error], ShouldEqual, hallucinationDetectedMsg)
+		So(s.GetStatusCode(), ShouldEqual, 500)
+	})
+}
+
+func Test_SetContextCompression(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test compression ratio is calculated from tokens", t, func() {
+		s := newMockSpan()
+		s.SetContextCompression(ctx, "llmlingua", 1000, 250, 0)
+		tags := s.GetTagMap()
+		So(tags[tracespec.ContextCompressionMethod], ShouldEqual, "llmlingua")
+		So(tags[tracespec.ContextOriginalTokens], ShouldEqual, 1000)
+		So(tags[tracespec.ContextCompressedTokens], ShouldEqual, 250)
+		So(tags[tracespec.ContextCompressionRatio], ShouldEqual, 4.0)
+		So(tags, ShouldNotContainKey, tracespec.ContextCompressionBelowMin)
+	})
+
+	Convey("Test compression ratio below threshold", t, func() {
+		s := newMockSpan()
+		s.compressionMinRatio = 3
+		s.SetContextCompression(ctx, "llmlingua", 1000, 500, 2)
+		So(s.GetTagMap()[tracespec.ContextCompressionRatio], ShouldEqual, 2.0)
+		So(s.GetTagMap()[tracespec.ContextCompressionBelowMin], ShouldEqual, true)
+	})
+}
+
+func Test_SetContextWindow(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test context window below default threshold", t, func() {
+		s := newMockSpan()
+		s.SetContextWindow(ctx, 900, 1000)
+		tags := s.GetTagMap()
+		So(tags[tracespec.ContextWindowUsed], ShouldEqual, 900)
+		So(tags[tracespec.ContextWindowLimit], ShouldEqual, 1000)
+		So(tags[tracespec.ContextWindowUtilization], ShouldEqual, 0.9)
+		So(tags, ShouldNotContainKey, tracespec.ContextWindowWarning)
+	})
+
+	Convey("Test context window above threshold", t, func() {
+		s := newMockSpan()
+		s.SetContextWindow(ctx, 901, 1000)
+		So(s.GetTagMap()[tracespec.ContextWindowWarning], ShouldEqual, true)
+
+		s = newMockSpan()
+		s.contextWindowThreshold = 0.5
+		s.SetContextWindow(ctx, 600, 1000)
+		So(s.GetTagMap()[tracespec.ContextWindowWarning], ShouldEqual, true)
+	})
+
+	Convey("Test utilization is not set without limit", t, func() {
+		s := newMockSpan()
+		s.SetContextWindow(ctx, 100, 0)
+		So(s.GetTagMap(), ShouldNotContainKey, tracespec.ContextWindowUtilization)
+	})
+}
+
+func Test_SetGuardrailResult(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test redacted content is not marked as error", t, func() {
+		s := newMockSpan()
+		s.SetGuardrailResult(ctx, tracespec.GuardrailResult{
+			GuardrailName: "pii_filter",
+			Triggered:     true,
+			Action:        tracespec.GuardrailActionRedact,
+			Categories:    []string{"email", "phone"},
+			Confidence:    0.8,
+		})
+		tags := s.GetTagMap()
+		So(tags[tracespec.GuardrailName], ShouldEqual, "pii_filter")
+		So(tags[tracespec.GuardrailTriggered], ShouldBeTrue)
+		So(tags[tracespec.GuardrailAction_], ShouldEqual, "redact")
+		So(tags[tracespec.GuardrailCategories], ShouldEqual, `["email","phone"]`)
+		So(tags[tracespec.GuardrailConfidence], ShouldEqual, 0.8)
+		So(tags, ShouldNotContainKey, tracespec.Error)
+		So(s.StatusCode, ShouldEqual, 0)
+	})
+
+	Convey("Test blocked content is marked as error", t, func() {
+		s := newMockSpan()
+		s.SetGuardrailResult(ctx, tracespec.GuardrailResult{
+			GuardrailName: "prompt_shield",
+			Triggered:     true,
+			Action:        tracespec.GuardrailActionBlock,
+			Categories:    []string{"jailbreak"},
+		})
+		So(s.GetTagMap()[tracespec.Error], ShouldEqual, "content blocked by guardrail [prompt_shield], categories: jailbreak")
+		So(s.StatusCode, ShouldEqual, consts.StatusCodeErrorDefault)
+	})
+}
+
+func Test_SetDocumentContext(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test documents are stored as JSON array", t, func() {
+		s := newMockSpan()
+		s.SetDocumentContext(ctx, []tracespec.DocumentContext{
+			{ID: "doc1", Title: "Refund Policy", Source: "kb/refund.md", ChunkIndex: 2, TokenCount: 120, RelevanceScore: 0.87},
+		})
+		So(s.GetTagMap()[tracespec.RAGDocuments], ShouldEqual,
+			`[{"id":"doc1","title":"Refund Policy","source":"kb/refund.md","chunk_index":2,"token_count":120,"relevance_score":0.87}]`)
+	})
+
+	Convey("Test empty documents", t, func() {
+		s := newMockSpan()
+		s.SetDocumentContext(ctx, nil)
+		So(s.GetTagMap()[tracespec.RAGDocuments], ShouldEqual, "[]")
+	})
+}
+
+func Test_SetRetrievalFilter(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test filter is stored as compact JSON", t, func() {
+		s := newMockSpan()
+		s.SetRetrievalFilter(ctx, map[string]interface{}{
+			"tenant": "t1",
+			"year":   map[string]interface{}{"$gte": 2024},
+		})
+		tags := s.GetTagMap()
+		So(tags[tracespec.RAGRetrievalFilter], ShouldEqual, `{"tenant":"t1","year":{"$gte":2024}}`)
+		So(tags, ShouldNotContainKey, tracespec.RAGRetrievalFilterKeys)
+	})
+
+	Convey("Test only keys are stored if filter is too large", t, func() {
+		s := newMockSpan()
+		s.SetRetrievalFilter(ctx, map[string]interface{}{
+			"ids":    strings.Repeat("a", consts.MaxBytesOfOneTagValueDefault),
+			"tenant": "t1",
+		})
+		tags := s.GetTagMap()
+		So(tags, ShouldNotContainKey, tracespec.RAGRetrievalFilter)
+		So(tags[tracespec.RAGRetrievalFilterKeys], ShouldEqual, `["ids","tenant"]`)
+	})
+}
+
+func Test_SetHypotheticalDocument(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test hypothetical document and strategy are set", t, func() {
+		s := newMockSpan()
+		s.SetHypotheticalDocument(ctx, "Refunds are issued within 14 days.")
+		tags := s.GetTagMap()
+		So(tags[tracespec.RAGHypotheticalDoc], ShouldEqual, "Refunds are issued within 14 days.")
+		So(tags[tracespec.RAGStrategy], ShouldEqual, tracespec.VRAGStrategyHyDE)
+	})
+}
+
+func Test_SetRetryContext(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Test retry tags are set", t, func() {
+		s := newMockSpan()
+		s.SetRetryContext(ctx, 2, 3, 1500*time.Millisecond)
+		tags := s.GetTagMap()
+		So(tags[tracespec.RetryAttempt], ShouldEqual, 2)
+		So(tags[tracespec.RetryMaxAttempts], ShouldEqual, 3)
+		So(tags[tracespec.RetryBackoffMillis], ShouldEqual, int64(1500))
+	})
+}
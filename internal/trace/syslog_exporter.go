@@ -0,0 +1,139 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+//go:build !windows && !plan9
+
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"sync"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+const (
+	// syslogSDID is the structured data id of span, 32473 is the enterprise number reserved for documentation.
+	syslogSDID = "span@32473"
+)
+
+var _ Exporter = (*SyslogExporter)(nil)
+
+// SyslogExporter exports spans to syslog daemon, one JSON-formatted line per span.
+// Spans with non-zero status code are written with LOG_ERR severity, others with LOG_INFO.
+type SyslogExporter struct {
+	network  string
+	addr     string
+	priority syslog.Priority
+	tag      string
+
+	mu     sync.Mutex
+	writer *syslog.Writer
+}
+
+// NewSyslogExporter connects to syslog daemon and creates a new SyslogExporter.
+// If network is empty, it connects to the local syslog server. The facility of priority is used.
+func NewSyslogExporter(network, addr string, priority syslog.Priority, tag string) (*SyslogExporter, error) {
+	e := &SyslogExporter{
+		network:  network,
+		addr:     addr,
+		priority: priority,
+		tag:      tag,
+	}
+	if err := e.connect(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *SyslogExporter) connect() error {
+	if e.writer != nil {
+		_ = e.writer.Close()
+		e.writer = nil
+	}
+	w, err := syslog.Dial(e.network, e.addr, e.priority, e.tag)
+	if err != nil {
+		return err
+	}
+	e.writer = w
+	return nil
+}
+
+// ExportSpans writes spans to syslog, it reconnects once if write failed.
+func (e *SyslogExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		msg, err := spanToSyslogMessage(span)
+		if err != nil {
+			logger.CtxErrorf(ctx, "failed to marshal span to syslog message: %v", err)
+			continue
+		}
+		if err = e.write(span, msg); err != nil {
+			logger.CtxWarnf(ctx, "failed to write span to syslog, reconnecting: %v", err)
+			if err = e.connect(); err != nil {
+				logger.CtxErrorf(ctx, "failed to reconnect syslog: %v", err)
+				return err
+			}
+			if err = e.write(span, msg); err != nil {
+				logger.CtxErrorf(ctx, "failed to write span to syslog: %v", err)
+				return err
+			}
+		}
+	}
+
+	logger.CtxDebugf(ctx, "exported %d spans to syslog", len(spans))
+	return nil
+}
+
+func (e *SyslogExporter) write(span *entity.UploadSpan, msg string) error {
+	if e.writer == nil {
+		if err := e.connect(); err != nil {
+			return err
+		}
+	}
+	if span.StatusCode != 0 {
+		return e.writer.Err(msg)
+	}
+	return e.writer.Info(msg)
+}
+
+// ExportFiles is a no-op for syslog exporter, as files are too large for syslog
+func (e *SyslogExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return nil
+}
+
+// Close closes the connection to syslog daemon.
+func (e *SyslogExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.writer == nil {
+		return nil
+	}
+	err := e.writer.Close()
+	e.writer = nil
+	return err
+}
+
+// spanToSyslogMessage formats span as syslog structured data followed by span JSON, e.g.
+// [span@32473 trace_id="xxx" span_id="xxx" status_code="0"] {"span_id":"xxx",...}
+func spanToSyslogMessage(span *entity.UploadSpan) (string, error) {
+	data, err := json.Marshal(span)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("[%s trace_id=%q span_id=%q status_code=\"%d\"] %s",
+		syslogSDID, span.TraceID, span.SpanID, span.StatusCode, data), nil
+}
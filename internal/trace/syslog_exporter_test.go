@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+//go:build !windows && !plan9
+
+package trace
+
+import (
+	"context"
+	"log/syslog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSyslogExporter(t *testing.T) {
+	Convey("SyslogExporter", t, func() {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		So(err, ShouldBeNil)
+		defer conn.Close()
+
+		exporter, err := NewSyslogExporter("udp", conn.LocalAddr().String(), syslog.LOG_LOCAL0, "cozeloop")
+		So(err, ShouldBeNil)
+		defer exporter.Close()
+
+		read := func() string {
+			buf := make([]byte, 4096)
+			_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+			n, _, err := conn.ReadFrom(buf)
+			So(err, ShouldBeNil)
+			return string(buf[:n])
+		}
+
+		err = exporter.ExportSpans(context.Background(), []*entity.UploadSpan{
+			{TraceID: "trace1", SpanID: "span1", SpanName: "ok"},
+			{TraceID: "trace1", SpanID: "span2", SpanName: "failed", StatusCode: 1},
+		})
+		So(err, ShouldBeNil)
+
+		// local0 is 16, priority = facility*8 + severity
+		msg := read()
+		So(strings.HasPrefix(msg, "<134>"), ShouldBeTrue)
+		So(msg, ShouldContainSubstring, `[span@32473 trace_id="trace1" span_id="span1" status_code="0"] {`)
+		So(msg, ShouldContainSubstring, `"span_name":"ok"`)
+
+		msg = read()
+		So(strings.HasPrefix(msg, "<131>"), ShouldBeTrue)
+		So(msg, ShouldContainSubstring, `"span_name":"failed"`)
+
+		Convey("should reconnect after closed", func() {
+			So(exporter.Close(), ShouldBeNil)
+			So(exporter.ExportSpans(context.Background(), []*entity.UploadSpan{{SpanName: "again"}}), ShouldBeNil)
+			So(read(), ShouldContainSubstring, `"span_name":"again"`)
+		})
+	})
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// SnakeCaseValidator returns a tag key validator, which rejects keys with uppercase letters or spaces.
+func SnakeCaseValidator() func(key string) error {
+	return func(key string) error {
+		for _, r := range key {
+			if unicode.IsUpper(r) {
+				return fmt.Errorf("key should not contain uppercase letter %q", r)
+			}
+			if unicode.IsSpace(r) {
+				return fmt.Errorf("key should not contain space")
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+	"golang.org/x/time/rate"
+)
+
+var _ Exporter = (*ThrottledExporter)(nil)
+
+// DefaultThrottleMaxWait is the default max time to wait for rate limiter before a span batch is dropped.
+const DefaultThrottleMaxWait = 5 * time.Second
+
+// ThrottledExporter limits the rate of span batches exported by inner, to protect downstream from spike traffic.
+// Batches which have to wait longer than maxWait are dropped. Files are exported without throttling.
+type ThrottledExporter struct {
+	inner   Exporter
+	limiter *rate.Limiter
+	maxWait time.Duration
+
+	droppedBatches int64
+}
+
+// ThrottleOption is used to set options for ThrottledExporter.
+type ThrottleOption func(e *ThrottledExporter)
+
+// WithThrottleMaxWait set the max time to wait for rate limiter, batches which need to wait longer are dropped.
+// Default is 5s, 0 means dropping batches exceeding the rate immediately.
+func WithThrottleMaxWait(d time.Duration) ThrottleOption {
+	return func(e *ThrottledExporter) {
+		if d >= 0 {
+			e.maxWait = d
+		}
+	}
+}
+
+// NewThrottledExporter creates a ThrottledExporter which exports at most rps span batches per second on average,
+// and allows bursts of up to burst batches. rps less than or equal to 0 means no limit.
+func NewThrottledExporter(inner Exporter, rps float64, burst int, opts ...ThrottleOption) *ThrottledExporter {
+	limit := rate.Limit(rps)
+	if rps <= 0 {
+		limit = rate.Inf
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	e := &ThrottledExporter{
+		inner:   inner,
+		limiter: rate.NewLimiter(limit, burst),
+		maxWait: DefaultThrottleMaxWait,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	return e
+}
+
+func (e *ThrottledExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	now := time.Now()
+	r := e.limiter.ReserveN(now, 1)
+	wait := r.DelayFrom(now)
+	if !r.OK() || wait > e.maxWait {
+		// give back the token, so that dropped batches don't delay the following ones
+		r.CancelAt(now)
+		atomic.AddInt64(&e.droppedBatches, 1)
+		logger.CtxWarnf(ctx, "export rate exceeded, drop span batch, span count: [%d]", len(spans))
+		return nil
+	}
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			r.Cancel()
+			return ctx.Err()
+		}
+	}
+	return e.inner.ExportSpans(ctx, spans)
+}
+
+func (e *ThrottledExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return e.inner.ExportFiles(ctx, files)
+}
+
+// DroppedBatches returns the number of span batches dropped because of exceeding the rate.
+func (e *ThrottledExporter) DroppedBatches() int64 {
+	return atomic.LoadInt64(&e.droppedBatches)
+}
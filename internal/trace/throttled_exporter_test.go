@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestThrottledExporter(t *testing.T) {
+	Convey("ThrottledExporter", t, func() {
+		ctx := context.Background()
+		spans := []*entity.UploadSpan{{TraceID: "trace1", SpanID: "span1"}}
+		inner := &funcExporter{}
+
+		Convey("should drop batches exceeding the rate", func() {
+			e := NewThrottledExporter(inner, 1, 2, WithThrottleMaxWait(0))
+			for i := 0; i < 3; i++ {
+				So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			}
+			So(inner.calls, ShouldEqual, 2)
+			So(e.DroppedBatches(), ShouldEqual, 1)
+		})
+
+		Convey("should wait for rate limiter within max wait", func() {
+			e := NewThrottledExporter(inner, 20, 1, WithThrottleMaxWait(time.Second))
+			start := time.Now()
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 40*time.Millisecond)
+			So(inner.calls, ShouldEqual, 2)
+			So(e.DroppedBatches(), ShouldEqual, 0)
+		})
+
+		Convey("should return when context is done", func() {
+			e := NewThrottledExporter(inner, 0.5, 1)
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			cancelCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+			So(e.ExportSpans(cancelCtx, spans), ShouldNotBeNil)
+			So(inner.calls, ShouldEqual, 1)
+		})
+
+		Convey("dropped batches should not take tokens", func() {
+			e := NewThrottledExporter(inner, 10, 1, WithThrottleMaxWait(0))
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			for i := 0; i < 5; i++ {
+				So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			}
+			So(e.DroppedBatches(), ShouldEqual, 5)
+			time.Sleep(120 * time.Millisecond)
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(inner.calls, ShouldEqual, 2)
+		})
+
+		Convey("should not limit if rps is not positive", func() {
+			e := NewThrottledExporter(inner, 0, 0, WithThrottleMaxWait(0))
+			for i := 0; i < 10; i++ {
+				So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			}
+			So(inner.calls, ShouldEqual, 10)
+		})
+	})
+}
@@ -5,9 +5,11 @@ package trace
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/alva-ai/cozeloop-go/entity"
 	"github.com/alva-ai/cozeloop-go/internal/consts"
 	"github.com/alva-ai/cozeloop-go/internal/httpclient"
 	"github.com/alva-ai/cozeloop-go/internal/logger"
@@ -19,6 +21,12 @@ type Provider struct {
 	httpClient    *httpclient.Client
 	opt           *Options
 	spanProcessor SpanProcessor
+	spanQuota     *spanQuota
+
+	cardinalityLimiter *cardinalityLimiter
+	collisionWarner    *spanNameCollisionWarner
+	finishHook         *spanFinishHook
+	modelDriftDetector *modelDriftDetector
 }
 
 type Options struct {
@@ -30,10 +38,68 @@ type Options struct {
 	SpanUploadPath       string
 	FileUploadPath       string
 	QueueConf            *QueueConf
+	MaxSpansPerTrace     int
+	ResourceTags         map[string]string // system tags added to every span, such as cloud.region
+	SpanNameFormatter    func(name, spanType string) string
+	InputFileThreshold   int      // input/output larger than it are exported as files by ExportFiles, 0 means disabled
+	InheritedTagKeys     []string // tags copied from parent span when starting child span
+	HTTPBodyRedactor     func(body []byte, contentType string) []byte
+	TagKeyValidator      func(key string) error // tags with invalid key are rejected, nil means no validation
+	FieldEncryption      *FieldEncryption       // encrypt input, output and tags before export, nil means disabled
+	ExporterOptions      []ExporterOption       // options of the default exporter to cozeloop server
+	// default latency budget of span types, checked on finish
+	LatencyBudgets map[string]time.Duration
+	// max unique string values of each tag key, exceeding values are replaced by _other_, 0 means no limit
+	CardinalityLimit int
+	// upload content of inputs set by SetMultiModalInputs as files
+	UploadMultiModalData bool
+	// warn on spans of the same parent with the same name
+	SpanNameCollisionWarn bool
+	// called with finished span before it is handed to span processor, synchronously or in a new goroutine
+	OnSpanFinish      func(span *entity.UploadSpan)
+	OnSpanFinishAsync func(span *entity.UploadSpan)
+	// max number of results kept by Span.SetVectorSearchResults, 0 means unlimited
+	MaxVectorResults int
+	// spans of hallucination score above it are marked as error, 0 means no threshold
+	HallucinationThreshold float64
+	// ratio of context window used above which Span.SetContextWindow warns, 0 means 0.9
+	ContextWindowThreshold float64
+	// min ratio of context compression below which Span.SetContextCompression warns, 0 means no threshold
+	CompressionMinRatio float64
+	// called on span start and end before the BatchSpanProcessor exporting spans
+	SpanProcessors []SpanProcessor
+	// reuse UploadSpans after they are exported successfully
+	SpanPooling bool
+	// override SpanQueueLength and OverflowStrategy of QueueConf if set
+	QueueSize             int
+	QueueOverflowStrategy OverflowStrategy
+	// override TraceGroupMaxAge of QueueConf if set
+	TraceGroupMaxAge time.Duration
+	// warn on spans garbage-collected without Finish, with the stack of StartSpan
+	LeakDetection bool
+	// called when model fingerprint of finished span differs from the last one of the same model
+	ModelDriftCallback func(model, oldFP, newFP string)
+	// log sampling decision made on StartSpan, nil means disabled
+	SamplingLogExporter *SamplingLogExporter
+	// collect gpu metrics of model spans on finish, nil means disabled
+	GPUMetricsCollector GPUMetricsCollector
+	// decide whether to keep traces when local root spans are started, nil means keeping all
+	Sampler Sampler
+	// drop spans without error on finish if they are dropped by the base sampler of NewErrorAwareSampler
+	ErrorAwareDropFilter bool
+	// set sampling.weight of 1/ratio on spans sampled by the ratio sampler
+	SamplingWeightTag bool
+	// set code.function, code.filepath and code.lineno of the caller on every span
+	CodeLocationOnAllSpans bool
+	// discount of each prompt cache read token by model name, subtracted from Span.EffectiveCost
+	PromptCacheDiscounts map[string]float64
+	// adapt memory of custom type passed to Span.SetCustomAgentMemory, nil means ignoring it
+	AgentMemorySerializer func(mem interface{}) tracespec.AgentMemory
 
 	// Local file export options
 	LocalFileExportEnabled bool
 	LocalFileExportPath    string
+	DebugExportEnabled     bool // print span summaries to stderr by DebugExporter
 }
 
 type StartSpanOptions struct {
@@ -45,6 +111,10 @@ type StartSpanOptions struct {
 	StartNewTrace bool
 	Scene         string
 	WorkspaceID   string
+
+	MaxConversationMessages int    // max number of messages kept by Span.SetConversationHistory, 0 means unlimited
+	ChainParentName         string // name of workflow span, used as prefix of span name set by Span.SetChainStep
+	ChatMessageFormat       bool   // FileExporter writes input and output of chat messages as `role: content`
 }
 
 type loopSpanKey struct{}
@@ -60,24 +130,50 @@ func NewTraceProvider(httpClient *httpclient.Client, options Options) *Provider
 
 	// Build local file export options
 	var localFileOpts *LocalFileExportOptions
-	if options.LocalFileExportEnabled {
+	if options.LocalFileExportEnabled || options.DebugExportEnabled {
 		localFileOpts = &LocalFileExportOptions{
 			Enabled:  options.LocalFileExportEnabled,
 			FilePath: options.LocalFileExportPath,
+			Debug:    options.DebugExportEnabled,
+		}
+	}
+
+	queueConf := options.QueueConf
+	if options.QueueSize > 0 || options.QueueOverflowStrategy != OverflowDrop || options.TraceGroupMaxAge > 0 {
+		conf := QueueConf{}
+		if queueConf != nil {
+			conf = *queueConf
+		}
+		if options.QueueSize > 0 {
+			conf.SpanQueueLength = options.QueueSize
+		}
+		if options.QueueOverflowStrategy != OverflowDrop {
+			conf.OverflowStrategy = options.QueueOverflowStrategy
 		}
+		if options.TraceGroupMaxAge > 0 {
+			conf.TraceGroupMaxAge = options.TraceGroupMaxAge
+		}
+		queueConf = &conf
 	}
 
 	c := &Provider{
 		httpClient: httpClient,
 		opt:        &options,
-		spanProcessor: NewBatchSpanProcessor(
+		spanProcessor: newMultiSpanProcessor(options.SpanProcessors, NewBatchSpanProcessor(
 			options.Exporter,
 			httpClient,
 			uploadPath,
 			options.FinishEventProcessor,
-			options.QueueConf,
+			queueConf,
 			localFileOpts,
-		),
+			options.SpanPooling,
+			options.ExporterOptions...,
+		)),
+		spanQuota:          newSpanQuota(options.MaxSpansPerTrace),
+		cardinalityLimiter: newCardinalityLimiter(options.CardinalityLimit),
+		collisionWarner:    newSpanNameCollisionWarner(options.SpanNameCollisionWarn),
+		finishHook:         newSpanFinishHook(options.OnSpanFinish, options.OnSpanFinishAsync),
+		modelDriftDetector: newModelDriftDetector(options.ModelDriftCallback),
 	}
 	return c
 }
@@ -88,7 +184,11 @@ func (t *Provider) GetOpts() *Options {
 
 func (t *Provider) StartSpan(ctx context.Context, name, spanType string, opts StartSpanOptions) (context.Context, *Span, error) {
 	// 0. check param
-	if name == "" {
+	if t.opt.SpanNameFormatter != nil {
+		name = t.opt.SpanNameFormatter(name, spanType)
+	}
+	defaultName := name == ""
+	if defaultName {
 		name = "unknown"
 	}
 	if spanType == "" {
@@ -117,16 +217,189 @@ func (t *Provider) StartSpan(ctx context.Context, name, spanType string, opts St
 			opts.Baggage = parentSpan.GetBaggage()
 		}
 	}
+	if parentSpan == nil && !opts.StartNewTrace {
+		// drop span if upstream decided not to sample
+		if sampled, ok := SamplingDecisionFromContext(ctx); ok && !sampled {
+			logger.CtxDebugf(ctx, "span[%s] is dropped by upstream sampling decision", name)
+			t.opt.SamplingLogExporter.logDecision(ctx, samplingLogRecord{
+				TraceID:     opts.TraceID,
+				SpanName:    name,
+				SpanType:    spanType,
+				SamplerType: SamplerTypeParentBased,
+				Decision:    SamplingDecisionDrop,
+				Reason:      "upstream sampling decision is not sampled",
+			})
+			return ctx, nil, nil
+		}
+		// fall back to remote parent span extracted by propagator
+		if remote := RemoteSpanContextFromContext(ctx); remote != nil {
+			if opts.TraceID == "" {
+				opts.TraceID = remote.GetTraceID()
+			}
+			if opts.ParentSpanID == "" {
+				opts.ParentSpanID = remote.GetSpanID()
+			}
+			if opts.Baggage == nil {
+				opts.Baggage = remote.GetBaggage()
+			}
+		}
+	}
 
-	// 2. internal start span
+	// 2. check span quota of the trace before the span is created, return nil span if exceeded, so that spans
+	// dropped by quota have no side effects, such as counting tags by cardinality limiter or calling Sampler
+	if opts.TraceID == "" {
+		opts.TraceID = util.Gen32CharID()
+	}
+	if t.spanQuota != nil {
+		count, ok := t.spanQuota.acquire(opts.TraceID)
+		if !ok {
+			logger.CtxWarnf(ctx, "span count of trace[%s] exceeds limit, count: %d, limit: %d, span[%s] is dropped",
+				opts.TraceID, count, t.spanQuota.maxSpans, name)
+			t.opt.SamplingLogExporter.logDecision(ctx, samplingLogRecord{
+				TraceID:     opts.TraceID,
+				SpanName:    name,
+				SpanType:    spanType,
+				SamplerType: SamplerTypeSpanQuota,
+				Decision:    SamplingDecisionDrop,
+				Reason:      fmt.Sprintf("span count of trace exceeds limit %d", t.spanQuota.maxSpans),
+			})
+			return ctx, nil, nil
+		}
+	}
+
+	// 3. internal start span, local root span is sampled by Sampler, and child spans follow their parent
 	loopSpan := t.startSpan(ctx, name, spanType, opts)
+	loopSpan.defaultName = defaultName
+	loopSpan.localRoot = parentSpan == nil || opts.StartNewTrace
+	loopSpan.spanQuota = t.spanQuota
+	var sampled *samplingLogRecord
+	if parentSpan != nil && !opts.StartNewTrace {
+		t.inheritTags(ctx, parentSpan, loopSpan)
+		// spans under a span with error are always kept
+		loopSpan.dropUnlessError = parentSpan.dropUnlessError && parentSpan.GetStatusCode() == 0
+		loopSpan.samplingWeight = parentSpan.samplingWeight
+	} else if _, ok := SamplingDecisionFromContext(ctx); t.opt.Sampler != nil && (!ok || opts.StartNewTrace) {
+		record := t.sample(ctx, loopSpan)
+		if record.Decision == SamplingDecisionDrop {
+			if t.spanQuota != nil {
+				t.spanQuota.release(opts.TraceID)
+			}
+			t.opt.SamplingLogExporter.logDecision(ctx, record)
+			// drop child spans started from the returned ctx too
+			return ContextWithSamplingDecision(ctx, false), nil, nil
+		}
+		sampled = &record
+	}
+
+	if t.opt.SamplingLogExporter != nil && sampled != nil {
+		t.opt.SamplingLogExporter.logDecision(ctx, *sampled)
+	} else if t.opt.SamplingLogExporter != nil {
+		samplerType, reason := keepReason(ctx, parentSpan != nil && !opts.StartNewTrace)
+		t.opt.SamplingLogExporter.logDecision(ctx, samplingLogRecord{
+			TraceID:     loopSpan.GetTraceID(),
+			SpanName:    name,
+			SpanType:    spanType,
+			SamplerType: samplerType,
+			Decision:    SamplingDecisionKeep,
+			Reason:      reason,
+		})
+	}
 
-	// 3. inject ctx
+	// 4. warn on span name collision, seen names are forgot when the local root span finished
+	if t.collisionWarner != nil {
+		t.collisionWarner.observe(ctx, loopSpan.GetTraceID(), loopSpan.ParentSpanID, loopSpan.Name)
+		if parentSpan == nil || opts.StartNewTrace {
+			loopSpan.collisionWarner = t.collisionWarner
+		}
+	}
+
+	// 5. track span leak, stack is only captured if enabled as it is expensive
+	if t.opt.LeakDetection {
+		trackSpanLeak(loopSpan)
+	}
+
+	// 6. record the caller of StartSpan outside the SDK
+	if t.opt.CodeLocationOnAllSpans {
+		loopSpan.SetCodeLocation(ctx)
+	}
+
+	// 7. set agent round of ctx, which is incremented by ContextWithNextAgentRound
+	if round, ok := AgentRoundFromContext(ctx); ok {
+		loopSpan.SetTags(ctx, oneTag(tracespec.AgentRound, round))
+	}
+
+	// 8. call span processors, such as enriching span
+	t.spanProcessor.OnStart(ctx, loopSpan)
+
+	// 9. inject ctx
 	ctx = context.WithValue(ctx, loopSpanKey{}, loopSpan)
 
 	return ctx, loopSpan, nil
 }
 
+// sample decides whether to keep the local root span by Sampler, and marks it to be dropped on finish unless
+// it has error if it is dropped by the base sampler of NewErrorAwareSampler.
+func (t *Provider) sample(ctx context.Context, span *Span) samplingLogRecord {
+	record := samplingLogRecord{
+		TraceID:     span.GetTraceID(),
+		SpanName:    span.GetSpanName(),
+		SpanType:    span.GetSpanType(),
+		SamplerType: getSamplerType(t.opt.Sampler),
+		Decision:    SamplingDecisionKeep,
+		Reason:      "sampled by sampler",
+	}
+	if !t.opt.Sampler.ShouldSample(ctx, record.TraceID, record.SpanName, record.SpanType) {
+		record.Decision = SamplingDecisionDrop
+		record.Reason = "not sampled by sampler"
+		return record
+	}
+	if s, ok := t.opt.Sampler.(*errorAwareSampler); ok && s.shouldDropUnlessError(ctx, record.TraceID, record.SpanName, record.SpanType) {
+		if !t.opt.ErrorAwareDropFilter {
+			record.Decision = SamplingDecisionDrop
+			record.Reason = "not sampled by base sampler, and drop filter is disabled"
+			return record
+		}
+		span.dropUnlessError = true
+		record.Reason = "not sampled by base sampler, kept until finish in case of error"
+	}
+	if t.opt.SamplingWeightTag {
+		if span.dropUnlessError {
+			// spans kept for error are not sampled by ratio
+			span.samplingWeight = 1
+		} else if ratio, ok := samplingRatio(t.opt.Sampler); ok && ratio > 0 {
+			span.samplingWeight = 1 / ratio
+		}
+	}
+	return record
+}
+
+// keepReason returns the sampler type and reason of keeping a span started from ctx.
+func keepReason(ctx context.Context, hasLocalParent bool) (samplerType, reason string) {
+	if hasLocalParent {
+		return SamplerTypeParentBased, "parent span is sampled"
+	}
+	if sampled, ok := SamplingDecisionFromContext(ctx); ok && sampled {
+		return SamplerTypeParentBased, "upstream sampling decision is sampled"
+	}
+	return SamplerTypeAlwaysOn, "no sampling rule matched"
+}
+
+// inheritTags copies tags of InheritedTagKeys from parent span to child span.
+// Tags set on child span later override the inherited ones.
+func (t *Provider) inheritTags(ctx context.Context, parent, child *Span) {
+	if len(t.opt.InheritedTagKeys) == 0 {
+		return
+	}
+	parentTags := parent.GetTagMap()
+	tags := make(map[string]interface{}, len(t.opt.InheritedTagKeys))
+	for _, key := range t.opt.InheritedTagKeys {
+		if value, ok := parentTags[key]; ok {
+			tags[key] = value
+		}
+	}
+	child.SetTags(ctx, tags)
+}
+
 func (t *Provider) GetSpanFromContext(ctx context.Context) *Span {
 	s, ok := ctx.Value(loopSpanKey{}).(*Span)
 	if !ok {
@@ -173,6 +446,14 @@ func (t *Provider) startSpan(ctx context.Context, spanName string, spanType stri
 		}
 	}
 
+	if options.ChatMessageFormat {
+		systemTagMap[consts.ChatMessageFormat] = chatMessageFormatRoleContent
+	}
+
+	for key, value := range t.opt.ResourceTags {
+		systemTagMap[key] = value
+	}
+
 	workSpaceID := t.opt.WorkspaceID
 	if options.WorkspaceID != "" {
 		workSpaceID = options.WorkspaceID
@@ -195,6 +476,7 @@ func (t *Provider) startSpan(ctx context.Context, spanName string, spanType stri
 		SystemTagMap:        systemTagMap,
 		StatusCode:          0,
 		ultraLargeReport:    t.opt.UltraLargeReport,
+		inputFileThreshold:  t.opt.InputFileThreshold,
 		multiModalityKeyMap: make(map[string]struct{}),
 		spanProcessor:       t.spanProcessor,
 		flags:               1, // for W3C, sampled by default
@@ -202,6 +484,24 @@ func (t *Provider) startSpan(ctx context.Context, spanName string, spanType stri
 		lock:                sync.RWMutex{},
 		bytesSize:           0, // The initial value is 0. Default fields do not count towards the size.
 		tagTruncateConf:     t.opt.TagTruncateConf,
+		httpBodyRedactor:    t.opt.HTTPBodyRedactor,
+		tagKeyValidator:     t.opt.TagKeyValidator,
+		fieldEncryption:     t.opt.FieldEncryption,
+		latencyBudgets:      t.opt.LatencyBudgets,
+		cardinalityLimiter:  t.cardinalityLimiter,
+
+		uploadMultiModalContent: t.opt.UploadMultiModalData,
+		maxConversationMessages: options.MaxConversationMessages,
+		maxVectorResults:        t.opt.MaxVectorResults,
+		hallucinationThreshold:  t.opt.HallucinationThreshold,
+		contextWindowThreshold:  t.opt.ContextWindowThreshold,
+		compressionMinRatio:     t.opt.CompressionMinRatio,
+		chainParentName:         options.ChainParentName,
+		finishHook:              t.finishHook,
+		modelDriftDetector:      t.modelDriftDetector,
+		gpuMetricsCollector:     t.opt.GPUMetricsCollector,
+		cacheDiscounts:          t.opt.PromptCacheDiscounts,
+		memorySerializer:        t.opt.AgentMemorySerializer,
 	}
 
 	// 3. set Baggage from parent span
@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// traceGrouper holds finished spans of each trace until its local root span is finished, so that spans of a trace
+// in this process are exported in the same batch. The local root span has no parent span in this process, which
+// may still have a remote parent, such as spans started by http middlewares. Traces held longer than maxTraceAge
+// are released without local root span.
+// It is only accessed by the consumer goroutine of BatchQueueManager, except heldSpans, so no lock is needed.
+type traceGrouper struct {
+	maxTraceAge time.Duration
+	traces      map[string]*pendingTrace
+	held        int64 // count of held spans, read by producers as they are counted against queue capacity
+}
+
+type pendingTrace struct {
+	firstSeen time.Time
+	spans     []interface{}
+}
+
+func newTraceGrouper(maxTraceAge time.Duration) *traceGrouper {
+	return &traceGrouper{
+		maxTraceAge: maxTraceAge,
+		traces:      make(map[string]*pendingTrace),
+	}
+}
+
+// add holds sd and returns spans of its trace if sd is the local root span. Items other than spans are returned
+// at once.
+func (g *traceGrouper) add(sd interface{}, now time.Time) []interface{} {
+	span, ok := sd.(*Span)
+	if !ok {
+		return []interface{}{sd}
+	}
+	traceID := span.GetTraceID()
+	trace, ok := g.traces[traceID]
+	if !ok {
+		trace = &pendingTrace{firstSeen: now}
+		g.traces[traceID] = trace
+	}
+	trace.spans = append(trace.spans, sd)
+	atomic.AddInt64(&g.held, 1)
+	if !span.isLocalRoot() {
+		return nil
+	}
+	return g.release(traceID, trace)
+}
+
+// expire returns spans of traces held for maxTraceAge, whose local root span may never be finished.
+func (g *traceGrouper) expire(now time.Time) []interface{} {
+	var spans []interface{}
+	for traceID, trace := range g.traces {
+		if now.Sub(trace.firstSeen) >= g.maxTraceAge {
+			spans = append(spans, g.release(traceID, trace)...)
+		}
+	}
+	return spans
+}
+
+// flushAll returns spans of all held traces, used by ForceFlush and Shutdown.
+func (g *traceGrouper) flushAll() []interface{} {
+	var spans []interface{}
+	for traceID, trace := range g.traces {
+		spans = append(spans, g.release(traceID, trace)...)
+	}
+	return spans
+}
+
+func (g *traceGrouper) release(traceID string, trace *pendingTrace) []interface{} {
+	delete(g.traces, traceID)
+	atomic.AddInt64(&g.held, -int64(len(trace.spans)))
+	return trace.spans
+}
+
+// heldSpans returns the count of held spans, it is safe to be called by producers.
+func (g *traceGrouper) heldSpans() int {
+	return int(atomic.LoadInt64(&g.held))
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newGroupedTestSpan creates a span whose parent is in this process unless parentID is empty or "0"
+func newGroupedTestSpan(traceID, spanID, parentID string) *Span {
+	return &Span{
+		SpanContext:  SpanContext{TraceID: traceID, SpanID: spanID},
+		ParentSpanID: parentID,
+		localRoot:    parentID == "" || parentID == "0",
+	}
+}
+
+func TestTraceGrouper(t *testing.T) {
+	Convey("traceGrouper", t, func() {
+		now := time.Now()
+		g := newTraceGrouper(time.Minute)
+		child := newGroupedTestSpan("t1", "child", "root")
+		other := newGroupedTestSpan("t2", "other", "remote")
+		root := newGroupedTestSpan("t1", "root", "0")
+
+		So(g.add(child, now), ShouldBeEmpty)
+		So(g.add(other, now.Add(30*time.Second)), ShouldBeEmpty)
+		So(g.heldSpans(), ShouldEqual, 2)
+		So(g.add(root, now), ShouldResemble, []interface{}{child, root})
+		So(g.heldSpans(), ShouldEqual, 1)
+		So(g.add("not span", now), ShouldResemble, []interface{}{"not span"})
+
+		So(g.expire(now.Add(time.Minute)), ShouldBeEmpty)
+		So(g.expire(now.Add(90*time.Second)), ShouldResemble, []interface{}{other})
+		So(g.traces, ShouldBeEmpty)
+		So(g.heldSpans(), ShouldEqual, 0)
+
+		// local root span with a remote parent releases its trace
+		remoteChild := newGroupedTestSpan("t3", "child", "local_root")
+		localRoot := newGroupedTestSpan("t3", "local_root", "remote")
+		localRoot.localRoot = true
+		So(g.add(remoteChild, now), ShouldBeEmpty)
+		So(g.add(localRoot, now), ShouldResemble, []interface{}{remoteChild, localRoot})
+
+		g.add(child, now)
+		So(g.flushAll(), ShouldResemble, []interface{}{child})
+		So(g.traces, ShouldBeEmpty)
+	})
+}
+
+func TestBatchQueueManagerTraceGrouped(t *testing.T) {
+	Convey("BatchQueueManager with trace grouped batching", t, func() {
+		ctx := context.Background()
+		exported := make(chan []interface{}, 16)
+		maxExportBatchLength := 2
+		newQM := func(batchTimeout, maxTraceAge time.Duration) *BatchQueueManager {
+			return newBatchQueueManager(batchQueueManagerOptions{
+				queueName:              queueNameSpan,
+				batchTimeout:           batchTimeout,
+				maxQueueLength:         4,
+				maxExportBatchLength:   maxExportBatchLength,
+				maxExportBatchByteSize: DefaultMaxExportBatchByteSize,
+				traceGroupMaxAge:       maxTraceAge,
+				exportFunc: func(ctx context.Context, s []interface{}) {
+					exported <- append([]interface{}(nil), s...)
+				},
+			})
+		}
+
+		Convey("spans of a trace are exported with root span", func() {
+			qm := newQM(time.Hour, time.Hour)
+			child := newGroupedTestSpan("t1", "child", "root")
+			root := newGroupedTestSpan("t1", "root", "")
+			qm.Enqueue(ctx, child, 0)
+			So(waitForCondition(func() bool { return qm.queue.len() == 0 }), ShouldBeTrue)
+			So(len(exported), ShouldEqual, 0)
+			qm.Enqueue(ctx, root, 0)
+			So(<-exported, ShouldResemble, []interface{}{child, root})
+			So(qm.Shutdown(ctx), ShouldBeNil)
+		})
+
+		Convey("traces without root span are exported after max trace age", func() {
+			qm := newQM(10*time.Millisecond, 20*time.Millisecond)
+			child := newGroupedTestSpan("t1", "child", "remote")
+			qm.Enqueue(ctx, child, 0)
+			select {
+			case spans := <-exported:
+				So(spans, ShouldResemble, []interface{}{child})
+			case <-time.After(time.Second):
+				t.Fatal("trace without root span is not exported")
+			}
+			So(qm.Shutdown(ctx), ShouldBeNil)
+		})
+
+		Convey("spans of a large trace are split by batch limits", func() {
+			maxExportBatchLength = 1
+			qm := newQM(time.Hour, time.Hour)
+			child := newGroupedTestSpan("t1", "child", "root")
+			root := newGroupedTestSpan("t1", "root", "")
+			qm.Enqueue(ctx, child, 0)
+			qm.Enqueue(ctx, root, 0)
+			So(<-exported, ShouldResemble, []interface{}{child})
+			So(<-exported, ShouldResemble, []interface{}{root})
+			So(qm.Shutdown(ctx), ShouldBeNil)
+		})
+
+		Convey("held spans are counted against queue capacity", func() {
+			qm := newQM(time.Hour, time.Hour)
+			for i := 0; i < 4; i++ {
+				qm.Enqueue(ctx, newGroupedTestSpan("t1", fmt.Sprintf("child%d", i), "root"), 0)
+			}
+			So(waitForCondition(func() bool { return qm.traceGrouper.heldSpans() == 4 }), ShouldBeTrue)
+			qm.Enqueue(ctx, newGroupedTestSpan("t1", "root", ""), 0)
+			So(atomic.LoadUint32(&qm.dropped), ShouldEqual, 1)
+			So(qm.ForceFlush(ctx), ShouldBeNil)
+			So(len(<-exported), ShouldEqual, 2)
+			So(len(<-exported), ShouldEqual, 2)
+			So(qm.traceGrouper.heldSpans(), ShouldEqual, 0)
+			So(qm.Shutdown(ctx), ShouldBeNil)
+		})
+
+		Convey("held spans are exported by ForceFlush", func() {
+			qm := newQM(time.Hour, time.Hour)
+			child := newGroupedTestSpan("t1", "child", "root")
+			qm.Enqueue(ctx, child, 0)
+			So(qm.ForceFlush(ctx), ShouldBeNil)
+			So(<-exported, ShouldResemble, []interface{}{child})
+			So(qm.Shutdown(ctx), ShouldBeNil)
+		})
+	})
+}
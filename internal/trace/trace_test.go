@@ -9,7 +9,9 @@ import (
 	"time"
 
 	. "github.com/bytedance/mockey"
+	"github.com/alva-ai/cozeloop-go/internal/consts"
 	"github.com/alva-ai/cozeloop-go/internal/httpclient"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -76,3 +78,66 @@ func Test_GetSpanFromHeader(t *testing.T) {
 		So(actual, ShouldEqual, expectedSpan)
 	})
 }
+
+func TestProvider_StartSpanInheritTags(t *testing.T) {
+	Convey("Provider.StartSpan with inherited tags", t, func() {
+		ctx := context.Background()
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws", InheritedTagKeys: []string{tracespec.ModelProvider, "environment"}},
+			spanProcessor: noopSpanProcessor{},
+		}
+		parentCtx, parent, err := p.StartSpan(ctx, "agent", "agent", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		parent.SetModelProvider(ctx, "openai")
+		parent.SetTags(ctx, map[string]interface{}{"environment": "prod", "other": "v"})
+
+		_, child, err := p.StartSpan(parentCtx, "llm", "model", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		So(child.GetTagMap()[tracespec.ModelProvider], ShouldEqual, "openai")
+		So(child.GetTagMap()["environment"], ShouldEqual, "prod")
+		So(child.GetTagMap(), ShouldNotContainKey, "other")
+
+		child.SetModelProvider(ctx, "anthropic")
+		So(child.GetTagMap()[tracespec.ModelProvider], ShouldEqual, "anthropic")
+	})
+}
+
+func TestProvider_StartSpanChatMessageFormat(t *testing.T) {
+	Convey("Provider.StartSpan with chat message format", t, func() {
+		ctx := context.Background()
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws"},
+			spanProcessor: noopSpanProcessor{},
+		}
+		_, chat, err := p.StartSpan(ctx, "chat", "model", StartSpanOptions{ChatMessageFormat: true})
+		So(err, ShouldBeNil)
+		So(chat.SystemTagMap[consts.ChatMessageFormat], ShouldEqual, chatMessageFormatRoleContent)
+
+		_, raw, err := p.StartSpan(ctx, "raw", "model", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		So(raw.SystemTagMap, ShouldNotContainKey, consts.ChatMessageFormat)
+	})
+}
+
+func TestProvider_StartSpanLocalRoot(t *testing.T) {
+	Convey("Provider.StartSpan marks spans without local parent as local root", t, func() {
+		ctx := ContextWithRemoteSpanContext(context.Background(), &SpanContext{TraceID: "trace1", SpanID: "remote"})
+		p := &Provider{
+			opt:           &Options{WorkspaceID: "ws"},
+			spanProcessor: noopSpanProcessor{},
+		}
+		rootCtx, root, err := p.StartSpan(ctx, "server", "custom", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		So(root.GetParentID(), ShouldEqual, "remote")
+		So(root.isLocalRoot(), ShouldBeTrue)
+
+		_, child, err := p.StartSpan(rootCtx, "child", "custom", StartSpanOptions{})
+		So(err, ShouldBeNil)
+		So(child.isLocalRoot(), ShouldBeFalse)
+
+		group, err := p.GroupSpans(rootCtx, "group", []*Span{root})
+		So(err, ShouldBeNil)
+		So(group.isLocalRoot(), ShouldBeTrue)
+		So(root.isLocalRoot(), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"sync"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+)
+
+// uploadSpanPool reuses UploadSpans and their tag maps after they are exported successfully,
+// which reduces allocations of services generating many spans. nil means pooling is disabled.
+type uploadSpanPool struct {
+	pool sync.Pool
+}
+
+func newUploadSpanPool(enable bool) *uploadSpanPool {
+	if !enable {
+		return nil
+	}
+	return &uploadSpanPool{
+		pool: sync.Pool{
+			New: func() interface{} {
+				return &entity.UploadSpan{}
+			},
+		},
+	}
+}
+
+// get returns a cleared UploadSpan, whose tag maps are empty but not nil if it is reused.
+func (p *uploadSpanPool) get() *entity.UploadSpan {
+	if p == nil {
+		return &entity.UploadSpan{}
+	}
+	return p.pool.Get().(*entity.UploadSpan)
+}
+
+// put clears spans and returns them to the pool, spans must not be used after it.
+func (p *uploadSpanPool) put(spans []*entity.UploadSpan) {
+	if p == nil {
+		return
+	}
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		resetUploadSpan(span)
+		p.pool.Put(span)
+	}
+}
+
+// resetUploadSpan zeroes all fields of span, maps are cleared and kept for reuse so that
+// tags of the previous span never leak into the next one.
+func resetUploadSpan(span *entity.UploadSpan) {
+	*span = entity.UploadSpan{
+		SystemTagsString: clearMap(span.SystemTagsString),
+		SystemTagsLong:   clearMap(span.SystemTagsLong),
+		SystemTagsDouble: clearMap(span.SystemTagsDouble),
+		TagsString:       clearMap(span.TagsString),
+		TagsLong:         clearMap(span.TagsLong),
+		TagsDouble:       clearMap(span.TagsDouble),
+		TagsBool:         clearMap(span.TagsBool),
+	}
+}
+
+func clearMap[K comparable, V any](m map[K]V) map[K]V {
+	for k := range m {
+		delete(m, k)
+	}
+	return m
+}
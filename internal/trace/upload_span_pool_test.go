@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// jsonCaptureExporter records exported spans in JSON, as pooled spans are reused after export
+type jsonCaptureExporter struct {
+	batches []string
+}
+
+func (e *jsonCaptureExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	data, err := json.Marshal(spans)
+	if err != nil {
+		return err
+	}
+	e.batches = append(e.batches, string(data))
+	return nil
+}
+
+func (e *jsonCaptureExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return nil
+}
+
+func TestUploadSpanPool(t *testing.T) {
+	Convey("uploadSpanPool", t, func() {
+		ctx := context.Background()
+		var disabled *uploadSpanPool
+		So(newUploadSpanPool(false), ShouldBeNil)
+		So(disabled.get(), ShouldResemble, &entity.UploadSpan{})
+		So(func() { disabled.put([]*entity.UploadSpan{{}}) }, ShouldNotPanic)
+
+		Convey("should zero all fields and clear maps on reset", func() {
+			span := &entity.UploadSpan{
+				SpanID:           "span1",
+				StatusCode:       1,
+				Input:            "input",
+				SystemTagsString: map[string]string{"a": "b"},
+				SystemTagsLong:   map[string]int64{"a": 1},
+				SystemTagsDouble: map[string]float64{"a": 1},
+				TagsString:       map[string]string{"a": "b"},
+				TagsLong:         map[string]int64{"a": 1},
+				TagsDouble:       map[string]float64{"a": 1},
+				TagsBool:         map[string]bool{"a": true},
+				Annotations:      []entity.SpanAnnotation{{Message: "m"}},
+			}
+			tags := span.TagsString
+			resetUploadSpan(span)
+			So(span.SpanID, ShouldBeEmpty)
+			So(span.StatusCode, ShouldEqual, 0)
+			So(span.Input, ShouldBeEmpty)
+			So(span.Annotations, ShouldBeNil)
+			So(span.SystemTagsString, ShouldBeEmpty)
+			So(span.SystemTagsLong, ShouldBeEmpty)
+			So(span.SystemTagsDouble, ShouldBeEmpty)
+			So(span.TagsString, ShouldBeEmpty)
+			So(span.TagsLong, ShouldBeEmpty)
+			So(span.TagsDouble, ShouldBeEmpty)
+			So(span.TagsBool, ShouldBeEmpty)
+			So(tags, ShouldBeEmpty)
+		})
+
+		Convey("should not leak tags between exported spans", func() {
+			p := &Provider{
+				opt:           &Options{WorkspaceID: "ws"},
+				spanProcessor: noopSpanProcessor{},
+			}
+			_, first, err := p.StartSpan(ctx, "first", "custom", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			first.SetTags(ctx, map[string]interface{}{"first_tag": "a", "count": 1, "ok": true})
+			first.Finish(ctx)
+			_, second, err := p.StartSpan(ctx, "second", "custom", StartSpanOptions{})
+			So(err, ShouldBeNil)
+			second.SetTags(ctx, map[string]interface{}{"second_tag": "b"})
+			second.Finish(ctx)
+
+			exporter := &jsonCaptureExporter{}
+			export := newExportSpansFunc(exporter, nil, nil, nil, newUploadSpanPool(true), nil)
+			export(ctx, []interface{}{first})
+			export(ctx, []interface{}{second})
+
+			So(len(exporter.batches), ShouldEqual, 2)
+			So(exporter.batches[0], ShouldContainSubstring, "first_tag")
+			So(exporter.batches[1], ShouldContainSubstring, "second_tag")
+			So(exporter.batches[1], ShouldNotContainSubstring, "first_tag")
+			So(exporter.batches[1], ShouldNotContainSubstring, `"count"`)
+			So(exporter.batches[1], ShouldNotContainSubstring, `"ok"`)
+		})
+	})
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/consts"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+var _ Exporter = (*ValidatingExporter)(nil)
+
+// ValidationAction is the behavior of ValidatingExporter on spans missing required tags.
+type ValidationAction int
+
+const (
+	// ValidationActionLog logs a warning and exports the span. It is the default.
+	ValidationActionLog ValidationAction = iota
+	// ValidationActionDrop logs a warning and drops the span.
+	ValidationActionDrop
+	// ValidationActionError drops the span and makes ExportSpans return an error.
+	ValidationActionError
+)
+
+// ValidationRule requires spans of SpanType to have all RequiredTags, in any of string, long, double and bool tags.
+type ValidationRule struct {
+	SpanType     string
+	RequiredTags []string
+	OnViolation  ValidationAction
+}
+
+// ValidatingExporter checks spans against the rule of their span type, and exports valid spans by inner.
+type ValidatingExporter struct {
+	inner  Exporter
+	rules  map[string]ValidationRule
+	strict bool
+}
+
+// ValidationOption is used to set options for ValidatingExporter.
+type ValidationOption func(e *ValidatingExporter)
+
+// WithStrictMode makes every violation handled as ValidationActionError, regardless of OnViolation of the rule.
+func WithStrictMode() ValidationOption {
+	return func(e *ValidatingExporter) {
+		e.strict = true
+	}
+}
+
+// NewValidatingExporter creates a ValidatingExporter. If there are multiple rules of a span type, the first one is used.
+// Spans of types without rule are exported without validation.
+func NewValidatingExporter(inner Exporter, rules []ValidationRule, opts ...ValidationOption) *ValidatingExporter {
+	e := &ValidatingExporter{
+		inner: inner,
+		rules: make(map[string]ValidationRule, len(rules)),
+	}
+	for _, rule := range rules {
+		if _, ok := e.rules[rule.SpanType]; !ok {
+			e.rules[rule.SpanType] = rule
+		}
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	return e
+}
+
+// ExportSpans exports spans satisfying their rules, and spans violating rules of ValidationActionLog.
+// It returns the error of the first span violating a rule of ValidationActionError, after valid spans are exported.
+func (e *ValidatingExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	var validationErr error
+	valid := make([]*entity.UploadSpan, 0, len(spans))
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		rule, ok := e.rules[span.SpanType]
+		if !ok {
+			valid = append(valid, span)
+			continue
+		}
+		missing := missingTags(span, rule.RequiredTags)
+		if len(missing) == 0 {
+			valid = append(valid, span)
+			continue
+		}
+		action := rule.OnViolation
+		if e.strict {
+			action = ValidationActionError
+		}
+		switch action {
+		case ValidationActionError:
+			if validationErr == nil {
+				validationErr = consts.ErrInvalidParam.Wrap(fmt.Errorf("span[%s] of type [%s] misses required tags %v",
+					span.SpanID, span.SpanType, missing))
+			}
+		case ValidationActionDrop:
+			logger.CtxWarnf(ctx, "span[%s] of type [%s] misses required tags %v, it is dropped",
+				span.SpanID, span.SpanType, missing)
+		default:
+			logger.CtxWarnf(ctx, "span[%s] of type [%s] misses required tags %v", span.SpanID, span.SpanType, missing)
+			valid = append(valid, span)
+		}
+	}
+	if len(valid) > 0 {
+		if err := e.inner.ExportSpans(ctx, valid); err != nil {
+			return err
+		}
+	}
+	return validationErr
+}
+
+func (e *ValidatingExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return e.inner.ExportFiles(ctx, files)
+}
+
+func missingTags(span *entity.UploadSpan, keys []string) []string {
+	var missing []string
+	for _, key := range keys {
+		if _, ok := span.TagsString[key]; ok {
+			continue
+		}
+		if _, ok := span.TagsLong[key]; ok {
+			continue
+		}
+		if _, ok := span.TagsDouble[key]; ok {
+			continue
+		}
+		if _, ok := span.TagsBool[key]; ok {
+			continue
+		}
+		missing = append(missing, key)
+	}
+	return missing
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidatingExporter(t *testing.T) {
+	Convey("ValidatingExporter", t, func() {
+		ctx := context.Background()
+		var exported []*entity.UploadSpan
+		inner := &funcExporter{exportSpans: func(ctx context.Context, spans []*entity.UploadSpan) error {
+			exported = append(exported, spans...)
+			return nil
+		}}
+		validModel := &entity.UploadSpan{
+			SpanID:     "valid",
+			SpanType:   tracespec.VModelSpanType,
+			TagsString: map[string]string{tracespec.ModelName: "gpt-4o"},
+			TagsLong:   map[string]int64{tracespec.InputTokens: 10},
+		}
+		invalidModel := &entity.UploadSpan{
+			SpanID:     "invalid",
+			SpanType:   tracespec.VModelSpanType,
+			TagsString: map[string]string{tracespec.ModelName: "gpt-4o"},
+		}
+		tool := &entity.UploadSpan{SpanID: "tool", SpanType: tracespec.VToolSpanType}
+		spans := []*entity.UploadSpan{validModel, invalidModel, tool}
+		rule := ValidationRule{
+			SpanType:     tracespec.VModelSpanType,
+			RequiredTags: []string{tracespec.ModelName, tracespec.InputTokens},
+		}
+
+		Convey("should export spans violating rules of log action", func() {
+			e := NewValidatingExporter(inner, []ValidationRule{rule})
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(exported, ShouldResemble, spans)
+		})
+
+		Convey("should drop spans violating rules of drop action", func() {
+			rule.OnViolation = ValidationActionDrop
+			e := NewValidatingExporter(inner, []ValidationRule{rule})
+			So(e.ExportSpans(ctx, spans), ShouldBeNil)
+			So(exported, ShouldResemble, []*entity.UploadSpan{validModel, tool})
+		})
+
+		Convey("should return error for spans violating rules of error action", func() {
+			rule.OnViolation = ValidationActionError
+			e := NewValidatingExporter(inner, []ValidationRule{rule})
+			So(e.ExportSpans(ctx, spans), ShouldNotBeNil)
+			So(exported, ShouldResemble, []*entity.UploadSpan{validModel, tool})
+		})
+
+		Convey("should return error for any violation in strict mode", func() {
+			e := NewValidatingExporter(inner, []ValidationRule{rule}, WithStrictMode())
+			So(e.ExportSpans(ctx, spans), ShouldNotBeNil)
+			So(exported, ShouldResemble, []*entity.UploadSpan{validModel, tool})
+			So(e.ExportSpans(ctx, []*entity.UploadSpan{validModel}), ShouldBeNil)
+		})
+
+		Convey("should return error of inner exporter", func() {
+			inner.exportSpans = func(ctx context.Context, spans []*entity.UploadSpan) error {
+				return errors.New("unavailable")
+			}
+			e := NewValidatingExporter(inner, []ValidationRule{rule})
+			So(e.ExportSpans(ctx, spans), ShouldNotBeNil)
+		})
+	})
+}
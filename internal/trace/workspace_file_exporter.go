@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+const (
+	DefaultWorkspaceFilePermissions os.FileMode = 0644
+
+	// defaultWorkspaceFileName is the file name of spans without workspace id, which is never produced by
+	// workspaceFileName for a non-empty workspace id, as `%` is always followed by two hex digits there.
+	defaultWorkspaceFileName = "%_default"
+)
+
+var _ Exporter = (*WorkspaceFileExporter)(nil)
+
+// WorkspaceFileExporter exports spans to local markdown files, one file per workspace, named `<dir>/<workspaceID>.md`,
+// which prevents spans of different tenants from being written to the same file. Characters of workspace id other than
+// letters, digits, `-` and `_` are escaped as `%XX`, and spans without workspace id are written to `%_default.md`.
+// Files are kept open until Close.
+type WorkspaceFileExporter struct {
+	dir   string
+	perm  os.FileMode
+	files sync.Map // file name -> *workspaceFile
+	mu    sync.Mutex
+}
+
+type workspaceFile struct {
+	mu     sync.Mutex
+	f      *os.File
+	closed bool // set by Close under mu, so that writes never go to a closed file
+}
+
+// WorkspaceFileOption is used to set options for WorkspaceFileExporter.
+type WorkspaceFileOption func(e *WorkspaceFileExporter)
+
+// WithWorkspaceFilePermissions set the permissions of created files. Default is 0644.
+func WithWorkspaceFilePermissions(perm os.FileMode) WorkspaceFileOption {
+	return func(e *WorkspaceFileExporter) {
+		e.perm = perm
+	}
+}
+
+// NewWorkspaceFileExporter creates a WorkspaceFileExporter writing files to dir, which is the current directory if empty.
+func NewWorkspaceFileExporter(dir string, opts ...WorkspaceFileOption) *WorkspaceFileExporter {
+	if dir == "" {
+		dir = "."
+	}
+	e := &WorkspaceFileExporter{
+		dir:  dir,
+		perm: DefaultWorkspaceFilePermissions,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	return e
+}
+
+// ExportSpans appends spans to the files of their workspaces.
+func (e *WorkspaceFileExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	var names []string
+	contents := make(map[string]*strings.Builder)
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		name := workspaceFileName(span.WorkspaceID)
+		sb, ok := contents[name]
+		if !ok {
+			sb = &strings.Builder{}
+			contents[name] = sb
+			names = append(names, name)
+		}
+		_ = spanToMarkdown(sb, span)
+	}
+
+	for _, name := range names {
+		if err := e.writeFile(name, contents[name].String()); err != nil {
+			logger.CtxErrorf(ctx, "failed to write spans to workspace trace file: %v", err)
+			return err
+		}
+	}
+	logger.CtxDebugf(ctx, "exported %d spans to %d workspace files in: %s", len(spans), len(names), e.dir)
+	return nil
+}
+
+// ExportFiles is a no-op, files are not written to the markdown files.
+func (e *WorkspaceFileExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return nil
+}
+
+// Close closes all opened files. Files are opened again if spans are exported after Close.
+func (e *WorkspaceFileExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var firstErr error
+	e.files.Range(func(key, value interface{}) bool {
+		// delete first, so that concurrent writes finding the file closed open a new one
+		e.files.Delete(key)
+		file := value.(*workspaceFile)
+		file.mu.Lock()
+		file.closed = true
+		if err := file.f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		file.mu.Unlock()
+		return true
+	})
+	return firstErr
+}
+
+// writeFile appends content to the file of name. If the file is closed by a concurrent Close, it is opened again.
+func (e *WorkspaceFileExporter) writeFile(name, content string) error {
+	for {
+		file, err := e.getFile(name)
+		if err != nil {
+			return err
+		}
+		file.mu.Lock()
+		if file.closed {
+			file.mu.Unlock()
+			continue
+		}
+		_, err = file.f.WriteString(content)
+		file.mu.Unlock()
+		return err
+	}
+}
+
+// getFile returns the cached file of name, or opens it in append mode.
+func (e *WorkspaceFileExporter) getFile(name string) (*workspaceFile, error) {
+	if v, ok := e.files.Load(name); ok {
+		return v.(*workspaceFile), nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if v, ok := e.files.Load(name); ok {
+		return v.(*workspaceFile), nil
+	}
+	if err := os.MkdirAll(e.dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(e.dir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, e.perm)
+	if err != nil {
+		return nil, err
+	}
+	file := &workspaceFile{f: f}
+	e.files.Store(name, file)
+	return file, nil
+}
+
+// workspaceFileName returns the file name of workspace. Characters other than letters, digits, `-` and `_` are
+// escaped as `%XX` of each byte, so that workspace id never escapes the directory, and different workspace ids
+// never share a file.
+func workspaceFileName(workspaceID string) string {
+	if workspaceID == "" {
+		return defaultWorkspaceFileName + ".md"
+	}
+	var sb strings.Builder
+	for i := 0; i < len(workspaceID); i++ {
+		c := workspaceID[i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' || c == '_' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String() + ".md"
+}
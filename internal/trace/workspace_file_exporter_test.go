@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWorkspaceFileExporter(t *testing.T) {
+	Convey("WorkspaceFileExporter", t, func() {
+		ctx := context.Background()
+		dir := filepath.Join(t.TempDir(), "traces")
+		e := NewWorkspaceFileExporter(dir, WithWorkspaceFilePermissions(0600))
+		defer e.Close()
+
+		So(e.ExportSpans(ctx, []*entity.UploadSpan{
+			{TraceID: "trace1", SpanID: "span1", SpanName: "tenant_a_span", WorkspaceID: "ws_a"},
+			{TraceID: "trace2", SpanID: "span2", SpanName: "tenant_b_span", WorkspaceID: "ws_b"},
+			{TraceID: "trace3", SpanID: "span3", SpanName: "escape_span", WorkspaceID: "../ws_c"},
+		}), ShouldBeNil)
+		So(e.ExportSpans(ctx, []*entity.UploadSpan{
+			{TraceID: "trace4", SpanID: "span4", SpanName: "tenant_a_span2", WorkspaceID: "ws_a"},
+		}), ShouldBeNil)
+
+		content, err := os.ReadFile(filepath.Join(dir, "ws_a.md"))
+		So(err, ShouldBeNil)
+		So(string(content), ShouldContainSubstring, "## Span: tenant_a_span\n")
+		So(string(content), ShouldContainSubstring, "## Span: tenant_a_span2\n")
+		So(string(content), ShouldNotContainSubstring, "tenant_b_span")
+
+		info, err := os.Stat(filepath.Join(dir, "ws_b.md"))
+		So(err, ShouldBeNil)
+		So(info.Mode().Perm(), ShouldEqual, os.FileMode(0600))
+
+		_, err = os.Stat(filepath.Join(dir, "%2E%2E%2Fws_c.md"))
+		So(err, ShouldBeNil)
+
+		Convey("workspace ids should never share a file", func() {
+			names := map[string]string{}
+			for _, id := range []string{"", "default", "%_default", "_default", "ws.a", "ws_a", "ws/a", "ws%2Fa", "工作区"} {
+				name := workspaceFileName(id)
+				So(names, ShouldNotContainKey, name)
+				names[name] = id
+				So(filepath.Base(name), ShouldEqual, name)
+			}
+			So(workspaceFileName(""), ShouldEqual, "%_default.md")
+		})
+
+		Convey("concurrent Close should not fail writes", func() {
+			var wg sync.WaitGroup
+			errs := make(chan error, 10)
+			for i := 0; i < 10; i++ {
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					errs <- e.ExportSpans(ctx, []*entity.UploadSpan{
+						{TraceID: "trace6", SpanID: "span6", SpanName: "concurrent", WorkspaceID: "ws_a"},
+					})
+				}()
+				go func() {
+					defer wg.Done()
+					_ = e.Close()
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				So(err, ShouldBeNil)
+			}
+			content, err := os.ReadFile(filepath.Join(dir, "ws_a.md"))
+			So(err, ShouldBeNil)
+			So(strings.Count(string(content), "## Span: concurrent\n"), ShouldEqual, 10)
+		})
+
+		Convey("files should be reopened after Close", func() {
+			So(e.Close(), ShouldBeNil)
+			So(e.ExportSpans(ctx, []*entity.UploadSpan{
+				{TraceID: "trace5", SpanID: "span5", SpanName: "after_close", WorkspaceID: "ws_a"},
+			}), ShouldBeNil)
+			content, err := os.ReadFile(filepath.Join(dir, "ws_a.md"))
+			So(err, ShouldBeNil)
+			So(string(content), ShouldContainSubstring, "## Span: after_close\n")
+		})
+	})
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/logger"
+)
+
+var _ Exporter = (*WriterExporter)(nil)
+
+// WriterExporter exports spans in markdown, or JSON lines, to an io.Writer such as os.Stdout or a bytes.Buffer.
+// Writes are serialized by a mutex, so it is safe for concurrent export. Buffered writers such as bufio.Writer
+// are not flushed by it.
+type WriterExporter struct {
+	w              io.Writer
+	streamingWrite bool // write markdown of each span through a fixed size buffer
+	ndjson         bool // write spans as JSON lines instead of markdown
+	mu             sync.Mutex
+}
+
+// WriterExporterOption is used to set options for WriterExporter.
+type WriterExporterOption func(e *WriterExporter)
+
+// WithJSONLinesFormat set WriterExporter to write each span as a line of JSON of UploadSpan, instead of markdown.
+func WithJSONLinesFormat() WriterExporterOption {
+	return func(e *WriterExporter) {
+		e.ndjson = true
+	}
+}
+
+// NewWriterExporter creates a WriterExporter writing spans to w in markdown by default.
+func NewWriterExporter(w io.Writer, opts ...WriterExporterOption) *WriterExporter {
+	e := &WriterExporter{
+		w: w,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	return e
+}
+
+func (e *WriterExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	if len(spans) == 0 || e.w == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.writeSpans(e.w, spans); err != nil {
+		logger.CtxErrorf(ctx, "failed to write spans to writer: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ExportFiles is a no-op, files of spans are not written.
+func (e *WriterExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	return nil
+}
+
+// writeSpans writes markdown of spans to f, through a fixed size buffer in streaming mode,
+// or span by span after building the markdown of each span in memory. In NDJSON format, each span
+// is written as a JSON line. Planning trees of spans are written after spans in markdown. It is also used by FileExporter to write spans to the opened file.
+func (e *WriterExporter) writeSpans(f io.Writer, spans []*entity.UploadSpan) error {
+	if e.ndjson {
+		w := bufio.NewWriterSize(f, fileExporterChunkSize)
+		enc := json.NewEncoder(w)
+		for _, span := range spans {
+			if span == nil {
+				continue
+			}
+			if err := enc.Encode(span); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	}
+	if e.streamingWrite {
+		w := bufio.NewWriterSize(f, fileExporterChunkSize)
+		for _, span := range spans {
+			if span == nil {
+				continue
+			}
+			if err := spanToMarkdown(w, span); err != nil {
+				return err
+			}
+		}
+		if err := writePlanningTrees(w, spans); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		sb := &strings.Builder{}
+		_ = spanToMarkdown(sb, span)
+		if _, err := io.WriteString(f, sb.String()); err != nil {
+			return err
+		}
+	}
+	return writePlanningTrees(f, spans)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package trace
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriterExporter(t *testing.T) {
+	Convey("WriterExporter", t, func() {
+		ctx := context.Background()
+		spans := []*entity.UploadSpan{
+			{TraceID: "trace1", SpanID: "span1", SpanName: "llm", SpanType: "model"},
+			nil,
+			{TraceID: "trace1", SpanID: "span2", SpanName: "tool", StatusCode: 1},
+		}
+
+		Convey("should write markdown to writer", func() {
+			buf := &bytes.Buffer{}
+			So(NewWriterExporter(buf).ExportSpans(ctx, spans), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, "# Trace: trace1")
+			So(buf.String(), ShouldContainSubstring, "## Span: llm")
+			So(buf.String(), ShouldContainSubstring, "## Span: tool")
+		})
+
+		Convey("should write JSON lines to writer", func() {
+			buf := &bytes.Buffer{}
+			So(NewWriterExporter(buf, WithJSONLinesFormat()).ExportSpans(ctx, spans), ShouldBeNil)
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			So(len(lines), ShouldEqual, 2)
+			var span entity.UploadSpan
+			So(json.Unmarshal([]byte(lines[1]), &span), ShouldBeNil)
+			So(span.SpanID, ShouldEqual, "span2")
+		})
+
+		Convey("should not flush buffered writer", func() {
+			buf := &bytes.Buffer{}
+			w := bufio.NewWriter(buf)
+			So(NewWriterExporter(w).ExportSpans(ctx, spans[:1]), ShouldBeNil)
+			So(buf.Len(), ShouldEqual, 0)
+			So(w.Flush(), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, "## Span: llm")
+		})
+
+		Convey("should write batches of concurrent export without interleaving", func() {
+			buf := &bytes.Buffer{}
+			e := NewWriterExporter(buf, WithJSONLinesFormat())
+			wg := sync.WaitGroup{}
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_ = e.ExportSpans(ctx, spans)
+				}()
+			}
+			wg.Wait()
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			So(len(lines), ShouldEqual, 20)
+			for _, line := range lines {
+				So(json.Valid([]byte(line)), ShouldBeTrue)
+			}
+		})
+	})
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"context"
+	"time"
+)
+
+// WithoutCancel returns a copy of parent that is not canceled when parent is canceled,
+// which keeps the values of parent. The same as context.WithoutCancel of Go 1.21.
+func WithoutCancel(parent context.Context) context.Context {
+	if parent == nil {
+		return context.Background()
+	}
+	return withoutCancelCtx{parent: parent}
+}
+
+type withoutCancelCtx struct {
+	parent context.Context
+}
+
+func (c withoutCancelCtx) Deadline() (deadline time.Time, ok bool) {
+	return time.Time{}, false
+}
+
+func (c withoutCancelCtx) Done() <-chan struct{} {
+	return nil
+}
+
+func (c withoutCancelCtx) Err() error {
+	return nil
+}
+
+func (c withoutCancelCtx) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testCtxKey struct{}
+
+func TestWithoutCancel(t *testing.T) {
+	Convey("WithoutCancel should keep values and ignore cancellation", t, func() {
+		parent, cancel := context.WithTimeout(context.WithValue(context.Background(), testCtxKey{}, "v"), time.Minute)
+		ctx := WithoutCancel(parent)
+		cancel()
+
+		So(parent.Err(), ShouldNotBeNil)
+		So(ctx.Err(), ShouldBeNil)
+		So(ctx.Done(), ShouldBeNil)
+		_, ok := ctx.Deadline()
+		So(ok, ShouldBeFalse)
+		So(ctx.Value(testCtxKey{}), ShouldEqual, "v")
+	})
+}
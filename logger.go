@@ -4,6 +4,8 @@
 package cozeloop
 
 import (
+	"context"
+
 	"github.com/alva-ai/cozeloop-go/internal/logger"
 )
 
@@ -37,3 +39,15 @@ func SetLogLevel(level LogLevel) {
 func GetLogger() Logger {
 	return logger.GetLogger()
 }
+
+// LogContextWithSpan returns a copy of ctx carrying trace_id and span_id of span.
+// Every log line emitted from the returned ctx by default logger contains them,
+// which correlates logs with traces. Custom Logger can get them by SpanFromLogContext.
+func LogContextWithSpan(ctx context.Context, span Span) context.Context {
+	return logger.WithSpan(ctx, span)
+}
+
+// SpanFromLogContext returns trace_id and span_id injected by LogContextWithSpan.
+func SpanFromLogContext(ctx context.Context) (traceID, spanID string) {
+	return logger.SpanFromContext(ctx)
+}
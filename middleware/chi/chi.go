@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package chi provides the Chi router middleware for automatic tracing.
+// It is a separate module, so that users not using Chi don't depend on it.
+package chi
+
+import (
+	"net/http"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/middleware"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	chiv5 "github.com/go-chi/chi/v5"
+)
+
+// Option is used to set options for TraceMiddleware, the same as the net/http middleware.
+type Option = middleware.Option
+
+// WithPropagator set the propagator used to extract trace context from request headers.
+// Default extracts cozeloop header format (the same as Span.ToHeader), W3C traceparent and B3 headers.
+func WithPropagator(propagator cozeloop.Propagator) Option {
+	return middleware.WithPropagator(propagator)
+}
+
+// WithSpanNameFormatter set the formatter of span name, which is applied to the route pattern.
+func WithSpanNameFormatter(fn func(name, spanType string) string) Option {
+	return middleware.WithSpanNameFormatter(fn)
+}
+
+// WithSpanType set the span type of server spans. Default is http_server.
+func WithSpanType(spanType string) Option {
+	return middleware.WithSpanType(spanType)
+}
+
+// TraceMiddleware returns a Chi middleware, which extracts trace context from request headers,
+// starts a span named with the route pattern, such as /users/{id}, and finishes it with the response status code.
+// The span is stored in the context of request, use GetSpan to retrieve it.
+func TraceMiddleware(client cozeloop.Client, opts ...Option) func(http.Handler) http.Handler {
+	config := middleware.NewConfig(opts...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := matchRoutePattern(r)
+			name := route
+			if name == "" {
+				name = r.URL.Path
+			}
+			ctx, span := config.StartSpan(r.Context(), client, name, &middleware.Request{
+				Method: r.Method,
+				Route:  route,
+				URL:    r.URL.String(),
+				Header: middleware.HeaderToMap(r.Header),
+			})
+			recorder := middleware.NewStatusRecorder(w)
+			defer func() {
+				if p := recover(); p != nil {
+					middleware.FinishSpan(ctx, span, http.StatusInternalServerError)
+					panic(p)
+				}
+				// route pattern of chi context is complete after routing
+				if rctx := chiv5.RouteContext(r.Context()); rctx != nil {
+					if pattern := rctx.RoutePattern(); pattern != "" && pattern != route {
+						span.SetTags(ctx, map[string]interface{}{tracespec.HTTPRoute: pattern})
+					}
+				}
+				middleware.FinishSpan(ctx, span, recorder.StatusCode())
+			}()
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetSpan returns the span started by TraceMiddleware from request, ok is false if not exist.
+func GetSpan(r *http.Request) (cozeloop.Span, bool) {
+	return middleware.SpanFromContext(r.Context())
+}
+
+// matchRoutePattern returns the route pattern of request. Middlewares registered by Use run before routing,
+// when the route pattern of chi context is incomplete, so the routes are matched in advance.
+func matchRoutePattern(r *http.Request) string {
+	rctx := chiv5.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	if rctx.Routes != nil {
+		matched := chiv5.NewRouteContext()
+		if rctx.Routes.Match(matched, r.Method, r.URL.Path) {
+			return matched.RoutePattern()
+		}
+	}
+	return rctx.RoutePattern()
+}
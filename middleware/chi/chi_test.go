@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package chi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	"github.com/alva-ai/cozeloop-go/tracetest"
+	chiv5 "github.com/go-chi/chi/v5"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTraceMiddleware(t *testing.T) {
+	Convey("TraceMiddleware", t, func() {
+		ctx := context.Background()
+		recorder := tracetest.NewRecorderExporter()
+		client, err := cozeloop.NewClient(cozeloop.WithWorkspaceID("ws"), cozeloop.WithAPIToken("token"),
+			cozeloop.WithExporter(recorder))
+		So(err, ShouldBeNil)
+		defer client.Close(ctx)
+
+		router := chiv5.NewRouter()
+		router.Use(TraceMiddleware(client))
+		router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := GetSpan(r); !ok {
+				w.WriteHeader(http.StatusTeapot)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+		req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		So(rec.Code, ShouldEqual, http.StatusCreated)
+		client.Flush(ctx)
+
+		spans := recorder.Spans()
+		So(len(spans), ShouldEqual, 1)
+		So(spans[0].SpanName, ShouldEqual, "/users/{id}")
+		So(spans[0].TraceID, ShouldEqual, "0af7651916cd43dd8448eb211c80319c")
+		So(spans[0].ParentID, ShouldEqual, "b7ad6b7169203331")
+		So(spans[0].TagsString[tracespec.HTTPMethod], ShouldEqual, http.MethodGet)
+		So(spans[0].TagsString[tracespec.HTTPRoute], ShouldEqual, "/users/{id}")
+		So(spans[0].TagsLong[tracespec.HTTPStatusCode], ShouldEqual, http.StatusCreated)
+	})
+}
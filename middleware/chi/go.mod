@@ -0,0 +1,37 @@
+module github.com/alva-ai/cozeloop-go/middleware/chi
+
+go 1.18
+
+require (
+	github.com/alva-ai/cozeloop-go v0.1.19
+	github.com/alva-ai/cozeloop-go/spec v0.0.0-20260222071616-f7727aea295e
+	github.com/go-chi/chi/v5 v5.0.12
+	github.com/smartystreets/goconvey v1.8.1
+)
+
+require (
+	github.com/bluele/gcache v0.0.2 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/golang-jwt/jwt v3.2.2+incompatible // indirect
+	github.com/gopherjs/gopherjs v1.17.2 // indirect
+	github.com/json-iterator/go v1.1.12 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/nikolalohinski/gonja/v2 v2.3.1 // indirect
+	github.com/pkg/errors v0.9.2-0.20201214064552-5dd12d0cfe7f // indirect
+	github.com/sirupsen/logrus v1.9.3 // indirect
+	github.com/smarty/assertions v1.15.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	golang.org/x/exp v0.0.0-20240404231335-c0f41cb1a7a0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	golang.org/x/time v0.7.0 // indirect
+)
+
+replace (
+	github.com/alva-ai/cozeloop-go => ../..
+	github.com/alva-ai/cozeloop-go/spec => ../../spec
+)
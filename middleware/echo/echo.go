@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package echo provides the Echo middleware for automatic tracing.
+// It is a separate module, so that users not using Echo don't depend on it.
+package echo
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/middleware"
+	echov4 "github.com/labstack/echo/v4"
+)
+
+// SpanContextKey is the key of span stored in echo.Context.
+const SpanContextKey = "cozeloop_span"
+
+// Option is used to set options for TraceMiddleware, the same as the net/http middleware.
+type Option = middleware.Option
+
+// WithPropagator set the propagator used to extract trace context from request headers.
+// Default extracts cozeloop header format (the same as Span.ToHeader), W3C traceparent and B3 headers.
+func WithPropagator(propagator cozeloop.Propagator) Option {
+	return middleware.WithPropagator(propagator)
+}
+
+// WithSpanNameFormatter set the formatter of span name, which is applied to the route template.
+func WithSpanNameFormatter(fn func(name, spanType string) string) Option {
+	return middleware.WithSpanNameFormatter(fn)
+}
+
+// WithSpanType set the span type of server spans. Default is http_server.
+func WithSpanType(spanType string) Option {
+	return middleware.WithSpanType(spanType)
+}
+
+// TraceMiddleware returns an Echo middleware, which extracts trace context from request headers,
+// starts a span named with the route template c.Path() for each request, and finishes it with the response code.
+// The span is stored in echo.Context, use GetSpan to retrieve it. It's also in the context of request.
+func TraceMiddleware(client cozeloop.Client, opts ...Option) echov4.MiddlewareFunc {
+	config := middleware.NewConfig(opts...)
+	return func(next echov4.HandlerFunc) echov4.HandlerFunc {
+		return func(c echov4.Context) (err error) {
+			req := c.Request()
+			name := c.Path()
+			if name == "" {
+				name = req.URL.Path
+			}
+			ctx, span := config.StartSpan(req.Context(), client, name, &middleware.Request{
+				Method: req.Method,
+				Route:  c.Path(),
+				URL:    req.URL.String(),
+				Header: middleware.HeaderToMap(req.Header),
+			})
+			c.SetRequest(req.WithContext(ctx))
+			c.Set(SpanContextKey, span)
+
+			defer func() {
+				if p := recover(); p != nil {
+					middleware.FinishSpan(ctx, span, http.StatusInternalServerError)
+					panic(p)
+				}
+			}()
+
+			err = next(c)
+			statusCode := c.Response().Status
+			if err != nil {
+				span.SetError(ctx, err)
+				// the error is handled by HTTPErrorHandler after middlewares, so the response is not written yet
+				var httpErr *echov4.HTTPError
+				if errors.As(err, &httpErr) {
+					statusCode = httpErr.Code
+				} else if !c.Response().Committed {
+					statusCode = http.StatusInternalServerError
+				}
+			}
+			middleware.FinishSpan(ctx, span, statusCode)
+			return err
+		}
+	}
+}
+
+// GetSpan returns the span started by TraceMiddleware from echo.Context, ok is false if not exist.
+func GetSpan(c echov4.Context) (cozeloop.Span, bool) {
+	span, ok := c.Get(SpanContextKey).(cozeloop.Span)
+	return span, ok
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package echo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	"github.com/alva-ai/cozeloop-go/tracetest"
+	echov4 "github.com/labstack/echo/v4"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTraceMiddleware(t *testing.T) {
+	Convey("TraceMiddleware", t, func() {
+		ctx := context.Background()
+		recorder := tracetest.NewRecorderExporter()
+		client, err := cozeloop.NewClient(cozeloop.WithWorkspaceID("ws"), cozeloop.WithAPIToken("token"),
+			cozeloop.WithExporter(recorder))
+		So(err, ShouldBeNil)
+		defer client.Close(ctx)
+
+		e := echov4.New()
+		e.Use(TraceMiddleware(client))
+		e.GET("/users/:id", func(c echov4.Context) error {
+			span, ok := GetSpan(c)
+			if !ok || client.GetSpanFromContext(c.Request().Context()).GetSpanID() != span.GetSpanID() {
+				return c.NoContent(http.StatusTeapot)
+			}
+			return c.String(http.StatusOK, "ok")
+		})
+		e.GET("/error", func(c echov4.Context) error {
+			return echov4.NewHTTPError(http.StatusServiceUnavailable, "unavailable")
+		})
+
+		Convey("should trace request with route template", func() {
+			req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+			req.Header.Set("X-Cozeloop-Traceparent", "00-0123456789abcdef0123456789abcdef-0123456789abcdef-01")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			So(rec.Code, ShouldEqual, http.StatusOK)
+			client.Flush(ctx)
+
+			spans := recorder.Spans()
+			So(len(spans), ShouldEqual, 1)
+			So(spans[0].SpanName, ShouldEqual, "/users/:id")
+			So(spans[0].TraceID, ShouldEqual, "0123456789abcdef0123456789abcdef")
+			So(spans[0].ParentID, ShouldEqual, "0123456789abcdef")
+			So(spans[0].TagsString[tracespec.HTTPRoute], ShouldEqual, "/users/:id")
+			So(spans[0].TagsLong[tracespec.HTTPStatusCode], ShouldEqual, http.StatusOK)
+		})
+
+		Convey("should record status code of returned error", func() {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/error", nil))
+			So(rec.Code, ShouldEqual, http.StatusServiceUnavailable)
+			client.Flush(ctx)
+
+			spans := recorder.Spans()
+			So(len(spans), ShouldEqual, 1)
+			So(spans[0].StatusCode, ShouldEqual, http.StatusServiceUnavailable)
+		})
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package fiber provides the Fiber middleware for automatic tracing.
+// It is a separate module, so that users not using Fiber don't depend on it.
+package fiber
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/middleware"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	fiberv2 "github.com/gofiber/fiber/v2"
+)
+
+// SpanLocalsKey is the key of span stored in fiber.Ctx locals.
+const SpanLocalsKey = "cozeloop_span"
+
+// Option is used to set options for TraceMiddleware, the same as the net/http middleware.
+type Option = middleware.Option
+
+// WithPropagator set the propagator used to extract trace context from request headers.
+// Default extracts cozeloop header format (the same as Span.ToHeader), W3C traceparent and B3 headers.
+func WithPropagator(propagator cozeloop.Propagator) Option {
+	return middleware.WithPropagator(propagator)
+}
+
+// WithSpanNameFormatter set the formatter of span name, which is applied to the route path.
+func WithSpanNameFormatter(fn func(name, spanType string) string) Option {
+	return middleware.WithSpanNameFormatter(fn)
+}
+
+// WithSpanType set the span type of server spans. Default is http_server.
+func WithSpanType(spanType string) Option {
+	return middleware.WithSpanType(spanType)
+}
+
+// TraceMiddleware returns a Fiber handler, which extracts trace context from request headers,
+// starts a span named with c.Route().Path, and finishes it with the response status code when handlers return.
+// The span is stored in c.Locals, use GetSpan to retrieve it. It's also in c.UserContext().
+//
+// Fiber resolves the route of handler after middlewares, so if TraceMiddleware is registered by app.Use,
+// the span is named with the mount path, and the matched route is set to tag http.route.
+// Register it on routes or use WithSpanNameFormatter if the span name matters.
+func TraceMiddleware(client cozeloop.Client, opts ...Option) fiberv2.Handler {
+	config := middleware.NewConfig(opts...)
+	return func(c *fiberv2.Ctx) (err error) {
+		header := make(map[string]string)
+		c.Request().Header.VisitAll(func(key, value []byte) {
+			if _, ok := header[string(key)]; !ok {
+				header[string(key)] = string(value)
+			}
+		})
+		ctx, span := config.StartSpan(c.UserContext(), client, c.Route().Path, &middleware.Request{
+			Method: c.Method(),
+			URL:    c.BaseURL() + c.OriginalURL(),
+			Header: header,
+		})
+		c.SetUserContext(ctx)
+		c.Locals(SpanLocalsKey, span)
+
+		defer func() {
+			if p := recover(); p != nil {
+				middleware.FinishSpan(ctx, span, http.StatusInternalServerError)
+				panic(p)
+			}
+		}()
+
+		err = c.Next()
+		span.SetTags(ctx, map[string]interface{}{tracespec.HTTPRoute: c.Route().Path})
+		statusCode := c.Response().StatusCode()
+		if err != nil {
+			span.SetError(ctx, err)
+			// the error is handled by ErrorHandler after handlers return, so the response is not written yet
+			var fiberErr *fiberv2.Error
+			if errors.As(err, &fiberErr) {
+				statusCode = fiberErr.Code
+			} else {
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		middleware.FinishSpan(ctx, span, statusCode)
+		return err
+	}
+}
+
+// GetSpan returns the span started by TraceMiddleware from fiber.Ctx, ok is false if not exist.
+func GetSpan(c *fiberv2.Ctx) (cozeloop.Span, bool) {
+	span, ok := c.Locals(SpanLocalsKey).(cozeloop.Span)
+	return span, ok
+}
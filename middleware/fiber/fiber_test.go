@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package fiber
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	"github.com/alva-ai/cozeloop-go/tracetest"
+	fiberv2 "github.com/gofiber/fiber/v2"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTraceMiddleware(t *testing.T) {
+	Convey("TraceMiddleware", t, func() {
+		ctx := context.Background()
+		recorder := tracetest.NewRecorderExporter()
+		client, err := cozeloop.NewClient(cozeloop.WithWorkspaceID("ws"), cozeloop.WithAPIToken("token"),
+			cozeloop.WithExporter(recorder))
+		So(err, ShouldBeNil)
+		defer client.Close(ctx)
+
+		app := fiberv2.New()
+		app.Get("/users/:id", TraceMiddleware(client), func(c *fiberv2.Ctx) error {
+			span, ok := GetSpan(c)
+			if !ok || client.GetSpanFromContext(c.UserContext()).GetSpanID() != span.GetSpanID() {
+				return c.SendStatus(http.StatusTeapot)
+			}
+			return c.SendString("ok")
+		})
+		app.Get("/error", TraceMiddleware(client), func(c *fiberv2.Ctx) error {
+			return fiberv2.NewError(http.StatusServiceUnavailable, "unavailable")
+		})
+
+		Convey("should trace request with route path", func() {
+			req := httptest.NewRequest(http.MethodGet, "/users/123?q=1", nil)
+			req.Header.Set("X-Cozeloop-Traceparent", "00-0123456789abcdef0123456789abcdef-0123456789abcdef-01")
+			resp, err := app.Test(req)
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			client.Flush(ctx)
+
+			spans := recorder.Spans()
+			So(len(spans), ShouldEqual, 1)
+			So(spans[0].SpanName, ShouldEqual, "/users/:id")
+			So(spans[0].TraceID, ShouldEqual, "0123456789abcdef0123456789abcdef")
+			So(spans[0].ParentID, ShouldEqual, "0123456789abcdef")
+			So(spans[0].TagsString[tracespec.HTTPMethod], ShouldEqual, http.MethodGet)
+			So(spans[0].TagsString[tracespec.HTTPURL], ShouldEqual, "http://example.com/users/123?q=1")
+			So(spans[0].TagsLong[tracespec.HTTPStatusCode], ShouldEqual, http.StatusOK)
+		})
+
+		Convey("should record status code of returned error", func() {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/error", nil))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusServiceUnavailable)
+			client.Flush(ctx)
+
+			spans := recorder.Spans()
+			So(len(spans), ShouldEqual, 1)
+			So(spans[0].StatusCode, ShouldEqual, http.StatusServiceUnavailable)
+		})
+	})
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package httpclient provides the http.RoundTripper for automatic tracing of outbound http requests.
+package httpclient
+
+import (
+	"net/http"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/middleware"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+)
+
+// Option is used to set options for TracingTransport.
+type Option func(c *config)
+
+type config struct {
+	propagator        cozeloop.Propagator
+	spanNameFormatter func(name, spanType string) string
+	keepQuery         bool
+}
+
+// WithPropagator set the propagator used to inject trace context into request headers.
+// Default injects cozeloop header format, the same as Span.ToHeader.
+func WithPropagator(propagator cozeloop.Propagator) Option {
+	return func(c *config) {
+		if propagator != nil {
+			c.propagator = propagator
+		}
+	}
+}
+
+// WithSpanNameFormatter set the formatter of span name, which is applied to the default span name,
+// such as `GET api.example.com`. See cozeloop.TemplateSpanNameFormatter.
+func WithSpanNameFormatter(fn func(name, spanType string) string) Option {
+	return func(c *config) {
+		c.spanNameFormatter = fn
+	}
+}
+
+// WithQueryParams set whether to keep query params in `http.url` tag. Default is false, query params are
+// stripped as they may contain credentials, such as api keys.
+func WithQueryParams(keep bool) Option {
+	return func(c *config) {
+		c.keepQuery = keep
+	}
+}
+
+// TracingTransport is an http.RoundTripper which starts a span for each request.
+type TracingTransport struct {
+	client cozeloop.Client
+	inner  http.RoundTripper
+	config *config
+}
+
+// NewTracingTransport returns an http.RoundTripper wrapping inner, which is http.DefaultTransport if nil.
+// For each request, it starts a child span of the span in the context of request named `<method> <host>`,
+// injects trace context into request headers, and finishes the span with the response status code when
+// the response is returned, or with the error if the request fails.
+func NewTracingTransport(client cozeloop.Client, inner http.RoundTripper, opts ...Option) http.RoundTripper {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	c := &config{
+		propagator: cozeloop.NewLoopPropagator(),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	return &TracingTransport{
+		client: client,
+		inner:  inner,
+		config: c,
+	}
+}
+
+func (t *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	name := req.Method + " " + req.URL.Host
+	if t.config.spanNameFormatter != nil {
+		name = t.config.spanNameFormatter(name, tracespec.VHTTPClientSpanType)
+	}
+	ctx, span := t.client.StartSpan(req.Context(), name, tracespec.VHTTPClientSpanType)
+	span.SetTags(ctx, map[string]interface{}{
+		tracespec.HTTPMethod: req.Method,
+		tracespec.HTTPURL:    t.spanURL(req),
+	})
+
+	// RoundTripper should not modify the request, so headers are injected into a copy
+	header := make(map[string]string)
+	t.config.propagator.Inject(ctx, header)
+	req = req.Clone(ctx)
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := t.inner.RoundTrip(req)
+	if err != nil {
+		span.SetError(ctx, err)
+		span.Finish(ctx)
+		return nil, err
+	}
+	middleware.FinishSpan(ctx, span, resp.StatusCode)
+	return resp, nil
+}
+
+// spanURL returns the url of request without user info, and without query params unless they are kept.
+func (t *TracingTransport) spanURL(req *http.Request) string {
+	u := *req.URL
+	u.User = nil
+	u.Fragment = ""
+	if !t.config.keepQuery {
+		u.RawQuery = ""
+		u.ForceQuery = false
+	}
+	return u.String()
+}
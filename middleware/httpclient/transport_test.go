@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	"github.com/alva-ai/cozeloop-go/tracetest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTracingTransport(t *testing.T) {
+	Convey("TracingTransport", t, func() {
+		ctx := context.Background()
+		recorder := tracetest.NewRecorderExporter()
+		client, err := cozeloop.NewClient(cozeloop.WithWorkspaceID("ws"), cozeloop.WithAPIToken("token"),
+			cozeloop.WithExporter(recorder))
+		So(err, ShouldBeNil)
+		defer client.Close(ctx)
+
+		var header http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		Convey("should trace request as child span", func() {
+			ctx, parent := client.StartSpan(ctx, "agent", "agent")
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/search?api_key=secret", nil)
+			So(err, ShouldBeNil)
+			httpClient := &http.Client{Transport: NewTracingTransport(client, nil)}
+			resp, err := httpClient.Do(req)
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+			So(req.Header, ShouldBeEmpty)
+			parent.Finish(ctx)
+			client.Flush(ctx)
+
+			spans := recorder.Spans()
+			So(len(spans), ShouldEqual, 2)
+			span := spans[0]
+			So(span.SpanType, ShouldEqual, tracespec.VHTTPClientSpanType)
+			So(span.SpanName, ShouldEqual, "GET "+req.URL.Host)
+			So(span.ParentID, ShouldEqual, parent.GetSpanID())
+			So(span.StatusCode, ShouldEqual, http.StatusServiceUnavailable)
+			So(span.TagsString[tracespec.HTTPMethod], ShouldEqual, http.MethodGet)
+			So(span.TagsString[tracespec.HTTPURL], ShouldEqual, server.URL+"/search")
+			So(span.TagsLong[tracespec.HTTPStatusCode], ShouldEqual, http.StatusServiceUnavailable)
+			So(header.Get("X-Cozeloop-Traceparent"), ShouldContainSubstring, span.SpanID)
+		})
+
+		Convey("should keep query params if set", func() {
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/search?q=go", nil)
+			So(err, ShouldBeNil)
+			resp, err := NewTracingTransport(client, nil, WithQueryParams(true)).RoundTrip(req)
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+			client.Flush(ctx)
+			So(recorder.Spans()[0].TagsString[tracespec.HTTPURL], ShouldEqual, server.URL+"/search?q=go")
+		})
+
+		Convey("should finish span with error", func() {
+			transport := NewTracingTransport(client, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			}))
+			req, err := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+			So(err, ShouldBeNil)
+			_, err = transport.RoundTrip(req)
+			So(err, ShouldNotBeNil)
+			client.Flush(ctx)
+
+			spans := recorder.Spans()
+			So(len(spans), ShouldEqual, 1)
+			So(spans[0].TagsString[tracespec.Error], ShouldEqual, "connection refused")
+			So(spans[0].StatusCode, ShouldNotEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package middleware provides the net/http middleware for automatic tracing, and the options shared by
+// middlewares of web frameworks, such as middleware/echo.
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+)
+
+// Option is used to set options for trace middlewares.
+type Option func(c *Config)
+
+// Config is the config of trace middlewares. It is exported for middlewares of web frameworks,
+// users should set it by Option.
+type Config struct {
+	Propagator        cozeloop.Propagator
+	SpanNameFormatter func(name, spanType string) string
+	SpanType          string
+}
+
+// WithPropagator set the propagator used to extract trace context from request headers.
+// Default extracts cozeloop header format (the same as Span.ToHeader), W3C traceparent and B3 headers.
+func WithPropagator(propagator cozeloop.Propagator) Option {
+	return func(c *Config) {
+		if propagator != nil {
+			c.Propagator = propagator
+		}
+	}
+}
+
+// WithSpanNameFormatter set the formatter of span name, which is applied to the default span name,
+// such as `GET /users/123` for net/http. See cozeloop.TemplateSpanNameFormatter.
+func WithSpanNameFormatter(fn func(name, spanType string) string) Option {
+	return func(c *Config) {
+		c.SpanNameFormatter = fn
+	}
+}
+
+// WithSpanType set the span type of server spans. Default is http_server.
+func WithSpanType(spanType string) Option {
+	return func(c *Config) {
+		if spanType != "" {
+			c.SpanType = spanType
+		}
+	}
+}
+
+// NewConfig creates Config with default values and applies opts.
+func NewConfig(opts ...Option) *Config {
+	propagator := cozeloop.NewCompositePropagator(
+		cozeloop.NewLoopPropagator(),
+		cozeloop.NewW3CPropagator(),
+		cozeloop.NewB3Propagator(),
+	)
+	c := &Config{
+		Propagator: propagator,
+		SpanType:   tracespec.VHTTPServerSpanType,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	return c
+}
+
+// Request is the information of incoming request, used to start server span.
+type Request struct {
+	Method string
+	Route  string // the matched route template, such as /users/:id, empty if unknown
+	URL    string
+	Header map[string]string
+}
+
+// StartSpan extracts trace context from request header and starts a server span,
+// which is a child of the remote span if exists.
+func (c *Config) StartSpan(ctx context.Context, client cozeloop.Client, name string, req *Request) (context.Context, cozeloop.Span) {
+	ctx = c.Propagator.Extract(ctx, req.Header)
+	if c.SpanNameFormatter != nil {
+		name = c.SpanNameFormatter(name, c.SpanType)
+	}
+	ctx, span := client.StartSpan(ctx, name, c.SpanType)
+	tags := map[string]interface{}{
+		tracespec.HTTPMethod: req.Method,
+		tracespec.HTTPURL:    req.URL,
+	}
+	if req.Route != "" {
+		tags[tracespec.HTTPRoute] = req.Route
+	}
+	span.SetTags(ctx, tags)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// FinishSpan sets the response status code and finishes span.
+// Status code of span is set to statusCode if it's 5xx, which means the request failed.
+func FinishSpan(ctx context.Context, span cozeloop.Span, statusCode int) {
+	span.SetTags(ctx, map[string]interface{}{tracespec.HTTPStatusCode: statusCode})
+	if statusCode >= http.StatusInternalServerError {
+		span.SetStatusCode(ctx, statusCode)
+	}
+	span.Finish(ctx)
+}
+
+type spanKey struct{}
+
+// SpanFromContext returns the span started by trace middlewares, ok is false if not exist.
+func SpanFromContext(ctx context.Context) (cozeloop.Span, bool) {
+	span, ok := ctx.Value(spanKey{}).(cozeloop.Span)
+	return span, ok
+}
+
+// TraceMiddleware returns a net/http middleware, which extracts trace context from request headers,
+// starts a span named `<method> <path>` for each request and finishes it with the response status code.
+// The span is stored in the context of request, use GetSpan to retrieve it.
+func TraceMiddleware(client cozeloop.Client, opts ...Option) func(http.Handler) http.Handler {
+	config := NewConfig(opts...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := config.StartSpan(r.Context(), client, r.Method+" "+r.URL.Path, &Request{
+				Method: r.Method,
+				URL:    r.URL.String(),
+				Header: HeaderToMap(r.Header),
+			})
+			recorder := NewStatusRecorder(w)
+			defer func() {
+				if p := recover(); p != nil {
+					FinishSpan(ctx, span, http.StatusInternalServerError)
+					panic(p)
+				}
+				FinishSpan(ctx, span, recorder.StatusCode())
+			}()
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetSpan returns the span started by TraceMiddleware from request, ok is false if not exist.
+func GetSpan(r *http.Request) (cozeloop.Span, bool) {
+	return SpanFromContext(r.Context())
+}
+
+// StatusRecorder records the status code written to http.ResponseWriter.
+type StatusRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+}
+
+// NewStatusRecorder wraps w to record the status code, which is 200 if WriteHeader is not called.
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+}
+
+// StatusCode returns the recorded status code.
+func (r *StatusRecorder) StatusCode() int {
+	return r.statusCode
+}
+
+func (r *StatusRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *StatusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *StatusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter, used by http.ResponseController.
+func (r *StatusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// HeaderToMap converts http.Header to map, only the first value of each key is kept.
+func HeaderToMap(header http.Header) map[string]string {
+	m := make(map[string]string, len(header))
+	for key, values := range header {
+		if len(values) > 0 {
+			m[key] = values[0]
+		}
+	}
+	return m
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	"github.com/alva-ai/cozeloop-go/tracetest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTraceMiddleware(t *testing.T) {
+	Convey("TraceMiddleware", t, func() {
+		ctx := context.Background()
+		recorder := tracetest.NewRecorderExporter()
+		client, err := cozeloop.NewClient(cozeloop.WithWorkspaceID("ws"), cozeloop.WithAPIToken("token"),
+			cozeloop.WithExporter(recorder))
+		So(err, ShouldBeNil)
+		defer client.Close(ctx)
+
+		var handlerSpan cozeloop.Span
+		handler := TraceMiddleware(client, WithSpanNameFormatter(func(name, spanType string) string {
+			return "server " + name
+		}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			span, ok := GetSpan(r)
+			So(ok, ShouldBeTrue)
+			handlerSpan = span
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+		req.Header.Set("X-Cozeloop-Traceparent", "00-0123456789abcdef0123456789abcdef-0123456789abcdef-01")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		So(rec.Code, ShouldEqual, http.StatusBadGateway)
+		client.Flush(ctx)
+
+		spans := recorder.Spans()
+		So(len(spans), ShouldEqual, 1)
+		So(spans[0].SpanID, ShouldEqual, handlerSpan.GetSpanID())
+		So(spans[0].SpanName, ShouldEqual, "server GET /users/123")
+		So(spans[0].SpanType, ShouldEqual, tracespec.VHTTPServerSpanType)
+		So(spans[0].TraceID, ShouldEqual, "0123456789abcdef0123456789abcdef")
+		So(spans[0].ParentID, ShouldEqual, "0123456789abcdef")
+		So(spans[0].StatusCode, ShouldEqual, http.StatusBadGateway)
+		So(spans[0].TagsString[tracespec.HTTPMethod], ShouldEqual, http.MethodGet)
+		So(spans[0].TagsLong[tracespec.HTTPStatusCode], ShouldEqual, http.StatusBadGateway)
+	})
+}
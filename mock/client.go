@@ -0,0 +1,315 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package mock provides a MockClient for testing application code which uses cozeloop.Client.
+package mock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+	"github.com/alva-ai/cozeloop-go/internal/util"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	"github.com/alva-ai/cozeloop-go/tracetest"
+)
+
+var _ cozeloop.Client = (*MockClient)(nil)
+
+// ErrNotSupported is returned by prompt methods of MockClient.
+var ErrNotSupported = errors.New("not supported by mock client")
+
+// MockClient is a cozeloop.Client which keeps spans in memory and never calls the server.
+// All spans started by StartSpan are recorded in order, and can be inspected by StartedSpans.
+type MockClient struct {
+	client   cozeloop.Client
+	recorder *tracetest.RecorderExporter
+
+	mu    sync.Mutex
+	spans []*MockSpan
+}
+
+// NewMockClient creates a new MockClient.
+func NewMockClient() *MockClient {
+	recorder := tracetest.NewRecorderExporter()
+	client, err := cozeloop.NewClient(
+		cozeloop.WithWorkspaceID("mock"),
+		cozeloop.WithAPIToken("mock"),
+		cozeloop.WithExporter(recorder),
+	)
+	if err != nil {
+		// only happens if options are invalid, return a client whose spans are noop
+		client = &cozeloop.NoopClient{}
+	}
+	return &MockClient{client: client, recorder: recorder}
+}
+
+// StartedSpans returns spans started by StartSpan in order, including the finished ones.
+func (c *MockClient) StartedSpans() []*MockSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := make([]*MockSpan, len(c.spans))
+	copy(res, c.spans)
+	return res
+}
+
+// ExportedSpans returns finished spans exported in order, call Flush before it to export all finished spans.
+func (c *MockClient) ExportedSpans() []*entity.UploadSpan {
+	return c.recorder.Spans()
+}
+
+// Reset clears the recorded spans, including the exported ones.
+func (c *MockClient) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spans = nil
+	c.recorder.Reset()
+}
+
+func (c *MockClient) StartSpan(ctx context.Context, name, spanType string, opts ...cozeloop.StartSpanOption) (context.Context, cozeloop.Span) {
+	ctx, span := c.client.StartSpan(ctx, name, spanType, opts...)
+	if span == cozeloop.Span(cozeloop.DefaultNoopSpan) {
+		return ctx, span
+	}
+
+	s := &MockSpan{Span: span, client: c, name: name, spanType: spanType}
+	s.parent = c.findSpan(s.ParentID())
+	c.mu.Lock()
+	c.spans = append(c.spans, s)
+	c.mu.Unlock()
+	return ctx, s
+}
+
+// SpanFactory returns a factory for cozeloop.WithSpanFactory, which starts MockSpan recorded by the MockClient
+// with the given IDs, so that spans started by a real client can be inspected in tests.
+func (c *MockClient) SpanFactory() cozeloop.SpanFactory {
+	return func(ctx context.Context, name, spanType, traceID, spanID, parentID string) cozeloop.Span {
+		_, span := c.StartSpan(ctx, name, spanType,
+			cozeloop.WithChildOf(&trace.SpanContext{SpanID: parentID, TraceID: traceID}),
+			cozeloop.WithSpanID(spanID))
+		return span
+	}
+}
+
+// GroupSpans groups spans by the underlying client, the group span is recorded as a started span,
+// and becomes the parent of the grouped spans.
+func (c *MockClient) GroupSpans(ctx context.Context, groupName string, spans []cozeloop.Span) (cozeloop.GroupSpan, error) {
+	innerSpans := make([]cozeloop.Span, 0, len(spans))
+	for _, span := range spans {
+		if s, ok := span.(*MockSpan); ok {
+			span = s.Span
+		}
+		innerSpans = append(innerSpans, span)
+	}
+	group, err := c.client.GroupSpans(ctx, groupName, innerSpans)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &MockGroupSpan{
+		MockSpan: &MockSpan{Span: group, client: c, name: groupName, spanType: tracespec.VGroupSpanType},
+		spans:    append([]cozeloop.Span(nil), spans...),
+	}
+	c.mu.Lock()
+	s.parent = c.findSpanLocked(s.ParentID())
+	for _, span := range spans {
+		if child, ok := span.(*MockSpan); ok {
+			child.setParent(s.MockSpan)
+		}
+	}
+	c.spans = append(c.spans, s.MockSpan)
+	c.mu.Unlock()
+	return s, nil
+}
+
+// StartRetrySpan starts a span by StartSpan with `retry.attempt` and `retry.max_attempts` tags.
+func (c *MockClient) StartRetrySpan(ctx context.Context, name, spanType string, attempt, maxAttempts int) (context.Context, cozeloop.Span) {
+	ctx, span := c.StartSpan(ctx, name, spanType)
+	span.SetTags(ctx, map[string]interface{}{
+		tracespec.RetryAttempt:     attempt,
+		tracespec.RetryMaxAttempts: maxAttempts,
+	})
+	return ctx, span
+}
+
+// NextAgentRound increments the round of agentic loop in ctx, spans started from it are tagged with `agent.round`.
+func (c *MockClient) NextAgentRound(ctx context.Context) context.Context {
+	return c.client.NextAgentRound(ctx)
+}
+
+// GetSpanFromContext returns the MockSpan in ctx, or a noop span if not found.
+func (c *MockClient) GetSpanFromContext(ctx context.Context) cozeloop.Span {
+	if s := c.findSpan(c.client.GetSpanFromContext(ctx).GetSpanID()); s != nil {
+		return s
+	}
+	return cozeloop.DefaultNoopSpan
+}
+
+func (c *MockClient) GetSpanFromHeader(ctx context.Context, header map[string]string) cozeloop.SpanContext {
+	return c.client.GetSpanFromHeader(ctx, header)
+}
+
+func (c *MockClient) Flush(ctx context.Context) {
+	c.client.Flush(ctx)
+}
+
+func (c *MockClient) GetWorkspaceID() string {
+	return c.client.GetWorkspaceID()
+}
+
+func (c *MockClient) Close(ctx context.Context) {
+	c.client.Close(ctx)
+}
+
+func (c *MockClient) RotateAPIToken(newToken string) error {
+	return nil
+}
+
+func (c *MockClient) GetPrompt(ctx context.Context, param cozeloop.GetPromptParam, options ...cozeloop.GetPromptOption) (*entity.Prompt, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *MockClient) PromptFormat(ctx context.Context, prompt *entity.Prompt, variables map[string]any, options ...cozeloop.PromptFormatOption) (messages []*entity.Message, err error) {
+	return nil, ErrNotSupported
+}
+
+func (c *MockClient) Execute(ctx context.Context, param *entity.ExecuteParam, options ...cozeloop.ExecuteOption) (entity.ExecuteResult, error) {
+	return entity.ExecuteResult{}, ErrNotSupported
+}
+
+func (c *MockClient) ExecuteStreaming(ctx context.Context, param *entity.ExecuteParam, options ...cozeloop.ExecuteStreamingOption) (entity.StreamReader[entity.ExecuteResult], error) {
+	return nil, ErrNotSupported
+}
+
+// findSpan returns the recorded MockSpan of span id.
+func (c *MockClient) findSpan(spanID string) *MockSpan {
+	if spanID == "" {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.findSpanLocked(spanID)
+}
+
+func (c *MockClient) findSpanLocked(spanID string) *MockSpan {
+	for i := len(c.spans) - 1; i >= 0; i-- {
+		if c.spans[i].GetSpanID() == spanID {
+			return c.spans[i]
+		}
+	}
+	return nil
+}
+
+// MockSpan is a fully functional in-memory span started by MockClient. Values set by setters
+// can be inspected by Tags, Baggage, StatusCode and so on.
+type MockSpan struct {
+	cozeloop.Span
+	client *MockClient
+
+	name     string
+	spanType string
+	parent   *MockSpan
+
+	mu       sync.Mutex
+	finished bool
+}
+
+// internalSpan is implemented by spans of cozeloop client.
+type internalSpan interface {
+	GetParentID() string
+	GetTagMap() map[string]interface{}
+	GetStatusCode() int32
+	GetAnnotations() []entity.SpanAnnotation
+	GetFinishTime() time.Time
+}
+
+var _ internalSpan = (*trace.Span)(nil)
+
+// Name returns the name passed to StartSpan.
+func (s *MockSpan) Name() string {
+	return s.name
+}
+
+// SpanType returns the span type passed to StartSpan.
+func (s *MockSpan) SpanType() string {
+	return s.spanType
+}
+
+// Parent returns the parent MockSpan, nil if the parent is not started by the MockClient, such as root spans.
+func (s *MockSpan) Parent() *MockSpan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.parent
+}
+
+func (s *MockSpan) setParent(parent *MockSpan) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.parent = parent
+}
+
+// ParentID returns the parent span id, which is "0" for root spans.
+func (s *MockSpan) ParentID() string {
+	if span, ok := s.Span.(internalSpan); ok {
+		return span.GetParentID()
+	}
+	return ""
+}
+
+// Tags returns a copy of tags set on the span, including input, output and tags set by other setters.
+func (s *MockSpan) Tags() map[string]interface{} {
+	if span, ok := s.Span.(internalSpan); ok {
+		return span.GetTagMap()
+	}
+	return nil
+}
+
+// StatusCode returns the status code set by SetStatusCode or SetError.
+func (s *MockSpan) StatusCode() int32 {
+	if span, ok := s.Span.(internalSpan); ok {
+		return span.GetStatusCode()
+	}
+	return 0
+}
+
+// Annotations returns annotations added by Annotate.
+func (s *MockSpan) Annotations() []entity.SpanAnnotation {
+	if span, ok := s.Span.(internalSpan); ok {
+		return span.GetAnnotations()
+	}
+	return nil
+}
+
+// Finished reports whether Finish is called.
+func (s *MockSpan) Finished() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.finished
+}
+
+func (s *MockSpan) Finish(ctx context.Context) {
+	s.mu.Lock()
+	s.finished = true
+	s.mu.Unlock()
+	s.Span.Finish(ctx)
+}
+
+// Fork starts a child span by MockClient, which is recorded too.
+func (s *MockSpan) Fork(ctx context.Context, name, spanType string) (context.Context, cozeloop.Span) {
+	return s.client.StartSpan(util.WithoutCancel(ctx), name, spanType, cozeloop.WithChildOf(s))
+}
+
+// MockGroupSpan is a group span created by MockClient.GroupSpans.
+type MockGroupSpan struct {
+	*MockSpan
+	spans []cozeloop.Span
+}
+
+// GroupedSpans returns the spans passed to GroupSpans.
+func (s *MockGroupSpan) GroupedSpans() []cozeloop.Span {
+	return append([]cozeloop.Span(nil), s.spans...)
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package mock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/spec/tracespec"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMockClient(t *testing.T) {
+	Convey("MockClient", t, func() {
+		ctx := context.Background()
+		client := NewMockClient()
+		defer client.Close(ctx)
+
+		// the function under test
+		run := func(ctx context.Context, c cozeloop.Client) {
+			ctx, root := c.StartSpan(ctx, "agent", "agent")
+			defer root.Finish(ctx)
+			root.SetInput(ctx, "question")
+			_, llm := c.StartSpan(ctx, "llm_call", tracespec.VModelSpanType)
+			llm.SetModelName(ctx, "gpt-4o")
+			llm.SetError(ctx, errors.New("timeout"))
+			llm.Finish(ctx)
+			So(c.GetSpanFromContext(ctx), ShouldEqual, root)
+		}
+		run(ctx, client)
+
+		spans := client.StartedSpans()
+		So(len(spans), ShouldEqual, 2)
+		So(spans[0].Name(), ShouldEqual, "agent")
+		So(spans[0].SpanType(), ShouldEqual, "agent")
+		So(spans[0].Parent(), ShouldBeNil)
+		So(spans[0].ParentID(), ShouldEqual, "0")
+		So(spans[0].Tags()[tracespec.Input], ShouldEqual, "question")
+		So(spans[0].Finished(), ShouldBeTrue)
+
+		So(spans[1].Name(), ShouldEqual, "llm_call")
+		So(spans[1].Parent(), ShouldEqual, spans[0])
+		So(spans[1].GetTraceID(), ShouldEqual, spans[0].GetTraceID())
+		So(spans[1].Tags()[tracespec.ModelName], ShouldEqual, "gpt-4o")
+		So(spans[1].StatusCode(), ShouldNotEqual, 0)
+
+		client.Flush(ctx)
+		So(len(client.ExportedSpans()), ShouldEqual, 2)
+
+		Convey("forked spans should be recorded", func() {
+			_, child := spans[0].Fork(ctx, "background", "task")
+			So(child.(*MockSpan).Parent(), ShouldEqual, spans[0])
+			So(len(client.StartedSpans()), ShouldEqual, 3)
+
+			client.Reset()
+			So(client.StartedSpans(), ShouldBeEmpty)
+		})
+
+		Convey("grouped spans should be moved under group span", func() {
+			client.Reset()
+			ctx, root := client.StartSpan(ctx, "agent", "agent")
+			_, tool1 := client.StartSpan(ctx, "search", "tool")
+			_, tool2 := client.StartSpan(ctx, "search", "tool")
+			group, err := client.GroupSpans(ctx, "tools", []cozeloop.Span{tool1, tool2})
+			So(err, ShouldBeNil)
+			So(len(group.GroupedSpans()), ShouldEqual, 2)
+
+			spans := client.StartedSpans()
+			So(len(spans), ShouldEqual, 4)
+			So(spans[3].Name(), ShouldEqual, "tools")
+			So(spans[3].Parent(), ShouldEqual, root)
+			So(spans[1].Parent(), ShouldEqual, spans[3])
+			So(spans[1].ParentID(), ShouldEqual, group.GetSpanID())
+		})
+
+		Convey("spans of client with span factory should be recorded", func() {
+			client.Reset()
+			c, err := cozeloop.NewClient(cozeloop.WithWorkspaceID("span_factory"), cozeloop.WithAPIToken("token"),
+				cozeloop.WithSpanFactory(client.SpanFactory()))
+			So(err, ShouldBeNil)
+			defer c.Close(ctx)
+			run(ctx, c)
+
+			spans := client.StartedSpans()
+			So(len(spans), ShouldEqual, 2)
+			So(spans[0].Name(), ShouldEqual, "agent")
+			So(spans[0].Tags()[tracespec.Input], ShouldEqual, "question")
+			So(spans[1].Parent(), ShouldEqual, spans[0])
+			So(spans[1].GetTraceID(), ShouldEqual, spans[0].GetTraceID())
+		})
+
+		Convey("prompt methods should not be supported", func() {
+			_, err := client.GetPrompt(ctx, cozeloop.GetPromptParam{PromptKey: "key"})
+			So(err, ShouldEqual, ErrNotSupported)
+		})
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package natsexporter
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func runTestServer(t *testing.T) *server.Server {
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		NoLog:     true,
+		NoSigs:    true,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	So(err, ShouldBeNil)
+	go s.Start()
+	So(s.ReadyForConnections(5*time.Second), ShouldBeTrue)
+	return s
+}
+
+func TestNATSExporter(t *testing.T) {
+	Convey("NATSExporter", t, func() {
+		ctx := context.Background()
+		spans := []*entity.UploadSpan{
+			{WorkspaceID: "ws1", SpanType: "model", SpanID: "span1"},
+			nil,
+			{WorkspaceID: "ws.2", SpanType: "", SpanID: "span2"},
+		}
+		s := runTestServer(t)
+		defer s.Shutdown()
+
+		Convey("should publish one message per span", func() {
+			sub, err := nats.Connect(s.ClientURL())
+			So(err, ShouldBeNil)
+			defer sub.Close()
+			msgs := make(chan *nats.Msg, 10)
+			_, err = sub.ChanSubscribe("cozeloop.spans.>", msgs)
+			So(err, ShouldBeNil)
+			So(sub.Flush(), ShouldBeNil)
+
+			exporter, err := NewNATSExporter(s.ClientURL(), "cozeloop.spans.")
+			So(err, ShouldBeNil)
+			defer exporter.Close()
+
+			So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+			msg := <-msgs
+			So(msg.Subject, ShouldEqual, "cozeloop.spans.ws1.model")
+			span := &entity.UploadSpan{}
+			So(json.Unmarshal(msg.Data, span), ShouldBeNil)
+			So(span.SpanID, ShouldEqual, "span1")
+			msg = <-msgs
+			So(msg.Subject, ShouldEqual, "cozeloop.spans.ws_2._")
+		})
+
+		Convey("should wait for jetstream ack", func() {
+			exporter, err := NewNATSExporter(s.ClientURL(), "cozeloop", WithJetStream(true))
+			So(err, ShouldBeNil)
+			defer exporter.Close()
+
+			// no stream captures the subjects
+			So(exporter.ExportSpans(ctx, spans), ShouldNotBeNil)
+
+			stream, err := exporter.js.CreateStream(ctx, jetstream.StreamConfig{Name: "SPANS", Subjects: []string{"cozeloop.>"}})
+			So(err, ShouldBeNil)
+			So(exporter.ExportSpans(ctx, spans), ShouldBeNil)
+			info, err := stream.Info(ctx)
+			So(err, ShouldBeNil)
+			So(info.State.Msgs, ShouldEqual, 2)
+		})
+
+		Convey("should fail with invalid params", func() {
+			_, err := NewNATSExporter("", "cozeloop")
+			So(err, ShouldNotBeNil)
+			_, err = NewNATSExporter(s.ClientURL(), "")
+			So(err, ShouldNotBeNil)
+
+			credsFile := filepath.Join(t.TempDir(), "user.creds")
+			So(os.WriteFile(credsFile, []byte("invalid"), 0o600), ShouldBeNil)
+			_, err = NewNATSExporter(s.ClientURL(), "cozeloop", WithCredentials(credsFile))
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestReconnectDelay(t *testing.T) {
+	Convey("reconnect delay should grow exponentially up to max", t, func() {
+		So(reconnectDelay(1), ShouldEqual, 100*time.Millisecond)
+		So(reconnectDelay(2), ShouldEqual, 200*time.Millisecond)
+		So(reconnectDelay(4), ShouldEqual, 800*time.Millisecond)
+		So(reconnectDelay(100), ShouldEqual, 5*time.Second)
+	})
+}
@@ -11,7 +11,26 @@ import (
 	"github.com/alva-ai/cozeloop-go/internal/trace"
 )
 
-var DefaultNoopSpan = trace.DefaultNoopSpan
+var DefaultNoopSpan = &noopSpan{}
+
+// noopSpan is a Span which does nothing.
+type noopSpan struct {
+	trace.NoopSpan
+}
+
+func (n *noopSpan) Fork(ctx context.Context, name, spanType string) (context.Context, Span) {
+	return ctx, DefaultNoopSpan
+}
+
+// noopGroupSpan is a GroupSpan which does nothing, returned by GroupSpans if any span to group is noop.
+type noopGroupSpan struct {
+	*noopSpan
+	spans []Span
+}
+
+func (n *noopGroupSpan) GroupedSpans() []Span {
+	return append([]Span(nil), n.spans...)
+}
 
 // NoopClient a noop client
 type NoopClient struct {
@@ -27,6 +46,11 @@ func (c *NoopClient) Close(ctx context.Context) {
 	logger.CtxWarnf(context.Background(), "Noop client not supported. %v", c.newClientError)
 }
 
+func (c *NoopClient) RotateAPIToken(newToken string) error {
+	logger.CtxWarnf(context.Background(), "Noop client not supported. %v", c.newClientError)
+	return c.newClientError
+}
+
 func (c *NoopClient) GetPrompt(ctx context.Context, param GetPromptParam, options ...GetPromptOption) (*entity.Prompt, error) {
 	logger.CtxWarnf(context.Background(), "Noop client not supported. %v", c.newClientError)
 	return nil, c.newClientError
@@ -52,6 +76,21 @@ func (c *NoopClient) StartSpan(ctx context.Context, name, spanType string, opts
 	return ctx, DefaultNoopSpan
 }
 
+func (c *NoopClient) GroupSpans(ctx context.Context, groupName string, spans []Span) (GroupSpan, error) {
+	logger.CtxWarnf(context.Background(), "Noop client not supported. %v", c.newClientError)
+	return nil, c.newClientError
+}
+
+func (c *NoopClient) StartRetrySpan(ctx context.Context, name, spanType string, attempt, maxAttempts int) (context.Context, Span) {
+	logger.CtxWarnf(context.Background(), "Noop client not supported. %v", c.newClientError)
+	return ctx, DefaultNoopSpan
+}
+
+func (c *NoopClient) NextAgentRound(ctx context.Context) context.Context {
+	logger.CtxWarnf(context.Background(), "Noop client not supported. %v", c.newClientError)
+	return ctx
+}
+
 func (c *NoopClient) GetSpanFromContext(ctx context.Context) Span {
 	logger.CtxWarnf(context.Background(), "Noop client not supported. %v", c.newClientError)
 	return DefaultNoopSpan
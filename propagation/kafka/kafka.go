@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package kafka propagates trace context through Kafka message headers.
+//
+// Header has the same fields as the header types of confluent-kafka-go and segmentio/kafka-go,
+// so they can be converted to each other directly, for example:
+//
+//	headers := make([]kafka.Header, 0, len(msg.Headers))
+//	for _, h := range msg.Headers {
+//		headers = append(headers, kafka.Header(h))
+//	}
+package kafka
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/alva-ai/cozeloop-go"
+)
+
+// Header is a key-value pair of Kafka message header.
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// InjectHeaders injects the trace context in ctx into headers, each propagated field is stored as an individual
+// header with lowercase key, such as traceparent for W3C propagator. Existing headers with the same keys
+// (case-insensitive) are replaced. Default propagator is cozeloop.NewLoopPropagator if nil.
+func InjectHeaders(ctx context.Context, headers []Header, propagator cozeloop.Propagator) []Header {
+	if propagator == nil {
+		propagator = cozeloop.NewLoopPropagator()
+	}
+	carrier := make(map[string]string)
+	propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return headers
+	}
+	keys := make([]string, 0, len(carrier))
+	values := make(map[string]string, len(carrier))
+	for key, value := range carrier {
+		key = strings.ToLower(key)
+		keys = append(keys, key)
+		values[key] = value
+	}
+	sort.Strings(keys)
+
+	result := make([]Header, 0, len(headers)+len(keys))
+	for _, header := range headers {
+		if _, ok := values[strings.ToLower(header.Key)]; !ok {
+			result = append(result, header)
+		}
+	}
+	for _, key := range keys {
+		result = append(result, Header{Key: key, Value: []byte(values[key])})
+	}
+	return result
+}
+
+// ExtractContext extracts the trace context from headers, and returns a ctx carrying it.
+// Spans started from the returned ctx will be children of the producer span.
+// If there are duplicated keys, the last one is used. Default propagator is cozeloop.NewLoopPropagator if nil.
+func ExtractContext(ctx context.Context, headers []Header, propagator cozeloop.Propagator) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	if propagator == nil {
+		propagator = cozeloop.NewLoopPropagator()
+	}
+	carrier := make(map[string]string, len(headers))
+	for _, header := range headers {
+		carrier[header.Key] = string(header.Value)
+	}
+	return propagator.Extract(ctx, carrier)
+}
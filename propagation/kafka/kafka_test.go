@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/tracetest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// segmentioHeader has the same fields as kafka.Header of segmentio/kafka-go.
+type segmentioHeader struct {
+	Key   string
+	Value []byte
+}
+
+func TestHeaderPropagation(t *testing.T) {
+	Convey("InjectHeaders and ExtractContext", t, func() {
+		ctx := context.Background()
+		recorder := tracetest.NewRecorderExporter()
+		client, err := cozeloop.NewClient(cozeloop.WithWorkspaceID("ws"), cozeloop.WithAPIToken("token"),
+			cozeloop.WithExporter(recorder))
+		So(err, ShouldBeNil)
+		defer client.Close(ctx)
+		propagator := cozeloop.NewW3CPropagator()
+
+		Convey("should link consumer span to producer span", func() {
+			producerCtx, producer := client.StartSpan(ctx, "produce", "kafka")
+			headers := InjectHeaders(producerCtx, []Header{
+				{Key: "content-type", Value: []byte("application/json")},
+				{Key: "Traceparent", Value: []byte("stale")},
+			}, propagator)
+			producer.Finish(producerCtx)
+			So(len(headers), ShouldEqual, 2)
+			So(headers[0].Key, ShouldEqual, "content-type")
+			So(headers[1].Key, ShouldEqual, "traceparent")
+			So(string(headers[1].Value), ShouldContainSubstring, producer.GetSpanID())
+
+			received := make([]segmentioHeader, 0, len(headers))
+			for _, h := range headers {
+				received = append(received, segmentioHeader(h))
+			}
+			extracted := make([]Header, 0, len(received))
+			for _, h := range received {
+				extracted = append(extracted, Header(h))
+			}
+			consumerCtx, consumer := client.StartSpan(ExtractContext(ctx, extracted, propagator), "consume", "kafka")
+			consumer.Finish(consumerCtx)
+			So(consumer.GetTraceID(), ShouldEqual, producer.GetTraceID())
+			client.Flush(ctx)
+
+			spans := recorder.Spans()
+			So(len(spans), ShouldEqual, 2)
+			So(spans[1].ParentID, ShouldEqual, producer.GetSpanID())
+		})
+
+		Convey("should keep headers unchanged without trace context", func() {
+			headers := []Header{{Key: "content-type", Value: []byte("application/json")}}
+			So(InjectHeaders(ctx, headers, propagator), ShouldResemble, headers)
+			So(ExtractContext(ctx, headers, propagator), ShouldEqual, ctx)
+			So(ExtractContext(ctx, nil, nil), ShouldEqual, ctx)
+		})
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package redis propagates trace context through Redis pub/sub and stream messages, which are represented
+// as map[string]interface{}, such as the Values of go-redis XAddArgs and XMessage, or the decoded payload of
+// pub/sub message.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/alva-ai/cozeloop-go"
+)
+
+// ContextKey is the reserved key of message to store the propagated trace context.
+const ContextKey = "_cozeloop_ctx"
+
+// InjectMessageHeaders injects the trace context in ctx into msg, as a JSON-encoded header map under ContextKey.
+// msg is not changed if there is no trace context in ctx. Default propagator is cozeloop.NewLoopPropagator if nil.
+func InjectMessageHeaders(ctx context.Context, msg map[string]interface{}, propagator cozeloop.Propagator) {
+	if msg == nil {
+		return
+	}
+	if propagator == nil {
+		propagator = cozeloop.NewLoopPropagator()
+	}
+	header := make(map[string]string)
+	propagator.Inject(ctx, header)
+	if len(header) == 0 {
+		return
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return
+	}
+	msg[ContextKey] = string(data)
+}
+
+// ExtractMessageContext extracts the trace context injected by InjectMessageHeaders from msg,
+// and returns a ctx carrying it. Spans started from the returned ctx will be children of the producer span.
+// ctx is returned as is if msg has no trace context. Default propagator is cozeloop.NewLoopPropagator if nil.
+func ExtractMessageContext(ctx context.Context, msg map[string]interface{}, propagator cozeloop.Propagator) context.Context {
+	header := messageHeader(msg[ContextKey])
+	if len(header) == 0 {
+		return ctx
+	}
+	if propagator == nil {
+		propagator = cozeloop.NewLoopPropagator()
+	}
+	return propagator.Extract(ctx, header)
+}
+
+// messageHeader decodes the value of ContextKey, which is string read from Redis, or map if payload of
+// message is decoded from JSON as a whole.
+func messageHeader(value interface{}) map[string]string {
+	header := make(map[string]string)
+	switch v := value.(type) {
+	case string:
+		_ = json.Unmarshal([]byte(v), &header)
+	case []byte:
+		_ = json.Unmarshal(v, &header)
+	case map[string]string:
+		header = v
+	case map[string]interface{}:
+		for key, item := range v {
+			if s, ok := item.(string); ok {
+				header[key] = s
+			}
+		}
+	}
+	return header
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go"
+	"github.com/alva-ai/cozeloop-go/tracetest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMessagePropagation(t *testing.T) {
+	Convey("InjectMessageHeaders and ExtractMessageContext", t, func() {
+		ctx := context.Background()
+		recorder := tracetest.NewRecorderExporter()
+		client, err := cozeloop.NewClient(cozeloop.WithWorkspaceID("ws"), cozeloop.WithAPIToken("token"),
+			cozeloop.WithExporter(recorder))
+		So(err, ShouldBeNil)
+		defer client.Close(ctx)
+
+		Convey("should link consumer span to producer span", func() {
+			producerCtx, producer := client.StartSpan(ctx, "publish", "redis")
+			msg := map[string]interface{}{"order_id": "123"}
+			InjectMessageHeaders(producerCtx, msg, nil)
+			producer.Finish(producerCtx)
+			So(msg[ContextKey], ShouldNotBeEmpty)
+
+			// the payload is encoded and decoded through redis
+			payload, err := json.Marshal(msg)
+			So(err, ShouldBeNil)
+			received := map[string]interface{}{}
+			So(json.Unmarshal(payload, &received), ShouldBeNil)
+
+			consumerCtx := ExtractMessageContext(ctx, received, nil)
+			consumerCtx, consumer := client.StartSpan(consumerCtx, "consume", "redis")
+			consumer.Finish(consumerCtx)
+			So(consumer.GetTraceID(), ShouldEqual, producer.GetTraceID())
+			client.Flush(ctx)
+
+			spans := recorder.Spans()
+			So(len(spans), ShouldEqual, 2)
+			So(spans[1].ParentID, ShouldEqual, producer.GetSpanID())
+		})
+
+		Convey("should extract from W3C headers", func() {
+			propagator := cozeloop.NewW3CPropagator()
+			msg := map[string]interface{}{
+				ContextKey: map[string]interface{}{
+					"traceparent": "00-0123456789abcdef0123456789abcdef-0123456789abcdef-01",
+				},
+			}
+			consumerCtx, consumer := client.StartSpan(ExtractMessageContext(ctx, msg, propagator), "consume", "redis")
+			consumer.Finish(consumerCtx)
+			So(consumer.GetTraceID(), ShouldEqual, "0123456789abcdef0123456789abcdef")
+		})
+
+		Convey("should keep message unchanged without trace context", func() {
+			msg := map[string]interface{}{"order_id": "123"}
+			InjectMessageHeaders(ctx, msg, nil)
+			So(msg, ShouldNotContainKey, ContextKey)
+			So(ExtractMessageContext(ctx, msg, nil), ShouldEqual, ctx)
+		})
+	})
+}
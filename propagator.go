@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"context"
+
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// Propagator injects span context into carrier and extracts span context from carrier,
+// which is used to link spans across process boundaries.
+// Spans started from the ctx returned by Extract will be children of the remote span.
+type Propagator = trace.Propagator
+
+// XRayTraceHeader is the header of AWS X-Ray trace context.
+const XRayTraceHeader = trace.XRayTraceHeader
+
+// NewLoopPropagator returns a Propagator using cozeloop header format, the same as Span.ToHeader.
+func NewLoopPropagator() Propagator {
+	return trace.NewLoopPropagator()
+}
+
+// NewXRayPropagator returns a Propagator using AWS X-Ray header format.
+// X-Ray header with Sampled=0 makes spans started from the extracted ctx dropped.
+func NewXRayPropagator() Propagator {
+	return trace.NewXRayPropagator()
+}
+
+// NewDatadogPropagator returns a Propagator using Datadog APM header format
+// (x-datadog-trace-id, x-datadog-parent-id, x-datadog-sampling-priority).
+// Sampling priority 0 makes spans started from the extracted ctx dropped.
+func NewDatadogPropagator() Propagator {
+	return trace.NewDatadogPropagator()
+}
+
+// NewW3CPropagator returns a Propagator using W3C Trace Context (traceparent) and W3C Baggage (baggage) header format.
+// traceparent with unsampled flags makes spans started from the extracted ctx dropped.
+func NewW3CPropagator() Propagator {
+	return trace.NewW3CPropagator()
+}
+
+// NewB3Propagator returns a Propagator using Zipkin B3 header format. It injects multiple headers
+// (X-B3-TraceId, X-B3-SpanId, X-B3-Sampled), and extracts both multiple headers and single b3 header.
+func NewB3Propagator() Propagator {
+	return trace.NewB3Propagator()
+}
+
+// NewCompositePropagator returns a Propagator which injects headers of all propagators,
+// and extracts span context by the first propagator which finds one.
+func NewCompositePropagator(propagators ...Propagator) Propagator {
+	return trace.NewCompositePropagator(propagators...)
+}
+
+// ContextWithSamplingDecision returns a copy of ctx carrying the sampling decision of upstream.
+// If sampled is false, spans started from ctx without local parent are dropped.
+func ContextWithSamplingDecision(ctx context.Context, sampled bool) context.Context {
+	return trace.ContextWithSamplingDecision(ctx, sampled)
+}
+
+// SamplingDecisionFromContext returns the sampling decision in ctx, ok is false if not exist.
+func SamplingDecisionFromContext(ctx context.Context) (sampled bool, ok bool) {
+	return trace.SamplingDecisionFromContext(ctx)
+}
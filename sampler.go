@@ -0,0 +1,25 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// Sampler decides whether spans of a trace are kept when its local root span is started, set by WithSampler.
+// Child spans follow the decision of their parent, and spans started under a dropped span are dropped too.
+type Sampler = trace.Sampler
+
+// NewRatioSampler creates a Sampler keeping traces of ratio in [0, 1], by hash of trace id.
+func NewRatioSampler(ratio float64) Sampler {
+	return trace.NewRatioSampler(ratio)
+}
+
+// NewErrorAwareSampler creates a Sampler keeping all spans with errors regardless of base sampler, such as
+// NewRatioSampler(0.01). As errors are unknown when spans are started, spans dropped by base are still recorded,
+// and dropped on finish unless they have error, which requires WithErrorAwareDropFilter. Without it, spans dropped
+// by base are dropped on start.
+func NewErrorAwareSampler(base Sampler) Sampler {
+	return trace.NewErrorAwareSampler(base)
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package security provides signature of span export payloads, which can be verified by a relay or proxy
+// receiving spans to detect tampering.
+//
+// The signature is sent in X-Cozeloop-Signature header formatted as t=<unix seconds>,v1=<hex HMAC-SHA256>,
+// where the HMAC is computed over "<unix seconds>.<request body>", so that a captured request can not be
+// replayed after the tolerance.
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader is the HTTP header carrying the signature of export payload.
+	SignatureHeader = "X-Cozeloop-Signature"
+
+	// DefaultSignatureTolerance is the max difference between signature timestamp and now accepted by VerifySignature.
+	DefaultSignatureTolerance = 5 * time.Minute
+
+	signatureTimestampKey = "t"
+	signatureV1Key        = "v1"
+)
+
+// Sign computes the signature of body at timestamp with secret.
+func Sign(secret, body []byte, timestamp time.Time) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return signatureTimestampKey + "=" + ts + "," + signatureV1Key + "=" + hex.EncodeToString(computeMAC(secret, ts, body))
+}
+
+// VerifySignature reports whether sig is a valid signature of body with secret,
+// and its timestamp is within DefaultSignatureTolerance of now.
+func VerifySignature(secret, body []byte, sig string) bool {
+	return VerifySignatureWithTolerance(secret, body, sig, DefaultSignatureTolerance)
+}
+
+// VerifySignatureWithTolerance is the same as VerifySignature, with custom tolerance of timestamp.
+// Tolerance less than or equal to 0 disables the timestamp check, which is not recommended.
+func VerifySignatureWithTolerance(secret, body []byte, sig string, tolerance time.Duration) bool {
+	var ts string
+	var macs [][]byte
+	for _, part := range strings.Split(sig, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case signatureTimestampKey:
+			ts = value
+		case signatureV1Key:
+			// multiple v1 signatures are allowed, e.g. during secret rotation
+			if mac, err := hex.DecodeString(value); err == nil {
+				macs = append(macs, mac)
+			}
+		}
+	}
+	if ts == "" || len(macs) == 0 {
+		return false
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	if tolerance > 0 {
+		diff := time.Since(time.Unix(unix, 0))
+		if diff > tolerance || diff < -tolerance {
+			return false
+		}
+	}
+
+	expected := computeMAC(secret, ts, body)
+	for _, mac := range macs {
+		if hmac.Equal(mac, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+func computeMAC(secret []byte, ts string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
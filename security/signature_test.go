@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package security
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestVerifySignature(t *testing.T) {
+	Convey("VerifySignature", t, func() {
+		secret := []byte("secret")
+		body := []byte(`{"spans":[]}`)
+
+		Convey("should accept valid signature", func() {
+			So(VerifySignature(secret, body, Sign(secret, body, time.Now())), ShouldBeTrue)
+		})
+
+		Convey("should reject tampered body or wrong secret", func() {
+			sig := Sign(secret, body, time.Now())
+			So(VerifySignature(secret, []byte(`{"spans":[{}]}`), sig), ShouldBeFalse)
+			So(VerifySignature([]byte("other"), body, sig), ShouldBeFalse)
+		})
+
+		Convey("should reject expired signature", func() {
+			sig := Sign(secret, body, time.Now().Add(-10*time.Minute))
+			So(VerifySignature(secret, body, sig), ShouldBeFalse)
+			So(VerifySignatureWithTolerance(secret, body, sig, time.Hour), ShouldBeTrue)
+		})
+
+		Convey("should reject malformed signature", func() {
+			So(VerifySignature(secret, body, ""), ShouldBeFalse)
+			So(VerifySignature(secret, body, "v1=abcd"), ShouldBeFalse)
+			So(VerifySignature(secret, body, "t=abc,v1=abcd"), ShouldBeFalse)
+		})
+	})
+}
@@ -5,9 +5,12 @@ package cozeloop
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+	"github.com/alva-ai/cozeloop-go/internal/util"
 	"github.com/alva-ai/cozeloop-go/spec/tracespec"
 )
 
@@ -30,8 +33,31 @@ type Span interface {
 	// GetStartTime returns the start time of the Span.
 	GetStartTime() time.Time
 
+	// GetSystemPrompt returns the system prompt set by SetSystemPrompt, false if it is not set.
+	GetSystemPrompt(ctx context.Context) (string, bool)
+
+	// Annotate Record a timestamped text annotation on the span.
+	Annotate(ctx context.Context, message string)
+
+	// EffectiveCost returns the cost of tokens which are not served from cache, that is
+	// (input_tokens - llm.cached_input_tokens + output_tokens - llm.reasoning_tokens) * costPerToken +
+	// llm.reasoning_tokens * reasoningCostPerToken.
+	// Cached tokens are billed at a lower rate, calculate their cost separately if needed.
+	// The cache discount of the fallback model is used if SetFallbackModel is called.
+	EffectiveCost(costPerToken, reasoningCostPerToken float64) float64
+
+	// CheckBudget returns the remaining input and output tokens of the budget set by SetTokenBudget,
+	// computed from input_tokens and output_tokens tags. Budget of 0 means unlimited.
+	// If exceeded, the span is marked as error and tagged with `llm.budget_exceeded`.
+	CheckBudget(ctx context.Context) (inputRemaining, outputRemaining int, exceeded bool)
+
 	// ToHeader Convert the span to headers. Used for cross-process correlation.
 	ToHeader() (map[string]string, error)
+
+	// Fork Start a child span which is detached from the cancellation of ctx.
+	// The child span carries the TraceID, workspace and baggage of the span,
+	// used for background tasks which outlive the request context.
+	Fork(ctx context.Context, name, spanType string) (context.Context, Span)
 }
 
 // Set system-defined fields
@@ -130,6 +156,273 @@ type commonSpanSetter interface {
 	// SetDeploymentEnv
 	// set the deployment env, identify custom env.
 	SetDeploymentEnv(ctx context.Context, deploymentEnv string)
+
+	// SetHTTPRequestBody key: `input`, `http.request.content_type`
+	// Set http request body as input. The body is redacted by the redactor set by WithHTTPBodyRedactor before stored,
+	// values of sensitive keys in JSON body are replaced with [REDACTED] by default.
+	SetHTTPRequestBody(ctx context.Context, body []byte, contentType string)
+
+	// SetHTTPResponseBody key: `output`, `http.status_code`
+	// Set http response body as output. The body is redacted like SetHTTPRequestBody.
+	SetHTTPResponseBody(ctx context.Context, body []byte, statusCode int)
+
+	// SetInputMessages key: `input`
+	// The messages sent to chat API, which are set as input in JSON and override any prior input.
+	SetInputMessages(ctx context.Context, messages []tracespec.ChatMessage)
+
+	// SetOutputMessages key: `output`
+	// The messages returned by chat API, which are set as output in JSON and override any prior output.
+	SetOutputMessages(ctx context.Context, messages []tracespec.ChatMessage)
+
+	// SetSystemPrompt key: `llm.system_prompt`
+	// The system prompt of model call, stored separately from input so that changes of system prompt can be tracked
+	// independently of user messages. It is truncated to the size limit of tag value.
+	SetSystemPrompt(ctx context.Context, prompt string)
+
+	// SetConversationHistory key: `input`
+	// The messages of multi-turn conversation, which are set as input in JSON and override any prior input.
+	// Use WithMaxConversationHistoryMessages when starting the span to keep only the most recent messages.
+	SetConversationHistory(ctx context.Context, messages []tracespec.ConversationMessage)
+
+	// SetMultiModalInputs key: `llm.image_input_bytes`, `llm.audio_input_bytes`
+	// Sum the bytes of image and audio inputs. If WithUploadMultiModalContent is enabled,
+	// Data of image and audio inputs are uploaded as attachments of input.
+	SetMultiModalInputs(ctx context.Context, inputs []tracespec.ModalInput)
+
+	// SetOutputFormat key: `llm.output_format`
+	// The format constraint of model output, such as JSON mode. If it is tracespec.OutputFormatJSON,
+	// string output set by SetOutput afterwards is validated, and `error` is set if it is not valid JSON.
+	SetOutputFormat(ctx context.Context, format tracespec.OutputFormat)
+
+	// SetOutputSchema key: `llm.output_schema`, `llm.output_schema_name`
+	// The JSON schema enforced on model output in structured generation, which is compacted and truncated
+	// like other tags if too long.
+	SetOutputSchema(ctx context.Context, schema json.RawMessage, schemaName string)
+
+	// ValidateOutput key: `llm.schema_validation_failed`
+	// Validate the output set by SetOutput against schema, and return the first violation. Only keywords type, enum,
+	// properties, required, additionalProperties and items of JSON schema are supported.
+	ValidateOutput(ctx context.Context, schema json.RawMessage) error
+
+	// SetCacheHit key: `llm.cache_hit`
+	// Whether the prompt hits the prompt cache of model provider.
+	SetCacheHit(ctx context.Context, hit bool)
+
+	// SetCachedInputTokens key: `llm.cached_input_tokens`
+	// The usage of input tokens served from cache, which are included in input_tokens, such as OpenAI.
+	SetCachedInputTokens(ctx context.Context, cachedInputTokens int)
+
+	// SetCacheReadInputTokens key: `llm.cache_read_input_tokens`
+	// The usage of input tokens read from cache, which are excluded from input_tokens, such as Anthropic.
+	SetCacheReadInputTokens(ctx context.Context, cacheReadInputTokens int)
+
+	// SetPromptCacheTokens key: `llm.cache_creation_tokens`, `llm.cache_read_tokens`
+	// The usage of prompt caching, such as cache_creation_input_tokens and cache_read_input_tokens of Anthropic.
+	// Unlike SetCacheHit, they are counts of tokens. Read tokens are discounted in EffectiveCost by
+	// the discount of the model set by WithPromptCacheDiscounts.
+	SetPromptCacheTokens(ctx context.Context, created, read int)
+
+	// SetReasoningTokens key: `llm.reasoning_tokens`
+	// The usage of output tokens used for chain-of-thought, which are included in output_tokens, such as OpenAI o1.
+	SetReasoningTokens(ctx context.Context, reasoningTokens int)
+
+	// SetTokenUsageByPart key: `llm.tokens_by_part`, `input_tokens`
+	// The input tokens of each message part, such as system, user, assistant and tool_call, which are set as JSON,
+	// and their total is set as input tokens, overriding SetInputTokens.
+	SetTokenUsageByPart(ctx context.Context, parts []tracespec.TokenUsagePart)
+
+	// SetTokenBudget key: `llm.input_token_budget`, `llm.output_token_budget`
+	// Set the maximum input and output tokens, 0 means unlimited. Use CheckBudget to check it.
+	SetTokenBudget(ctx context.Context, inputBudget, outputBudget int)
+
+	// SetContextWindow key: `llm.context_window_used`, `llm.context_window_limit`, `llm.context_window_utilization`
+	// If used exceeds limit * threshold set by WithContextWindowWarningThreshold (default 0.9),
+	// `llm.context_window_warning` is set and a warning is logged.
+	SetContextWindow(ctx context.Context, used, limit int)
+
+	// SetContextCompression key: `context.compression_method`, `context.original_tokens`, `context.compressed_tokens`,
+	// `context.compression_ratio`
+	// The result of compressing long context, such as by LLMLingua. ratio is original / compressed tokens, which is
+	// calculated from tokens if it is 0. If it is lower than the threshold set by WithContextCompressionThreshold,
+	// `context.compression_below_min` is set and a warning is logged.
+	SetContextCompression(ctx context.Context, method string, originalTokens, compressedTokens int, ratio float64)
+
+	// SetModelParameters key: `llm.temperature`, `llm.top_p`, `llm.max_tokens`, `llm.stop`,
+	// `llm.frequency_penalty`, `llm.presence_penalty`
+	// The inference parameters of the LLM, only non-zero parameters are set.
+	SetModelParameters(ctx context.Context, params tracespec.ModelParameters)
+
+	// SetFallbackModel key: `llm.fallback_model`, `llm.fallback_provider`, `llm.fallback_reason`, `llm.primary_model`
+	// The model called instead of the primary one, reason is from enum VFallbackReason in tracespec, such as rate_limited.
+	// Call it after SetModelName, the model name set before is kept in `llm.primary_model`.
+	SetFallbackModel(ctx context.Context, fallbackModelName, fallbackModelProvider, reason string)
+
+	// SetModelFingerprint key: `llm.model_fingerprint`
+	// The fingerprint of model backend returned by provider, such as system_fingerprint of OpenAI.
+	// Use WithModelDriftCallback to be notified when it changes for the same model name.
+	SetModelFingerprint(ctx context.Context, fingerprint string)
+
+	// SetFineTuningMetadata key: `ft.base_model`, `ft.model_id`, `ft.training_dataset_id`, `ft.training_steps`, `ft.epoch`
+	// The metadata of fine-tuned model, to compare it with the base model. Empty fields are not set.
+	SetFineTuningMetadata(ctx context.Context, meta tracespec.FineTuningMeta)
+
+	// SetGPUMetrics key: `gpu.device_id`, `gpu.utilization`, `gpu.memory_used_mb`, `gpu.memory_total_mb`,
+	// `gpu.power_watts`, `gpu.temperature`
+	// The telemetry of GPU running model inference. Use WithGPUMetricsCollector to set it on model spans automatically.
+	SetGPUMetrics(ctx context.Context, metrics tracespec.GPUMetrics)
+
+	// SetLatencyBudget key: `slo.latency_budget_micros`
+	// Set the latency budget of span, which overrides the default set by WithGlobalLatencyBudgets.
+	// If the duration exceeds it on finish, `slo.violated` and `slo.excess_micros` are set.
+	SetLatencyBudget(ctx context.Context, budget time.Duration)
+
+	// SetFunctionCall key: `function.name`, `function.arguments`, `function.call_id`
+	// The function call generated by the model, such as OpenAI function calling.
+	SetFunctionCall(ctx context.Context, call tracespec.FunctionCall)
+
+	// SetFunctionCallResult key: `function.result`, `function.is_error`, `function.call_id`
+	// The result of function call, which is correlated to the call by call id.
+	SetFunctionCallResult(ctx context.Context, result tracespec.FunctionCallResult)
+
+	// SetToolSchema key: `tool.schema`
+	// The JSON schema of tool definition given to the model, for auditing capability exposure.
+	// The value is truncated like other tags if too long.
+	SetToolSchema(ctx context.Context, schema json.RawMessage)
+
+	// SetCitations key: `rag.citations`
+	// The source documents cited by the model response, serialized as a JSON array.
+	// The value is truncated like other tags if too long, so keep excerpts short.
+	SetCitations(ctx context.Context, citations []tracespec.Citation)
+
+	// SetDocumentContext key: `rag.documents`
+	// The retrieved documents given to the model as context, serialized as a JSON array.
+	SetDocumentContext(ctx context.Context, docs []tracespec.DocumentContext)
+
+	// SetHypotheticalDocument key: `rag.hypothetical_document`, `rag.strategy`
+	// The hypothetical document generated by HyDE as the query of embedding search. `rag.strategy` is set to
+	// `hyde` to distinguish it from standard embedding retrieval. The document is truncated like other tags.
+	SetHypotheticalDocument(ctx context.Context, doc string)
+
+	// SetRetrievalFilter key: `rag.retrieval_filter`, or `rag.retrieval_filter_keys` if too large
+	// The metadata filter of vector search, serialized as compact JSON. If it exceeds the size limit of tag value,
+	// only the keys of filter are stored as a JSON array.
+	SetRetrievalFilter(ctx context.Context, filter map[string]interface{})
+
+	// SetEmbeddingCacheResult key: `embedding.cache_hit`, `embedding.cached_count`, `embedding.recomputed_count`
+	// The result of embedding cache lookup. If all embeddings are served from cache, the latency of cache is set as
+	// `embedding.cache_only_duration_micros` on finish, which is from start to the time set by SetCacheCheckpointTime,
+	// or the duration of span if it is not set.
+	SetEmbeddingCacheResult(ctx context.Context, hit bool, cachedCount, recomputedCount int)
+
+	// SetCacheCheckpointTime key: `embedding.cache_checkpoint_time`
+	// The time when cache lookup is done, which splits cache latency from compute latency. Unit: microseconds.
+	SetCacheCheckpointTime(ctx context.Context, t time.Time)
+
+	// SetVectorSearchResults key: `vector.search_results`
+	// The results of similarity search of vector database, stored as JSON array in descending order of score.
+	// Use WithMaxVectorResultsInSpan to keep only the top K results. Metadata values are truncated.
+	SetVectorSearchResults(ctx context.Context, results []tracespec.VectorSearchResult)
+
+	// SetRerankResult key: `rerank.model`, `rerank.input_document_count`, `rerank.output_document_count`,
+	// `rerank.top_score`, `rerank.mean_score`, `rerank.min_score`
+	// The result of reranking documents. Scores are summarized by max, mean and min instead of stored verbatim,
+	// and score tags are not set if scores is empty.
+	SetRerankResult(ctx context.Context, model string, inputCount, outputCount int, scores []float64)
+
+	// SetVectorDBSystem key: `vector_db.system`
+	// The vector database product, such as pinecone, weaviate, qdrant. Use it on spans of type vector_db.
+	SetVectorDBSystem(ctx context.Context, system string)
+
+	// SetVectorDBOperation key: `vector_db.operation`
+	// The operation of vector database, such as upsert, query, delete, fetch.
+	SetVectorDBOperation(ctx context.Context, op string)
+
+	// SetVectorDBIndexName key: `vector_db.index_name`
+	SetVectorDBIndexName(ctx context.Context, name string)
+
+	// SetVectorDBNamespace key: `vector_db.namespace`
+	SetVectorDBNamespace(ctx context.Context, namespace string)
+
+	// SetVectorDBQueryVector key: `vector_db.query_vector_dims`
+	// The dimensionality of query vector. The vector itself is not stored as it is too large.
+	SetVectorDBQueryVector(ctx context.Context, dims int)
+
+	// SetSearchQuery key: `search.query`
+	SetSearchQuery(ctx context.Context, query string)
+
+	// SetSearchEngine key: `search.engine`
+	// The search engine, such as bing, google, or name of internal search service.
+	SetSearchEngine(ctx context.Context, engine string)
+
+	// SetSearchResultCount key: `search.total_results`, `search.returned_results`
+	// The number of matched results reported by the engine, and the number of results returned.
+	SetSearchResultCount(ctx context.Context, total, returned int)
+
+	// SetSearchResultSnippets key: `search.snippets`
+	// The snippets of returned results in JSON. Each snippet is truncated to 512 characters, and the last snippets
+	// are dropped if the JSON array exceeds the size limit of tag value.
+	SetSearchResultSnippets(ctx context.Context, snippets []string)
+
+	// SetEvaluationResult key: `eval.evaluator`, `eval.score`, `eval.max_score`, `eval.passed`, `eval.rationale`
+	// and `eval.label.<name>` for each label.
+	// The result of evaluating an output, such as by LLM judge. Use tracespec.VEvaluationSpanType as span type
+	// for spans of evaluation runs.
+	SetEvaluationResult(ctx context.Context, result tracespec.EvaluationResult)
+
+	// SetHallucinationScore key: `safety.hallucination_score`, `safety.grounded_claims`, `safety.total_claims`
+	// The degree of fabricated information in model output, such as judged against retrieved context.
+	// If the score is above the threshold set by WithHallucinationThreshold, the span is marked as error.
+	SetHallucinationScore(ctx context.Context, score float64, groundedClaims, totalClaims int)
+
+	// SetGuardrailResult key: `guardrail.name`, `guardrail.triggered`, `guardrail.action`, `guardrail.categories`,
+	// `guardrail.confidence`
+	// The outcome of a safety guardrail, such as prompt shield or content classifier. Use
+	// tracespec.VGuardrailSpanType as span type for spans of guardrail checks. If the guardrail is triggered
+	// and the content is blocked, the span is marked as error.
+	SetGuardrailResult(ctx context.Context, result tracespec.GuardrailResult)
+
+	// SetSelfConsistencyVotes key: `llm.sc_unique_answers`, `llm.sc_total_votes`, `llm.sc_majority_answer`,
+	// `llm.sc_majority_confidence`, `llm.sc_votes`
+	// The votes of answers of self-consistency sampling. Confidence of majority answer is its count / total votes.
+	// Votes are set as JSON in descending order of count, and the least voted are dropped if it is too large.
+	SetSelfConsistencyVotes(ctx context.Context, votes []tracespec.SelfConsistencyVote)
+
+	// SetAgentRound key: `agent.round`, `agent.max_rounds`, `agent.max_rounds_reached`
+	// The iteration of agentic loop, such as think/act/observe cycles of ReAct agents. `agent.max_rounds_reached`
+	// is set if round >= maxRounds. Use Client.NextAgentRound to set `agent.round` on spans of each iteration.
+	SetAgentRound(ctx context.Context, round, maxRounds int)
+
+	// SetPlanningStep key: `plan.node_id`, `plan.parent_node_id`, `plan.depth`, `plan.score`, `plan.pruned`,
+	// `plan.selected_for_expansion`
+	// The node of planning tree evaluated by the span, use tracespec.VPlanningSpanType as span type. Markdown written
+	// by FileExporter renders nodes of each trace in an exported batch as an ASCII tree.
+	SetPlanningStep(ctx context.Context, step tracespec.PlanningStep)
+
+	// SetAgentMemory key: `agent.memory_entry_count`, `agent.memory_size_bytes`, `agent.memory_retrieved_entries`,
+	// `agent.memory_type`
+	// The state of agent working memory when the action is taken. Empty memory type is not set.
+	SetAgentMemory(ctx context.Context, mem tracespec.AgentMemory)
+
+	// SetCustomAgentMemory key: the same as SetAgentMemory
+	// The memory of custom type, which is adapted to tracespec.AgentMemory by the serializer set by
+	// WithAgentMemorySerializer. It is ignored if no serializer is set.
+	SetCustomAgentMemory(ctx context.Context, mem interface{})
+
+	// SetChainStep key: `chain.step_index`, `chain.step_name`, `chain.total_steps`
+	// The position of the span among steps of a sequential pipeline, such as extract, transform and load.
+	// If the span is started with empty name, its name is set to `<workflowName>/<stepName>`, where workflowName
+	// is the name of span set by WithChainStepParent.
+	SetChainStep(ctx context.Context, stepIndex int, stepName string, totalSteps int)
+
+	// SetRetryContext key: `retry.attempt`, `retry.max_attempts`, `retry.backoff_millis`
+	// The attempt of span among retries of a failed operation such as model call, starting from 1, and the backoff
+	// waited before it. Use Client.StartRetrySpan to start the span of each attempt.
+	SetRetryContext(ctx context.Context, attempt, maxAttempts int, backoffDuration time.Duration)
+
+	// SetCodeLocation key: `code.function`, `code.filepath`, `code.lineno`
+	// The source code location of the caller, found by unwinding the stack to the first frame outside the SDK.
+	// Use WithCodeLocationOnAllSpans to set it on every span on start.
+	SetCodeLocation(ctx context.Context)
 }
 
 // SpanContext is the interface for span Baggage transfer.
@@ -138,3 +431,44 @@ type SpanContext interface {
 	GetTraceID() string
 	GetBaggage() map[string]string
 }
+
+// GroupSpan is a synthetic span created by GroupSpans. Its input is a JSON summary of names of the grouped spans,
+// and its tags `group.span_count`, `group.error_count`, `group.total_duration_micros` and
+// `group.max_duration_micros` are set on finish.
+type GroupSpan interface {
+	Span
+
+	// GroupedSpans returns the spans grouped under the span.
+	GroupedSpans() []Span
+}
+
+// loopSpan is the implement of Span, which wraps the internal span.
+type loopSpan struct {
+	*trace.Span
+	client *loopClient
+}
+
+func (s *loopSpan) getInternalSpan() *trace.Span {
+	if s == nil {
+		return nil
+	}
+	return s.Span
+}
+
+// loopGroupSpan is the implement of GroupSpan.
+type loopGroupSpan struct {
+	*loopSpan
+	spans []Span
+}
+
+func (s *loopGroupSpan) GroupedSpans() []Span {
+	return append([]Span(nil), s.spans...)
+}
+
+func (s *loopSpan) Fork(ctx context.Context, name, spanType string) (context.Context, Span) {
+	if s == nil || s.Span == nil || s.client == nil {
+		return ctx, DefaultNoopSpan
+	}
+	return s.client.StartSpan(util.WithoutCancel(ctx), name, spanType,
+		WithChildOf(s.Span), WithSpanWorkspaceID(s.GetSpaceID()))
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package cozeloop
+
+import (
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+// SpanProcessor is called synchronously when spans are started and finished, set by WithSpanProcessor.
+// OnStart can enrich the span, such as setting tags from the environment. OnEnd receives the finished span,
+// which must not be modified.
+type SpanProcessor = trace.SpanProcessor
+
+// ReadOnlySpan is the read-only view of a finished span, passed to SpanProcessor.OnEnd.
+type ReadOnlySpan = trace.ReadOnlySpan
+
+// ReadWriteSpan is a started span which can be modified, passed to SpanProcessor.OnStart.
+type ReadWriteSpan = trace.ReadWriteSpan
+
+// BatchSpanProcessor is the SpanProcessor of client exporting finished spans and their files in batches.
+// It is always called after processors set by WithSpanProcessor.
+type BatchSpanProcessor = trace.BatchSpanProcessor
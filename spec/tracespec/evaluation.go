@@ -0,0 +1,14 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package tracespec
+
+// EvaluationResult is the result of evaluating an output, such as by LLM judge, recorded by Span.SetEvaluationResult.
+type EvaluationResult struct {
+	EvaluatorName string
+	Score         float64
+	MaxScore      float64 // Optional. The max score of evaluator, such as 1 or 10.
+	Passed        bool
+	Rationale     string            // Optional. The reason for the score given by evaluator.
+	Labels        map[string]string // Optional. Set as tags with key prefix `eval.label.`.
+}
@@ -0,0 +1,14 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package tracespec
+
+// GPUMetrics is the telemetry of a GPU device, recorded by Span.SetGPUMetrics.
+type GPUMetrics struct {
+	DeviceID           int
+	UtilizationPercent float64 // In [0, 100].
+	MemoryUsedMB       int64
+	MemoryTotalMB      int64
+	PowerWatts         float64
+	Temperature        float64 // In Celsius.
+}
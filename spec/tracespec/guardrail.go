@@ -0,0 +1,23 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package tracespec
+
+// GuardrailAction is the action taken by guardrail on the checked content.
+type GuardrailAction string
+
+const (
+	GuardrailActionAllow  GuardrailAction = "allow"
+	GuardrailActionBlock  GuardrailAction = "block"
+	GuardrailActionRedact GuardrailAction = "redact"
+)
+
+// GuardrailResult is the outcome of a safety guardrail, such as prompt shield or content classifier,
+// recorded by Span.SetGuardrailResult.
+type GuardrailResult struct {
+	GuardrailName string
+	Triggered     bool
+	Action        GuardrailAction
+	Categories    []string // Optional. The violated categories, such as violence, self_harm.
+	Confidence    float64  // Optional. The confidence of classifier, in [0, 1].
+}
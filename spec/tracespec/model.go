@@ -32,6 +32,110 @@ type ModelCallOption struct {
 	ReasoningEffort  string   `json:"reasoning_effort,omitempty"`
 }
 
+// ModelParameters is the inference parameters of model, set by Span.SetModelParameters as separate tags.
+// Zero values are treated as unset.
+type ModelParameters struct {
+	Temperature      float64
+	TopP             float64
+	MaxTokens        int
+	Stop             []string
+	FrequencyPenalty float64
+	PresencePenalty  float64
+}
+
+// TokenUsagePart is the input tokens of a message part, set by Span.SetTokenUsageByPart.
+type TokenUsagePart struct {
+	Part   string `json:"part"` // from enum VTokenPart in span_value
+	Tokens int    `json:"tokens"`
+}
+
+// SelfConsistencyVote is the votes of an answer of self-consistency sampling, set by Span.SetSelfConsistencyVotes.
+type SelfConsistencyVote struct {
+	Answer string `json:"answer"`
+	Count  int    `json:"count"`
+}
+
+// OutputFormat is the format constraint of model output, set by Span.SetOutputFormat.
+type OutputFormat string
+
+const (
+	OutputFormatText       OutputFormat = "text"
+	OutputFormatJSON       OutputFormat = "json"
+	OutputFormatMarkdown   OutputFormat = "markdown"
+	OutputFormatStructured OutputFormat = "structured" // structured output following a JSON schema
+)
+
+// ModalType is the type of ModalInput.
+type ModalType string
+
+const (
+	ModalTypeText  ModalType = "text"
+	ModalTypeImage ModalType = "image"
+	ModalTypeAudio ModalType = "audio"
+)
+
+// ModalInput is an input of multi-modal model, set by Span.SetMultiModalInputs.
+type ModalInput struct {
+	Type      ModalType
+	SizeBytes int    // Size of the content, len(Data) is used if it is 0.
+	Format    string // Format of the content, such as jpeg, png, wav.
+	Data      []byte // Optional. The content, which is uploaded only if uploading multi-modal content is enabled.
+}
+
+// FineTuningMeta is the metadata of fine-tuned model, recorded by Span.SetFineTuningMetadata.
+type FineTuningMeta struct {
+	BaseModel         string
+	FineTunedModelID  string
+	TrainingDatasetID string
+	TrainingSteps     int
+	Epoch             float64
+}
+
+// AgentMemory is the state of agent working memory, recorded by Span.SetAgentMemory.
+type AgentMemory struct {
+	EntryCount       int
+	MemorySizeBytes  int
+	RetrievedEntries int
+	MemoryType       string // from enum VAgentMemoryType in span_value, such as buffer, summary, entity, vector
+}
+
+// PlanningStep is a node of planning tree, such as a thought of tree-of-thought, set by Span.SetPlanningStep.
+type PlanningStep struct {
+	NodeID               string
+	ParentNodeID         string // empty for root node
+	Depth                int
+	Score                float64
+	Pruned               bool
+	SelectedForExpansion bool
+}
+
+// FunctionCall is a function call generated by the model, recorded by Span.SetFunctionCall.
+type FunctionCall struct {
+	Name      string
+	Arguments json.RawMessage
+	CallID    string
+}
+
+// FunctionCallResult is the result of function call, recorded by Span.SetFunctionCallResult.
+type FunctionCallResult struct {
+	CallID  string
+	Content string
+	IsError bool
+}
+
+// ConversationMessage is a message of multi-turn conversation, set by Span.SetConversationHistory.
+type ConversationMessage struct {
+	Role    string `json:"role"` // from enum VRole in span_value
+	Content string `json:"content"`
+}
+
+// ChatMessage is a message of chat API, set by Span.SetInputMessages and Span.SetOutputMessages.
+type ChatMessage struct {
+	Role    string `json:"role"` // from enum VRole in span_value
+	Content string `json:"content"`
+	Name    string `json:"name,omitempty"`
+}
+
 type ModelMessage struct {
 	Role             string              `json:"role"`                        // from enum VRole in span_value
 	Content          string              `json:"content,omitempty"`           // single content
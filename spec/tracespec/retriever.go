@@ -24,3 +24,28 @@ type RetrieverCallOption struct {
 	MinScore *float64 `json:"min_score,omitempty"`
 	Filter   string   `json:"filter,omitempty"`
 }
+
+// VectorSearchResult is a result of similarity search of vector database, recorded by Span.SetVectorSearchResults.
+type VectorSearchResult struct {
+	ID       string            `json:"id"`
+	Score    float64           `json:"score"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// DocumentContext is a retrieved document chunk given to the model as context, recorded by Span.SetDocumentContext.
+type DocumentContext struct {
+	ID             string  `json:"id"`
+	Title          string  `json:"title,omitempty"`
+	Source         string  `json:"source,omitempty"` // URL or file path of the document.
+	ChunkIndex     int     `json:"chunk_index"`
+	TokenCount     int     `json:"token_count"`
+	RelevanceScore float64 `json:"relevance_score"`
+}
+
+// Citation is a source document cited by the model, recorded by Span.SetCitations.
+type Citation struct {
+	DocumentID     string  `json:"document_id"`
+	Source         string  `json:"source,omitempty"` // URL or file path of the document.
+	RelevanceScore float64 `json:"relevance_score"`
+	Excerpt        string  `json:"excerpt,omitempty"`
+}
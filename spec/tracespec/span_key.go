@@ -9,11 +9,137 @@ const (
 	Stream            = "stream"             // Used to identify whether it is a streaming output.
 	ReasoningTokens   = "reasoning_tokens"   // The token usage during the reasoning process.
 	ReasoningDuration = "reasoning_duration" // The duration during the reasoning process. The unit is microseconds.
+
+	CacheHit             = "llm.cache_hit"               // Whether the prompt hits the cache of model provider.
+	CachedInputTokens    = "llm.cached_input_tokens"     // The input tokens served from cache, which are included in input_tokens, like OpenAI.
+	CacheReadInputTokens = "llm.cache_read_input_tokens" // The input tokens read from cache, which are excluded from input_tokens, like Anthropic.
+	LLMReasoningTokens   = "llm.reasoning_tokens"        // The output tokens used for chain-of-thought, which are included in output_tokens, like OpenAI o1.
+	CacheCreationTokens  = "llm.cache_creation_tokens"   // The input tokens written to prompt cache, like cache_creation_input_tokens of Anthropic.
+	CacheReadTokens      = "llm.cache_read_tokens"       // The input tokens read from prompt cache, like cache_read_input_tokens of Anthropic.
+	TokensByPart         = "llm.tokens_by_part"          // JSON array of input tokens of each message part, set by SetTokenUsageByPart.
+
+	ModelFingerprint = "llm.model_fingerprint" // The fingerprint of model backend returned by provider, like system_fingerprint of OpenAI.
+	SystemPrompt     = "llm.system_prompt"     // The system prompt, stored separately from user messages in input.
+	LLMOutputFormat  = "llm.output_format"     // The format constraint of model output, such as json of JSON mode, from enum OutputFormat.
+
+	OutputSchema           = "llm.output_schema"            // The compacted JSON schema enforced on model output.
+	OutputSchemaName       = "llm.output_schema_name"       // The name of output schema, such as the name of Pydantic model.
+	SchemaValidationFailed = "llm.schema_validation_failed" // Whether the output does not conform to the schema, set by ValidateOutput.
+
+	SCUniqueAnswers      = "llm.sc_unique_answers"      // The number of distinct answers of self-consistency sampling.
+	SCTotalVotes         = "llm.sc_total_votes"         // The number of sampled reasoning paths.
+	SCMajorityAnswer     = "llm.sc_majority_answer"     // The answer of most votes.
+	SCMajorityConfidence = "llm.sc_majority_confidence" // Votes of majority answer / total votes.
+	SCVotes              = "llm.sc_votes"               // JSON array of SelfConsistencyVote in descending order of count.
+
+	PrimaryModel     = "llm.primary_model"     // The model name set before falling back, set by SetFallbackModel.
+	FallbackModel    = "llm.fallback_model"    // The model actually called when the primary model is unavailable.
+	FallbackProvider = "llm.fallback_provider" // The provider of fallback model.
+	FallbackReason   = "llm.fallback_reason"   // The reason of falling back, from enum VFallbackReason in span_value.
+
+	ImageInputBytes = "llm.image_input_bytes" // The total bytes of image inputs, set by SetMultiModalInputs.
+	AudioInputBytes = "llm.audio_input_bytes" // The total bytes of audio inputs, set by SetMultiModalInputs.
+
+	Temperature      = "llm.temperature"
+	TopP             = "llm.top_p"
+	MaxTokens        = "llm.max_tokens"
+	StopSequences    = "llm.stop" // JSON array of stop sequences.
+	FrequencyPenalty = "llm.frequency_penalty"
+	PresencePenalty  = "llm.presence_penalty"
+
+	InputTokenBudget  = "llm.input_token_budget"  // The maximum input tokens allowed, 0 means unlimited.
+	OutputTokenBudget = "llm.output_token_budget" // The maximum output tokens allowed, 0 means unlimited.
+	BudgetExceeded    = "llm.budget_exceeded"     // Whether input or output tokens exceed the budget.
+
+	ContextWindowUsed        = "llm.context_window_used"        // The tokens used of model context window.
+	ContextWindowLimit       = "llm.context_window_limit"       // The size of model context window.
+	ContextWindowUtilization = "llm.context_window_utilization" // used / limit.
+	ContextWindowWarning     = "llm.context_window_warning"     // Whether utilization exceeds the warning threshold.
+)
+
+// Tags for fine-tuned model, set by SetFineTuningMetadata.
+const (
+	FTBaseModel         = "ft.base_model"
+	FTModelID           = "ft.model_id"
+	FTTrainingDatasetID = "ft.training_dataset_id"
+	FTTrainingSteps     = "ft.training_steps"
+	FTEpoch             = "ft.epoch"
+)
+
+// Tags for steps of sequential pipeline, set by SetChainStep.
+const (
+	ChainStepIndex  = "chain.step_index"
+	ChainStepName   = "chain.step_name"
+	ChainTotalSteps = "chain.total_steps"
+)
+
+// Tags for factuality of model output, set by SetHallucinationScore.
+const (
+	HallucinationScore = "safety.hallucination_score" // The degree of fabricated information, higher means less grounded.
+	GroundedClaims     = "safety.grounded_claims"     // The number of claims supported by retrieved context.
+	TotalClaims        = "safety.total_claims"
 )
 
 // Tags for tool-type span.
 const (
 	ToolCallID = "tool_call_id"
+	ToolSchema = "tool.schema" // The JSON schema of tool definition given to the model.
+)
+
+// Tags for function calling, set by SetFunctionCall and SetFunctionCallResult.
+const (
+	FunctionName      = "function.name"
+	FunctionArguments = "function.arguments" // Arguments generated by the model, in JSON.
+	FunctionCallID    = "function.call_id"
+	FunctionResult    = "function.result"
+	FunctionIsError   = "function.is_error" // Whether the function call result is an error.
+)
+
+// Tags for http request, set by SetHTTPRequestBody, SetHTTPResponseBody and http middlewares.
+const (
+	HTTPRequestContentType = "http.request.content_type"
+	HTTPStatusCode         = "http.status_code"
+	HTTPMethod             = "http.method"
+	HTTPURL                = "http.url"
+	HTTPRoute              = "http.route" // The matched route template, such as /users/:id.
+)
+
+// Tags for database-type span, set by database instrumentations such as dynamodbtrace.
+const (
+	DBSystem              = "db.system" // The database product, such as dynamodb.
+	DBTableName           = "db.table_name"
+	DBOperation           = "db.operation" // The operation name, such as GetItem.
+	DBConsumedCapacityRCU = "db.consumed_capacity_rcu"
+	DBConsumedCapacityWCU = "db.consumed_capacity_wcu"
+	AWSRequestID          = "aws.request_id"
+)
+
+// Tags for vector_db-type span.
+const (
+	VectorDBSystem          = "vector_db.system"    // The vector database product, such as pinecone, weaviate, qdrant.
+	VectorDBOperation       = "vector_db.operation" // The operation, such as upsert, query, delete, fetch.
+	VectorDBIndexName       = "vector_db.index_name"
+	VectorDBNamespace       = "vector_db.namespace"
+	VectorDBQueryVectorDims = "vector_db.query_vector_dims" // The dimensionality of query vector, which is not stored itself.
+)
+
+// Tags for search-type span, which can also be set on tool-type span of search tool.
+const (
+	SearchQuery           = "search.query"
+	SearchEngine          = "search.engine" // The search engine, such as bing, google, or name of internal search service.
+	SearchTotalResults    = "search.total_results"
+	SearchReturnedResults = "search.returned_results"
+	SearchSnippets        = "search.snippets" // JSON array of snippets of returned results, in order of rank.
+)
+
+// Tags for reranking of RAG pipelines, set by SetRerankResult.
+const (
+	RerankModel               = "rerank.model" // The reranking model, such as a cross-encoder.
+	RerankInputDocumentCount  = "rerank.input_document_count"
+	RerankOutputDocumentCount = "rerank.output_document_count"
+	RerankTopScore            = "rerank.top_score"
+	RerankMeanScore           = "rerank.mean_score"
+	RerankMinScore            = "rerank.min_score"
 )
 
 // Tags for retriever-type span
@@ -24,6 +150,119 @@ const (
 	ESName            = "es_name"            // When using ES to provide retrieval capabilities, es name.
 	ESIndex           = "es_index"           // When using ES to provide retrieval capabilities, es index.
 	ESCluster         = "es_cluster"         // When using ES to provide retrieval capabilities, es cluster.
+
+	RAGCitations = "rag.citations" // The source documents cited by the model, JSON array of Citation.
+	RAGDocuments = "rag.documents" // The documents given to the model as context, JSON array of DocumentContext.
+
+	RAGRetrievalFilter     = "rag.retrieval_filter"      // The metadata filter of vector search, compact JSON object.
+	RAGRetrievalFilterKeys = "rag.retrieval_filter_keys" // The sorted keys of metadata filter in JSON array, set if the filter is too large.
+	RAGHypotheticalDoc     = "rag.hypothetical_document" // The hypothetical document generated by HyDE as the query of embedding search.
+	RAGStrategy            = "rag.strategy"              // The retrieval strategy, such as hyde.
+
+	VectorSearchResults = "vector.search_results" // The top results of similarity search, JSON array of VectorSearchResult.
+
+	EmbeddingCacheHit          = "embedding.cache_hit"                  // Whether any embedding is served from cache.
+	EmbeddingCachedCount       = "embedding.cached_count"               // The number of embeddings served from cache.
+	EmbeddingRecomputedCount   = "embedding.recomputed_count"           // The number of embeddings computed by model.
+	EmbeddingCacheCheckpoint   = "embedding.cache_checkpoint_time"      // The time when cache lookup is done, unit: microseconds.
+	EmbeddingCacheOnlyDuration = "embedding.cache_only_duration_micros" // The latency of span served only from cache, set on finish.
+)
+
+// Tags for latency SLO of span, set by SetLatencyBudget or WithGlobalLatencyBudgets and checked on finish.
+const (
+	SLOLatencyBudget = "slo.latency_budget_micros" // The latency budget of span, unit: microseconds.
+	SLOViolated      = "slo.violated"              // Whether the duration of span exceeds the latency budget.
+	SLOExcessMicros  = "slo.excess_micros"         // The duration exceeding the latency budget, unit: microseconds.
+)
+
+// Tags for long-context compression, such as LLMLingua, set by SetContextCompression.
+const (
+	ContextCompressionMethod   = "context.compression_method"
+	ContextOriginalTokens      = "context.original_tokens"
+	ContextCompressedTokens    = "context.compressed_tokens"
+	ContextCompressionRatio    = "context.compression_ratio"     // original_tokens / compressed_tokens.
+	ContextCompressionBelowMin = "context.compression_below_min" // Whether the ratio is lower than the threshold set by WithContextCompressionThreshold.
+)
+
+// Tags for iteration of agentic loop, set by SetAgentRound or spans started from ctx of Client.NextAgentRound.
+const (
+	AgentRound            = "agent.round" // The iteration of think/act/observe cycle, starting from 1.
+	AgentMaxRounds        = "agent.max_rounds"
+	AgentMaxRoundsReached = "agent.max_rounds_reached" // Whether the round reaches max rounds.
+)
+
+// Tags for node of planning tree, such as tree-of-thought or MCTS, set by SetPlanningStep.
+const (
+	PlanNodeID               = "plan.node_id"
+	PlanParentNodeID         = "plan.parent_node_id" // Empty for root node.
+	PlanDepth                = "plan.depth"          // The depth of node, 0 for root node.
+	PlanScore                = "plan.score"          // The value of node evaluated by the planner.
+	PlanPruned               = "plan.pruned"
+	PlanSelectedForExpansion = "plan.selected_for_expansion"
+)
+
+// Tags for state of agent working memory, set by SetAgentMemory.
+const (
+	AgentMemoryEntryCount       = "agent.memory_entry_count"       // The number of entries in agent memory.
+	AgentMemorySizeBytes        = "agent.memory_size_bytes"        // The size of agent memory.
+	AgentMemoryRetrievedEntries = "agent.memory_retrieved_entries" // The number of entries retrieved for the action.
+	AgentMemoryType             = "agent.memory_type"              // From enum VAgentMemoryType in span_value.
+)
+
+// Tags for source code location of span, set by SetCodeLocation or WithCodeLocationOnAllSpans.
+const (
+	CodeFunction = "code.function" // The full name of function, such as github.com/org/repo/pkg.(*Agent).Run.
+	CodeFilepath = "code.filepath"
+	CodeLineNo   = "code.lineno"
+)
+
+// Tags for sampling, set on finish if enabled by WithSamplingWeightTag.
+const (
+	SamplingWeight = "sampling.weight" // The number of spans represented by the span, 1/ratio of the ratio sampler.
+)
+
+// Tags for evaluation result, set by SetEvaluationResult.
+const (
+	EvalEvaluator   = "eval.evaluator"
+	EvalScore       = "eval.score"
+	EvalMaxScore    = "eval.max_score"
+	EvalPassed      = "eval.passed"
+	EvalRationale   = "eval.rationale"
+	EvalLabelPrefix = "eval.label." // Prefix of keys of labels, such as eval.label.dataset.
+)
+
+// Tags for guardrail result, set by SetGuardrailResult.
+const (
+	GuardrailName       = "guardrail.name"
+	GuardrailTriggered  = "guardrail.triggered"
+	GuardrailAction_    = "guardrail.action" // The action taken on the content: allow, block or redact.
+	GuardrailCategories = "guardrail.categories"
+	GuardrailConfidence = "guardrail.confidence"
+)
+
+// Tags for GPU telemetry, set by SetGPUMetrics.
+const (
+	GPUDeviceID      = "gpu.device_id"
+	GPUUtilization   = "gpu.utilization" // The utilization of GPU in percent, in [0, 100].
+	GPUMemoryUsedMB  = "gpu.memory_used_mb"
+	GPUMemoryTotalMB = "gpu.memory_total_mb"
+	GPUPowerWatts    = "gpu.power_watts"
+	GPUTemperature   = "gpu.temperature" // The temperature of GPU in Celsius.
+)
+
+// Tags for retry attempts, set by SetRetryContext.
+const (
+	RetryAttempt       = "retry.attempt" // Starts from 1.
+	RetryMaxAttempts   = "retry.max_attempts"
+	RetryBackoffMillis = "retry.backoff_millis" // The backoff waited before this attempt.
+)
+
+// Tags for group-type span, set by GroupSpans when the group span is finished.
+const (
+	GroupSpanCount           = "group.span_count"
+	GroupErrorCount          = "group.error_count" // The count of grouped spans with non-zero status code.
+	GroupTotalDurationMicros = "group.total_duration_micros"
+	GroupMaxDurationMicros   = "group.max_duration_micros"
 )
 
 // Tags for prompt-type span.
@@ -12,6 +12,15 @@ const (
 	VModelSpanType                  = "model"
 	VRetrieverSpanType              = "retriever"
 	VToolSpanType                   = "tool"
+	VHTTPServerSpanType             = "http_server"
+	VHTTPClientSpanType             = "http_client" // Span of an outbound http request.
+	VEvaluationSpanType             = "evaluation"  // Span of an evaluation run, such as LLM judge.
+	VGroupSpanType                  = "group"       // Synthetic span grouping spans, created by GroupSpans.
+	VGuardrailSpanType              = "guardrail"   // Span of a safety check, such as prompt shield or content filter.
+	VDatabaseSpanType               = "database"    // Span of a database operation.
+	VVectorDBSpanType               = "vector_db"   // Span of a vector database operation, such as Pinecone upsert.
+	VSearchSpanType                 = "search"      // Span of a search operation, such as web search tool or Bing API call.
+	VPlanningSpanType               = "planning"    // Span of a node of planning agents, such as tree-of-thought or MCTS.
 )
 
 const (
@@ -32,6 +41,34 @@ const (
 	VToolChoiceFunction = "function" // Forces the model to call that tool.
 )
 
+// Tag values for rag.strategy.
+const (
+	VRAGStrategyHyDE = "hyde" // Hypothetical document embedding, set by SetHypotheticalDocument.
+)
+
+// Tag values for part of llm.tokens_by_part.
+const (
+	VTokenPartSystem    = "system"
+	VTokenPartUser      = "user"
+	VTokenPartAssistant = "assistant"
+	VTokenPartToolCall  = "tool_call"
+)
+
+// Tag values for agent.memory_type.
+const (
+	VAgentMemoryTypeBuffer  = "buffer"
+	VAgentMemoryTypeSummary = "summary"
+	VAgentMemoryTypeEntity  = "entity"
+	VAgentMemoryTypeVector  = "vector"
+)
+
+// Tag values for llm.fallback_reason.
+const (
+	VFallbackReasonRateLimited           = "rate_limited"
+	VFallbackReasonModelUnavailable      = "model_unavailable"
+	VFallbackReasonLatencyBudgetExceeded = "latency_budget_exceeded"
+)
+
 // Tag values for runtime tags.
 const (
 	VLangGo         = "go"
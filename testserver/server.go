@@ -0,0 +1,200 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package testserver provides a mock CozeLoop server for integration tests of code instrumented with cozeloop trace.
+package testserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+)
+
+const (
+	// PathIngestTrace is the path of span upload API, the same as the default span upload path of cozeloop client.
+	PathIngestTrace = "/v1/loop/traces/ingest"
+	// PathUploadFile is the path of file upload API, the same as the default file upload path of cozeloop client.
+	PathUploadFile = "/v1/loop/files/upload"
+
+	maxUploadFileMemory = 32 << 20
+)
+
+// TestServer is a mock CozeLoop server listening on a random local port, which records received spans and files.
+// Use URL with cozeloop.WithAPIBaseURL to send spans to it.
+type TestServer struct {
+	t      testing.TB
+	server *httptest.Server
+
+	mu          sync.Mutex
+	spans       []*entity.UploadSpan
+	files       []*entity.UploadFile
+	statusCodes []int
+	// closed and replaced once spans are received, to wake up WaitForSpans
+	received chan struct{}
+}
+
+// New starts a TestServer, which is closed when the test and all its subtests complete.
+func New(t testing.TB) *TestServer {
+	s := &TestServer{
+		t:        t,
+		received: make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathIngestTrace, s.handleIngestTrace)
+	mux.HandleFunc(PathUploadFile, s.handleUploadFile)
+	s.server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// URL returns the base URL of the server, such as http://127.0.0.1:12345.
+func (s *TestServer) URL() string {
+	return s.server.URL
+}
+
+// Close shuts down the server, it is called automatically when the test completes.
+func (s *TestServer) Close() {
+	s.server.Close()
+}
+
+// SetStatusCodes set HTTP status codes responded to the next requests in order, used to test retry logic.
+// Requests responded with status code other than 200 are not recorded. Requests after them are responded with 200.
+func (s *TestServer) SetStatusCodes(codes ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statusCodes = append([]int(nil), codes...)
+}
+
+// Spans returns a copy of received spans in receive order.
+func (s *TestServer) Spans() []*entity.UploadSpan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]*entity.UploadSpan, len(s.spans))
+	copy(res, s.spans)
+	return res
+}
+
+// Files returns a copy of received files in receive order. TosKey of files is the name of uploaded file.
+func (s *TestServer) Files() []*entity.UploadFile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]*entity.UploadFile, len(s.files))
+	copy(res, s.files)
+	return res
+}
+
+// Reset clears received spans and files, and status codes set by SetStatusCodes.
+func (s *TestServer) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spans = nil
+	s.files = nil
+	s.statusCodes = nil
+}
+
+// WaitForSpans blocks until at least n spans are received and returns received spans. The test fails
+// immediately if the spans are not received in timeout, so it must be called from the goroutine running the test.
+func (s *TestServer) WaitForSpans(n int, timeout time.Duration) []*entity.UploadSpan {
+	s.t.Helper()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		count, received := len(s.spans), s.received
+		s.mu.Unlock()
+		if count >= n {
+			return s.Spans()
+		}
+		select {
+		case <-received:
+		case <-timer.C:
+			s.t.Fatalf("testserver: %d spans received in %v, want at least %d", count, timeout, n)
+			return nil
+		}
+	}
+}
+
+func (s *TestServer) handleIngestTrace(w http.ResponseWriter, r *http.Request) {
+	if code := s.nextStatusCode(); code != http.StatusOK {
+		writeResponse(w, code, code, http.StatusText(code))
+		return
+	}
+	data := struct {
+		Spans []*entity.UploadSpan `json:"spans"`
+	}{}
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &data)
+	}
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, http.StatusBadRequest, fmt.Sprintf("invalid spans: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	for _, span := range data.Spans {
+		if span != nil {
+			s.spans = append(s.spans, span)
+		}
+	}
+	close(s.received)
+	s.received = make(chan struct{})
+	s.mu.Unlock()
+	writeResponse(w, http.StatusOK, 0, "")
+}
+
+func (s *TestServer) handleUploadFile(w http.ResponseWriter, r *http.Request) {
+	if code := s.nextStatusCode(); code != http.StatusOK {
+		writeResponse(w, code, code, http.StatusText(code))
+		return
+	}
+	if err := r.ParseMultipartForm(maxUploadFileMemory); err != nil {
+		writeResponse(w, http.StatusBadRequest, http.StatusBadRequest, fmt.Sprintf("invalid form: %v", err))
+		return
+	}
+	f, header, err := r.FormFile("file")
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, http.StatusBadRequest, fmt.Sprintf("invalid file: %v", err))
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, http.StatusBadRequest, fmt.Sprintf("invalid file: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	s.files = append(s.files, &entity.UploadFile{
+		TosKey:  header.Filename,
+		Data:    string(data),
+		SpaceID: r.FormValue("workspace_id"),
+	})
+	s.mu.Unlock()
+	writeResponse(w, http.StatusOK, 0, "")
+}
+
+func (s *TestServer) nextStatusCode() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.statusCodes) == 0 {
+		return http.StatusOK
+	}
+	code := s.statusCodes[0]
+	s.statusCodes = s.statusCodes[1:]
+	return code
+}
+
+// writeResponse writes the response in the format of CozeLoop OpenAPI, where code 0 means success.
+func writeResponse(w http.ResponseWriter, statusCode, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "msg": msg})
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package testserver
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alva-ai/cozeloop-go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTestServer(t *testing.T) {
+	Convey("TestServer", t, func() {
+		ctx := context.Background()
+		srv := New(t)
+		client, err := cozeloop.NewClient(
+			cozeloop.WithWorkspaceID("testserver"),
+			cozeloop.WithAPIToken("token"),
+			cozeloop.WithAPIBaseURL(srv.URL()),
+		)
+		So(err, ShouldBeNil)
+		defer client.Close(ctx)
+
+		Convey("should record uploaded spans", func() {
+			ctx, root := client.StartSpan(ctx, "agent", "agent")
+			_, child := client.StartSpan(ctx, "llm", "model")
+			child.Finish(ctx)
+			root.Finish(ctx)
+			client.Flush(ctx)
+
+			spans := srv.WaitForSpans(2, 5*time.Second)
+			So(len(spans), ShouldEqual, 2)
+			So(spans[0].SpanName, ShouldEqual, "llm")
+			So(spans[0].ParentID, ShouldEqual, root.GetSpanID())
+			So(spans[1].WorkspaceID, ShouldEqual, "testserver")
+		})
+
+		Convey("should record spans retried after error status codes", func() {
+			srv.SetStatusCodes(http.StatusServiceUnavailable)
+			_, span := client.StartSpan(ctx, "retried", "custom")
+			span.Finish(ctx)
+			client.Flush(ctx)
+
+			spans := srv.WaitForSpans(1, 5*time.Second)
+			So(spans[0].SpanName, ShouldEqual, "retried")
+		})
+
+		Convey("should record uploaded files", func() {
+			body := &bytes.Buffer{}
+			w := multipart.NewWriter(body)
+			part, err := w.CreateFormFile("file", "tos_key")
+			So(err, ShouldBeNil)
+			_, _ = part.Write([]byte("content"))
+			So(w.WriteField("workspace_id", "testserver"), ShouldBeNil)
+			So(w.Close(), ShouldBeNil)
+
+			resp, err := http.Post(srv.URL()+PathUploadFile, w.FormDataContentType(), body)
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			files := srv.Files()
+			So(len(files), ShouldEqual, 1)
+			So(files[0].TosKey, ShouldEqual, "tos_key")
+			So(files[0].Data, ShouldEqual, "content")
+			So(files[0].SpaceID, ShouldEqual, "testserver")
+		})
+
+		Convey("should respond status codes in order", func() {
+			srv.SetStatusCodes(http.StatusTooManyRequests, http.StatusInternalServerError)
+			for _, code := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusOK} {
+				resp, err := http.Post(srv.URL()+PathIngestTrace, "application/json", strings.NewReader(`{"spans":[{"span_id":"1"}]}`))
+				So(err, ShouldBeNil)
+				resp.Body.Close()
+				So(resp.StatusCode, ShouldEqual, code)
+			}
+			So(len(srv.Spans()), ShouldEqual, 1)
+			srv.Reset()
+			So(srv.Spans(), ShouldBeEmpty)
+		})
+	})
+}
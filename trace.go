@@ -22,6 +22,17 @@ type TraceClient interface {
 	GetSpanFromHeader(ctx context.Context, header map[string]string) SpanContext
 	// Flush Force the reporting of spans in the queue.
 	Flush(ctx context.Context)
+	// GroupSpans Create a synthetic span under the parent of spans, and move spans under it, used to fold
+	// many sibling spans such as parallel tool calls. Spans must be of the same trace and parent, and not finished.
+	// A noop GroupSpan is returned if any span is noop, e.g. dropped by sampler, or the group span is dropped by
+	// span quota. The statistics of spans are set when the group span is finished, so finish it after the grouped spans.
+	GroupSpans(ctx context.Context, groupName string, spans []Span) (GroupSpan, error)
+	// StartRetrySpan Start a span of an attempt among retries, as child of the span in ctx, with `retry.attempt`
+	// and `retry.max_attempts` tags. Use Span.SetRetryContext to set the backoff waited before the attempt.
+	StartRetrySpan(ctx context.Context, name, spanType string, attempt, maxAttempts int) (context.Context, Span)
+	// NextAgentRound Increment the round of agentic loop in ctx, starting from 1. Spans started from the
+	// returned ctx are tagged with `agent.round`, call it at the beginning of each iteration.
+	NextAgentRound(ctx context.Context) context.Context
 }
 
 type startSpanOptions = trace.StartSpanOptions
@@ -29,6 +40,10 @@ type startSpanOptions = trace.StartSpanOptions
 // StartSpanOption is used to set options for the span.
 type StartSpanOption = func(o *startSpanOptions)
 
+// SpanFactory creates the span returned by StartSpan, see WithSpanFactory.
+// parentID is "0" for root spans.
+type SpanFactory func(ctx context.Context, name, spanType, traceID, spanID, parentID string) Span
+
 // WithStartTime Set the start time of the span.
 // This field is optional. If not specified, the time when StartSpan is called will be used as the default.
 func WithStartTime(t time.Time) StartSpanOption {
@@ -73,6 +88,38 @@ func WithSpanWorkspaceID(workspaceID string) StartSpanOption {
 	}
 }
 
+// WithMaxConversationHistoryMessages Set the max number of messages set by Span.SetConversationHistory.
+// This field is optional. If specified, only the most recent n messages are kept. Default is unlimited.
+func WithMaxConversationHistoryMessages(n int) StartSpanOption {
+	return func(ops *startSpanOptions) {
+		ops.MaxConversationMessages = n
+	}
+}
+
+// WithChatMessageFormat Set FileExporter to write input and output of the span as `**role**: content` in markdown
+// instead of raw JSON, if they are chat messages such as those set by Span.SetInputMessages.
+// This field is optional. Default is raw JSON.
+func WithChatMessageFormat() StartSpanOption {
+	return func(ops *startSpanOptions) {
+		ops.ChatMessageFormat = true
+	}
+}
+
+// WithChainStepParent Set the workflow span of a pipeline step as the parent span of the span.
+// This field is optional. If specified, the span is a child of parentSpan, and the name of parentSpan
+// is used as the prefix of span name set by Span.SetChainStep.
+func WithChainStepParent(parentSpan Span) StartSpanOption {
+	return func(ops *startSpanOptions) {
+		if parentSpan == nil {
+			return
+		}
+		WithChildOf(parentSpan)(ops)
+		if s, ok := parentSpan.(interface{ getInternalSpan() *trace.Span }); ok {
+			ops.ChainParentName = s.getInternalSpan().GetSpanName()
+		}
+	}
+}
+
 // WithSpanID Set the spanID of the span.
 // Only use when specifying a SpanID! By default, SDK can automatically generate a SpanID
 // SpanID must be a combination of 16 digits and letters.
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+// Package tracetest provides utilities for testing code instrumented with cozeloop trace.
+package tracetest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	"github.com/alva-ai/cozeloop-go/internal/trace"
+)
+
+var _ trace.Exporter = (*RecorderExporter)(nil)
+
+// RecorderExporter records exported spans and files in memory, used in tests with cozeloop.WithExporter.
+// Spans are copied when recorded, so they are kept intact when spans are reused by cozeloop.WithSpanPooling.
+type RecorderExporter struct {
+	mu    sync.Mutex
+	spans []*entity.UploadSpan
+	files []*entity.UploadFile
+}
+
+// NewRecorderExporter creates a new RecorderExporter.
+func NewRecorderExporter() *RecorderExporter {
+	return &RecorderExporter{}
+}
+
+func (e *RecorderExporter) ExportSpans(ctx context.Context, spans []*entity.UploadSpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, span := range spans {
+		if span != nil {
+			e.spans = append(e.spans, trace.CloneUploadSpan(span))
+		}
+	}
+	return nil
+}
+
+func (e *RecorderExporter) ExportFiles(ctx context.Context, files []*entity.UploadFile) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, file := range files {
+		if file != nil {
+			e.files = append(e.files, file)
+		}
+	}
+	return nil
+}
+
+// Spans returns a copy of recorded spans in export order.
+func (e *RecorderExporter) Spans() []*entity.UploadSpan {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	res := make([]*entity.UploadSpan, len(e.spans))
+	copy(res, e.spans)
+	return res
+}
+
+// Files returns a copy of recorded files in export order.
+func (e *RecorderExporter) Files() []*entity.UploadFile {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	res := make([]*entity.UploadFile, len(e.files))
+	copy(res, e.files)
+	return res
+}
+
+// Reset clears recorded spans and files.
+func (e *RecorderExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+	e.files = nil
+}
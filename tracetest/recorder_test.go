@@ -0,0 +1,30 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package tracetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRecorderExporter(t *testing.T) {
+	Convey("RecorderExporter should keep recorded spans intact after they are reused", t, func() {
+		ctx := context.Background()
+		e := NewRecorderExporter()
+		span := &entity.UploadSpan{SpanID: "span1", SpanName: "chat", TagsString: map[string]string{"model": "gpt"}}
+		So(e.ExportSpans(ctx, []*entity.UploadSpan{span, nil}), ShouldBeNil)
+
+		// cleared and reused by span pooling
+		*span = entity.UploadSpan{TagsString: span.TagsString}
+		delete(span.TagsString, "model")
+
+		spans := e.Spans()
+		So(len(spans), ShouldEqual, 1)
+		So(spans[0].SpanName, ShouldEqual, "chat")
+		So(spans[0].TagsString, ShouldResemble, map[string]string{"model": "gpt"})
+	})
+}
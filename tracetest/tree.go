@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package tracetest
+
+import (
+	"fmt"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+)
+
+// SpanNode is a node of span tree.
+type SpanNode struct {
+	Span     *entity.UploadSpan
+	Children []*SpanNode
+}
+
+// BuildTree reconstructs parent-child hierarchy from flat span list, and returns root spans
+// whose ParentID is empty or "0". Order of roots and children follows the order of spans.
+// It returns error if ParentID of any span doesn't resolve, or span id is duplicated.
+func BuildTree(spans []*entity.UploadSpan) ([]*SpanNode, error) {
+	nodes := make(map[string]*SpanNode, len(spans))
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		if _, ok := nodes[span.SpanID]; ok {
+			return nil, fmt.Errorf("duplicated span id: %s", span.SpanID)
+		}
+		nodes[span.SpanID] = &SpanNode{Span: span}
+	}
+
+	var roots []*SpanNode
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		node := nodes[span.SpanID]
+		if isRootSpan(span) {
+			roots = append(roots, node)
+			continue
+		}
+		parent, ok := nodes[span.ParentID]
+		if !ok {
+			return nil, fmt.Errorf("parent id [%s] of span [%s] doesn't resolve", span.ParentID, span.SpanID)
+		}
+		parent.Children = append(parent.Children, node)
+	}
+	return roots, nil
+}
+
+func isRootSpan(span *entity.UploadSpan) bool {
+	return span.ParentID == "" || span.ParentID == "0"
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2025 Bytedance Ltd. and/or its affiliates
+// SPDX-License-Identifier: MIT
+
+package tracetest
+
+import (
+	"testing"
+
+	"github.com/alva-ai/cozeloop-go/entity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBuildTree(t *testing.T) {
+	Convey("BuildTree", t, func() {
+		Convey("should build hierarchy from flat spans", func() {
+			// spans are exported in finish order, children first
+			spans := []*entity.UploadSpan{
+				{SpanID: "llm", ParentID: "agent", SpanName: "llm_call"},
+				{SpanID: "tool", ParentID: "agent", SpanName: "tool_call"},
+				{SpanID: "agent", ParentID: "0", SpanName: "agent"},
+				{SpanID: "other", ParentID: "", SpanName: "other_root"},
+			}
+			tree, err := BuildTree(spans)
+			So(err, ShouldBeNil)
+			So(len(tree), ShouldEqual, 2)
+			So(tree[0].Span.SpanName, ShouldEqual, "agent")
+			So(len(tree[0].Children), ShouldEqual, 2)
+			So(tree[0].Children[0].Span.SpanName, ShouldEqual, "llm_call")
+			So(tree[0].Children[1].Span.SpanName, ShouldEqual, "tool_call")
+			So(tree[1].Span.SpanName, ShouldEqual, "other_root")
+		})
+
+		Convey("should return error if parent doesn't resolve", func() {
+			_, err := BuildTree([]*entity.UploadSpan{{SpanID: "child", ParentID: "missing"}})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("should return error if span id is duplicated", func() {
+			_, err := BuildTree([]*entity.UploadSpan{{SpanID: "a"}, {SpanID: "a"}})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}